@@ -6,6 +6,7 @@ import (
 	"bufio"
 	"fmt"
 	"io"
+	"math"
 	"regexp"
 	"strconv"
 	"strings"
@@ -360,7 +361,7 @@ func WelchTTest(baseline, current []float64) (tStat, df float64) {
 		return 0, 0
 	}
 
-	tStat = diff / sqrt(se)
+	tStat = diff / math.Sqrt(se)
 
 	// Welch-Satterthwaite degrees of freedom
 	n1, n2 := float64(len(baseline)), float64(len(current))
@@ -376,49 +377,17 @@ func WelchTTest(baseline, current []float64) (tStat, df float64) {
 	return tStat, df
 }
 
-// TwoTailedPValue approximates the two-tailed p-value from t-statistic and df
-// Using a simple approximation (not exact, but good enough for benchmark comparison)
+// TwoTailedPValue returns the two-tailed p-value of a t-statistic with df
+// degrees of freedom, P(|T| > |t|), from the Student's t distribution.
 func TwoTailedPValue(tStat, df float64) float64 {
-	if df <= 0 {
+	if df <= 0 || math.IsNaN(tStat) {
 		return 1.0
 	}
-
-	// For large df, approximate with normal distribution
-	if df > 30 {
-		// Two-tailed test: P(|Z| > |t|) = 2 * (1 - Φ(|t|))
-		// Using approximation: 1 - Φ(z) ≈ 0.5 * exp(-0.717*z - 0.416*z*z)
-		z := abs(tStat)
-		if z > 3.5 {
-			return 0.0005 // Very significant
-		}
-		p := 0.5 * exp(-0.717*z-0.416*z*z)
-		return 2 * p
-	}
-
-	// For small df, use rough t-distribution approximation
-	// This is a simplified version - for production, use a proper implementation
-	criticalValues := map[float64]map[float64]float64{
-		5:  {1.0: 0.37, 2.0: 0.10, 3.0: 0.03},
-		10: {1.0: 0.34, 2.0: 0.07, 3.0: 0.01},
-		20: {1.0: 0.33, 2.0: 0.06, 3.0: 0.005},
-	}
-
-	// Find closest df
-	closestDF := 5.0
-	if df > 15 {
-		closestDF = 20
-	} else if df > 7 {
-		closestDF = 10
-	}
-
-	absT := abs(tStat)
-	for t, p := range criticalValues[closestDF] {
-		if absT <= t {
-			return p
-		}
+	if math.IsInf(tStat, 0) {
+		return 0
 	}
-
-	return 0.001 // Very significant
+	// P(|T| > |t|) = I_x(df/2, 1/2) with x = df/(df+t²).
+	return regularizedIncompleteBeta(df/2, 0.5, df/(df+tStat*tStat))
 }
 
 // IsSignificant returns true if p < alpha (typically 0.05)
@@ -450,41 +419,68 @@ func variance(data []float64, mean float64) float64 {
 	return sum / float64(len(data)-1) // Sample variance
 }
 
-func sqrt(x float64) float64 {
+// regularizedIncompleteBeta computes I_x(a, b) with the continued fraction
+// from Numerical Recipes (betai/betacf).
+func regularizedIncompleteBeta(a, b, x float64) float64 {
 	if x <= 0 {
 		return 0
 	}
-	// Simple Newton-Raphson square root
-	z := x
-	for i := 0; i < 10; i++ {
-		z = (z + x/z) / 2
+	if x >= 1 {
+		return 1
 	}
-	return z
-}
-
-func abs(x float64) float64 {
-	if x < 0 {
-		return -x
+	lga, _ := math.Lgamma(a)
+	lgb, _ := math.Lgamma(b)
+	lgab, _ := math.Lgamma(a + b)
+	front := math.Exp(lgab - lga - lgb + a*math.Log(x) + b*math.Log(1-x))
+	if x < (a+1)/(a+b+2) {
+		return front * betaContinuedFraction(a, b, x) / a
 	}
-	return x
+	return 1 - front*betaContinuedFraction(b, a, 1-x)/b
 }
 
-func exp(x float64) float64 {
-	// Simple Taylor series approximation for e^x
-	if x == 0 {
-		return 1.0
-	}
-	if x < -10 {
-		return 0
-	}
-	result := 1.0
-	term := 1.0
-	for i := 1; i < 20; i++ {
-		term *= x / float64(i)
-		result += term
-		if term < 1e-15 {
+func betaContinuedFraction(a, b, x float64) float64 {
+	const (
+		maxIterations = 300
+		epsilon       = 1e-15
+		tiny          = 1e-300
+	)
+	c := 1.0
+	d := 1 - (a+b)*x/(a+1)
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	h := d
+	for m := 1; m <= maxIterations; m++ {
+		fm := float64(m)
+		// Even step.
+		num := fm * (b - fm) * x / ((a + 2*fm - 1) * (a + 2*fm))
+		d = 1 + num*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + num/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		h *= d * c
+		// Odd step.
+		num = -(a + fm) * (a + b + fm) * x / ((a + 2*fm) * (a + 2*fm + 1))
+		d = 1 + num*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + num/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < epsilon {
 			break
 		}
 	}
-	return result
+	return h
 }
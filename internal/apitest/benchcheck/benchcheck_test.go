@@ -5,6 +5,7 @@ package benchcheck
 
 import (
 	"bytes"
+	"math"
 	"strings"
 	"testing"
 )
@@ -484,18 +485,24 @@ func TestTwoTailedPValue(t *testing.T) {
 		name  string
 		tStat float64
 		df    float64
+		want  float64
 	}{
-		{"small df", 2.0, 10},
-		{"zero df", 2.0, 0},
-		{"very large t", 5.0, 50},
+		{"small df", 2.0, 10, 0.07339},
+		{"zero df", 2.0, 0, 1},
+		{"very large t", 5.0, 50, 0.0000075},
+		{"critical value df 10", 2.228, 10, 0.05},
+		{"critical value df 3", -3.182, 3, 0.05},
+		{"normal limit", 1.96, 1e6, 0.05},
+		{"zero t", 0, 5, 1},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			// Just verify it doesn't panic
-			// Note: The approximation has known limitations for certain ranges
-			_ = TwoTailedPValue(tt.tStat, tt.df)
+			got := TwoTailedPValue(tt.tStat, tt.df)
+			if math.Abs(got-tt.want) > 0.0005 {
+				t.Errorf("TwoTailedPValue(%v, %v) = %.6f, want %.6f", tt.tStat, tt.df, got, tt.want)
+			}
 		})
 	}
 }
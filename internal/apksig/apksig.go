@@ -0,0 +1,187 @@
+// Package apksig verifies the signatures on APKs and App Bundles: APK
+// Signature Scheme v2, v3 and v3.1 blocks, and JAR (v1) signatures under
+// META-INF. It reports the signer certificates, their SHA-256 fingerprints,
+// and the v3 key rotation lineage.
+package apksig
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Signature scheme names.
+const (
+	SchemeV1  = "v1"
+	SchemeV2  = "v2"
+	SchemeV3  = "v3"
+	SchemeV31 = "v3.1"
+)
+
+// Certificate summarizes a signer certificate.
+type Certificate struct {
+	SHA256    string            `json:"sha256"`
+	Subject   string            `json:"subject"`
+	Issuer    string            `json:"issuer"`
+	NotBefore time.Time         `json:"notBefore"`
+	NotAfter  time.Time         `json:"notAfter"`
+	X509      *x509.Certificate `json:"-"`
+}
+
+// Signer is one verified signer of a scheme.
+type Signer struct {
+	Certificate Certificate `json:"certificate"`
+	// MinSDK and MaxSDK bound the platform versions a v3 signer applies to.
+	MinSDK int `json:"minSdk,omitempty"`
+	MaxSDK int `json:"maxSdk,omitempty"`
+	// Lineage is the v3 proof-of-rotation chain, oldest key first and ending
+	// with this signer's certificate. Empty when the key was never rotated.
+	Lineage []Certificate `json:"lineage,omitempty"`
+}
+
+// SchemeResult is the outcome of verifying one signature scheme.
+type SchemeResult struct {
+	Scheme   string   `json:"scheme"`
+	Verified bool     `json:"verified"`
+	Error    string   `json:"error,omitempty"`
+	Signers  []Signer `json:"signers,omitempty"`
+}
+
+// Result lists the signature schemes found in an artifact, in scheme order.
+type Result struct {
+	Schemes []SchemeResult `json:"schemes"`
+}
+
+// Signed reports whether any signature scheme is present.
+func (r *Result) Signed() bool {
+	return len(r.Schemes) > 0
+}
+
+// Verified reports whether the artifact is signed and every scheme present
+// verified.
+func (r *Result) Verified() bool {
+	if !r.Signed() {
+		return false
+	}
+	for _, s := range r.Schemes {
+		if !s.Verified {
+			return false
+		}
+	}
+	return true
+}
+
+// Scheme returns the result for the named scheme, or nil when absent.
+func (r *Result) Scheme(name string) *SchemeResult {
+	for i := range r.Schemes {
+		if r.Schemes[i].Scheme == name {
+			return &r.Schemes[i]
+		}
+	}
+	return nil
+}
+
+// Names returns the names of the schemes present.
+func (r *Result) Names() []string {
+	out := make([]string, 0, len(r.Schemes))
+	for _, s := range r.Schemes {
+		out = append(out, s.Scheme)
+	}
+	return out
+}
+
+// Signer returns the signer a platform would attribute the artifact to: the
+// newest verified scheme wins, so a v3 rotated key takes precedence over the
+// original key in v2 and v1. It returns nil when nothing verified.
+func (r *Result) Signer() *Signer {
+	for i := len(r.Schemes) - 1; i >= 0; i-- {
+		s := r.Schemes[i]
+		if s.Verified && len(s.Signers) > 0 {
+			return &s.Signers[len(s.Signers)-1]
+		}
+	}
+	return nil
+}
+
+// Verify opens the APK or AAB at path and verifies every signature scheme
+// it carries. Verification failures are reported per scheme in the result;
+// the error is reserved for files that cannot be read as zip archives.
+func Verify(path string) (*Result, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	zr, err := zip.NewReader(f, info.Size())
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a valid APK/AAB (zip): %w", path, err)
+	}
+
+	result := &Result{Schemes: make([]SchemeResult, 0)}
+	if jar := verifyJAR(zr); jar != nil {
+		result.Schemes = append(result.Schemes, *jar)
+	}
+
+	layout, err := locateSigningBlock(f, info.Size())
+	if err != nil {
+		return nil, err
+	}
+	if layout.blocks != nil {
+		content := &contentDigester{r: f, layout: layout, cache: make(map[uint32][]byte)}
+		for _, id := range schemeBlockOrder {
+			value, ok := layout.blocks[id]
+			if !ok {
+				continue
+			}
+			result.Schemes = append(result.Schemes, verifySchemeBlock(schemeBlockIDs[id], value, content))
+		}
+	}
+	return result, nil
+}
+
+// NewCertificate summarizes an X.509 certificate.
+func NewCertificate(cert *x509.Certificate) Certificate {
+	return Certificate{
+		SHA256:    Fingerprint(cert.Raw),
+		Subject:   cert.Subject.String(),
+		Issuer:    cert.Issuer.String(),
+		NotBefore: cert.NotBefore,
+		NotAfter:  cert.NotAfter,
+		X509:      cert,
+	}
+}
+
+// Fingerprint formats the SHA-256 of a DER certificate the way keytool and
+// the Play Console print it: upper-case hex pairs joined by colons.
+func Fingerprint(der []byte) string {
+	sum := sha256.Sum256(der)
+	return formatHex(sum[:])
+}
+
+// NormalizeFingerprint accepts a SHA-256 fingerprint with or without colons
+// or spaces, in either case, and returns it in Fingerprint form.
+func NormalizeFingerprint(s string) (string, error) {
+	cleaned := strings.NewReplacer(":", "", " ", "", "-", "").Replace(strings.TrimSpace(s))
+	raw, err := hex.DecodeString(cleaned)
+	if err != nil || len(raw) != sha256.Size {
+		return "", fmt.Errorf("invalid SHA-256 fingerprint %q: want 32 hex bytes", s)
+	}
+	return formatHex(raw), nil
+}
+
+func formatHex(b []byte) string {
+	parts := make([]string, len(b))
+	for i, c := range b {
+		parts[i] = fmt.Sprintf("%02X", c)
+	}
+	return strings.Join(parts, ":")
+}
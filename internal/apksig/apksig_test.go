@@ -0,0 +1,252 @@
+//go:build unit
+// +build unit
+
+package apksig
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/artifact/artifacttest"
+)
+
+func fixtureManifest() *artifacttest.Element {
+	return artifacttest.Manifest("com.example.app", 42)
+}
+
+func TestVerify_Unsigned(t *testing.T) {
+	res, err := Verify(artifacttest.WriteAPK(t, fixtureManifest()))
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if res.Signed() || res.Verified() || res.Signer() != nil {
+		t.Errorf("unsigned APK reported as signed: %+v", res)
+	}
+}
+
+func TestVerify_JARSignedBundle(t *testing.T) {
+	signer := artifacttest.NewSigner(t, "upload")
+	path := artifacttest.WriteAAB(t, fixtureManifest())
+	signer.SignJAR(t, path)
+
+	res, err := Verify(path)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if !res.Verified() || !reflect.DeepEqual(res.Names(), []string{SchemeV1}) {
+		t.Fatalf("result = %+v", res)
+	}
+	got := res.Signer()
+	if got == nil || got.Certificate.SHA256 != signer.Fingerprint() || !strings.Contains(got.Certificate.Subject, "upload") {
+		t.Errorf("signer = %+v, want %s", got, signer.Fingerprint())
+	}
+}
+
+func TestVerify_V1V2V3(t *testing.T) {
+	signer := artifacttest.NewSigner(t, "app")
+	path := artifacttest.WriteAPK(t, fixtureManifest())
+	signer.SignJAR(t, path)
+	signer.SignAPK(t, path, artifacttest.BlockIDV2, artifacttest.BlockIDV3)
+
+	res, err := Verify(path)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if want := []string{SchemeV1, SchemeV2, SchemeV3}; !reflect.DeepEqual(res.Names(), want) {
+		t.Fatalf("schemes = %v, want %v", res.Names(), want)
+	}
+	for _, s := range res.Schemes {
+		if !s.Verified {
+			t.Errorf("%s not verified: %s", s.Scheme, s.Error)
+		}
+	}
+	v3 := res.Scheme(SchemeV3)
+	if v3.Signers[0].MinSDK != 24 || len(v3.Signers[0].Lineage) != 0 {
+		t.Errorf("v3 signer = %+v", v3.Signers[0])
+	}
+}
+
+func TestVerify_RotationLineage(t *testing.T) {
+	old := artifacttest.NewSigner(t, "original")
+	current := artifacttest.NewSigner(t, "rotated")
+	current.Previous = old
+	// As apksigner does after rotation: v1 and v2 stay with the original key
+	// so older platforms keep installing updates, v3 carries the new key.
+	path := artifacttest.WriteAPK(t, fixtureManifest())
+	old.SignJAR(t, path)
+	signTwoKeys(t, path, old, current)
+
+	res, err := Verify(path)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if !res.Verified() {
+		t.Fatalf("result = %+v", res)
+	}
+	got := res.Signer()
+	if got.Certificate.SHA256 != current.Fingerprint() {
+		t.Errorf("effective signer = %s, want rotated key %s", got.Certificate.SHA256, current.Fingerprint())
+	}
+	if len(got.Lineage) != 2 || got.Lineage[0].SHA256 != old.Fingerprint() || got.Lineage[1].SHA256 != current.Fingerprint() {
+		t.Errorf("lineage = %+v", got.Lineage)
+	}
+	if v2 := res.Scheme(SchemeV2); v2.Signers[0].Certificate.SHA256 != old.Fingerprint() {
+		t.Errorf("v2 signer = %s, want original key", v2.Signers[0].Certificate.SHA256)
+	}
+}
+
+// signTwoKeys signs v2 with old and v3 with current in one signing block.
+func signTwoKeys(t *testing.T, path string, old, current *artifacttest.Signer) {
+	t.Helper()
+	v2 := copyFile(t, path)
+	old.SignAPK(t, v2, artifacttest.BlockIDV2)
+	v3 := copyFile(t, path)
+	current.SignAPK(t, v3, artifacttest.BlockIDV3)
+
+	artifacttest.InsertSigningBlock(t, path, map[uint32][]byte{
+		blockIDV2: blockPairs(t, v2)[blockIDV2],
+		blockIDV3: blockPairs(t, v3)[blockIDV3],
+	})
+}
+
+func copyFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.CreateTemp(t.TempDir(), "*.apk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = f.Close()
+	out := f.Name()
+	if err := os.WriteFile(out, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func blockPairs(t *testing.T, path string) map[uint32][]byte {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	info, _ := f.Stat()
+	layout, err := locateSigningBlock(f, info.Size())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return layout.blocks
+}
+
+func TestVerify_TamperedContent(t *testing.T) {
+	signer := artifacttest.NewSigner(t, "app")
+
+	t.Run("v2 content digest", func(t *testing.T) {
+		path := artifacttest.WriteAPK(t, fixtureManifest(), artifacttest.Entry{Name: "assets/data.txt", Data: []byte("original"), Stored: true})
+		signer.SignAPK(t, path, artifacttest.BlockIDV2)
+		data, _ := os.ReadFile(path)
+		i := bytes.Index(data, []byte("original"))
+		copy(data[i:], "tampered")
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		res, err := Verify(path)
+		if err != nil {
+			t.Fatalf("Verify: %v", err)
+		}
+		v2 := res.Scheme(SchemeV2)
+		if v2 == nil || v2.Verified || !strings.Contains(v2.Error, "content digest mismatch") {
+			t.Errorf("v2 = %+v", v2)
+		}
+		if res.Verified() {
+			t.Error("tampered APK reported as verified")
+		}
+	})
+
+	t.Run("v1 entry digest", func(t *testing.T) {
+		path := artifacttest.WriteAAB(t, fixtureManifest())
+		signer.SignJAR(t, path)
+		rewriteEntry(t, path, "base/dex/classes.dex", []byte("patched"))
+		res, err := Verify(path)
+		if err != nil {
+			t.Fatalf("Verify: %v", err)
+		}
+		v1 := res.Scheme(SchemeV1)
+		if v1 == nil || v1.Verified || !strings.Contains(v1.Error, "classes.dex") {
+			t.Errorf("v1 = %+v", v1)
+		}
+	})
+
+	t.Run("v1 unsigned entry", func(t *testing.T) {
+		path := artifacttest.WriteAAB(t, fixtureManifest())
+		signer.SignJAR(t, path)
+		rewriteEntry(t, path, "base/assets/extra.bin", []byte("added"))
+		res, _ := Verify(path)
+		if v1 := res.Scheme(SchemeV1); v1.Verified || !strings.Contains(v1.Error, "not covered") {
+			t.Errorf("v1 = %+v", v1)
+		}
+	})
+}
+
+// rewriteEntry replaces (or adds) one zip entry, keeping the others intact.
+func rewriteEntry(t *testing.T, path, name string, data []byte) {
+	t.Helper()
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range zr.File {
+		if f.Name == name {
+			continue
+		}
+		if err := zw.Copy(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w, _ := zw.Create(name)
+	_, _ = w.Write(data)
+	_ = zw.Close()
+	_ = zr.Close()
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseJARManifest_Continuation(t *testing.T) {
+	data := []byte("Manifest-Version: 1.0\r\n\r\nName: res/a-very-long-directory-name/with-a-file-name-that-wraps-past-seven\r\n ty-two.xml\r\nSHA-256-Digest: abc=\r\n\r\n")
+	m, err := parseJARManifest(data)
+	if err != nil {
+		t.Fatalf("parseJARManifest: %v", err)
+	}
+	name := "res/a-very-long-directory-name/with-a-file-name-that-wraps-past-seventy-two.xml"
+	section, ok := m.entries[name]
+	if !ok {
+		t.Fatalf("entries = %v", m.entries)
+	}
+	if !bytes.HasSuffix(section.raw, []byte("abc=\r\n\r\n")) || !bytes.HasPrefix(section.raw, []byte("Name: ")) {
+		t.Errorf("raw section = %q", section.raw)
+	}
+}
+
+func TestNormalizeFingerprint(t *testing.T) {
+	want := strings.TrimSuffix(strings.Repeat("AB:", 32), ":")
+	for _, in := range []string{want, strings.ToLower(want), strings.Repeat("ab", 32), " " + strings.ReplaceAll(want, ":", " ") + " "} {
+		got, err := NormalizeFingerprint(in)
+		if err != nil || got != want {
+			t.Errorf("NormalizeFingerprint(%q) = %q, %v", in, got, err)
+		}
+	}
+	if _, err := NormalizeFingerprint("AB:CD"); err == nil {
+		t.Error("short fingerprint accepted")
+	}
+}
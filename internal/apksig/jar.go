@@ -0,0 +1,421 @@
+package apksig
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	_ "crypto/sha1" // register SHA-1 for crypto.Hash; legacy JAR signatures use it
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"math/big"
+	"path"
+	"sort"
+	"strings"
+)
+
+const jarManifestPath = "META-INF/MANIFEST.MF"
+
+// jarDigestAttrs maps JAR digest attribute prefixes to hashes, strongest
+// first.
+var jarDigestAttrs = []struct {
+	name string
+	hash crypto.Hash
+}{
+	{"SHA-512", crypto.SHA512},
+	{"SHA-384", crypto.SHA384},
+	{"SHA-256", crypto.SHA256},
+	{"SHA1", crypto.SHA1},
+	{"SHA-1", crypto.SHA1},
+}
+
+// isJARSignatureFile reports whether name is part of the JAR signature
+// itself and therefore not listed in the manifest.
+func isJARSignatureFile(name string) bool {
+	dir, base := path.Split(name)
+	if dir != "META-INF/" {
+		return false
+	}
+	if name == jarManifestPath {
+		return true
+	}
+	switch strings.ToUpper(path.Ext(base)) {
+	case ".SF", ".RSA", ".DSA", ".EC":
+		return true
+	}
+	return strings.HasPrefix(strings.ToUpper(base), "SIG-")
+}
+
+// verifyJAR verifies the v1 signature, or returns nil when there is none.
+func verifyJAR(zr *zip.Reader) *SchemeResult {
+	files := make(map[string]*zip.File, len(zr.File))
+	var blocks []string
+	for _, f := range zr.File {
+		files[f.Name] = f
+		dir, base := path.Split(f.Name)
+		if dir != "META-INF/" {
+			continue
+		}
+		switch strings.ToUpper(path.Ext(base)) {
+		case ".RSA", ".DSA", ".EC":
+			blocks = append(blocks, f.Name)
+		}
+	}
+	if len(blocks) == 0 {
+		return nil
+	}
+	sort.Strings(blocks)
+
+	result := &SchemeResult{Scheme: SchemeV1}
+	fail := func(err error) *SchemeResult {
+		result.Error = err.Error()
+		result.Signers = nil
+		return result
+	}
+
+	manifestData, err := readZipFile(files[jarManifestPath])
+	if err != nil {
+		return fail(fmt.Errorf("read %s: %w", jarManifestPath, err))
+	}
+	manifest, err := parseJARManifest(manifestData)
+	if err != nil {
+		return fail(fmt.Errorf("parse %s: %w", jarManifestPath, err))
+	}
+	if err := verifyJAREntries(zr, files, manifest); err != nil {
+		return fail(err)
+	}
+
+	for _, blockName := range blocks {
+		sfName := strings.TrimSuffix(blockName, path.Ext(blockName)) + ".SF"
+		sfData, err := readZipFile(files[sfName])
+		if err != nil {
+			return fail(fmt.Errorf("read %s: %w", sfName, err))
+		}
+		blockData, err := readZipFile(files[blockName])
+		if err != nil {
+			return fail(fmt.Errorf("read %s: %w", blockName, err))
+		}
+		cert, err := verifyPKCS7(blockData, sfData)
+		if err != nil {
+			return fail(fmt.Errorf("%s: %w", blockName, err))
+		}
+		if err := verifySignatureFile(sfData, manifestData, manifest); err != nil {
+			return fail(fmt.Errorf("%s: %w", sfName, err))
+		}
+		result.Signers = append(result.Signers, Signer{Certificate: NewCertificate(cert)})
+	}
+	result.Verified = true
+	return result
+}
+
+func readZipFile(f *zip.File) ([]byte, error) {
+	if f == nil {
+		return nil, fmt.Errorf("entry not found")
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rc.Close() }()
+	return io.ReadAll(rc)
+}
+
+// jarSection is a manifest or signature file section: its attributes and
+// the exact bytes (including the blank line that ends it) a digest covers.
+type jarSection struct {
+	attrs map[string]string
+	raw   []byte
+}
+
+// jarManifest is a parsed MANIFEST.MF or .SF file.
+type jarManifest struct {
+	main    jarSection
+	entries map[string]jarSection
+}
+
+// parseJARManifest splits a manifest into sections, joining continuation
+// lines (a leading space continues the previous value).
+func parseJARManifest(data []byte) (*jarManifest, error) {
+	m := &jarManifest{entries: make(map[string]jarSection)}
+	first := true
+	for len(data) > 0 {
+		end := sectionEnd(data)
+		raw := data[:end]
+		data = data[end:]
+
+		section := jarSection{attrs: make(map[string]string), raw: raw}
+		var lastKey string
+		for _, line := range strings.Split(strings.ReplaceAll(string(raw), "\r\n", "\n"), "\n") {
+			if line == "" {
+				continue
+			}
+			if strings.HasPrefix(line, " ") {
+				if lastKey == "" {
+					return nil, fmt.Errorf("continuation line without attribute")
+				}
+				section.attrs[lastKey] += line[1:]
+				continue
+			}
+			key, value, ok := strings.Cut(line, ": ")
+			if !ok {
+				return nil, fmt.Errorf("malformed line %q", line)
+			}
+			section.attrs[key] = value
+			lastKey = key
+		}
+		if first {
+			m.main = section
+			first = false
+			continue
+		}
+		name := section.attrs["Name"]
+		if name == "" {
+			if len(section.attrs) == 0 {
+				continue
+			}
+			return nil, fmt.Errorf("section without Name attribute")
+		}
+		m.entries[name] = section
+	}
+	return m, nil
+}
+
+// sectionEnd returns the length of the first section including the empty
+// line that terminates it.
+func sectionEnd(data []byte) int {
+	for i := 0; i < len(data); {
+		lineEnd := bytes.IndexByte(data[i:], '\n')
+		if lineEnd < 0 {
+			return len(data)
+		}
+		line := bytes.TrimSuffix(data[i:i+lineEnd], []byte("\r"))
+		i += lineEnd + 1
+		if len(line) == 0 {
+			return i
+		}
+	}
+	return len(data)
+}
+
+// digestAttr returns the strongest "<alg><suffix>" digest attribute in attrs.
+func digestAttr(attrs map[string]string, suffix string) (crypto.Hash, []byte, bool) {
+	for _, d := range jarDigestAttrs {
+		v, ok := attrs[d.name+suffix]
+		if !ok {
+			continue
+		}
+		raw, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			continue
+		}
+		return d.hash, raw, true
+	}
+	return 0, nil, false
+}
+
+func sum(h crypto.Hash, data []byte) []byte {
+	w := h.New()
+	w.Write(data)
+	return w.Sum(nil)
+}
+
+// verifyJAREntries checks that every archive entry outside the signature
+// files is listed in the manifest with a matching digest, and that the
+// manifest lists nothing that is missing.
+func verifyJAREntries(zr *zip.Reader, files map[string]*zip.File, manifest *jarManifest) error {
+	for _, f := range zr.File {
+		if strings.HasSuffix(f.Name, "/") || isJARSignatureFile(f.Name) {
+			continue
+		}
+		section, ok := manifest.entries[f.Name]
+		if !ok {
+			return fmt.Errorf("entry %s is not covered by the JAR signature", f.Name)
+		}
+		h, want, ok := digestAttr(section.attrs, "-Digest")
+		if !ok {
+			return fmt.Errorf("manifest section for %s has no supported digest", f.Name)
+		}
+		data, err := readZipFile(f)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", f.Name, err)
+		}
+		if !bytes.Equal(sum(h, data), want) {
+			return fmt.Errorf("digest of %s does not match the manifest: the artifact was modified after signing", f.Name)
+		}
+	}
+	for name := range manifest.entries {
+		if files[name] == nil {
+			return fmt.Errorf("manifest lists %s, which is missing from the archive", name)
+		}
+	}
+	return nil
+}
+
+// verifySignatureFile checks the .SF digests of the manifest: the whole
+// manifest digest when present, otherwise each section digest.
+func verifySignatureFile(sfData, manifestData []byte, manifest *jarManifest) error {
+	sf, err := parseJARManifest(sfData)
+	if err != nil {
+		return err
+	}
+	if h, want, ok := digestAttr(sf.main.attrs, "-Digest-Manifest"); ok && bytes.Equal(sum(h, manifestData), want) {
+		return nil
+	}
+	for name, section := range manifest.entries {
+		sfSection, ok := sf.entries[name]
+		if !ok {
+			return fmt.Errorf("entry %s is not covered by the signature file", name)
+		}
+		h, want, ok := digestAttr(sfSection.attrs, "-Digest")
+		if !ok {
+			return fmt.Errorf("signature file section for %s has no supported digest", name)
+		}
+		if !bytes.Equal(sum(h, section.raw), want) {
+			return fmt.Errorf("manifest section for %s does not match the signature file", name)
+		}
+	}
+	return nil
+}
+
+type pkcs7ContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"explicit,optional,tag:0"`
+}
+
+type pkcs7SignedData struct {
+	Version          int
+	DigestAlgorithms asn1.RawValue
+	ContentInfo      asn1.RawValue
+	Certificates     asn1.RawValue `asn1:"optional,tag:0"`
+	CRLs             asn1.RawValue `asn1:"optional,tag:1"`
+	SignerInfos      asn1.RawValue
+}
+
+type pkcs7SignerInfo struct {
+	Version                   int
+	IssuerAndSerial           pkcs7IssuerAndSerial
+	DigestAlgorithm           pkix.AlgorithmIdentifier
+	AuthenticatedAttributes   asn1.RawValue `asn1:"optional,tag:0"`
+	DigestEncryptionAlgorithm pkix.AlgorithmIdentifier
+	EncryptedDigest           []byte
+	UnauthenticatedAttributes asn1.RawValue `asn1:"optional,tag:1"`
+}
+
+type pkcs7IssuerAndSerial struct {
+	Issuer asn1.RawValue
+	Serial *big.Int
+}
+
+type pkcs7Attribute struct {
+	Type   asn1.ObjectIdentifier
+	Values asn1.RawValue `asn1:"set"`
+}
+
+var (
+	oidSignedData    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidMessageDigest = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+
+	pkcs7DigestHashes = map[string]crypto.Hash{
+		"1.3.14.3.2.26":          crypto.SHA1,
+		"2.16.840.1.101.3.4.2.1": crypto.SHA256,
+		"2.16.840.1.101.3.4.2.2": crypto.SHA384,
+		"2.16.840.1.101.3.4.2.3": crypto.SHA512,
+	}
+)
+
+// verifyPKCS7 verifies the detached PKCS#7 signature in a JAR signature block
+// over content (the .SF file) and returns the signer certificate.
+func verifyPKCS7(data, content []byte) (*x509.Certificate, error) {
+	var info pkcs7ContentInfo
+	if _, err := asn1.Unmarshal(data, &info); err != nil {
+		return nil, fmt.Errorf("not a PKCS#7 structure: %w", err)
+	}
+	if !info.ContentType.Equal(oidSignedData) {
+		return nil, fmt.Errorf("PKCS#7 content type %s is not signedData", info.ContentType)
+	}
+	var sd pkcs7SignedData
+	if _, err := asn1.Unmarshal(info.Content.Bytes, &sd); err != nil {
+		return nil, fmt.Errorf("decode PKCS#7 signedData: %w", err)
+	}
+	certs, err := x509.ParseCertificates(sd.Certificates.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse signer certificate: %w", err)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("PKCS#7 signedData carries no certificates")
+	}
+
+	var si pkcs7SignerInfo
+	if _, err := asn1.Unmarshal(sd.SignerInfos.Bytes, &si); err != nil {
+		return nil, fmt.Errorf("PKCS#7 signedData has no readable signer info: %w", err)
+	}
+	var cert *x509.Certificate
+	for _, c := range certs {
+		if c.SerialNumber.Cmp(si.IssuerAndSerial.Serial) == 0 && bytes.Equal(c.RawIssuer, si.IssuerAndSerial.Issuer.FullBytes) {
+			cert = c
+			break
+		}
+	}
+	if cert == nil {
+		return nil, fmt.Errorf("signer certificate not found in PKCS#7 certificates")
+	}
+
+	h, ok := pkcs7DigestHashes[si.DigestAlgorithm.Algorithm.String()]
+	if !ok {
+		return nil, fmt.Errorf("unsupported digest algorithm %s", si.DigestAlgorithm.Algorithm)
+	}
+	signed := content
+	if len(si.AuthenticatedAttributes.FullBytes) > 0 {
+		digest, err := messageDigestAttr(si.AuthenticatedAttributes.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(digest, sum(h, content)) {
+			return nil, fmt.Errorf("signed messageDigest does not match the signature file")
+		}
+		// Authenticated attributes are signed as a SET, not the [0] tag they
+		// are encoded with.
+		signed = append([]byte{0x31}, si.AuthenticatedAttributes.FullBytes[1:]...)
+	}
+
+	digest := sum(h, signed)
+	switch pub := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		err = rsa.VerifyPKCS1v15(pub, h, digest, si.EncryptedDigest)
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(pub, digest, si.EncryptedDigest) {
+			err = fmt.Errorf("ECDSA signature mismatch")
+		}
+	default:
+		err = fmt.Errorf("unsupported signer key type %T", cert.PublicKey)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("signature does not verify: %w", err)
+	}
+	return cert, nil
+}
+
+func messageDigestAttr(attrs []byte) ([]byte, error) {
+	for len(attrs) > 0 {
+		var attr pkcs7Attribute
+		rest, err := asn1.Unmarshal(attrs, &attr)
+		if err != nil {
+			return nil, fmt.Errorf("decode authenticated attributes: %w", err)
+		}
+		attrs = rest
+		if !attr.Type.Equal(oidMessageDigest) {
+			continue
+		}
+		var digest []byte
+		if _, err := asn1.Unmarshal(attr.Values.Bytes, &digest); err != nil {
+			return nil, fmt.Errorf("decode messageDigest: %w", err)
+		}
+		return digest, nil
+	}
+	return nil, fmt.Errorf("authenticated attributes carry no messageDigest")
+}
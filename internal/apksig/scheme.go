@@ -0,0 +1,538 @@
+package apksig
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	_ "crypto/sha256" // register SHA-256 for crypto.Hash
+	_ "crypto/sha512" // register SHA-512 for crypto.Hash
+	"crypto/x509"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// APK Signing Block layout (see apksig ApkSigningBlockUtils).
+const (
+	apkSigBlockMagic     = "APK Sig Block 42"
+	apkSigBlockMinSize   = 32
+	eocdSignature        = 0x06054b50
+	eocdMinSize          = 22
+	eocdMaxCommentLength = 0xffff
+
+	blockIDV2       = 0x7109871a
+	blockIDV3       = 0xf05368c0
+	blockIDV31      = 0x1b93ad61
+	attrIDRotation  = 0x3ba06f8c
+	contentChunkLen = 1 << 20
+)
+
+var schemeBlockIDs = map[uint32]string{
+	blockIDV2:  SchemeV2,
+	blockIDV3:  SchemeV3,
+	blockIDV31: SchemeV31,
+}
+
+var schemeBlockOrder = []uint32{blockIDV2, blockIDV3, blockIDV31}
+
+// sigAlgorithm is an APK Signature Scheme signature algorithm.
+type sigAlgorithm struct {
+	hash crypto.Hash
+	kind string
+	// rank orders algorithms by preference; verity variants digest a
+	// Merkle tree rather than the chunked content and are never chosen.
+	rank int
+}
+
+const (
+	kindRSAPSS   = "rsa-pss"
+	kindRSAPKCS1 = "rsa-pkcs1"
+	kindECDSA    = "ecdsa"
+)
+
+var sigAlgorithms = map[uint32]sigAlgorithm{
+	0x0101: {crypto.SHA256, kindRSAPSS, 2},
+	0x0102: {crypto.SHA512, kindRSAPSS, 3},
+	0x0103: {crypto.SHA256, kindRSAPKCS1, 2},
+	0x0104: {crypto.SHA512, kindRSAPKCS1, 3},
+	0x0201: {crypto.SHA256, kindECDSA, 2},
+	0x0202: {crypto.SHA512, kindECDSA, 3},
+}
+
+// blockLayout records where the signing block and central directory sit.
+type blockLayout struct {
+	size       int64
+	blockStart int64 // start of the signing block, or the central directory when absent
+	cdStart    int64
+	eocdStart  int64
+	blocks     map[uint32][]byte // nil when the APK has no signing block
+}
+
+// locateSigningBlock finds the End of Central Directory record and the APK
+// Signing Block that immediately precedes the central directory.
+func locateSigningBlock(r io.ReaderAt, size int64) (*blockLayout, error) {
+	eocdStart, cdStart, err := findEOCD(r, size)
+	if err != nil {
+		return nil, err
+	}
+	layout := &blockLayout{size: size, blockStart: cdStart, cdStart: cdStart, eocdStart: eocdStart}
+	if cdStart < apkSigBlockMinSize {
+		return layout, nil
+	}
+
+	footer := make([]byte, 24)
+	if _, err := r.ReadAt(footer, cdStart-24); err != nil {
+		return nil, fmt.Errorf("read signing block footer: %w", err)
+	}
+	if string(footer[8:]) != apkSigBlockMagic {
+		return layout, nil
+	}
+	blockSize := int64(binary.LittleEndian.Uint64(footer[:8]))
+	start := cdStart - blockSize - 8
+	if blockSize < 24 || start < 0 {
+		return nil, fmt.Errorf("APK Signing Block size %d out of range", blockSize)
+	}
+	block := make([]byte, blockSize+8)
+	if _, err := r.ReadAt(block, start); err != nil {
+		return nil, fmt.Errorf("read APK Signing Block: %w", err)
+	}
+	if int64(binary.LittleEndian.Uint64(block[:8])) != blockSize {
+		return nil, fmt.Errorf("APK Signing Block header and footer sizes differ")
+	}
+
+	layout.blockStart = start
+	layout.blocks = make(map[uint32][]byte)
+	pairs := block[8 : len(block)-24]
+	for len(pairs) > 0 {
+		if len(pairs) < 12 {
+			return nil, fmt.Errorf("truncated APK Signing Block entry")
+		}
+		n := binary.LittleEndian.Uint64(pairs[:8])
+		if n < 4 || n > uint64(len(pairs)-8) {
+			return nil, fmt.Errorf("APK Signing Block entry length %d out of range", n)
+		}
+		id := binary.LittleEndian.Uint32(pairs[8:12])
+		layout.blocks[id] = pairs[12 : 8+n]
+		pairs = pairs[8+n:]
+	}
+	return layout, nil
+}
+
+// findEOCD returns the offsets of the End of Central Directory record and
+// the central directory it points to.
+func findEOCD(r io.ReaderAt, size int64) (eocdStart, cdStart int64, err error) {
+	window := min(int64(eocdMinSize+eocdMaxCommentLength), size)
+	tail := make([]byte, window)
+	if _, err := r.ReadAt(tail, size-window); err != nil && err != io.EOF {
+		return 0, 0, err
+	}
+	for i := len(tail) - eocdMinSize; i >= 0; i-- {
+		if binary.LittleEndian.Uint32(tail[i:]) != eocdSignature {
+			continue
+		}
+		commentLen := int(binary.LittleEndian.Uint16(tail[i+20:]))
+		if i+eocdMinSize+commentLen != len(tail) {
+			continue
+		}
+		offset := int64(binary.LittleEndian.Uint32(tail[i+16:]))
+		if offset == 0xffffffff {
+			return 0, 0, fmt.Errorf("ZIP64 archives are not supported")
+		}
+		return size - window + int64(i), offset, nil
+	}
+	return 0, 0, fmt.Errorf("end of central directory record not found")
+}
+
+// contentDigester computes the chunked content digests v2 and v3 sign: the
+// zip entries, the central directory, and the EOCD record with its central
+// directory offset pointing at the signing block.
+type contentDigester struct {
+	r      io.ReaderAt
+	layout *blockLayout
+	cache  map[uint32][]byte
+}
+
+func (d *contentDigester) digest(h crypto.Hash) ([]byte, error) {
+	if cached, ok := d.cache[uint32(h)]; ok {
+		return cached, nil
+	}
+	eocd := make([]byte, d.layout.size-d.layout.eocdStart)
+	if _, err := d.r.ReadAt(eocd, d.layout.eocdStart); err != nil {
+		return nil, err
+	}
+	binary.LittleEndian.PutUint32(eocd[16:], uint32(d.layout.blockStart))
+
+	sections := []io.Reader{
+		io.NewSectionReader(d.r, 0, d.layout.blockStart),
+		io.NewSectionReader(d.r, d.layout.cdStart, d.layout.eocdStart-d.layout.cdStart),
+		bytes.NewReader(eocd),
+	}
+	var chunkDigests []byte
+	chunks := 0
+	buf := make([]byte, contentChunkLen)
+	for _, section := range sections {
+		for {
+			n, err := io.ReadFull(section, buf)
+			if n > 0 {
+				ch := h.New()
+				ch.Write([]byte{0xa5})
+				_ = binary.Write(ch, binary.LittleEndian, uint32(n))
+				ch.Write(buf[:n])
+				chunkDigests = ch.Sum(chunkDigests)
+				chunks++
+			}
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	top := h.New()
+	top.Write([]byte{0x5a})
+	_ = binary.Write(top, binary.LittleEndian, uint32(chunks))
+	top.Write(chunkDigests)
+	sum := top.Sum(nil)
+	d.cache[uint32(h)] = sum
+	return sum, nil
+}
+
+// verifySchemeBlock verifies every signer in a v2, v3 or v3.1 block.
+func verifySchemeBlock(scheme string, value []byte, content *contentDigester) SchemeResult {
+	result := SchemeResult{Scheme: scheme}
+	fail := func(err error) SchemeResult {
+		result.Error = err.Error()
+		result.Signers = nil
+		return result
+	}
+
+	signers, err := (&reader{value}).prefixed()
+	if err != nil {
+		return fail(fmt.Errorf("read signers: %w", err))
+	}
+	r := &reader{signers}
+	for !r.empty() {
+		raw, err := r.prefixed()
+		if err != nil {
+			return fail(fmt.Errorf("read signer: %w", err))
+		}
+		signer, err := verifySigner(scheme, raw, content)
+		if err != nil {
+			return fail(fmt.Errorf("signer #%d: %w", len(result.Signers)+1, err))
+		}
+		result.Signers = append(result.Signers, *signer)
+	}
+	if len(result.Signers) == 0 {
+		return fail(fmt.Errorf("no signers"))
+	}
+	result.Verified = true
+	return result
+}
+
+// verifySigner checks one signer: its signature over the signed data, the
+// content digest, and that the public key matches the first certificate.
+func verifySigner(scheme string, raw []byte, content *contentDigester) (*Signer, error) {
+	v3 := scheme != SchemeV2
+	r := &reader{raw}
+	signedData, err := r.prefixed()
+	if err != nil {
+		return nil, fmt.Errorf("read signed data: %w", err)
+	}
+	var minSDK, maxSDK uint32
+	if v3 {
+		if minSDK, err = r.uint32(); err != nil {
+			return nil, err
+		}
+		if maxSDK, err = r.uint32(); err != nil {
+			return nil, err
+		}
+	}
+	signatures, err := r.prefixed()
+	if err != nil {
+		return nil, fmt.Errorf("read signatures: %w", err)
+	}
+	publicKeyDER, err := r.prefixed()
+	if err != nil {
+		return nil, fmt.Errorf("read public key: %w", err)
+	}
+	publicKey, err := x509.ParsePKIXPublicKey(publicKeyDER)
+	if err != nil {
+		return nil, fmt.Errorf("parse public key: %w", err)
+	}
+
+	algID, signature, err := bestSignature(signatures)
+	if err != nil {
+		return nil, err
+	}
+	alg := sigAlgorithms[algID]
+	if err := verifySignature(publicKey, alg, signedData, signature); err != nil {
+		return nil, fmt.Errorf("signature over signed data does not verify: %w", err)
+	}
+
+	sd := &reader{signedData}
+	digests, err := sd.prefixed()
+	if err != nil {
+		return nil, fmt.Errorf("read digests: %w", err)
+	}
+	certs, err := sd.prefixed()
+	if err != nil {
+		return nil, fmt.Errorf("read certificates: %w", err)
+	}
+	if v3 {
+		sdMin, err := sd.uint32()
+		if err != nil {
+			return nil, err
+		}
+		sdMax, err := sd.uint32()
+		if err != nil {
+			return nil, err
+		}
+		if sdMin != minSDK || sdMax != maxSDK {
+			return nil, fmt.Errorf("SDK range in signed data differs from signer")
+		}
+	}
+	attrs, err := sd.prefixed()
+	if err != nil {
+		return nil, fmt.Errorf("read additional attributes: %w", err)
+	}
+
+	expected, err := findDigest(digests, algID)
+	if err != nil {
+		return nil, err
+	}
+	actual, err := content.digest(alg.hash)
+	if err != nil {
+		return nil, fmt.Errorf("digest content: %w", err)
+	}
+	if !bytes.Equal(expected, actual) {
+		return nil, fmt.Errorf("content digest mismatch: the APK was modified after signing")
+	}
+
+	certDER, err := (&reader{certs}).prefixed()
+	if err != nil {
+		return nil, fmt.Errorf("read certificate: %w", err)
+	}
+	cert, err := x509.ParseCertificate(certDER)
+	if err != nil {
+		return nil, fmt.Errorf("parse certificate: %w", err)
+	}
+	if !bytes.Equal(cert.RawSubjectPublicKeyInfo, publicKeyDER) {
+		return nil, fmt.Errorf("public key does not match the signer certificate")
+	}
+
+	signer := &Signer{Certificate: NewCertificate(cert)}
+	if v3 {
+		signer.MinSDK, signer.MaxSDK = int(minSDK), int(maxSDK)
+		lineage, err := parseLineageAttr(attrs)
+		if err != nil {
+			return nil, err
+		}
+		if len(lineage) > 0 && lineage[len(lineage)-1].SHA256 != signer.Certificate.SHA256 {
+			return nil, fmt.Errorf("rotation lineage does not end with the signer certificate")
+		}
+		signer.Lineage = lineage
+	}
+	return signer, nil
+}
+
+// bestSignature picks the strongest supported signature from a signatures
+// sequence.
+func bestSignature(signatures []byte) (uint32, []byte, error) {
+	var (
+		bestID  uint32
+		bestSig []byte
+		found   bool
+	)
+	r := &reader{signatures}
+	for !r.empty() {
+		entry, err := r.prefixed()
+		if err != nil {
+			return 0, nil, fmt.Errorf("read signature: %w", err)
+		}
+		er := &reader{entry}
+		id, err := er.uint32()
+		if err != nil {
+			return 0, nil, err
+		}
+		sig, err := er.prefixed()
+		if err != nil {
+			return 0, nil, err
+		}
+		alg, ok := sigAlgorithms[id]
+		if !ok {
+			continue
+		}
+		if !found || alg.rank > sigAlgorithms[bestID].rank {
+			bestID, bestSig, found = id, sig, true
+		}
+	}
+	if !found {
+		return 0, nil, fmt.Errorf("no supported signature algorithm")
+	}
+	return bestID, bestSig, nil
+}
+
+func findDigest(digests []byte, algID uint32) ([]byte, error) {
+	r := &reader{digests}
+	for !r.empty() {
+		entry, err := r.prefixed()
+		if err != nil {
+			return nil, fmt.Errorf("read digest: %w", err)
+		}
+		er := &reader{entry}
+		id, err := er.uint32()
+		if err != nil {
+			return nil, err
+		}
+		digest, err := er.prefixed()
+		if err != nil {
+			return nil, err
+		}
+		if id == algID {
+			return digest, nil
+		}
+	}
+	return nil, fmt.Errorf("no digest for signature algorithm 0x%04x", algID)
+}
+
+// parseLineageAttr finds the proof-of-rotation attribute and verifies that
+// each certificate in it was signed over by its predecessor.
+func parseLineageAttr(attrs []byte) ([]Certificate, error) {
+	r := &reader{attrs}
+	for !r.empty() {
+		entry, err := r.prefixed()
+		if err != nil {
+			return nil, fmt.Errorf("read attribute: %w", err)
+		}
+		er := &reader{entry}
+		id, err := er.uint32()
+		if err != nil {
+			return nil, err
+		}
+		if id == attrIDRotation {
+			return parseLineage(er.b)
+		}
+	}
+	return nil, nil
+}
+
+func parseLineage(data []byte) ([]Certificate, error) {
+	r := &reader{data}
+	if _, err := r.uint32(); err != nil { // format version
+		return nil, fmt.Errorf("read lineage version: %w", err)
+	}
+	var (
+		lineage    []Certificate
+		parent     *x509.Certificate
+		parentAlgo uint32
+	)
+	for !r.empty() {
+		node, err := r.prefixed()
+		if err != nil {
+			return nil, fmt.Errorf("read lineage node: %w", err)
+		}
+		nr := &reader{node}
+		signedData, err := nr.prefixed()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := nr.uint32(); err != nil { // capability flags
+			return nil, err
+		}
+		sigAlgID, err := nr.uint32()
+		if err != nil {
+			return nil, err
+		}
+		signature, err := nr.prefixed()
+		if err != nil {
+			return nil, err
+		}
+
+		sr := &reader{signedData}
+		certDER, err := sr.prefixed()
+		if err != nil {
+			return nil, err
+		}
+		nextAlgo, err := sr.uint32()
+		if err != nil {
+			return nil, err
+		}
+		cert, err := x509.ParseCertificate(certDER)
+		if err != nil {
+			return nil, fmt.Errorf("lineage certificate #%d: %w", len(lineage)+1, err)
+		}
+
+		if parent != nil {
+			if sigAlgID != parentAlgo {
+				return nil, fmt.Errorf("lineage node #%d signature algorithm differs from the one its parent declared", len(lineage)+1)
+			}
+			alg, ok := sigAlgorithms[sigAlgID]
+			if !ok {
+				return nil, fmt.Errorf("lineage node #%d uses unsupported algorithm 0x%04x", len(lineage)+1, sigAlgID)
+			}
+			if err := verifySignature(parent.PublicKey, alg, signedData, signature); err != nil {
+				return nil, fmt.Errorf("lineage node #%d is not signed by its predecessor: %w", len(lineage)+1, err)
+			}
+		}
+		lineage = append(lineage, NewCertificate(cert))
+		parent, parentAlgo = cert, nextAlgo
+	}
+	return lineage, nil
+}
+
+func verifySignature(publicKey crypto.PublicKey, alg sigAlgorithm, data, signature []byte) error {
+	h := alg.hash.New()
+	h.Write(data)
+	digest := h.Sum(nil)
+	switch alg.kind {
+	case kindRSAPSS, kindRSAPKCS1:
+		pub, ok := publicKey.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("algorithm needs an RSA key, got %T", publicKey)
+		}
+		if alg.kind == kindRSAPSS {
+			return rsa.VerifyPSS(pub, alg.hash, digest, signature, &rsa.PSSOptions{SaltLength: alg.hash.Size()})
+		}
+		return rsa.VerifyPKCS1v15(pub, alg.hash, digest, signature)
+	case kindECDSA:
+		pub, ok := publicKey.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("algorithm needs an EC key, got %T", publicKey)
+		}
+		if !ecdsa.VerifyASN1(pub, digest, signature) {
+			return fmt.Errorf("ECDSA signature mismatch")
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported signature algorithm %s", alg.kind)
+}
+
+// reader walks the little-endian, uint32 length-prefixed structures of the
+// signing block.
+type reader struct{ b []byte }
+
+func (r *reader) empty() bool { return len(r.b) == 0 }
+
+func (r *reader) uint32() (uint32, error) {
+	if len(r.b) < 4 {
+		return 0, fmt.Errorf("truncated structure")
+	}
+	v := binary.LittleEndian.Uint32(r.b)
+	r.b = r.b[4:]
+	return v, nil
+}
+
+func (r *reader) prefixed() ([]byte, error) {
+	n, err := r.uint32()
+	if err != nil {
+		return nil, err
+	}
+	if uint64(n) > uint64(len(r.b)) {
+		return nil, fmt.Errorf("length prefix %d exceeds remaining %d bytes", n, len(r.b))
+	}
+	v := r.b[:n]
+	r.b = r.b[n:]
+	return v, nil
+}
@@ -0,0 +1,231 @@
+// Package artifact opens Android app artifacts (APK and AAB) and decodes the
+// metadata gpd validates before upload: the binary or proto manifest, the
+// bundle module layout, and the raw zip entries later checks walk.
+package artifact
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Kind identifies the artifact container format.
+type Kind string
+
+const (
+	KindAPK Kind = "apk"
+	KindAAB Kind = "aab"
+)
+
+// Well-known entry paths.
+const (
+	apkManifestPath    = "AndroidManifest.xml"
+	bundleManifestPath = "manifest/AndroidManifest.xml"
+	bundleBaseModule   = "base"
+	bundleConfigPath   = "BundleConfig.pb"
+)
+
+// BundleMappingPath is where the Android Gradle plugin embeds the R8 or
+// ProGuard mapping file in a bundle.
+const BundleMappingPath = "BUNDLE-METADATA/com.android.tools.build.obfuscation/proguard.map"
+
+// Bundle top-level entries that are not modules.
+var bundleMetadataDirs = map[string]bool{
+	"BUNDLE-METADATA": true,
+	"META-INF":        true,
+}
+
+// Artifact is an opened APK or AAB.
+type Artifact struct {
+	Path     string
+	Kind     Kind
+	Size     int64
+	Manifest *Manifest
+	// Modules lists bundle module names (base first); empty for APKs.
+	Modules []string
+
+	zr    *zip.ReadCloser
+	files map[string]*zip.File
+}
+
+// Open reads the artifact at path and decodes its manifest. The container
+// format is detected from the zip contents rather than the file extension.
+func Open(path string) (*Artifact, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("%s is a directory, expected an APK or AAB file", path)
+	}
+
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a valid APK/AAB (zip): %w", path, err)
+	}
+
+	a := &Artifact{
+		Path:  path,
+		Size:  info.Size(),
+		zr:    zr,
+		files: make(map[string]*zip.File, len(zr.File)),
+	}
+	for _, f := range zr.File {
+		a.files[f.Name] = f
+	}
+
+	if err := a.load(); err != nil {
+		_ = zr.Close()
+		return nil, err
+	}
+	return a, nil
+}
+
+func (a *Artifact) load() error {
+	switch {
+	case a.files[bundleBaseModule+"/"+bundleManifestPath] != nil:
+		a.Kind = KindAAB
+		a.Modules = a.bundleModules()
+		data, err := a.ReadFile(bundleBaseModule + "/" + bundleManifestPath)
+		if err != nil {
+			return err
+		}
+		root, err := parseProtoXML(data)
+		if err != nil {
+			return fmt.Errorf("decode bundle manifest: %w", err)
+		}
+		a.Manifest = newManifest(root)
+	case a.files[apkManifestPath] != nil:
+		a.Kind = KindAPK
+		data, err := a.ReadFile(apkManifestPath)
+		if err != nil {
+			return err
+		}
+		root, err := parseAXML(data)
+		if err != nil {
+			return fmt.Errorf("decode APK manifest: %w", err)
+		}
+		a.Manifest = newManifest(root)
+	default:
+		return fmt.Errorf("%s has no AndroidManifest.xml or base/manifest/AndroidManifest.xml", a.Path)
+	}
+
+	if a.Manifest.Package == "" {
+		return fmt.Errorf("manifest in %s does not declare a package", a.Path)
+	}
+	return nil
+}
+
+// bundleModules returns the top-level directories that carry a module
+// manifest, with the base module first.
+func (a *Artifact) bundleModules() []string {
+	var modules []string
+	for name := range a.files {
+		module, rest, ok := strings.Cut(name, "/")
+		if !ok || rest != bundleManifestPath || module == bundleBaseModule {
+			continue
+		}
+		modules = append(modules, module)
+	}
+	sort.Strings(modules)
+	return append([]string{bundleBaseModule}, modules...)
+}
+
+// UnknownBundleDirs returns top-level bundle directories that are neither
+// modules (they lack manifest/AndroidManifest.xml) nor bundle metadata.
+// bundletool rejects bundles that contain any.
+func (a *Artifact) UnknownBundleDirs() []string {
+	if a.Kind != KindAAB {
+		return nil
+	}
+	modules := make(map[string]bool, len(a.Modules))
+	for _, m := range a.Modules {
+		modules[m] = true
+	}
+	seen := make(map[string]bool)
+	var out []string
+	for name := range a.files {
+		dir, _, ok := strings.Cut(name, "/")
+		if !ok || modules[dir] || bundleMetadataDirs[dir] || seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		out = append(out, dir)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// BundletoolVersion returns the bundletool version recorded in
+// BundleConfig.pb.
+func (a *Artifact) BundletoolVersion() (string, error) {
+	data, err := a.ReadFile(bundleConfigPath)
+	if err != nil {
+		return "", err
+	}
+	// BundleConfig.bundletool (1) -> Bundletool.version (2).
+	fields, err := decodeProto(data)
+	if err != nil {
+		return "", fmt.Errorf("decode %s: %w", bundleConfigPath, err)
+	}
+	for _, f := range fields {
+		if f.Number != 1 || f.Wire != wireBytes {
+			continue
+		}
+		inner, err := decodeProto(f.Bytes)
+		if err != nil {
+			return "", fmt.Errorf("decode %s: %w", bundleConfigPath, err)
+		}
+		for _, g := range inner {
+			if g.Number == 2 && g.Wire == wireBytes {
+				return string(g.Bytes), nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// EmbeddedMapping opens the mapping file embedded in a bundle. It returns
+// nil without error when there is none (APKs, unobfuscated builds).
+func (a *Artifact) EmbeddedMapping() (io.ReadCloser, error) {
+	f := a.files[BundleMappingPath]
+	if a.Kind != KindAAB || f == nil {
+		return nil, nil
+	}
+	return f.Open()
+}
+
+// Close releases the underlying zip reader.
+func (a *Artifact) Close() error {
+	if a == nil || a.zr == nil {
+		return nil
+	}
+	return a.zr.Close()
+}
+
+// Files returns the zip entries in archive order.
+func (a *Artifact) Files() []*zip.File {
+	return a.zr.File
+}
+
+// File returns the named entry, or nil when absent.
+func (a *Artifact) File(name string) *zip.File {
+	return a.files[name]
+}
+
+// ReadFile returns the contents of the named entry.
+func (a *Artifact) ReadFile(name string) ([]byte, error) {
+	f := a.files[name]
+	if f == nil {
+		return nil, fmt.Errorf("%s: entry %s not found", a.Path, name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rc.Close() }()
+	return io.ReadAll(rc)
+}
@@ -0,0 +1,387 @@
+//go:build unit
+// +build unit
+
+package artifact
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/artifact/artifacttest"
+)
+
+const testPackage = "com.example.app"
+
+func assertFixtureManifest(t *testing.T, m *Manifest) {
+	t.Helper()
+	if m.Package != testPackage {
+		t.Errorf("Package = %q", m.Package)
+	}
+	if m.VersionCode != 42 || m.VersionName != "1.4.2" {
+		t.Errorf("version = %d/%q", m.VersionCode, m.VersionName)
+	}
+	if m.MinSDK != 24 || m.TargetSDK != 34 || m.CompileSDK != 34 {
+		t.Errorf("sdk = min %d target %d compile %d", m.MinSDK, m.TargetSDK, m.CompileSDK)
+	}
+	wantPerms := []string{"android.permission.INTERNET", "android.permission.READ_SMS"}
+	if got := m.PermissionNames(); !reflect.DeepEqual(got, wantPerms) {
+		t.Errorf("permissions = %v, want %v", got, wantPerms)
+	}
+	if len(m.Features) != 1 || m.Features[0].Name != "android.hardware.camera" || m.Features[0].Required {
+		t.Errorf("features = %+v", m.Features)
+	}
+	if len(m.Activities) != 1 || m.Activities[0].Name != ".MainActivity" || !m.Activities[0].IsExported() {
+		t.Errorf("activities = %+v", m.Activities)
+	}
+	if len(m.Activities[0].IntentFilters) != 1 || m.Activities[0].IntentFilters[0].Actions[0] != "android.intent.action.MAIN" {
+		t.Errorf("activity intent filters = %+v", m.Activities[0].IntentFilters)
+	}
+	if len(m.Services) != 1 || m.Services[0].IsExported() {
+		t.Errorf("services = %+v", m.Services)
+	}
+	if len(m.Receivers) != 1 || m.Receivers[0].Exported != nil || !m.Receivers[0].IsExported() {
+		t.Errorf("receivers = %+v (implicit export via intent filter expected)", m.Receivers)
+	}
+	if app := m.Application; app == nil || app.Debuggable != nil || app.AllowBackup != nil || app.UsesCleartextTraffic != nil {
+		t.Errorf("application = %+v, want no explicit flags", app)
+	}
+}
+
+func TestOpen_APK(t *testing.T) {
+	a, err := Open(artifacttest.WriteAPK(t, artifacttest.Manifest(testPackage, 42)))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer func() { _ = a.Close() }()
+
+	if a.Kind != KindAPK {
+		t.Errorf("Kind = %s, want apk", a.Kind)
+	}
+	if len(a.Modules) != 0 {
+		t.Errorf("Modules = %v, want none for APK", a.Modules)
+	}
+	assertFixtureManifest(t, a.Manifest)
+}
+
+func TestOpen_AAB(t *testing.T) {
+	path := artifacttest.WriteAAB(t, artifacttest.Manifest(testPackage, 42), artifacttest.Entry{
+		Name: "camera/" + bundleManifestPath,
+		Data: artifacttest.EncodeProtoXML(artifacttest.E("manifest", []string{"package", testPackage})),
+	})
+	a, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer func() { _ = a.Close() }()
+
+	if a.Kind != KindAAB {
+		t.Errorf("Kind = %s, want aab", a.Kind)
+	}
+	if want := []string{"base", "camera"}; !reflect.DeepEqual(a.Modules, want) {
+		t.Errorf("Modules = %v, want %v", a.Modules, want)
+	}
+	assertFixtureManifest(t, a.Manifest)
+}
+
+func TestOpen_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    func(t *testing.T) string
+		wantErr string
+	}{
+		{
+			name:    "not a zip",
+			path:    func(t *testing.T) string { return writeZipless(t) },
+			wantErr: "not a valid APK/AAB",
+		},
+		{
+			name: "no manifest",
+			path: func(t *testing.T) string {
+				return artifacttest.WriteZip(t, "empty.apk", []artifacttest.Entry{{Name: "classes.dex", Data: []byte("x")}})
+			},
+			wantErr: "has no AndroidManifest.xml",
+		},
+		{
+			name: "corrupt manifest",
+			path: func(t *testing.T) string {
+				return artifacttest.WriteZip(t, "bad.apk", []artifacttest.Entry{{Name: apkManifestPath, Data: []byte("<manifest/>")}})
+			},
+			wantErr: "decode APK manifest",
+		},
+		{
+			name: "missing package",
+			path: func(t *testing.T) string {
+				return artifacttest.WriteZip(t, "nopkg.apk", []artifacttest.Entry{{Name: apkManifestPath, Data: artifacttest.EncodeAXML(artifacttest.E("manifest", nil))}})
+			},
+			wantErr: "does not declare a package",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Open(tc.path(t))
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("Open error = %v, want containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func writeZipless(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "plain.apk")
+	if err := os.WriteFile(path, []byte("not a zip"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	return path
+}
+
+func TestParseStringPool_UTF8(t *testing.T) {
+	// Header: type, headerSize, size, count=2, styles=0, flags=UTF8, stringsStart, stylesStart.
+	chunk := []byte{
+		0x01, 0x00, 0x1c, 0x00, 0, 0, 0, 0,
+		2, 0, 0, 0, 0, 0, 0, 0, 0x00, 0x01, 0, 0, 36, 0, 0, 0, 0, 0, 0, 0,
+		0, 0, 0, 0, 5, 0, 0, 0,
+		2, 2, 'h', 'i', 0,
+		2, 2, 'a', 'b', 0,
+	}
+	pool, err := parseStringPool(chunk)
+	if err != nil {
+		t.Fatalf("parseStringPool: %v", err)
+	}
+	if want := []string{"hi", "ab"}; !reflect.DeepEqual(pool, want) {
+		t.Errorf("pool = %v, want %v", pool, want)
+	}
+}
+
+func TestProtoXML_CompiledPrimitiveFallback(t *testing.T) {
+	// An attribute with no source value but a compiled int_decimal_value.
+	item := artifacttest.ProtoBytes(nil, 7, artifacttest.ProtoVarint(nil, 6, 33))
+	attr := artifacttest.ProtoBytes(nil, 2, []byte("targetSdkVersion"))
+	attr = artifacttest.ProtoBytes(attr, 1, []byte(androidNamespace))
+	attr = artifacttest.ProtoVarint(attr, 5, 0x01010270)
+	attr = artifacttest.ProtoBytes(attr, 6, item)
+	usesSDK := artifacttest.ProtoBytes(artifacttest.ProtoBytes(nil, 3, []byte("uses-sdk")), 4, attr)
+	manifest := artifacttest.ProtoBytes(nil, 3, []byte("manifest"))
+	manifest = artifacttest.ProtoBytes(manifest, 5, artifacttest.ProtoBytes(nil, 1, usesSDK))
+
+	root, err := parseProtoXML(artifacttest.ProtoBytes(nil, 1, manifest))
+	if err != nil {
+		t.Fatalf("parseProtoXML: %v", err)
+	}
+	m := newManifest(root)
+	if m.TargetSDK != 33 {
+		t.Errorf("TargetSDK = %d, want 33", m.TargetSDK)
+	}
+	if m.MinSDK != 1 {
+		t.Errorf("MinSDK default = %d, want 1", m.MinSDK)
+	}
+}
+
+func TestFormatProtoItem_Reference(t *testing.T) {
+	// Item{ref: Reference{id: 0x7f0f001b, name: "string/app_name"}} as aapt2
+	// writes it for android:label="@string/app_name"; the REFERENCE type is
+	// the zero value and omitted.
+	label := []byte{
+		0x0a, 0x17,
+		0x10, 0x9b, 0x80, 0xbc, 0xf8, 0x07,
+		0x1a, 0x0f, 's', 't', 'r', 'i', 'n', 'g', '/', 'a', 'p', 'p', '_', 'n', 'a', 'm', 'e',
+	}
+	if got := formatProtoItem(label); got != "@string/app_name" {
+		t.Errorf("label = %q, want @string/app_name", got)
+	}
+	// Item{ref: Reference{type: ATTRIBUTE, id: 0x01010036}} without a name.
+	attr := []byte{0x0a, 0x07, 0x08, 0x01, 0x10, 0xb6, 0x80, 0x84, 0x08}
+	if got := formatProtoItem(attr); got != "?0x01010036" {
+		t.Errorf("attr = %q, want ?0x01010036", got)
+	}
+}
+
+func TestModuleSizes(t *testing.T) {
+	module := func(name string, delivery *artifacttest.Element) artifacttest.Entry {
+		return artifacttest.Entry{
+			Name: name + "/" + bundleManifestPath,
+			Data: artifacttest.EncodeProtoXML(artifacttest.E("manifest", []string{"package", testPackage, "split", name},
+				artifacttest.E("module", nil, delivery))),
+		}
+	}
+	path := artifacttest.WriteAAB(t, artifacttest.Manifest(testPackage, 42),
+		module("camera", artifacttest.E("delivery", nil, artifacttest.E("on-demand", nil))),
+		module("maps", artifacttest.E("delivery", nil, artifacttest.E("install-time", nil))),
+		artifacttest.Entry{Name: "maps/dex/classes.dex", Data: make([]byte, 4096), Stored: true},
+		artifacttest.Entry{Name: "BUNDLE-METADATA/com.android.tools.build.obfuscation/proguard.map", Data: []byte("x")},
+	)
+	a, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer func() { _ = a.Close() }()
+
+	sizes, err := a.ModuleSizes()
+	if err != nil {
+		t.Fatalf("ModuleSizes: %v", err)
+	}
+	got := map[string]string{}
+	files := 0
+	for _, s := range sizes {
+		got[s.Module] = s.Delivery
+		files += s.Files
+	}
+	want := map[string]string{"base": DeliveryInstallTime, "camera": DeliveryOnDemand, "maps": DeliveryInstallTime}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("deliveries = %v, want %v", got, want)
+	}
+	if files != 5 {
+		t.Errorf("module files = %d, want 5 (bundle metadata excluded)", files)
+	}
+	if sizes[2].Module != "maps" || sizes[2].Uncompressed < 4096 {
+		t.Errorf("maps size = %+v", sizes[2])
+	}
+}
+
+func TestNativeLibs(t *testing.T) {
+	apk := artifacttest.WriteAPK(t, artifacttest.Manifest(testPackage, 42),
+		artifacttest.Entry{Name: "lib/arm64-v8a/libgood.so", Data: artifacttest.ELF(true, PageSize16K), Stored: true, Align: PageSize16K},
+		artifacttest.Entry{Name: "lib/arm64-v8a/libunaligned.so", Data: artifacttest.ELF(true, PageSize16K), Stored: true},
+		artifacttest.Entry{Name: "lib/x86_64/lib4k.so", Data: artifacttest.ELF(true, 4096)},
+		artifacttest.Entry{Name: "lib/armeabi-v7a/libold.so", Data: artifacttest.ELF(false, 4096)},
+		artifacttest.Entry{Name: "lib/arm64-v8a/libjunk.so", Data: []byte("not elf")},
+		artifacttest.Entry{Name: "assets/lib/arm64-v8a/libignored.so", Data: []byte("x")},
+	)
+	a, err := Open(apk)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer func() { _ = a.Close() }()
+
+	libs, err := a.NativeLibs()
+	if err != nil {
+		t.Fatalf("NativeLibs: %v", err)
+	}
+	byPath := make(map[string]NativeLib, len(libs))
+	for _, lib := range libs {
+		byPath[lib.Path] = lib
+	}
+	if len(libs) != 5 {
+		t.Fatalf("libs = %+v", libs)
+	}
+	if good := byPath["lib/arm64-v8a/libgood.so"]; good.LoadAlign != PageSize16K || !good.Is64Bit || good.ZipAligned == nil || !*good.ZipAligned {
+		t.Errorf("libgood = %+v", good)
+	}
+	if lib := byPath["lib/arm64-v8a/libunaligned.so"]; lib.ZipAligned == nil || *lib.ZipAligned {
+		t.Errorf("libunaligned = %+v", lib)
+	}
+	if lib := byPath["lib/x86_64/lib4k.so"]; lib.LoadAlign != 4096 || !lib.Compressed || lib.ZipAligned != nil || lib.ABI != "x86_64" {
+		t.Errorf("lib4k = %+v", lib)
+	}
+	if lib := byPath["lib/armeabi-v7a/libold.so"]; lib.Is64Bit || lib.LoadAlign != 4096 {
+		t.Errorf("libold = %+v", lib)
+	}
+	if lib := byPath["lib/arm64-v8a/libjunk.so"]; lib.Error == "" {
+		t.Errorf("libjunk = %+v", lib)
+	}
+
+	aab := artifacttest.WriteAAB(t, artifacttest.Manifest(testPackage, 42),
+		artifacttest.Entry{Name: "base/lib/arm64-v8a/libapp.so", Data: artifacttest.ELF(true, PageSize16K), Stored: true},
+		artifacttest.Entry{Name: "BUNDLE-METADATA/lib/arm64-v8a/libx.so", Data: []byte("x")},
+	)
+	b, err := Open(aab)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer func() { _ = b.Close() }()
+	libs, err = b.NativeLibs()
+	if err != nil {
+		t.Fatalf("NativeLibs: %v", err)
+	}
+	if len(libs) != 1 || libs[0].Module != "base" || libs[0].ABI != "arm64-v8a" || libs[0].ZipAligned != nil {
+		t.Errorf("bundle libs = %+v", libs)
+	}
+}
+
+func TestBuildID(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"note section", artifacttest.SharedObject([]byte{0xde, 0xad, 0xbe, 0xef, 0x01}, ".symtab"), "deadbeef01"},
+		{"no build id", artifacttest.SharedObject(nil), ""},
+		{"no section headers", artifacttest.ELF(true, PageSize16K), ""},
+	} {
+		ef, err := elf.NewFile(bytes.NewReader(tc.data))
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if got := BuildID(ef); got != tc.want {
+			t.Errorf("%s: BuildID = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+
+	// A crafted note: namesz that wraps when padded in 32 bits, and a
+	// PT_NOTE segment claiming a terabyte.
+	crafted := artifacttest.SharedObject([]byte{1, 2, 3, 4})
+	binary.LittleEndian.PutUint64(crafted[64+56+32:], 1<<40)
+	binary.LittleEndian.PutUint32(crafted[64+2*56:], 0xfffffffd)
+	if ef, err := elf.NewFile(bytes.NewReader(crafted)); err == nil {
+		if got := BuildID(ef); got != "" {
+			t.Errorf("crafted: BuildID = %q", got)
+		}
+	}
+
+	aab := artifacttest.WriteAAB(t, artifacttest.Manifest(testPackage, 42),
+		artifacttest.Entry{Name: "base/lib/arm64-v8a/libapp.so", Data: artifacttest.SharedObject([]byte{1, 2, 3, 4})})
+	a, err := Open(aab)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer func() { _ = a.Close() }()
+	libs, err := a.NativeLibs()
+	if err != nil || len(libs) != 1 || libs[0].BuildID != "01020304" || libs[0].LoadAlign != PageSize16K {
+		t.Errorf("libs = %+v, err = %v", libs, err)
+	}
+}
+
+func TestEntrySizes(t *testing.T) {
+	path := artifacttest.WriteAAB(t, artifacttest.Manifest(testPackage, 42),
+		artifacttest.Entry{Name: "base/lib/arm64-v8a/libapp.so", Data: []byte("so")},
+		artifacttest.Entry{Name: "base/res/drawable-xxhdpi-v4/icon.png", Data: []byte("png")},
+		artifacttest.Entry{Name: "base/res/layout/main.xml", Data: []byte("xml")},
+		artifacttest.Entry{Name: "base/resources.pb", Data: []byte("pb")},
+		artifacttest.Entry{Name: "base/assets/data.bin", Data: []byte("bin")},
+		artifacttest.Entry{Name: "base/root/kotlin/kotlin.kotlin_builtins", Data: []byte("k")},
+		artifacttest.Entry{Name: "BUNDLE-METADATA/com.android.tools.build.obfuscation/proguard.map", Data: []byte("x")},
+	)
+	a, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer func() { _ = a.Close() }()
+
+	got := make(map[string]string)
+	for _, e := range a.EntrySizes() {
+		got[e.Path] = e.Category + "/" + e.ABI + e.Density
+	}
+	want := map[string]string{
+		"base/manifest/AndroidManifest.xml":       "manifest/",
+		"base/dex/classes.dex":                    "dex/",
+		"base/lib/arm64-v8a/libapp.so":            "native/arm64-v8a",
+		"base/res/drawable-xxhdpi-v4/icon.png":    "resources/xxhdpi",
+		"base/res/layout/main.xml":                "resources/",
+		"base/resources.pb":                       "resources/",
+		"base/assets/data.bin":                    "assets/",
+		"base/root/kotlin/kotlin.kotlin_builtins": "other/",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("categories = %v, want %v", got, want)
+	}
+
+	if d := resourceDensity("mipmap-en-480dpi"); d != "480dpi" {
+		t.Errorf("resourceDensity(480dpi) = %q", d)
+	}
+}
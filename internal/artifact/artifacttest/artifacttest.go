@@ -0,0 +1,587 @@
+// Package artifacttest builds synthetic APK and AAB files for tests. It
+// encodes manifests in both the binary AXML form used by APKs and the aapt2
+// proto form used by bundles, so tests do not need checked-in binaries.
+package artifacttest
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/binary"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf16"
+)
+
+// XML namespaces for framework and dynamic delivery attributes.
+const (
+	AndroidNamespace = "http://schemas.android.com/apk/res/android"
+	DistNamespace    = "http://schemas.android.com/apk/distribution"
+)
+
+// attrIDs maps android: attribute names to framework resource IDs.
+var attrIDs = map[string]uint32{
+	"label":                     0x01010001,
+	"name":                      0x01010003,
+	"permission":                0x01010006,
+	"hasCode":                   0x0101000c,
+	"enabled":                   0x0101000e,
+	"debuggable":                0x0101000f,
+	"exported":                  0x01010010,
+	"scheme":                    0x01010027,
+	"host":                      0x01010028,
+	"path":                      0x0101002a,
+	"pathPrefix":                0x0101002b,
+	"minSdkVersion":             0x0101020c,
+	"versionCode":               0x0101021b,
+	"versionName":               0x0101021c,
+	"targetSdkVersion":          0x01010270,
+	"maxSdkVersion":             0x01010271,
+	"testOnly":                  0x01010272,
+	"allowBackup":               0x01010280,
+	"required":                  0x0101028e,
+	"fullBackupContent":         0x01010473,
+	"extractNativeLibs":         0x010104ea,
+	"usesCleartextTraffic":      0x010104ec,
+	"autoVerify":                0x010104ee,
+	"networkSecurityConfig":     0x01010527,
+	"compileSdkVersion":         0x01010572,
+	"compileSdkVersionCodename": 0x01010573,
+	"dataExtractionRules":       0x0101064a,
+}
+
+// Element is a manifest element fixture.
+type Element struct {
+	Name     string
+	Attrs    []Attr
+	Children []*Element
+}
+
+// Attr is a manifest attribute. Names prefixed with "android:" are encoded in
+// the android namespace with their framework resource ID; names prefixed with
+// "dist:" are encoded in the distribution namespace.
+type Attr struct {
+	Name  string
+	Value string
+}
+
+// E builds an element from name/value attribute pairs.
+func E(name string, attrs []string, children ...*Element) *Element {
+	e := &Element{Name: name, Children: children}
+	for i := 0; i+1 < len(attrs); i += 2 {
+		e.Attrs = append(e.Attrs, Attr{Name: attrs[i], Value: attrs[i+1]})
+	}
+	return e
+}
+
+// Child returns the first direct child with the given name, or nil.
+func (e *Element) Child(name string) *Element {
+	for _, c := range e.Children {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// Set replaces or adds an attribute.
+func (e *Element) Set(name, value string) *Element {
+	for i := range e.Attrs {
+		if e.Attrs[i].Name == name {
+			e.Attrs[i].Value = value
+			return e
+		}
+	}
+	e.Attrs = append(e.Attrs, Attr{Name: name, Value: value})
+	return e
+}
+
+// Manifest returns a representative manifest for pkg at versionCode: SDK
+// 24/34, INTERNET and READ_SMS permissions, an optional camera feature, an
+// exported launcher activity, a private service and a receiver that is
+// exported only implicitly through its intent filter.
+func Manifest(pkg string, versionCode int64) *Element {
+	return E("manifest", []string{
+		"package", pkg,
+		"android:versionCode", strconv.FormatInt(versionCode, 10),
+		"android:versionName", "1.4.2",
+		"android:compileSdkVersion", "34",
+	},
+		E("uses-sdk", []string{"android:minSdkVersion", "24", "android:targetSdkVersion", "34"}),
+		E("uses-permission", []string{"android:name", "android.permission.INTERNET"}),
+		E("uses-permission", []string{"android:name", "android.permission.READ_SMS"}),
+		E("uses-feature", []string{"android:name", "android.hardware.camera", "android:required", "false"}),
+		E("application", []string{"android:label", "Example"},
+			E("activity", []string{"android:name", ".MainActivity", "android:exported", "true"},
+				E("intent-filter", nil,
+					E("action", []string{"android:name", "android.intent.action.MAIN"}),
+					E("category", []string{"android:name", "android.intent.category.LAUNCHER"}),
+				),
+			),
+			E("service", []string{"android:name", ".SyncService", "android:exported", "false"}),
+			E("receiver", []string{"android:name", ".BootReceiver"},
+				E("intent-filter", nil,
+					E("action", []string{"android:name", "android.intent.action.BOOT_COMPLETED"}),
+				),
+			),
+		),
+	)
+}
+
+func splitAttr(name string) (ns, local string, resID uint32) {
+	if local, ok := strings.CutPrefix(name, "android:"); ok {
+		return AndroidNamespace, local, attrIDs[local]
+	}
+	if local, ok := strings.CutPrefix(name, "dist:"); ok {
+		return DistNamespace, local, 0
+	}
+	return "", name, 0
+}
+
+// AXML chunk and value types (see ResourceTypes.h).
+const (
+	resStringPoolType   = 0x0001
+	resXMLType          = 0x0003
+	resXMLStartNSType   = 0x0100
+	resXMLStartElemType = 0x0102
+	resXMLEndElemType   = 0x0103
+	resXMLResourceMap   = 0x0180
+	noEntry             = 0xffffffff
+
+	typeString     = 0x03
+	typeIntDec     = 0x10
+	typeIntBoolean = 0x12
+)
+
+// EncodeAXML serializes an element tree to Android binary XML.
+func EncodeAXML(root *Element) []byte {
+	var (
+		pool   []string
+		index  = map[string]uint32{}
+		resIDs []uint32
+	)
+	intern := func(s string) uint32 {
+		if i, ok := index[s]; ok {
+			return i
+		}
+		index[s] = uint32(len(pool))
+		pool = append(pool, s)
+		return index[s]
+	}
+	// Attribute names with resource IDs must occupy the first pool slots so
+	// the resource map lines up with them.
+	var walkIDs func(e *Element)
+	walkIDs = func(e *Element) {
+		for _, a := range e.Attrs {
+			_, local, id := splitAttr(a.Name)
+			if id == 0 {
+				continue
+			}
+			if _, ok := index[local]; !ok {
+				intern(local)
+				resIDs = append(resIDs, id)
+			}
+		}
+		for _, c := range e.Children {
+			walkIDs(c)
+		}
+	}
+	walkIDs(root)
+
+	var body bytes.Buffer
+	nsPrefix, nsURI := intern("android"), intern(AndroidNamespace)
+	writeChunk(&body, resXMLStartNSType, 16, func(b *bytes.Buffer) {
+		le(b, uint32(1), uint32(noEntry), nsPrefix, nsURI)
+	})
+
+	var walk func(e *Element)
+	walk = func(e *Element) {
+		name := intern(e.Name)
+		writeChunk(&body, resXMLStartElemType, 16, func(b *bytes.Buffer) {
+			le(b, uint32(1), uint32(noEntry))
+			le(b, uint32(noEntry), name, uint16(20), uint16(20), uint16(len(e.Attrs)), uint16(0), uint16(0), uint16(0))
+			for _, a := range e.Attrs {
+				nsURIValue, local, _ := splitAttr(a.Name)
+				ns := uint32(noEntry)
+				if nsURIValue != "" {
+					ns = intern(nsURIValue)
+				}
+				raw := uint32(noEntry)
+				dataType, data := uint8(typeString), uint32(0)
+				if n, err := strconv.ParseInt(a.Value, 10, 32); err == nil {
+					dataType, data = typeIntDec, uint32(int32(n))
+				} else if a.Value == "true" || a.Value == "false" {
+					dataType = typeIntBoolean
+					if a.Value == "true" {
+						data = 0xffffffff
+					}
+				} else {
+					raw = intern(a.Value)
+					data = raw
+				}
+				le(b, ns, intern(local), raw, uint16(8), uint8(0), dataType, data)
+			}
+		})
+		for _, c := range e.Children {
+			walk(c)
+		}
+		writeChunk(&body, resXMLEndElemType, 16, func(b *bytes.Buffer) {
+			le(b, uint32(1), uint32(noEntry), uint32(noEntry), name)
+		})
+	}
+	walk(root)
+
+	var doc bytes.Buffer
+	writeChunk(&doc, resStringPoolType, 28, func(b *bytes.Buffer) {
+		var data bytes.Buffer
+		offsets := make([]uint32, len(pool))
+		for i, s := range pool {
+			offsets[i] = uint32(data.Len())
+			units := utf16.Encode([]rune(s))
+			le(&data, uint16(len(units)))
+			for _, u := range units {
+				le(&data, u)
+			}
+			le(&data, uint16(0))
+		}
+		for data.Len()%4 != 0 {
+			data.WriteByte(0)
+		}
+		stringsStart := uint32(28 + 4*len(pool))
+		le(b, uint32(len(pool)), uint32(0), uint32(0), stringsStart, uint32(0))
+		for _, o := range offsets {
+			le(b, o)
+		}
+		b.Write(data.Bytes())
+	})
+	writeChunk(&doc, resXMLResourceMap, 8, func(b *bytes.Buffer) {
+		for _, id := range resIDs {
+			le(b, id)
+		}
+	})
+	doc.Write(body.Bytes())
+
+	var out bytes.Buffer
+	le(&out, uint16(resXMLType), uint16(8), uint32(8+doc.Len()))
+	out.Write(doc.Bytes())
+	return out.Bytes()
+}
+
+// writeChunk writes a ResChunk_header followed by the payload. headerSize
+// covers the 8 common bytes plus any type-specific header fields the payload
+// function writes first.
+func writeChunk(w *bytes.Buffer, chunkType uint16, headerSize uint16, payload func(*bytes.Buffer)) {
+	var b bytes.Buffer
+	payload(&b)
+	le(w, chunkType, headerSize, uint32(8+b.Len()))
+	w.Write(b.Bytes())
+}
+
+func le(w *bytes.Buffer, values ...interface{}) {
+	for _, v := range values {
+		_ = binary.Write(w, binary.LittleEndian, v)
+	}
+}
+
+// EncodeProtoXML serializes an element tree to an aapt2 XmlNode message.
+func EncodeProtoXML(root *Element) []byte {
+	return ProtoBytes(nil, 1, encodeProtoElement(root))
+}
+
+func encodeProtoElement(e *Element) []byte {
+	var b []byte
+	b = ProtoBytes(b, 3, []byte(e.Name))
+	for _, a := range e.Attrs {
+		ns, local, id := splitAttr(a.Name)
+		var attr []byte
+		if ns != "" {
+			attr = ProtoBytes(attr, 1, []byte(ns))
+		}
+		attr = ProtoBytes(attr, 2, []byte(local))
+		attr = ProtoBytes(attr, 3, []byte(a.Value))
+		if id != 0 {
+			attr = ProtoVarint(attr, 5, uint64(id))
+		}
+		b = ProtoBytes(b, 4, attr)
+	}
+	for _, c := range e.Children {
+		b = ProtoBytes(b, 5, ProtoBytes(nil, 1, encodeProtoElement(c)))
+	}
+	return b
+}
+
+// ProtoBytes appends a length-delimited protobuf field.
+func ProtoBytes(b []byte, field int, payload []byte) []byte {
+	b = binary.AppendUvarint(b, uint64(field<<3|2))
+	b = binary.AppendUvarint(b, uint64(len(payload)))
+	return append(b, payload...)
+}
+
+// ProtoVarint appends a varint protobuf field.
+func ProtoVarint(b []byte, field int, v uint64) []byte {
+	b = binary.AppendUvarint(b, uint64(field<<3))
+	return binary.AppendUvarint(b, v)
+}
+
+// Entry is a fixture archive member. Stored entries are written without
+// compression; Align, when set, pads a stored entry's local header (as
+// zipalign does) so its data starts at a multiple of Align.
+type Entry struct {
+	Name   string
+	Data   []byte
+	Stored bool
+	Align  int
+}
+
+// WriteZip writes entries to name under t.TempDir and returns the path.
+func WriteZip(t *testing.T, name string, entries []Entry) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	// The zip writer only settles an entry's offset once the next one
+	// starts, so aligned entries are padded from the offsets of a previous
+	// pass, first misaligned entry first.
+	extras := make([][]byte, len(entries))
+	for {
+		data := buildZip(t, entries, extras)
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatalf("zip reread: %v", err)
+		}
+		settled := true
+		for i, e := range entries {
+			if !e.Stored || e.Align <= 0 {
+				continue
+			}
+			offset, err := zr.File[i].DataOffset()
+			if err != nil {
+				t.Fatalf("zip offset %s: %v", e.Name, err)
+			}
+			if offset%int64(e.Align) != 0 {
+				extras[i] = alignmentExtra(int(offset)-len(extras[i]), e.Align)
+				settled = false
+				break
+			}
+		}
+		if settled {
+			if err := os.WriteFile(path, data, 0o600); err != nil {
+				t.Fatalf("write %s: %v", path, err)
+			}
+			return path
+		}
+	}
+}
+
+func buildZip(t *testing.T, entries []Entry, extras [][]byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for i, e := range entries {
+		method := zip.Deflate
+		if e.Stored {
+			method = zip.Store
+		}
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: e.Name, Method: method, Extra: extras[i]})
+		if err != nil {
+			t.Fatalf("zip create %s: %v", e.Name, err)
+		}
+		if _, err := w.Write(e.Data); err != nil {
+			t.Fatalf("zip write %s: %v", e.Name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("zip close: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// alignmentExtra returns an extra field that moves data that would start at
+// offset to the next multiple of align, using the zipalign extra field ID.
+func alignmentExtra(offset, align int) []byte {
+	pad := (align - (offset+4)%align) % align
+	extra := binary.LittleEndian.AppendUint16(nil, 0xd935)
+	extra = binary.LittleEndian.AppendUint16(extra, uint16(pad))
+	return append(extra, make([]byte, pad)...)
+}
+
+// ELF returns a minimal little-endian ELF shared object with one PT_LOAD
+// segment aligned to align, 64-bit (AArch64) or 32-bit (ARM).
+func ELF(is64 bool, align uint64) []byte {
+	var b bytes.Buffer
+	b.Write([]byte{0x7f, 'E', 'L', 'F'})
+	if is64 {
+		b.Write([]byte{2, 1, 1})
+		b.Write(make([]byte, 9))
+		// e_type, e_machine, e_version, e_entry, e_phoff, e_shoff, e_flags,
+		// e_ehsize, e_phentsize, e_phnum, e_shentsize, e_shnum, e_shstrndx
+		le(&b, uint16(3), uint16(183), uint32(1), uint64(0), uint64(64), uint64(0), uint32(0),
+			uint16(64), uint16(56), uint16(1), uint16(64), uint16(0), uint16(0))
+		// p_type, p_flags, p_offset, p_vaddr, p_paddr, p_filesz, p_memsz, p_align
+		le(&b, uint32(1), uint32(5), uint64(0), uint64(0), uint64(0), uint64(120), uint64(120), align)
+		return b.Bytes()
+	}
+	b.Write([]byte{1, 1, 1})
+	b.Write(make([]byte, 9))
+	le(&b, uint16(3), uint16(40), uint32(1), uint32(0), uint32(52), uint32(0), uint32(0),
+		uint16(52), uint16(32), uint16(1), uint16(40), uint16(0), uint16(0))
+	// p_type, p_offset, p_vaddr, p_paddr, p_filesz, p_memsz, p_flags, p_align
+	le(&b, uint32(1), uint32(0), uint32(0), uint32(0), uint32(84), uint32(84), uint32(5), uint32(align))
+	return b.Bytes()
+}
+
+// SharedObject returns a 64-bit ARM ELF shared object with one 16 KB
+// aligned PT_LOAD segment, a GNU build ID note (omitted when buildID is
+// nil) and empty sections with the given names, e.g. ".symtab" or
+// ".debug_info" for an unstripped library.
+func SharedObject(buildID []byte, sections ...string) []byte {
+	const ehsize, phentsize, shentsize = 64, 56, 64
+	phnum := 1
+	var note bytes.Buffer
+	if buildID != nil {
+		phnum = 2
+		le(&note, uint32(4), uint32(len(buildID)), uint32(3))
+		note.WriteString("GNU\x00")
+		note.Write(buildID)
+		note.Write(make([]byte, (4-len(buildID)%4)%4))
+	}
+	noteOff := uint64(ehsize + phnum*phentsize)
+
+	// Section names: null, [.note.gnu.build-id], sections..., .shstrtab.
+	names := []string{""}
+	if buildID != nil {
+		names = append(names, ".note.gnu.build-id")
+	}
+	names = append(append(names, sections...), ".shstrtab")
+	var strtab bytes.Buffer
+	nameOff := make([]uint32, len(names))
+	for i, n := range names {
+		nameOff[i] = uint32(strtab.Len())
+		strtab.WriteString(n + "\x00")
+	}
+	strOff := noteOff + uint64(note.Len())
+	shoff := (strOff + uint64(strtab.Len()) + 7) &^ 7
+
+	var b bytes.Buffer
+	b.Write([]byte{0x7f, 'E', 'L', 'F', 2, 1, 1})
+	b.Write(make([]byte, 9))
+	le(&b, uint16(3), uint16(183), uint32(1), uint64(0), uint64(ehsize), shoff, uint32(0),
+		uint16(ehsize), uint16(phentsize), uint16(phnum), uint16(shentsize), uint16(len(names)), uint16(len(names)-1))
+	le(&b, uint32(1), uint32(5), uint64(0), uint64(0), uint64(0), shoff, shoff, uint64(16384))
+	if buildID != nil {
+		le(&b, uint32(4), uint32(4), noteOff, noteOff, noteOff, uint64(note.Len()), uint64(note.Len()), uint64(4))
+	}
+	b.Write(note.Bytes())
+	b.Write(strtab.Bytes())
+	b.Write(make([]byte, int(shoff)-b.Len()))
+
+	// sh_name, sh_type, sh_flags, sh_addr, sh_offset, sh_size, sh_link,
+	// sh_info, sh_addralign, sh_entsize
+	for i, n := range names {
+		typ, off, size := uint32(1), strOff, uint64(0)
+		switch {
+		case i == 0:
+			typ, off = 0, 0
+		case n == ".note.gnu.build-id":
+			typ, off, size = 7, noteOff, uint64(note.Len())
+		case n == ".symtab":
+			typ = 2
+		case n == ".shstrtab":
+			typ, size = 3, uint64(strtab.Len())
+		}
+		le(&b, nameOff[i], typ, uint64(0), uint64(0), off, size, uint32(0), uint32(0), uint64(1), uint64(0))
+	}
+	return b.Bytes()
+}
+
+// WriteAPK writes an APK with the given manifest plus extra entries.
+func WriteAPK(t *testing.T, manifest *Element, extra ...Entry) string {
+	t.Helper()
+	entries := append([]Entry{
+		{Name: "AndroidManifest.xml", Data: EncodeAXML(manifest)},
+		{Name: "classes.dex", Data: []byte("dex\n035\x00")},
+	}, extra...)
+	return WriteZip(t, "app.apk", entries)
+}
+
+// WriteAAB writes a bundle with the given base manifest plus extra entries.
+func WriteAAB(t *testing.T, manifest *Element, extra ...Entry) string {
+	t.Helper()
+	entries := append([]Entry{
+		{Name: "BundleConfig.pb", Data: ProtoBytes(nil, 1, ProtoBytes(nil, 2, []byte("1.15.6")))},
+		{Name: "base/manifest/AndroidManifest.xml", Data: EncodeProtoXML(manifest)},
+		{Name: "base/dex/classes.dex", Data: []byte("dex\n035\x00")},
+	}, extra...)
+	return WriteZip(t, "app.aab", entries)
+}
+
+// Certificate returns a throwaway self-signed certificate (DER) for cn and
+// its private key.
+func Certificate(t *testing.T, cn string) ([]byte, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Unix(0, 0),
+		NotAfter:     time.Unix(0, 0).AddDate(50, 0, 0),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	return der, key
+}
+
+// InsertSigningBlock rewrites the zip at path with an APK Signing Block
+// holding the given ID-value pairs immediately before the central directory,
+// patching the End of Central Directory offset to match.
+func InsertSigningBlock(t *testing.T, path string, pairs map[uint32][]byte) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	eocd := len(data) - 22
+	if eocd < 0 || binary.LittleEndian.Uint32(data[eocd:]) != 0x06054b50 {
+		t.Fatalf("%s: end of central directory not at expected offset", path)
+	}
+	cd := int(binary.LittleEndian.Uint32(data[eocd+16:]))
+
+	var ids []uint32
+	for id := range pairs {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	var body bytes.Buffer
+	for _, id := range ids {
+		le(&body, uint64(4+len(pairs[id])), id)
+		body.Write(pairs[id])
+	}
+	size := uint64(body.Len() + 8 + 16)
+	var block bytes.Buffer
+	le(&block, size)
+	block.Write(body.Bytes())
+	le(&block, size)
+	block.WriteString("APK Sig Block 42")
+
+	out := make([]byte, 0, len(data)+block.Len())
+	out = append(out, data[:cd]...)
+	out = append(out, block.Bytes()...)
+	out = append(out, data[cd:]...)
+	binary.LittleEndian.PutUint32(out[eocd+block.Len()+16:], uint32(cd+block.Len()))
+	if err := os.WriteFile(path, out, 0o600); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
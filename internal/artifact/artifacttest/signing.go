@@ -0,0 +1,290 @@
+package artifacttest
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"testing"
+)
+
+// Signing block IDs and the ECDSA-with-SHA-256 algorithm the fixtures use.
+const (
+	BlockIDV2        = 0x7109871a
+	BlockIDV3        = 0xf05368c0
+	sigECDSASHA256   = 0x0201
+	attrIDRotation   = 0x3ba06f8c
+	v3MaxSDKUnbound  = 0x7fffffff
+	contentChunkSize = 1 << 20
+)
+
+// Signer is a throwaway signing identity. Previous, when set, is the key it
+// rotated from; v3 signatures then carry the proof-of-rotation lineage.
+type Signer struct {
+	Cert     []byte
+	Key      *ecdsa.PrivateKey
+	Previous *Signer
+}
+
+// NewSigner returns a signer with a fresh self-signed certificate for cn.
+func NewSigner(t *testing.T, cn string) *Signer {
+	t.Helper()
+	cert, key := Certificate(t, cn)
+	return &Signer{Cert: cert, Key: key}
+}
+
+// Fingerprint returns the certificate SHA-256 as colon-separated upper-case
+// hex.
+func (s *Signer) Fingerprint() string {
+	sum := sha256.Sum256(s.Cert)
+	var b bytes.Buffer
+	for i, c := range sum {
+		if i > 0 {
+			b.WriteByte(':')
+		}
+		fmt.Fprintf(&b, "%02X", c)
+	}
+	return b.String()
+}
+
+func (s *Signer) sign(data []byte) []byte {
+	digest := sha256.Sum256(data)
+	sig, err := ecdsa.SignASN1(rand.Reader, s.Key, digest[:])
+	if err != nil {
+		panic(err)
+	}
+	return sig
+}
+
+// SignJAR rewrites the zip at path with a JAR (v1) signature by s: a
+// MANIFEST.MF listing every entry, a .SF over it, and a PKCS#7 .EC block.
+func (s *Signer) SignJAR(t *testing.T, path string) {
+	t.Helper()
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatalf("open %s: %v", path, err)
+	}
+	defer func() { _ = zr.Close() }()
+
+	var out bytes.Buffer
+	zw := zip.NewWriter(&out)
+	mf := bytes.NewBufferString("Manifest-Version: 1.0\r\nCreated-By: gpd artifacttest\r\n\r\n")
+	sf := bytes.Buffer{}
+	var sections bytes.Buffer
+	for _, f := range zr.File {
+		if err := zw.Copy(f); err != nil {
+			t.Fatalf("copy %s: %v", f.Name, err)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("read %s: %v", f.Name, err)
+		}
+		data, _ := io.ReadAll(rc)
+		_ = rc.Close()
+		sum := sha256.Sum256(data)
+		section := fmt.Sprintf("Name: %s\r\nSHA-256-Digest: %s\r\n\r\n", f.Name, base64.StdEncoding.EncodeToString(sum[:]))
+		mf.WriteString(section)
+		sectionSum := sha256.Sum256([]byte(section))
+		fmt.Fprintf(&sections, "Name: %s\r\nSHA-256-Digest: %s\r\n\r\n", f.Name, base64.StdEncoding.EncodeToString(sectionSum[:]))
+	}
+	mfSum := sha256.Sum256(mf.Bytes())
+	fmt.Fprintf(&sf, "Signature-Version: 1.0\r\nSHA-256-Digest-Manifest: %s\r\nCreated-By: gpd artifacttest\r\n\r\n",
+		base64.StdEncoding.EncodeToString(mfSum[:]))
+	sf.Write(sections.Bytes())
+
+	for _, e := range []Entry{
+		{Name: "META-INF/MANIFEST.MF", Data: mf.Bytes()},
+		{Name: "META-INF/UPLOAD.SF", Data: sf.Bytes()},
+		{Name: "META-INF/UPLOAD.EC", Data: s.pkcs7(t, sf.Bytes())},
+	} {
+		w, err := zw.Create(e.Name)
+		if err != nil {
+			t.Fatalf("zip create %s: %v", e.Name, err)
+		}
+		_, _ = w.Write(e.Data)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("zip close: %v", err)
+	}
+	if err := os.WriteFile(path, out.Bytes(), 0o600); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+// pkcs7 builds a detached PKCS#7 SignedData over content without
+// authenticated attributes, as apksigner writes them.
+func (s *Signer) pkcs7(t *testing.T, content []byte) []byte {
+	t.Helper()
+	cert, err := x509.ParseCertificate(s.Cert)
+	if err != nil {
+		t.Fatalf("parse certificate: %v", err)
+	}
+	sha256Alg := pkix.AlgorithmIdentifier{Algorithm: asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}}
+	ecAlg := pkix.AlgorithmIdentifier{Algorithm: asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}}
+	signerInfo, err := asn1.Marshal(struct {
+		Version         int
+		IssuerAndSerial struct {
+			Issuer asn1.RawValue
+			Serial *big.Int
+		}
+		DigestAlgorithm           pkix.AlgorithmIdentifier
+		DigestEncryptionAlgorithm pkix.AlgorithmIdentifier
+		EncryptedDigest           []byte
+	}{
+		Version: 1,
+		IssuerAndSerial: struct {
+			Issuer asn1.RawValue
+			Serial *big.Int
+		}{asn1.RawValue{FullBytes: cert.RawIssuer}, cert.SerialNumber},
+		DigestAlgorithm:           sha256Alg,
+		DigestEncryptionAlgorithm: ecAlg,
+		EncryptedDigest:           s.sign(content),
+	})
+	if err != nil {
+		t.Fatalf("marshal signer info: %v", err)
+	}
+	digestAlgs, _ := asn1.Marshal(sha256Alg)
+	data, _ := asn1.Marshal(struct{ ContentType asn1.ObjectIdentifier }{asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}})
+	sd, err := asn1.Marshal(struct {
+		Version          int
+		DigestAlgorithms asn1.RawValue
+		ContentInfo      asn1.RawValue
+		Certificates     asn1.RawValue
+		SignerInfos      asn1.RawValue
+	}{
+		Version:          1,
+		DigestAlgorithms: asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: digestAlgs},
+		ContentInfo:      asn1.RawValue{FullBytes: data},
+		Certificates:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: s.Cert},
+		SignerInfos:      asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: signerInfo},
+	})
+	if err != nil {
+		t.Fatalf("marshal signed data: %v", err)
+	}
+	out, err := asn1.Marshal(struct {
+		ContentType asn1.ObjectIdentifier
+		Content     asn1.RawValue
+	}{
+		ContentType: asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2},
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: sd},
+	})
+	if err != nil {
+		t.Fatalf("marshal content info: %v", err)
+	}
+	return out
+}
+
+// SignAPK inserts an APK Signing Block into the APK at path holding a v2
+// and/or v3 signature by s (pass BlockIDV2, BlockIDV3). Sign with SignJAR
+// first when a v1 signature is also wanted: rewriting the zip afterwards
+// would drop the block.
+func (s *Signer) SignAPK(t *testing.T, path string, blockIDs ...uint32) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	eocd := len(data) - 22
+	cd := int(binary.LittleEndian.Uint32(data[eocd+16:]))
+	// The block goes where the central directory starts, so the EOCD offset
+	// the digest covers is unchanged.
+	digest := chunkedSHA256(data[:cd], data[cd:eocd], data[eocd:])
+
+	pairs := make(map[uint32][]byte, len(blockIDs))
+	for _, id := range blockIDs {
+		pairs[id] = s.schemeBlock(id == BlockIDV3, digest)
+	}
+	InsertSigningBlock(t, path, pairs)
+}
+
+func (s *Signer) schemeBlock(v3 bool, digest []byte) []byte {
+	digests := lp(lp(u32(sigECDSASHA256), lp(digest)))
+	certs := lp(lp(s.Cert))
+	var attrs []byte
+	if v3 && s.Previous != nil {
+		attrs = lp(u32(attrIDRotation), s.lineage())
+	}
+	var signedData []byte
+	if v3 {
+		signedData = cat(digests, certs, u32(24), u32(v3MaxSDKUnbound), lp(attrs))
+	} else {
+		signedData = cat(digests, certs, lp(attrs))
+	}
+	cert, _ := x509.ParseCertificate(s.Cert)
+	signatures := lp(lp(u32(sigECDSASHA256), lp(s.sign(signedData))))
+
+	var signer []byte
+	if v3 {
+		signer = cat(lp(signedData), u32(24), u32(v3MaxSDKUnbound), signatures, lp(cert.RawSubjectPublicKeyInfo))
+	} else {
+		signer = cat(lp(signedData), signatures, lp(cert.RawSubjectPublicKeyInfo))
+	}
+	return lp(lp(signer))
+}
+
+// lineage encodes the proof-of-rotation chain ending with s, each node
+// signed by its predecessor.
+func (s *Signer) lineage() []byte {
+	var chain []*Signer
+	for cur := s; cur != nil; cur = cur.Previous {
+		chain = append([]*Signer{cur}, chain...)
+	}
+	out := u32(1)
+	var parent *Signer
+	for _, node := range chain {
+		signedData := cat(lp(node.Cert), u32(sigECDSASHA256))
+		algo, sig := uint32(0), []byte(nil)
+		if parent != nil {
+			algo, sig = sigECDSASHA256, parent.sign(signedData)
+		}
+		out = append(out, lp(cat(lp(signedData), u32(0), u32(algo), lp(sig)))...)
+		parent = node
+	}
+	return out
+}
+
+// chunkedSHA256 is the v2/v3 content digest over the given sections.
+func chunkedSHA256(sections ...[]byte) []byte {
+	var chunks []byte
+	count := 0
+	for _, section := range sections {
+		for len(section) > 0 {
+			n := min(len(section), contentChunkSize)
+			h := sha256.New()
+			h.Write(cat([]byte{0xa5}, u32(uint32(n)), section[:n]))
+			chunks = h.Sum(chunks)
+			count++
+			section = section[n:]
+		}
+	}
+	top := sha256.Sum256(cat([]byte{0x5a}, u32(uint32(count)), chunks))
+	return top[:]
+}
+
+func u32(v uint32) []byte {
+	return binary.LittleEndian.AppendUint32(nil, v)
+}
+
+// lp length-prefixes the concatenation of parts.
+func lp(parts ...[]byte) []byte {
+	body := cat(parts...)
+	return append(u32(uint32(len(body))), body...)
+}
+
+func cat(parts ...[]byte) []byte {
+	var out []byte
+	for _, p := range parts {
+		out = append(out, p...)
+	}
+	return out
+}
@@ -0,0 +1,271 @@
+package artifact
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"unicode/utf16"
+)
+
+// Chunk types used by the Android binary XML (AXML) format.
+const (
+	resStringPoolType   = 0x0001
+	resXMLType          = 0x0003
+	resXMLStartNSType   = 0x0100
+	resXMLEndNSType     = 0x0101
+	resXMLStartElemType = 0x0102
+	resXMLEndElemType   = 0x0103
+	resXMLCDataType     = 0x0104
+	resXMLResourceMap   = 0x0180
+
+	stringPoolUTF8Flag = 1 << 8
+	noEntry            = 0xffffffff
+)
+
+// Res_value data types relevant to manifest attributes.
+const (
+	typeReference  = 0x01
+	typeAttribute  = 0x02
+	typeString     = 0x03
+	typeFloat      = 0x04
+	typeIntDec     = 0x10
+	typeIntHex     = 0x11
+	typeIntBoolean = 0x12
+)
+
+// parseAXML decodes a binary AndroidManifest.xml (as found in APKs) into an
+// element tree.
+func parseAXML(data []byte) (*xmlElement, error) {
+	if len(data) < 8 {
+		return nil, fmt.Errorf("binary xml too short")
+	}
+	if binary.LittleEndian.Uint16(data[0:2]) != resXMLType {
+		return nil, fmt.Errorf("not a binary xml document")
+	}
+	headerSize := int(binary.LittleEndian.Uint16(data[2:4]))
+	total := int(binary.LittleEndian.Uint32(data[4:8]))
+	if total > len(data) {
+		total = len(data)
+	}
+
+	var (
+		pool   []string
+		resMap []uint32
+		root   *xmlElement
+		stack  []*xmlElement
+	)
+
+	for off := headerSize; off+8 <= total; {
+		chunkType := binary.LittleEndian.Uint16(data[off : off+2])
+		chunkHeader := int(binary.LittleEndian.Uint16(data[off+2 : off+4]))
+		chunkSize := int(binary.LittleEndian.Uint32(data[off+4 : off+8]))
+		if chunkSize < 8 || off+chunkSize > total {
+			return nil, fmt.Errorf("invalid chunk size %d at offset %d", chunkSize, off)
+		}
+		chunk := data[off : off+chunkSize]
+
+		switch chunkType {
+		case resStringPoolType:
+			var err error
+			pool, err = parseStringPool(chunk)
+			if err != nil {
+				return nil, err
+			}
+		case resXMLResourceMap:
+			for i := chunkHeader; i+4 <= chunkSize; i += 4 {
+				resMap = append(resMap, binary.LittleEndian.Uint32(chunk[i:i+4]))
+			}
+		case resXMLStartElemType:
+			elem, err := parseStartElement(chunk, chunkHeader, pool, resMap)
+			if err != nil {
+				return nil, err
+			}
+			if len(stack) == 0 {
+				if root == nil {
+					root = elem
+				}
+			} else {
+				parent := stack[len(stack)-1]
+				parent.Children = append(parent.Children, elem)
+			}
+			stack = append(stack, elem)
+		case resXMLEndElemType:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case resXMLStartNSType, resXMLEndNSType, resXMLCDataType:
+			// Namespace and text nodes carry nothing the manifest model needs.
+		}
+		off += chunkSize
+	}
+
+	if root == nil {
+		return nil, fmt.Errorf("binary xml has no root element")
+	}
+	return root, nil
+}
+
+func parseStartElement(chunk []byte, headerSize int, pool []string, resMap []uint32) (*xmlElement, error) {
+	if len(chunk) < headerSize+20 {
+		return nil, fmt.Errorf("truncated start element")
+	}
+	ext := chunk[headerSize:]
+	elem := &xmlElement{
+		Name: poolString(pool, binary.LittleEndian.Uint32(ext[4:8])),
+	}
+	attrStart := int(binary.LittleEndian.Uint16(ext[8:10]))
+	attrSize := int(binary.LittleEndian.Uint16(ext[10:12]))
+	attrCount := int(binary.LittleEndian.Uint16(ext[12:14]))
+	if attrSize < 20 {
+		attrSize = 20
+	}
+
+	for i := 0; i < attrCount; i++ {
+		a := attrStart + i*attrSize
+		if a+20 > len(ext) {
+			return nil, fmt.Errorf("truncated attribute in <%s>", elem.Name)
+		}
+		nameIdx := binary.LittleEndian.Uint32(ext[a+4 : a+8])
+		rawIdx := binary.LittleEndian.Uint32(ext[a+8 : a+12])
+		dataType := ext[a+15]
+		value := binary.LittleEndian.Uint32(ext[a+16 : a+20])
+
+		attr := xmlAttr{
+			Namespace: poolString(pool, binary.LittleEndian.Uint32(ext[a:a+4])),
+			Name:      poolString(pool, nameIdx),
+		}
+		if int(nameIdx) < len(resMap) {
+			attr.ResID = resMap[nameIdx]
+		}
+		attr.Value = formatTypedValue(pool, rawIdx, dataType, value)
+		elem.Attrs = append(elem.Attrs, attr)
+	}
+	return elem, nil
+}
+
+// formatTypedValue renders a Res_value the way aapt2 dump would show it.
+func formatTypedValue(pool []string, rawIdx uint32, dataType uint8, value uint32) string {
+	switch dataType {
+	case typeString:
+		return poolString(pool, value)
+	case typeIntDec:
+		return strconv.FormatInt(int64(int32(value)), 10)
+	case typeFloat:
+		return strconv.FormatFloat(float64(math.Float32frombits(value)), 'g', -1, 32)
+	case typeIntHex:
+		return fmt.Sprintf("0x%x", value)
+	case typeIntBoolean:
+		if value != 0 {
+			return "true"
+		}
+		return "false"
+	case typeReference:
+		return fmt.Sprintf("@0x%08x", value)
+	case typeAttribute:
+		return fmt.Sprintf("?0x%08x", value)
+	}
+	if rawIdx != noEntry {
+		return poolString(pool, rawIdx)
+	}
+	return strconv.FormatUint(uint64(value), 10)
+}
+
+func poolString(pool []string, idx uint32) string {
+	if idx == noEntry || int(idx) >= len(pool) {
+		return ""
+	}
+	return pool[idx]
+}
+
+// parseStringPool decodes a ResStringPool chunk in either UTF-8 or UTF-16 form.
+func parseStringPool(chunk []byte) ([]string, error) {
+	if len(chunk) < 28 {
+		return nil, fmt.Errorf("truncated string pool")
+	}
+	headerSize := int(binary.LittleEndian.Uint16(chunk[2:4]))
+	count := int(binary.LittleEndian.Uint32(chunk[8:12]))
+	flags := binary.LittleEndian.Uint32(chunk[16:20])
+	stringsStart := int(binary.LittleEndian.Uint32(chunk[20:24]))
+	utf8 := flags&stringPoolUTF8Flag != 0
+
+	if headerSize+count*4 > len(chunk) {
+		return nil, fmt.Errorf("string pool offsets out of range")
+	}
+
+	out := make([]string, count)
+	for i := 0; i < count; i++ {
+		rel := int(binary.LittleEndian.Uint32(chunk[headerSize+i*4:]))
+		pos := stringsStart + rel
+		if pos >= len(chunk) {
+			return nil, fmt.Errorf("string %d out of range", i)
+		}
+		var (
+			s   string
+			err error
+		)
+		if utf8 {
+			s, err = decodeUTF8PoolString(chunk[pos:])
+		} else {
+			s, err = decodeUTF16PoolString(chunk[pos:])
+		}
+		if err != nil {
+			return nil, fmt.Errorf("string %d: %w", i, err)
+		}
+		out[i] = s
+	}
+	return out, nil
+}
+
+func decodeUTF8PoolString(b []byte) (string, error) {
+	// UTF-16 length first, then UTF-8 byte length; each is 1 or 2 bytes.
+	_, n := utf8PoolLength(b)
+	if n == 0 {
+		return "", fmt.Errorf("truncated length")
+	}
+	size, m := utf8PoolLength(b[n:])
+	if m == 0 {
+		return "", fmt.Errorf("truncated length")
+	}
+	start := n + m
+	if start+size > len(b) {
+		return "", fmt.Errorf("truncated data")
+	}
+	return string(b[start : start+size]), nil
+}
+
+func utf8PoolLength(b []byte) (int, int) {
+	if len(b) < 1 {
+		return 0, 0
+	}
+	if b[0]&0x80 == 0 {
+		return int(b[0]), 1
+	}
+	if len(b) < 2 {
+		return 0, 0
+	}
+	return int(b[0]&0x7f)<<8 | int(b[1]), 2
+}
+
+func decodeUTF16PoolString(b []byte) (string, error) {
+	if len(b) < 2 {
+		return "", fmt.Errorf("truncated length")
+	}
+	length := int(binary.LittleEndian.Uint16(b))
+	start := 2
+	if length&0x8000 != 0 {
+		if len(b) < 4 {
+			return "", fmt.Errorf("truncated length")
+		}
+		length = (length&0x7fff)<<16 | int(binary.LittleEndian.Uint16(b[2:]))
+		start = 4
+	}
+	if start+length*2 > len(b) {
+		return "", fmt.Errorf("truncated data")
+	}
+	units := make([]uint16, length)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(b[start+i*2:])
+	}
+	return string(utf16.Decode(units)), nil
+}
@@ -0,0 +1,371 @@
+package artifact
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// XML namespaces for framework and dynamic delivery attributes.
+const (
+	androidNamespace = "http://schemas.android.com/apk/res/android"
+	distNamespace    = "http://schemas.android.com/apk/distribution"
+)
+
+// Module delivery modes declared by <dist:module>.
+const (
+	DeliveryInstallTime = "install-time"
+	DeliveryFastFollow  = "fast-follow"
+	DeliveryOnDemand    = "on-demand"
+)
+
+// androidAttrNames maps framework attribute resource IDs to their names.
+// Compiled manifests may strip or obfuscate attribute name strings, but the
+// resource ID is always present for android: attributes.
+var androidAttrNames = map[uint32]string{
+	0x01010001: "label",
+	0x01010003: "name",
+	0x01010006: "permission",
+	0x0101000c: "hasCode",
+	0x0101000e: "enabled",
+	0x0101000f: "debuggable",
+	0x01010010: "exported",
+	0x01010027: "scheme",
+	0x01010028: "host",
+	0x01010029: "port",
+	0x0101002a: "path",
+	0x0101002b: "pathPrefix",
+	0x0101002c: "pathPattern",
+	0x0101020c: "minSdkVersion",
+	0x0101021b: "versionCode",
+	0x0101021c: "versionName",
+	0x01010270: "targetSdkVersion",
+	0x01010271: "maxSdkVersion",
+	0x01010272: "testOnly",
+	0x01010280: "allowBackup",
+	0x01010281: "glEsVersion",
+	0x0101028e: "required",
+	0x01010473: "fullBackupContent",
+	0x010104ea: "extractNativeLibs",
+	0x010104ec: "usesCleartextTraffic",
+	0x010104ee: "autoVerify",
+	0x01010527: "networkSecurityConfig",
+	0x01010572: "compileSdkVersion",
+	0x01010573: "compileSdkVersionCodename",
+	0x0101064a: "dataExtractionRules",
+}
+
+// xmlElement is a format-neutral manifest element produced by both the AXML
+// and proto decoders.
+type xmlElement struct {
+	Name     string
+	Attrs    []xmlAttr
+	Children []*xmlElement
+}
+
+type xmlAttr struct {
+	Namespace string
+	Name      string
+	Value     string
+	ResID     uint32
+}
+
+// attr returns the value of an android: (or un-namespaced) attribute.
+func (e *xmlElement) attr(name string) (string, bool) {
+	for _, a := range e.Attrs {
+		attrName := a.Name
+		if known, ok := androidAttrNames[a.ResID]; ok {
+			attrName = known
+		}
+		if attrName != name {
+			continue
+		}
+		if a.Namespace == "" || a.Namespace == androidNamespace {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+// distAttr returns the value of a dist: attribute.
+func (e *xmlElement) distAttr(name string) (string, bool) {
+	for _, a := range e.Attrs {
+		if a.Namespace == distNamespace && a.Name == name {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+func (e *xmlElement) attrString(name string) string {
+	v, _ := e.attr(name)
+	return v
+}
+
+func (e *xmlElement) attrInt(name string) int64 {
+	v, ok := e.attr(name)
+	if !ok {
+		return 0
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(v), 0, 64)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+func (e *xmlElement) attrBool(name string) *bool {
+	v, ok := e.attr(name)
+	if !ok {
+		return nil
+	}
+	b, err := strconv.ParseBool(strings.TrimSpace(v))
+	if err != nil {
+		return nil
+	}
+	return &b
+}
+
+func (e *xmlElement) children(name string) []*xmlElement {
+	var out []*xmlElement
+	for _, c := range e.Children {
+		if c.Name == name {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// Manifest is the subset of AndroidManifest.xml gpd reasons about.
+type Manifest struct {
+	Package            string       `json:"package"`
+	Split              string       `json:"split,omitempty"`
+	VersionCode        int64        `json:"versionCode"`
+	VersionName        string       `json:"versionName,omitempty"`
+	MinSDK             int          `json:"minSdk,omitempty"`
+	TargetSDK          int          `json:"targetSdk,omitempty"`
+	MaxSDK             int          `json:"maxSdk,omitempty"`
+	CompileSDK         int          `json:"compileSdk,omitempty"`
+	CompileSDKCodename string       `json:"compileSdkCodename,omitempty"`
+	Permissions        []Permission `json:"permissions"`
+	Features           []Feature    `json:"features"`
+	Activities         []Component  `json:"activities"`
+	Services           []Component  `json:"services"`
+	Receivers          []Component  `json:"receivers"`
+	Providers          []Component  `json:"providers"`
+	Application        *Application `json:"application,omitempty"`
+	Module             *ModuleInfo  `json:"module,omitempty"`
+}
+
+// Application holds the security-relevant <application> attributes. Flags
+// are nil when the attribute is absent so the platform default applies.
+type Application struct {
+	Debuggable            *bool  `json:"debuggable,omitempty"`
+	TestOnly              *bool  `json:"testOnly,omitempty"`
+	AllowBackup           *bool  `json:"allowBackup,omitempty"`
+	FullBackupContent     string `json:"fullBackupContent,omitempty"`
+	DataExtractionRules   string `json:"dataExtractionRules,omitempty"`
+	UsesCleartextTraffic  *bool  `json:"usesCleartextTraffic,omitempty"`
+	NetworkSecurityConfig string `json:"networkSecurityConfig,omitempty"`
+}
+
+// ModuleInfo is the <dist:module> declaration of a bundle module.
+type ModuleInfo struct {
+	// Type is "feature" or "asset-pack".
+	Type     string `json:"type"`
+	Delivery string `json:"delivery"`
+}
+
+// Permission is a <uses-permission> (or uses-permission-sdk-23) entry.
+type Permission struct {
+	Name   string `json:"name"`
+	MaxSDK int    `json:"maxSdk,omitempty"`
+}
+
+// Feature is a <uses-feature> entry.
+type Feature struct {
+	Name        string `json:"name,omitempty"`
+	Required    bool   `json:"required"`
+	GLESVersion string `json:"glEsVersion,omitempty"`
+}
+
+// Component is an activity, activity-alias, service, receiver or provider.
+type Component struct {
+	Name          string         `json:"name"`
+	Exported      *bool          `json:"exported,omitempty"`
+	Permission    string         `json:"permission,omitempty"`
+	Enabled       *bool          `json:"enabled,omitempty"`
+	IntentFilters []IntentFilter `json:"intentFilters,omitempty"`
+}
+
+// IntentFilter is an <intent-filter> declared on a component.
+type IntentFilter struct {
+	Actions    []string     `json:"actions,omitempty"`
+	Categories []string     `json:"categories,omitempty"`
+	Data       []IntentData `json:"data,omitempty"`
+	AutoVerify bool         `json:"autoVerify,omitempty"`
+}
+
+// IntentData is a <data> element within an intent filter.
+type IntentData struct {
+	Scheme string `json:"scheme,omitempty"`
+	Host   string `json:"host,omitempty"`
+	Path   string `json:"path,omitempty"`
+}
+
+// IsExported reports whether the component is reachable from other apps.
+// Without an explicit android:exported, components with intent filters are
+// exported by default (an install-time error from API 31 onwards).
+func (c Component) IsExported() bool {
+	if c.Exported != nil {
+		return *c.Exported
+	}
+	return len(c.IntentFilters) > 0
+}
+
+// PermissionNames returns the requested permission names, sorted.
+func (m *Manifest) PermissionNames() []string {
+	out := make([]string, 0, len(m.Permissions))
+	for _, p := range m.Permissions {
+		out = append(out, p.Name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// newManifest builds a Manifest from a decoded <manifest> element.
+func newManifest(root *xmlElement) *Manifest {
+	m := &Manifest{
+		Package:            root.attrString("package"),
+		Split:              root.attrString("split"),
+		VersionCode:        root.attrInt("versionCode"),
+		VersionName:        root.attrString("versionName"),
+		CompileSDK:         int(root.attrInt("compileSdkVersion")),
+		CompileSDKCodename: root.attrString("compileSdkVersionCodename"),
+		Permissions:        make([]Permission, 0),
+		Features:           make([]Feature, 0),
+		Activities:         make([]Component, 0),
+		Services:           make([]Component, 0),
+		Receivers:          make([]Component, 0),
+		Providers:          make([]Component, 0),
+	}
+
+	for _, child := range root.Children {
+		switch child.Name {
+		case "uses-sdk":
+			m.MinSDK = int(child.attrInt("minSdkVersion"))
+			m.TargetSDK = int(child.attrInt("targetSdkVersion"))
+			m.MaxSDK = int(child.attrInt("maxSdkVersion"))
+		case "uses-permission", "uses-permission-sdk-23":
+			m.Permissions = append(m.Permissions, Permission{
+				Name:   child.attrString("name"),
+				MaxSDK: int(child.attrInt("maxSdkVersion")),
+			})
+		case "uses-feature":
+			required := true
+			if r := child.attrBool("required"); r != nil {
+				required = *r
+			}
+			m.Features = append(m.Features, Feature{
+				Name:        child.attrString("name"),
+				Required:    required,
+				GLESVersion: child.attrString("glEsVersion"),
+			})
+		case "application":
+			m.parseApplication(child)
+		case "module":
+			m.Module = newModuleInfo(child)
+		}
+	}
+
+	// The minimum SDK defaults to 1 and the target SDK to the minimum.
+	if m.MinSDK == 0 {
+		m.MinSDK = 1
+	}
+	if m.TargetSDK == 0 {
+		m.TargetSDK = m.MinSDK
+	}
+	return m
+}
+
+// newModuleInfo decodes <dist:module>. Without an explicit <dist:delivery>,
+// the legacy dist:onDemand flag decides, and install-time is the default.
+func newModuleInfo(e *xmlElement) *ModuleInfo {
+	info := &ModuleInfo{Type: "feature", Delivery: DeliveryInstallTime}
+	if t, ok := e.distAttr("type"); ok && t != "" {
+		info.Type = t
+	}
+	if v, ok := e.distAttr("onDemand"); ok && v == "true" {
+		info.Delivery = DeliveryOnDemand
+	}
+	for _, d := range e.children("delivery") {
+		for _, mode := range d.Children {
+			switch mode.Name {
+			case DeliveryInstallTime, DeliveryFastFollow, DeliveryOnDemand:
+				info.Delivery = mode.Name
+			}
+		}
+	}
+	return info
+}
+
+func (m *Manifest) parseApplication(app *xmlElement) {
+	m.Application = &Application{
+		Debuggable:            app.attrBool("debuggable"),
+		TestOnly:              app.attrBool("testOnly"),
+		AllowBackup:           app.attrBool("allowBackup"),
+		FullBackupContent:     app.attrString("fullBackupContent"),
+		DataExtractionRules:   app.attrString("dataExtractionRules"),
+		UsesCleartextTraffic:  app.attrBool("usesCleartextTraffic"),
+		NetworkSecurityConfig: app.attrString("networkSecurityConfig"),
+	}
+	for _, child := range app.Children {
+		switch child.Name {
+		case "activity", "activity-alias":
+			m.Activities = append(m.Activities, newComponent(child))
+		case "service":
+			m.Services = append(m.Services, newComponent(child))
+		case "receiver":
+			m.Receivers = append(m.Receivers, newComponent(child))
+		case "provider":
+			m.Providers = append(m.Providers, newComponent(child))
+		}
+	}
+}
+
+func newComponent(e *xmlElement) Component {
+	c := Component{
+		Name:       e.attrString("name"),
+		Exported:   e.attrBool("exported"),
+		Permission: e.attrString("permission"),
+		Enabled:    e.attrBool("enabled"),
+	}
+	for _, f := range e.children("intent-filter") {
+		filter := IntentFilter{}
+		if v := f.attrBool("autoVerify"); v != nil {
+			filter.AutoVerify = *v
+		}
+		for _, a := range f.children("action") {
+			filter.Actions = append(filter.Actions, a.attrString("name"))
+		}
+		for _, cat := range f.children("category") {
+			filter.Categories = append(filter.Categories, cat.attrString("name"))
+		}
+		for _, d := range f.children("data") {
+			data := IntentData{
+				Scheme: d.attrString("scheme"),
+				Host:   d.attrString("host"),
+				Path:   d.attrString("path"),
+			}
+			if data.Path == "" {
+				data.Path = d.attrString("pathPrefix")
+			}
+			if data.Path == "" {
+				data.Path = d.attrString("pathPattern")
+			}
+			filter.Data = append(filter.Data, data)
+		}
+		c.IntentFilters = append(c.IntentFilters, filter)
+	}
+	return c
+}
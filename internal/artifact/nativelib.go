@@ -0,0 +1,182 @@
+package artifact
+
+import (
+	"archive/zip"
+	"bytes"
+	"debug/elf"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// PageSize16K is the page size 64-bit native libraries must support on
+// Android 15+ devices; Play rejects new apps and updates that do not.
+const PageSize16K = 16 * 1024
+
+// NativeLib describes one lib/<abi>/*.so entry and how its ELF LOAD segments
+// and zip placement line up with 16 KB pages.
+type NativeLib struct {
+	Path string `json:"path"`
+	// Module is the bundle module holding the library; empty for APKs.
+	Module     string `json:"module,omitempty"`
+	ABI        string `json:"abi"`
+	Is64Bit    bool   `json:"is64Bit"`
+	Compressed bool   `json:"compressed"`
+	// LoadAlign is the smallest p_align across PT_LOAD segments; every
+	// segment must be at least 16 KB aligned for the library to load.
+	LoadAlign uint64 `json:"loadAlign"`
+	// BuildID is the hex GNU build ID note, which ties a stripped library
+	// to its debug symbols; empty when the library was linked without one.
+	BuildID string `json:"buildId,omitempty"`
+	// ZipOffset and ZipAligned describe where an uncompressed library's
+	// data starts in an APK. The platform maps such libraries straight from
+	// the APK, so the offset must be a multiple of 16 KB. Both are unset for
+	// compressed libraries and for bundles, where bundletool does the
+	// aligning when it generates APKs.
+	ZipOffset  int64 `json:"zipOffset,omitempty"`
+	ZipAligned *bool `json:"zipAligned,omitempty"`
+	// Error is set when the entry is not a readable ELF file.
+	Error string `json:"error,omitempty"`
+}
+
+// NativeLibs walks every lib/<abi>/*.so in an APK, or in each module of an
+// AAB, and reads its ELF program headers. Unreadable libraries are reported
+// with Error set rather than failing the whole walk.
+func (a *Artifact) NativeLibs() ([]NativeLib, error) {
+	libs := make([]NativeLib, 0)
+	for _, f := range a.zr.File {
+		lib, ok := a.nativeLibEntry(f.Name)
+		if !ok {
+			continue
+		}
+		lib.Compressed = f.Method != zip.Store
+		if a.Kind == KindAPK && !lib.Compressed {
+			offset, err := f.DataOffset()
+			if err != nil {
+				return nil, fmt.Errorf("locate %s: %w", f.Name, err)
+			}
+			aligned := offset%PageSize16K == 0
+			lib.ZipOffset, lib.ZipAligned = offset, &aligned
+		}
+		if err := lib.readELF(f); err != nil {
+			lib.Error = err.Error()
+		}
+		libs = append(libs, lib)
+	}
+	return libs, nil
+}
+
+// nativeLibEntry matches lib/<abi>/<name>.so, prefixed with the module
+// directory in bundles.
+func (a *Artifact) nativeLibEntry(name string) (NativeLib, bool) {
+	lib := NativeLib{Path: name}
+	rest := name
+	if a.Kind == KindAAB {
+		module, inModule, ok := strings.Cut(name, "/")
+		if !ok || bundleMetadataDirs[module] {
+			return lib, false
+		}
+		lib.Module, rest = module, inModule
+	}
+	parts := strings.Split(rest, "/")
+	if len(parts) != 3 || parts[0] != "lib" || path.Ext(parts[2]) != ".so" {
+		return lib, false
+	}
+	lib.ABI = parts[1]
+	return lib, true
+}
+
+func (lib *NativeLib) readELF(f *zip.File) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rc.Close() }()
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(rc); err != nil {
+		return err
+	}
+	ef, err := elf.NewFile(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		return fmt.Errorf("not an ELF shared object: %w", err)
+	}
+	defer func() { _ = ef.Close() }()
+
+	lib.Is64Bit = ef.Class == elf.ELFCLASS64
+	lib.BuildID = BuildID(ef)
+	found := false
+	for _, p := range ef.Progs {
+		if p.Type != elf.PT_LOAD {
+			continue
+		}
+		if !found || p.Align < lib.LoadAlign {
+			lib.LoadAlign = p.Align
+		}
+		found = true
+	}
+	if !found {
+		return fmt.Errorf("no PT_LOAD segments")
+	}
+	return nil
+}
+
+// ntGNUBuildID is the note type of a GNU build ID.
+const ntGNUBuildID = 3
+
+// maxNoteBytes caps how much of a PT_NOTE segment is read; its size comes
+// from an untrusted program header, and build ID notes are tiny.
+const maxNoteBytes = 1 << 20
+
+// BuildID returns the hex GNU build ID of an ELF file, read from its note
+// sections, or from PT_NOTE segments when section headers were stripped.
+// It returns "" when the file has no build ID.
+func BuildID(ef *elf.File) string {
+	for _, s := range ef.Sections {
+		if s.Type != elf.SHT_NOTE {
+			continue
+		}
+		if data, err := s.Data(); err == nil {
+			if id := findBuildID(data, ef); id != "" {
+				return id
+			}
+		}
+	}
+	for _, p := range ef.Progs {
+		if p.Type != elf.PT_NOTE {
+			continue
+		}
+		data, err := io.ReadAll(io.LimitReader(p.Open(), maxNoteBytes))
+		if err == nil {
+			if id := findBuildID(data, ef); id != "" {
+				return id
+			}
+		}
+	}
+	return ""
+}
+
+// findBuildID walks the notes in data: 4-byte namesz, descsz and type, then
+// the name and descriptor, each padded to 4 bytes.
+func findBuildID(data []byte, ef *elf.File) string {
+	// Sizes come from the file; pad in 64 bits so they cannot wrap.
+	pad := func(n uint32) uint64 { return (uint64(n) + 3) &^ 3 }
+	for len(data) >= 12 {
+		namesz := ef.ByteOrder.Uint32(data[0:4])
+		descsz := ef.ByteOrder.Uint32(data[4:8])
+		typ := ef.ByteOrder.Uint32(data[8:12])
+		data = data[12:]
+		if pad(namesz)+pad(descsz) > uint64(len(data)) {
+			return ""
+		}
+		nameEnd, descEnd := int(pad(namesz)), int(pad(namesz)+uint64(descsz))
+		name := strings.TrimRight(string(data[:namesz]), "\x00")
+		desc := data[nameEnd:descEnd]
+		if name == "GNU" && typ == ntGNUBuildID && len(desc) > 0 {
+			return hex.EncodeToString(desc)
+		}
+		data = data[nameEnd+int(pad(descsz)):]
+	}
+	return ""
+}
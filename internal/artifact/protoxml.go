@@ -0,0 +1,233 @@
+package artifact
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Protobuf wire types.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// protoField is a single decoded protobuf field. Varint and fixed values are
+// stored in Num; length-delimited payloads in Bytes.
+type protoField struct {
+	Number int
+	Wire   int
+	Num    uint64
+	Bytes  []byte
+}
+
+// decodeProto splits a protobuf message into its top-level fields without a
+// schema. Callers interpret field numbers against the aapt2 Resources.proto
+// and bundletool Config.proto definitions.
+func decodeProto(b []byte) ([]protoField, error) {
+	var fields []protoField
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		if n <= 0 {
+			return nil, fmt.Errorf("invalid protobuf tag")
+		}
+		b = b[n:]
+		f := protoField{Number: int(key >> 3), Wire: int(key & 7)}
+		switch f.Wire {
+		case wireVarint:
+			v, m := binary.Uvarint(b)
+			if m <= 0 {
+				return nil, fmt.Errorf("invalid varint in field %d", f.Number)
+			}
+			f.Num = v
+			b = b[m:]
+		case wireFixed64:
+			if len(b) < 8 {
+				return nil, fmt.Errorf("truncated fixed64 in field %d", f.Number)
+			}
+			f.Num = binary.LittleEndian.Uint64(b)
+			b = b[8:]
+		case wireBytes:
+			l, m := binary.Uvarint(b)
+			if m <= 0 || uint64(len(b)-m) < l {
+				return nil, fmt.Errorf("truncated bytes in field %d", f.Number)
+			}
+			f.Bytes = b[m : m+int(l)]
+			b = b[m+int(l):]
+		case wireFixed32:
+			if len(b) < 4 {
+				return nil, fmt.Errorf("truncated fixed32 in field %d", f.Number)
+			}
+			f.Num = uint64(binary.LittleEndian.Uint32(b))
+			b = b[4:]
+		default:
+			return nil, fmt.Errorf("unsupported wire type %d in field %d", f.Wire, f.Number)
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// parseProtoXML decodes an aapt2 XmlNode message (the manifest format used
+// inside Android App Bundles) into an element tree.
+func parseProtoXML(data []byte) (*xmlElement, error) {
+	fields, err := decodeProto(data)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range fields {
+		// XmlNode.element = 1
+		if f.Number == 1 && f.Wire == wireBytes {
+			return parseProtoElement(f.Bytes)
+		}
+	}
+	return nil, fmt.Errorf("proto xml has no root element")
+}
+
+func parseProtoElement(data []byte) (*xmlElement, error) {
+	fields, err := decodeProto(data)
+	if err != nil {
+		return nil, err
+	}
+	elem := &xmlElement{}
+	for _, f := range fields {
+		if f.Wire != wireBytes {
+			continue
+		}
+		switch f.Number {
+		case 3: // XmlElement.name
+			elem.Name = string(f.Bytes)
+		case 4: // XmlElement.attribute
+			attr, err := parseProtoAttribute(f.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("<%s>: %w", elem.Name, err)
+			}
+			elem.Attrs = append(elem.Attrs, attr)
+		case 5: // XmlElement.child (XmlNode)
+			node, err := decodeProto(f.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			for _, nf := range node {
+				if nf.Number == 1 && nf.Wire == wireBytes {
+					child, err := parseProtoElement(nf.Bytes)
+					if err != nil {
+						return nil, err
+					}
+					elem.Children = append(elem.Children, child)
+				}
+			}
+		}
+	}
+	return elem, nil
+}
+
+func parseProtoAttribute(data []byte) (xmlAttr, error) {
+	fields, err := decodeProto(data)
+	if err != nil {
+		return xmlAttr{}, err
+	}
+	var (
+		attr     xmlAttr
+		compiled []byte
+	)
+	for _, f := range fields {
+		switch f.Number {
+		case 1:
+			attr.Namespace = string(f.Bytes)
+		case 2:
+			attr.Name = string(f.Bytes)
+		case 3:
+			attr.Value = string(f.Bytes)
+		case 5:
+			attr.ResID = uint32(f.Num)
+		case 6:
+			compiled = f.Bytes
+		}
+	}
+	if attr.Value == "" && compiled != nil {
+		attr.Value = formatProtoItem(compiled)
+	}
+	return attr, nil
+}
+
+// formatProtoItem renders a compiled Item when the attribute carries no
+// source string, which is the case for values aapt2 generates itself.
+func formatProtoItem(data []byte) string {
+	fields, err := decodeProto(data)
+	if err != nil {
+		return ""
+	}
+	for _, f := range fields {
+		switch f.Number {
+		case 1: // Reference
+			return formatProtoReference(f.Bytes)
+		case 2, 3: // String, RawString
+			s, _ := decodeProto(f.Bytes)
+			for _, sf := range s {
+				if sf.Number == 1 {
+					return string(sf.Bytes)
+				}
+			}
+		case 7: // Primitive
+			return formatProtoPrimitive(f.Bytes)
+		}
+	}
+	return ""
+}
+
+// formatProtoReference renders a Reference (type=1, id=2, name=3) as
+// @type/name, ?attr/name for attribute references, or @0x<id> when aapt2
+// kept no name.
+func formatProtoReference(data []byte) string {
+	fields, err := decodeProto(data)
+	if err != nil {
+		return ""
+	}
+	var (
+		attrRef bool
+		id      uint64
+		name    string
+	)
+	for _, f := range fields {
+		switch f.Number {
+		case 1:
+			attrRef = f.Num == 1 // Reference.Type.ATTRIBUTE
+		case 2:
+			id = f.Num
+		case 3:
+			name = string(f.Bytes)
+		}
+	}
+	prefix := "@"
+	if attrRef {
+		prefix = "?"
+	}
+	if name != "" {
+		return prefix + name
+	}
+	return fmt.Sprintf("%s0x%08x", prefix, id)
+}
+
+func formatProtoPrimitive(data []byte) string {
+	fields, err := decodeProto(data)
+	if err != nil {
+		return ""
+	}
+	for _, f := range fields {
+		switch f.Number {
+		case 3: // float_value
+			return strconv.FormatFloat(float64(math.Float32frombits(uint32(f.Num))), 'g', -1, 32)
+		case 6: // int_decimal_value
+			return strconv.FormatInt(int64(int32(f.Num)), 10)
+		case 7: // int_hexadecimal_value
+			return fmt.Sprintf("0x%x", uint32(f.Num))
+		case 8: // boolean_value
+			return strconv.FormatBool(f.Num != 0)
+		}
+	}
+	return ""
+}
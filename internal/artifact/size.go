@@ -0,0 +1,178 @@
+package artifact
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ModuleSize is the zip footprint of one bundle module, or of a whole APK.
+type ModuleSize struct {
+	// Module is the bundle module name; empty for APKs.
+	Module string `json:"module,omitempty"`
+	// Type and Delivery come from the module's <dist:module> declaration.
+	Type         string `json:"type,omitempty"`
+	Delivery     string `json:"delivery"`
+	Files        int    `json:"files"`
+	Compressed   int64  `json:"compressedBytes"`
+	Uncompressed int64  `json:"uncompressedBytes"`
+}
+
+// ModuleManifest decodes the manifest of a bundle module. The base module's
+// manifest is the artifact manifest.
+func (a *Artifact) ModuleManifest(module string) (*Manifest, error) {
+	if a.Kind != KindAAB {
+		return nil, fmt.Errorf("%s is not a bundle", a.Path)
+	}
+	if module == bundleBaseModule {
+		return a.Manifest, nil
+	}
+	data, err := a.ReadFile(module + "/" + bundleManifestPath)
+	if err != nil {
+		return nil, err
+	}
+	root, err := parseProtoXML(data)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s module manifest: %w", module, err)
+	}
+	return newManifest(root), nil
+}
+
+// ModuleSizes sums zip entry sizes per bundle module, base first. Bundle
+// metadata outside module directories (BundleConfig.pb, BUNDLE-METADATA,
+// META-INF) is never delivered to devices and is not counted. An APK is
+// reported as a single install-time entry.
+func (a *Artifact) ModuleSizes() ([]ModuleSize, error) {
+	if a.Kind == KindAPK {
+		size := ModuleSize{Delivery: DeliveryInstallTime}
+		for _, f := range a.zr.File {
+			size.add(f.CompressedSize64, f.UncompressedSize64)
+		}
+		return []ModuleSize{size}, nil
+	}
+
+	sizes := make([]ModuleSize, len(a.Modules))
+	index := make(map[string]int, len(a.Modules))
+	for i, module := range a.Modules {
+		m, err := a.ModuleManifest(module)
+		if err != nil {
+			return nil, err
+		}
+		sizes[i] = ModuleSize{Module: module, Type: "feature", Delivery: DeliveryInstallTime}
+		if m.Module != nil {
+			sizes[i].Type = m.Module.Type
+			sizes[i].Delivery = m.Module.Delivery
+		}
+		index[module] = i
+	}
+	for _, f := range a.zr.File {
+		module, _, ok := strings.Cut(f.Name, "/")
+		if !ok {
+			continue
+		}
+		if i, ok := index[module]; ok {
+			sizes[i].add(f.CompressedSize64, f.UncompressedSize64)
+		}
+	}
+	return sizes, nil
+}
+
+func (s *ModuleSize) add(compressed, uncompressed uint64) {
+	s.Files++
+	s.Compressed += int64(compressed)
+	s.Uncompressed += int64(uncompressed)
+}
+
+// Entry size categories.
+const (
+	CategoryDex       = "dex"
+	CategoryNative    = "native"
+	CategoryResources = "resources"
+	CategoryAssets    = "assets"
+	CategoryManifest  = "manifest"
+	CategoryOther     = "other"
+)
+
+// densityQualifiers are the screen density resource qualifiers.
+var densityQualifiers = map[string]bool{
+	"ldpi": true, "mdpi": true, "tvdpi": true, "hdpi": true, "xhdpi": true,
+	"xxhdpi": true, "xxxhdpi": true, "nodpi": true, "anydpi": true,
+}
+
+// EntrySize is the zip footprint of one entry, classified by what it holds.
+type EntrySize struct {
+	Path string `json:"path"`
+	// Module is the bundle module; empty for APKs.
+	Module   string `json:"module,omitempty"`
+	Category string `json:"category"`
+	// ABI is set for native libraries, Density for density-qualified
+	// resources.
+	ABI          string `json:"abi,omitempty"`
+	Density      string `json:"density,omitempty"`
+	Compressed   int64  `json:"compressedBytes"`
+	Uncompressed int64  `json:"uncompressedBytes"`
+}
+
+// EntrySizes classifies every zip entry that reaches devices. Bundle
+// metadata is skipped, as in ModuleSizes.
+func (a *Artifact) EntrySizes() []EntrySize {
+	out := make([]EntrySize, 0, len(a.zr.File))
+	modules := make(map[string]bool, len(a.Modules))
+	for _, m := range a.Modules {
+		modules[m] = true
+	}
+	for _, f := range a.zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		e := EntrySize{Path: f.Name, Compressed: int64(f.CompressedSize64), Uncompressed: int64(f.UncompressedSize64)}
+		rest := f.Name
+		if a.Kind == KindAAB {
+			module, inModule, ok := strings.Cut(f.Name, "/")
+			if !ok || !modules[module] {
+				continue
+			}
+			e.Module, rest = module, inModule
+		}
+		e.Category, e.ABI, e.Density = classifyEntry(a.Kind, rest)
+		out = append(out, e)
+	}
+	return out
+}
+
+// classifyEntry maps an APK path, or a path inside a bundle module, to its
+// category plus ABI or density qualifier.
+func classifyEntry(kind Kind, name string) (category, abi, density string) {
+	dir, rest, _ := strings.Cut(name, "/")
+	switch {
+	case dir == "lib" && strings.Contains(rest, "/"):
+		abi, _, _ = strings.Cut(rest, "/")
+		return CategoryNative, abi, ""
+	case dir == "res":
+		qualified, _, _ := strings.Cut(rest, "/")
+		return CategoryResources, "", resourceDensity(qualified)
+	case dir == "assets":
+		return CategoryAssets, "", ""
+	case name == "resources.arsc" || name == "resources.pb":
+		return CategoryResources, "", ""
+	case name == apkManifestPath || (kind == KindAAB && dir == "manifest"):
+		return CategoryManifest, "", ""
+	case kind == KindAAB && dir == "dex",
+		kind == KindAPK && !strings.Contains(name, "/") && strings.HasSuffix(name, ".dex"):
+		return CategoryDex, "", ""
+	}
+	return CategoryOther, "", ""
+}
+
+// resourceDensity returns the density qualifier of a res/ directory such
+// as drawable-xxhdpi-v4, or "" when it has none.
+func resourceDensity(dir string) string {
+	for _, q := range strings.Split(dir, "-")[1:] {
+		if densityQualifiers[q] {
+			return q
+		}
+		if n, ok := strings.CutSuffix(q, "dpi"); ok && n != "" && strings.Trim(n, "0123456789") == "" {
+			return q
+		}
+	}
+	return ""
+}
@@ -0,0 +1,405 @@
+// Package alerting delivers monitor and automation alerts to the sinks
+// configured for a profile: signed JSON webhooks, Slack-compatible incoming
+// webhooks, SMTP email and local commands. Repeats of an alert are
+// suppressed for a cooldown window, and failed deliveries are retried with
+// exponential backoff. Kong adapters live in package cli.
+package alerting
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/smtp"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/config"
+)
+
+// Sink types.
+const (
+	SinkWebhook = "webhook"
+	SinkSlack   = "slack"
+	SinkEmail   = "email"
+	SinkCommand = "command"
+)
+
+// Delivery statuses.
+const (
+	StatusSent       = "sent"
+	StatusSuppressed = "suppressed"
+	StatusFailed     = "failed"
+)
+
+// Webhook signature headers. The signature is the hex HMAC-SHA256 of
+// "<timestamp>.<body>" keyed with the sink secret, prefixed with "sha256=".
+const (
+	HeaderSignature = "X-Gpd-Signature"
+	HeaderTimestamp = "X-Gpd-Timestamp"
+)
+
+// DefaultTemplate renders an alert when neither the sink nor the profile
+// sets a template.
+const DefaultTemplate = `[{{.Severity}}] {{.Package}}: {{.Metric}} is {{printf "%.4g" .Value}} (threshold {{printf "%.4g" .Threshold}}) from {{.Source}}`
+
+// Defaults applied when the profile leaves them unset.
+const (
+	DefaultCooldown = time.Hour
+	DefaultRetries  = 3
+	DefaultBackoff  = 2 * time.Second
+)
+
+// Alert is one condition worth telling someone about.
+type Alert struct {
+	// Key identifies the alert for dedupe and cooldown; it defaults to
+	// "<package>/<metric>".
+	Key       string            `json:"key"`
+	Source    string            `json:"source"`
+	Package   string            `json:"package"`
+	Metric    string            `json:"metric"`
+	Severity  string            `json:"severity"`
+	Value     float64           `json:"value"`
+	Threshold float64           `json:"threshold"`
+	Time      time.Time         `json:"time"`
+	Labels    map[string]string `json:"labels,omitempty"`
+}
+
+func (a Alert) key() string {
+	if a.Key != "" {
+		return a.Key
+	}
+	return a.Package + "/" + a.Metric
+}
+
+// Payload is the JSON body webhook and command sinks receive.
+type Payload struct {
+	Alert
+	Message string `json:"message"`
+}
+
+// Delivery is the outcome of one alert on one sink.
+type Delivery struct {
+	Sink     string `json:"sink"`
+	Key      string `json:"key"`
+	Status   string `json:"status"`
+	Attempts int    `json:"attempts,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+type sink struct {
+	config.AlertSink
+	name string
+	tmpl *template.Template
+}
+
+// Notifier delivers alerts to a profile's sinks.
+type Notifier struct {
+	sinks     []sink
+	cooldown  time.Duration
+	retries   int
+	backoff   time.Duration
+	statePath string
+	sent      map[string]time.Time
+
+	client   *http.Client
+	now      func() time.Time
+	sleep    func(context.Context, time.Duration) error
+	sendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// New validates cfg and returns a notifier for it. Cooldown state is kept
+// in statePath when it is set, so that it holds across runs.
+func New(cfg *config.AlertConfig, statePath string) (*Notifier, error) {
+	if cfg == nil || len(cfg.Sinks) == 0 {
+		return nil, errors.New("no alert sinks configured")
+	}
+	n := &Notifier{
+		cooldown:  DefaultCooldown,
+		retries:   DefaultRetries,
+		backoff:   DefaultBackoff,
+		statePath: statePath,
+		sent:      make(map[string]time.Time),
+		client:    &http.Client{Timeout: 30 * time.Second},
+		now:       time.Now,
+		sleep:     sleepContext,
+		sendMail:  smtp.SendMail,
+	}
+	var err error
+	if cfg.Cooldown != "" {
+		if n.cooldown, err = time.ParseDuration(cfg.Cooldown); err != nil || n.cooldown < 0 {
+			return nil, fmt.Errorf("invalid cooldown %q", cfg.Cooldown)
+		}
+	}
+	if cfg.Backoff != "" {
+		if n.backoff, err = time.ParseDuration(cfg.Backoff); err != nil || n.backoff < 0 {
+			return nil, fmt.Errorf("invalid backoff %q", cfg.Backoff)
+		}
+	}
+	if cfg.Retries != nil {
+		if *cfg.Retries < 0 {
+			return nil, fmt.Errorf("invalid retries %d", *cfg.Retries)
+		}
+		n.retries = *cfg.Retries
+	}
+	for i, sc := range cfg.Sinks {
+		s := sink{AlertSink: sc, name: sc.Name}
+		if s.name == "" {
+			s.name = fmt.Sprintf("%s-%d", sc.Type, i+1)
+		}
+		if err := validateSink(sc); err != nil {
+			return nil, fmt.Errorf("sink %s: %w", s.name, err)
+		}
+		text := sc.Template
+		if text == "" {
+			text = cfg.Template
+		}
+		if text == "" {
+			text = DefaultTemplate
+		}
+		if s.tmpl, err = template.New(s.name).Option("missingkey=zero").Parse(text); err != nil {
+			return nil, fmt.Errorf("sink %s: invalid template: %w", s.name, err)
+		}
+		n.sinks = append(n.sinks, s)
+	}
+	if err := n.loadState(); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+func validateSink(s config.AlertSink) error {
+	switch s.Type {
+	case SinkWebhook, SinkSlack:
+		if s.URL == "" {
+			return errors.New("url is required")
+		}
+	case SinkEmail:
+		if s.SMTPHost == "" || s.From == "" || len(s.To) == 0 {
+			return errors.New("smtpHost, from and to are required")
+		}
+	case SinkCommand:
+		if len(s.Command) == 0 {
+			return errors.New("command is required")
+		}
+	default:
+		return fmt.Errorf("unknown type %q (want webhook, slack, email or command)", s.Type)
+	}
+	return nil
+}
+
+// Notify delivers each alert to every sink, skipping alerts whose key was
+// delivered within the cooldown. An alert counts as delivered when any sink
+// accepted it.
+func (n *Notifier) Notify(ctx context.Context, alerts []Alert) []Delivery {
+	deliveries := make([]Delivery, 0, len(alerts)*len(n.sinks))
+	for _, alert := range alerts {
+		if alert.Time.IsZero() {
+			alert.Time = n.now()
+		}
+		key := alert.key()
+		if last, ok := n.sent[key]; ok && n.now().Sub(last) < n.cooldown {
+			for _, s := range n.sinks {
+				deliveries = append(deliveries, Delivery{Sink: s.name, Key: key, Status: StatusSuppressed})
+			}
+			continue
+		}
+		delivered := false
+		for _, s := range n.sinks {
+			d := n.deliver(ctx, s, alert)
+			d.Key = key
+			delivered = delivered || d.Status == StatusSent
+			deliveries = append(deliveries, d)
+		}
+		if delivered {
+			n.sent[key] = n.now()
+		}
+	}
+	if err := n.saveState(); err != nil {
+		deliveries = append(deliveries, Delivery{Sink: "state", Status: StatusFailed, Error: err.Error()})
+	}
+	return deliveries
+}
+
+// permanentError marks a failure retrying cannot fix.
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+
+func (n *Notifier) deliver(ctx context.Context, s sink, alert Alert) Delivery {
+	d := Delivery{Sink: s.name}
+	var msg bytes.Buffer
+	if err := s.tmpl.Execute(&msg, alert); err != nil {
+		d.Status, d.Error = StatusFailed, fmt.Sprintf("render template: %v", err)
+		return d
+	}
+	delay := n.backoff
+	for {
+		d.Attempts++
+		err := n.send(ctx, s, alert, msg.String())
+		if err == nil {
+			d.Status, d.Error = StatusSent, ""
+			return d
+		}
+		d.Status, d.Error = StatusFailed, err.Error()
+		var perm permanentError
+		if errors.As(err, &perm) || d.Attempts > n.retries {
+			return d
+		}
+		if err := n.sleep(ctx, delay); err != nil {
+			return d
+		}
+		delay *= 2
+	}
+}
+
+func (n *Notifier) send(ctx context.Context, s sink, alert Alert, message string) error {
+	switch s.Type {
+	case SinkWebhook:
+		body, err := json.Marshal(Payload{Alert: alert, Message: message})
+		if err != nil {
+			return permanentError{err}
+		}
+		headers := map[string]string{}
+		for k, v := range s.Headers {
+			headers[k] = os.ExpandEnv(v)
+		}
+		if secret := os.ExpandEnv(s.Secret); secret != "" {
+			ts := strconv.FormatInt(n.now().Unix(), 10)
+			headers[HeaderTimestamp] = ts
+			headers[HeaderSignature] = Sign(secret, ts, body)
+		}
+		return n.post(ctx, os.ExpandEnv(s.URL), body, headers)
+	case SinkSlack:
+		body, err := json.Marshal(map[string]string{"text": message})
+		if err != nil {
+			return permanentError{err}
+		}
+		return n.post(ctx, os.ExpandEnv(s.URL), body, nil)
+	case SinkEmail:
+		port := s.SMTPPort
+		if port == 0 {
+			port = 587
+		}
+		var auth smtp.Auth
+		if s.Username != "" {
+			auth = smtp.PlainAuth("", s.Username, os.ExpandEnv(s.Password), s.SMTPHost)
+		}
+		subject := strings.SplitN(message, "\n", 2)[0]
+		mail := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n%s\r\n",
+			s.From, strings.Join(s.To, ", "), subject, message)
+		return n.sendMail(fmt.Sprintf("%s:%d", s.SMTPHost, port), auth, s.From, s.To, []byte(mail))
+	case SinkCommand:
+		body, err := json.Marshal(Payload{Alert: alert, Message: message})
+		if err != nil {
+			return permanentError{err}
+		}
+		cmd := exec.CommandContext(ctx, s.Command[0], s.Command[1:]...) //nolint:gosec // G204: the command comes from the user's own config
+		cmd.Stdin = bytes.NewReader(body)
+		cmd.Env = append(os.Environ(),
+			"GPD_ALERT_KEY="+alert.key(),
+			"GPD_ALERT_SEVERITY="+alert.Severity,
+			"GPD_ALERT_MESSAGE="+message)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("%v: %s", err, strings.TrimSpace(string(out)))
+		}
+		return nil
+	}
+	return permanentError{fmt.Errorf("unknown sink type %q", s.Type)}
+}
+
+// post sends a JSON body. Client errors other than 408 and 429 are
+// permanent.
+func (n *Notifier) post(ctx context.Context, url string, body []byte, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return permanentError{err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "gpd-alerts")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 300 {
+		return nil
+	}
+	err = fmt.Errorf("HTTP %d", resp.StatusCode)
+	if resp.StatusCode < 500 && resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+		return permanentError{err}
+	}
+	return err
+}
+
+// Sign returns the signature header value of a webhook body.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (n *Notifier) loadState() error {
+	if n.statePath == "" {
+		return nil
+	}
+	data, err := os.ReadFile(n.statePath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read alert state: %w", err)
+	}
+	if err := json.Unmarshal(data, &n.sent); err != nil {
+		return fmt.Errorf("parse alert state %s: %w", n.statePath, err)
+	}
+	return nil
+}
+
+func (n *Notifier) saveState() error {
+	if n.statePath == "" {
+		return nil
+	}
+	// Entries past the cooldown no longer suppress anything.
+	for key, last := range n.sent {
+		if n.now().Sub(last) >= n.cooldown {
+			delete(n.sent, key)
+		}
+	}
+	data, err := json.MarshalIndent(n.sent, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(n.statePath), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(n.statePath, data, 0o600)
+}
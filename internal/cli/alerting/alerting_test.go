@@ -0,0 +1,200 @@
+//go:build unit
+// +build unit
+
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/config"
+)
+
+var testAlert = Alert{
+	Source: "monitor watch", Package: "com.example.app", Metric: "crashRate",
+	Severity: "critical", Value: 0.0231, Threshold: 0.01,
+	Time: time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC),
+}
+
+func newTestNotifier(t *testing.T, cfg *config.AlertConfig, statePath string) *Notifier {
+	t.Helper()
+	n, err := New(cfg, statePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n.now = func() time.Time { return testAlert.Time }
+	n.sleep = func(context.Context, time.Duration) error { return nil }
+	return n
+}
+
+func TestWebhookSignedPayload(t *testing.T) {
+	var got Payload
+	var sig, ts string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		sig, ts = r.Header.Get(HeaderSignature), r.Header.Get(HeaderTimestamp)
+		if want := Sign("s3cret", ts, body); sig != want {
+			t.Errorf("signature = %s, want %s", sig, want)
+		}
+		if r.Header.Get("X-Team") != "mobile" {
+			t.Errorf("custom header missing")
+		}
+		_ = json.Unmarshal(body, &got)
+	}))
+	defer srv.Close()
+
+	t.Setenv("GPD_TEST_SECRET", "s3cret")
+	n := newTestNotifier(t, &config.AlertConfig{Sinks: []config.AlertSink{{
+		Type: SinkWebhook, URL: srv.URL, Secret: "${GPD_TEST_SECRET}", Headers: map[string]string{"X-Team": "mobile"},
+	}}}, "")
+	d := n.Notify(context.Background(), []Alert{testAlert})
+	if len(d) != 1 || d[0].Status != StatusSent || d[0].Key != "com.example.app/crashRate" {
+		t.Fatalf("deliveries = %+v", d)
+	}
+	if got.Metric != "crashRate" || got.Message != "[critical] com.example.app: crashRate is 0.0231 (threshold 0.01) from monitor watch" {
+		t.Errorf("payload = %+v", got)
+	}
+	if ts != "1792141200" {
+		t.Errorf("timestamp = %s", ts)
+	}
+}
+
+func TestSlackRetriesWithBackoff(t *testing.T) {
+	var calls int32
+	var text string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		text = body["text"]
+	}))
+	defer srv.Close()
+
+	n := newTestNotifier(t, &config.AlertConfig{
+		Template: "{{.Package}} {{.Metric}}",
+		Backoff:  "1s",
+		Sinks:    []config.AlertSink{{Type: SinkSlack, URL: srv.URL}},
+	}, "")
+	var delays []time.Duration
+	n.sleep = func(_ context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+	d := n.Notify(context.Background(), []Alert{testAlert})
+	if d[0].Status != StatusSent || d[0].Attempts != 3 {
+		t.Fatalf("delivery = %+v", d[0])
+	}
+	if len(delays) != 2 || delays[0] != time.Second || delays[1] != 2*time.Second {
+		t.Errorf("delays = %v", delays)
+	}
+	if text != "com.example.app crashRate" {
+		t.Errorf("text = %q", text)
+	}
+}
+
+func TestClientErrorIsNotRetried(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	n := newTestNotifier(t, &config.AlertConfig{Sinks: []config.AlertSink{{Type: SinkSlack, URL: srv.URL}}}, "")
+	d := n.Notify(context.Background(), []Alert{testAlert})
+	if d[0].Status != StatusFailed || d[0].Attempts != 1 || calls != 1 {
+		t.Errorf("delivery = %+v after %d calls", d[0], calls)
+	}
+}
+
+func TestCooldownPersistsAcrossRuns(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+	}))
+	defer srv.Close()
+
+	state := filepath.Join(t.TempDir(), "alerts", "default.json")
+	cfg := &config.AlertConfig{Cooldown: "30m", Sinks: []config.AlertSink{{Type: SinkSlack, URL: srv.URL}}}
+	first := newTestNotifier(t, cfg, state)
+	if d := first.Notify(context.Background(), []Alert{testAlert, testAlert}); d[0].Status != StatusSent || d[1].Status != StatusSuppressed {
+		t.Fatalf("first run = %+v", d)
+	}
+
+	second := newTestNotifier(t, cfg, state)
+	second.now = func() time.Time { return testAlert.Time.Add(10 * time.Minute) }
+	if d := second.Notify(context.Background(), []Alert{testAlert}); d[0].Status != StatusSuppressed {
+		t.Fatalf("within cooldown = %+v", d)
+	}
+	third := newTestNotifier(t, cfg, state)
+	third.now = func() time.Time { return testAlert.Time.Add(time.Hour) }
+	if d := third.Notify(context.Background(), []Alert{testAlert}); d[0].Status != StatusSent {
+		t.Fatalf("after cooldown = %+v", d)
+	}
+	if calls != 2 {
+		t.Errorf("calls = %d, want 2", calls)
+	}
+}
+
+func TestEmailSink(t *testing.T) {
+	n := newTestNotifier(t, &config.AlertConfig{Sinks: []config.AlertSink{{
+		Type: SinkEmail, SMTPHost: "smtp.example.com", From: "gpd@example.com", To: []string{"oncall@example.com"},
+	}}}, "")
+	var addr string
+	var msg []byte
+	n.sendMail = func(a string, _ smtp.Auth, _ string, _ []string, m []byte) error {
+		addr, msg = a, m
+		return nil
+	}
+	if d := n.Notify(context.Background(), []Alert{testAlert}); d[0].Status != StatusSent {
+		t.Fatalf("delivery = %+v", d[0])
+	}
+	if addr != "smtp.example.com:587" || !strings.Contains(string(msg), "Subject: [critical] com.example.app: crashRate") {
+		t.Errorf("addr = %s, msg = %q", addr, msg)
+	}
+}
+
+func TestCommandSink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	out := filepath.Join(t.TempDir(), "alert.json")
+	n := newTestNotifier(t, &config.AlertConfig{Sinks: []config.AlertSink{{
+		Type: SinkCommand, Command: []string{"sh", "-c", `cat > "$1" && test "$GPD_ALERT_SEVERITY" = critical`, "sh", out},
+	}}}, "")
+	if d := n.Notify(context.Background(), []Alert{testAlert}); d[0].Status != StatusSent {
+		t.Fatalf("delivery = %+v", d[0])
+	}
+	data, err := os.ReadFile(out)
+	if err != nil || !strings.Contains(string(data), `"message":"[critical]`) {
+		t.Errorf("stdin = %s (%v)", data, err)
+	}
+}
+
+func TestNewRejectsInvalidConfig(t *testing.T) {
+	for name, cfg := range map[string]*config.AlertConfig{
+		"no sinks":     {},
+		"unknown type": {Sinks: []config.AlertSink{{Type: "pager"}}},
+		"missing url":  {Sinks: []config.AlertSink{{Type: SinkWebhook}}},
+		"bad template": {Template: "{{.Nope", Sinks: []config.AlertSink{{Type: SinkSlack, URL: "http://x"}}},
+		"bad cooldown": {Cooldown: "soon", Sinks: []config.AlertSink{{Type: SinkSlack, URL: "http://x"}}},
+	} {
+		if _, err := New(cfg, ""); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,346 @@
+// Package anomaly detects anomalies in daily vitals series. A detector
+// learns what is expected from a baseline window and flags the days of the
+// current window that exceed it. Vitals get worse as they rise, so only
+// increases are flagged, and values are rates or counts, so expected ranges
+// never go below zero. Kong adapters live in package cli.
+package anomaly
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Detectors.
+const (
+	// DetectorWindow compares the mean of the current window to a multiple
+	// of the baseline mean.
+	DetectorWindow = "window"
+	// DetectorEWMA runs an exponentially weighted moving average control
+	// chart over the current window.
+	DetectorEWMA = "ewma"
+	// DetectorCUSUM accumulates upward deviations from the baseline mean
+	// and flags when they exceed a decision interval.
+	DetectorCUSUM = "cusum"
+	// DetectorMAD scores each day by its robust z-score against the
+	// baseline median and median absolute deviation.
+	DetectorMAD = "mad"
+	// DetectorSeasonal is DetectorMAD against the median of the same day of
+	// the week, so weekly cycles are not flagged.
+	DetectorSeasonal = "seasonal"
+)
+
+// Detectors lists the available detectors.
+var Detectors = []string{DetectorWindow, DetectorEWMA, DetectorCUSUM, DetectorMAD, DetectorSeasonal}
+
+// MinSeasonalBaseline is the number of baseline days the seasonal detector
+// needs: two of each day of the week.
+const MinSeasonalBaseline = 14
+
+// madScale makes the median absolute deviation a consistent estimator of
+// the standard deviation of normally distributed values.
+const madScale = 1.4826
+
+// Point is the value of a series on one day.
+type Point struct {
+	Date  time.Time
+	Value float64
+}
+
+// Options tunes the detectors. Zero fields take the defaults.
+type Options struct {
+	// Multiplier is the window detector's limit, as a multiple of the
+	// baseline mean. Default 2.
+	Multiplier float64
+	// Threshold is the width of the expected range in standard deviations
+	// for the ewma, mad and seasonal detectors. Default 3.
+	Threshold float64
+	// Lambda is the EWMA smoothing weight of the newest day. Default 0.3.
+	Lambda float64
+	// Slack is the CUSUM allowance in standard deviations: smaller
+	// deviations do not accumulate. Default 0.5.
+	Slack float64
+	// Limit is the CUSUM decision interval in standard deviations.
+	// Default 4.
+	Limit float64
+}
+
+func (o Options) withDefaults() Options {
+	if o.Multiplier <= 0 {
+		o.Multiplier = 2
+	}
+	if o.Threshold <= 0 {
+		o.Threshold = 3
+	}
+	if o.Lambda <= 0 || o.Lambda > 1 {
+		o.Lambda = 0.3
+	}
+	if o.Slack <= 0 {
+		o.Slack = 0.5
+	}
+	if o.Limit <= 0 {
+		o.Limit = 4
+	}
+	return o
+}
+
+// Finding is one anomalous day, or for the window detector the current
+// window as a whole, dated by its last day.
+type Finding struct {
+	Detector string
+	Date     time.Time
+	// Value is the observed value: the day's value, or the window mean.
+	Value float64
+	// Expected is the baseline mean (window, ewma, cusum) or median (mad,
+	// seasonal).
+	Expected float64
+	// Lower and Upper bound the range the detector expected; for ewma they
+	// are the control limits of the smoothed value.
+	Lower float64
+	Upper float64
+	// Score is the detector statistic in standard deviations: the z-score,
+	// or the CUSUM sum.
+	Score float64
+	// Confidence is the probability, between 0 and 1, that the finding is
+	// not noise under the baseline's distribution.
+	Confidence float64
+}
+
+// Detect runs detector over current, learning from baseline. Both series
+// must be in date order.
+func Detect(detector string, baseline, current []Point, opts Options) ([]Finding, error) {
+	opts = opts.withDefaults()
+	if len(baseline) == 0 || len(current) == 0 {
+		return nil, nil
+	}
+	switch detector {
+	case DetectorWindow:
+		return window(baseline, current, opts), nil
+	case DetectorEWMA:
+		return ewma(baseline, current, opts), nil
+	case DetectorCUSUM:
+		return cusum(baseline, current, opts), nil
+	case DetectorMAD:
+		return robust(baseline, current, opts), nil
+	case DetectorSeasonal:
+		return seasonal(baseline, current, opts), nil
+	}
+	return nil, fmt.Errorf("unknown detector %q (want one of %v)", detector, Detectors)
+}
+
+func window(baseline, current []Point, opts Options) []Finding {
+	mean, sd := meanStdDev(values(baseline))
+	value, _ := meanStdDev(values(current))
+	limit := mean * opts.Multiplier
+	if mean <= 0 || value <= limit {
+		return nil
+	}
+	// The window mean varies less than single days do.
+	score := (value - mean) / (floorScale(sd, mean) / math.Sqrt(float64(len(current))))
+	return []Finding{{
+		Detector:   DetectorWindow,
+		Date:       current[len(current)-1].Date,
+		Value:      value,
+		Expected:   mean,
+		Lower:      0,
+		Upper:      limit,
+		Score:      round(score),
+		Confidence: normalConfidence(score),
+	}}
+}
+
+func ewma(baseline, current []Point, opts Options) []Finding {
+	mean, sd := meanStdDev(values(baseline))
+	sd = floorScale(sd, mean)
+	lambda := opts.Lambda
+	smoothed := mean
+	var findings []Finding
+	for i, p := range current {
+		smoothed = lambda*p.Value + (1-lambda)*smoothed
+		t := float64(i + 1)
+		sigma := sd * math.Sqrt(lambda/(2-lambda)*(1-math.Pow(1-lambda, 2*t)))
+		upper := mean + opts.Threshold*sigma
+		if smoothed <= upper {
+			continue
+		}
+		score := (smoothed - mean) / sigma
+		findings = append(findings, Finding{
+			Detector:   DetectorEWMA,
+			Date:       p.Date,
+			Value:      p.Value,
+			Expected:   mean,
+			Lower:      math.Max(0, mean-opts.Threshold*sigma),
+			Upper:      upper,
+			Score:      round(score),
+			Confidence: normalConfidence(score),
+		})
+	}
+	return findings
+}
+
+func cusum(baseline, current []Point, opts Options) []Finding {
+	mean, sd := meanStdDev(values(baseline))
+	sd = floorScale(sd, mean)
+	sum := 0.0
+	var findings []Finding
+	for _, p := range current {
+		sum = math.Max(0, sum+(p.Value-mean)/sd-opts.Slack)
+		if sum <= opts.Limit {
+			continue
+		}
+		findings = append(findings, Finding{
+			Detector: DetectorCUSUM,
+			Date:     p.Date,
+			Value:    p.Value,
+			Expected: mean,
+			Lower:    math.Max(0, mean-opts.Slack*sd),
+			Upper:    mean + opts.Slack*sd,
+			Score:    round(sum),
+			// An in-control CUSUM exceeds s with probability about
+			// exp(-2·slack·s).
+			Confidence: round(1 - math.Exp(-2*opts.Slack*sum)),
+		})
+		// Restart after a signal so a sustained shift is reported again
+		// only once it has accumulated anew.
+		sum = 0
+	}
+	return findings
+}
+
+func robust(baseline, current []Point, opts Options) []Finding {
+	median, scale := medianScale(values(baseline))
+	var findings []Finding
+	for _, p := range current {
+		if f, ok := robustFinding(DetectorMAD, p, median, scale, opts); ok {
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+// seasonal expects each day to be near the baseline median of its day of
+// the week. The spread comes from all baseline days once the weekly cycle
+// is removed, since a month holds only four or five of each weekday.
+func seasonal(baseline, current []Point, opts Options) []Finding {
+	byDay := make(map[time.Weekday][]float64)
+	for _, p := range baseline {
+		byDay[p.Date.Weekday()] = append(byDay[p.Date.Weekday()], p.Value)
+	}
+	overall, _ := medianScale(values(baseline))
+	level := make(map[time.Weekday]float64, len(byDay))
+	for day, vs := range byDay {
+		if len(vs) >= 2 {
+			level[day], _ = medianScale(vs)
+		}
+	}
+	expected := func(day time.Weekday) float64 {
+		if v, ok := level[day]; ok {
+			return v
+		}
+		return overall
+	}
+	residuals := make([]float64, 0, len(baseline))
+	for _, p := range baseline {
+		residuals = append(residuals, p.Value-expected(p.Date.Weekday()))
+	}
+	_, scale := medianScale(residuals)
+
+	var findings []Finding
+	for _, p := range current {
+		if f, ok := robustFinding(DetectorSeasonal, p, expected(p.Date.Weekday()), floorScale(scale, overall), opts); ok {
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+func robustFinding(detector string, p Point, expected, scale float64, opts Options) (Finding, bool) {
+	scale = floorScale(scale, expected)
+	upper := expected + opts.Threshold*scale
+	if p.Value <= upper {
+		return Finding{}, false
+	}
+	score := (p.Value - expected) / scale
+	return Finding{
+		Detector:   detector,
+		Date:       p.Date,
+		Value:      p.Value,
+		Expected:   expected,
+		Lower:      math.Max(0, expected-opts.Threshold*scale),
+		Upper:      upper,
+		Score:      round(score),
+		Confidence: normalConfidence(score),
+	}, true
+}
+
+func values(points []Point) []float64 {
+	out := make([]float64, len(points))
+	for i, p := range points {
+		out[i] = p.Value
+	}
+	return out
+}
+
+func meanStdDev(vs []float64) (mean, sd float64) {
+	for _, v := range vs {
+		mean += v
+	}
+	mean /= float64(len(vs))
+	if len(vs) < 2 {
+		return mean, 0
+	}
+	for _, v := range vs {
+		sd += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(sd / float64(len(vs)-1))
+}
+
+// medianScale returns the median of vs and its scaled median absolute
+// deviation.
+func medianScale(vs []float64) (median, scale float64) {
+	median = medianOf(vs)
+	deviations := make([]float64, len(vs))
+	for i, v := range vs {
+		deviations[i] = math.Abs(v - median)
+	}
+	return median, madScale * medianOf(deviations)
+}
+
+func medianOf(vs []float64) float64 {
+	if len(vs) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), vs...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// floorScale keeps a flat baseline from turning every change into an
+// infinite score: the spread is at least 1% of the level.
+func floorScale(scale, level float64) float64 {
+	if floor := math.Abs(level) * 0.01; scale < floor {
+		scale = floor
+	}
+	if scale == 0 {
+		scale = 1e-9
+	}
+	return scale
+}
+
+// normalConfidence is the probability that a normal value lies within
+// score standard deviations of its mean.
+func normalConfidence(score float64) float64 {
+	if score <= 0 {
+		return 0
+	}
+	return round(math.Erf(score / math.Sqrt2))
+}
+
+func round(v float64) float64 {
+	return math.Round(v*1e4) / 1e4
+}
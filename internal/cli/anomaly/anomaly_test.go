@@ -0,0 +1,125 @@
+//go:build unit
+// +build unit
+
+package anomaly
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// start is a Monday.
+var start = time.Date(2026, 9, 7, 0, 0, 0, 0, time.UTC)
+
+// weekly returns days of a series that doubles on weekends, with a little
+// noise, starting offset days after start.
+func weekly(offset, days int) []Point {
+	noise := []float64{0, 0.0002, -0.0001, 0.0001, -0.0002, 0.0001, 0}
+	points := make([]Point, 0, days)
+	for i := offset; i < offset+days; i++ {
+		date := start.AddDate(0, 0, i)
+		v := 0.01
+		if date.Weekday() == time.Saturday || date.Weekday() == time.Sunday {
+			v = 0.02
+		}
+		points = append(points, Point{Date: date, Value: v + noise[i%len(noise)]})
+	}
+	return points
+}
+
+func near(a, b float64) bool { return math.Abs(a-b) < 1e-12 }
+
+func TestSeasonalIgnoresWeekendsButNotSpikes(t *testing.T) {
+	baseline, current := weekly(0, 28), weekly(28, 7)
+
+	mad, err := Detect(DetectorMAD, baseline, current, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mad) != 2 {
+		t.Errorf("mad should flag the weekend, got %+v", mad)
+	}
+
+	got, err := Detect(DetectorSeasonal, baseline, current, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("seasonal flagged the weekly cycle: %+v", got)
+	}
+
+	current[2].Value = 0.03 // a Wednesday
+	got, _ = Detect(DetectorSeasonal, baseline, current, Options{})
+	if len(got) != 1 || !got[0].Date.Equal(current[2].Date) {
+		t.Fatalf("findings = %+v", got)
+	}
+	f := got[0]
+	if f.Detector != DetectorSeasonal || !near(f.Expected, 0.0099) || f.Upper <= f.Expected || f.Upper >= f.Value || f.Confidence < 0.99 {
+		t.Errorf("finding = %+v", f)
+	}
+}
+
+func TestEWMAAndCUSUMCatchSustainedShifts(t *testing.T) {
+	var baseline, current []Point
+	for i := 0; i < 30; i++ {
+		v := 0.010
+		if i%2 == 1 {
+			v = 0.011
+		}
+		baseline = append(baseline, Point{Date: start.AddDate(0, 0, i), Value: v})
+	}
+	// A shift of about two standard deviations: no single day is extreme.
+	for i := 30; i < 37; i++ {
+		current = append(current, Point{Date: start.AddDate(0, 0, i), Value: 0.0115})
+	}
+
+	if got, _ := Detect(DetectorMAD, baseline, current, Options{}); len(got) != 0 {
+		t.Errorf("mad flagged single days: %+v", got)
+	}
+	got, _ := Detect(DetectorEWMA, baseline, current, Options{})
+	if len(got) == 0 || got[0].Value != 0.0115 || !near(got[0].Expected, 0.0105) {
+		t.Fatalf("ewma findings = %+v", got)
+	}
+	got, _ = Detect(DetectorCUSUM, baseline, current, Options{})
+	if len(got) == 0 || got[0].Score <= 4 || got[0].Confidence <= 0.98 {
+		t.Fatalf("cusum findings = %+v", got)
+	}
+	// Restarting after a signal reports a sustained shift periodically,
+	// not every day.
+	if len(got) >= len(current) {
+		t.Errorf("cusum reported every day: %+v", got)
+	}
+}
+
+func TestWindow(t *testing.T) {
+	baseline := []Point{{Date: start, Value: 0.01}, {Date: start.AddDate(0, 0, 1), Value: 0.01}}
+	current := []Point{{Date: start.AddDate(0, 0, 2), Value: 0.05}, {Date: start.AddDate(0, 0, 3), Value: 0.05}}
+	got, err := Detect(DetectorWindow, baseline, current, Options{Multiplier: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Expected != 0.01 || got[0].Upper != 0.02 || got[0].Value != 0.05 || !got[0].Date.Equal(current[1].Date) || got[0].Confidence != 1 {
+		t.Fatalf("findings = %+v", got)
+	}
+	if got, _ := Detect(DetectorWindow, baseline, current, Options{Multiplier: 6}); len(got) != 0 {
+		t.Errorf("findings below the limit: %+v", got)
+	}
+}
+
+func TestDetectEdgeCases(t *testing.T) {
+	if _, err := Detect("zscore", weekly(0, 7), weekly(7, 7), Options{}); err == nil {
+		t.Error("expected an error for an unknown detector")
+	}
+	for _, d := range Detectors {
+		if got, err := Detect(d, nil, weekly(0, 7), Options{}); err != nil || got != nil {
+			t.Errorf("%s without baseline = %+v, %v", d, got, err)
+		}
+	}
+	// A flat baseline still yields finite scores.
+	flat := []Point{{Date: start, Value: 0.01}, {Date: start.AddDate(0, 0, 1), Value: 0.01}}
+	got, _ := Detect(DetectorMAD, flat, []Point{{Date: start.AddDate(0, 0, 2), Value: 0.0102}}, Options{})
+	if len(got) != 0 {
+		t.Errorf("a 2%% rise on a flat baseline was flagged: %+v", got)
+	}
+}
@@ -0,0 +1,367 @@
+// Package artifactdiff holds pure helpers for gpd inspect diff: it captures
+// the release-relevant surface of an APK or AAB (manifest, exported
+// components, native ABIs, bundle modules, size) and reports what changed
+// between two builds, as structured data, flat rows for tabular formats, or
+// markdown for PR comments. Kong adapters live in package cli.
+package artifactdiff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/artifact"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/sizereport"
+)
+
+// Snapshot is the part of an artifact a release reviewer compares.
+type Snapshot struct {
+	File        string        `json:"file"`
+	Format      artifact.Kind `json:"format"`
+	Package     string        `json:"package"`
+	VersionCode int64         `json:"versionCode"`
+	VersionName string        `json:"versionName,omitempty"`
+	MinSDK      int           `json:"minSdk"`
+	TargetSDK   int           `json:"targetSdk"`
+	CompileSDK  int           `json:"compileSdk,omitempty"`
+
+	permissions []string
+	exported    []string
+	abis        []string
+	modules     map[string]artifact.ModuleSize
+	size        *sizereport.Report
+}
+
+// Capture reads a snapshot from an opened artifact. Exported components of
+// every bundle module are included, qualified by module when not in base.
+func Capture(a *artifact.Artifact) (*Snapshot, error) {
+	m := a.Manifest
+	s := &Snapshot{
+		File:        a.Path,
+		Format:      a.Kind,
+		Package:     m.Package,
+		VersionCode: m.VersionCode,
+		VersionName: m.VersionName,
+		MinSDK:      m.MinSDK,
+		TargetSDK:   m.TargetSDK,
+		CompileSDK:  m.CompileSDK,
+		permissions: m.PermissionNames(),
+		exported:    exportedComponents(m, ""),
+		modules:     make(map[string]artifact.ModuleSize),
+	}
+	for _, module := range a.Modules {
+		if module == "base" {
+			continue
+		}
+		mm, err := a.ModuleManifest(module)
+		if err != nil {
+			return nil, err
+		}
+		s.exported = append(s.exported, exportedComponents(mm, module)...)
+	}
+	sort.Strings(s.exported)
+
+	report, err := sizereport.Build(a)
+	if err != nil {
+		return nil, err
+	}
+	s.size = report
+	for _, b := range report.ABIs {
+		s.abis = append(s.abis, b.Name)
+	}
+	sort.Strings(s.abis)
+	for _, ms := range report.Modules {
+		s.modules[ms.Module] = ms
+	}
+	return s, nil
+}
+
+// exportedComponents lists components reachable from other apps as
+// "<kind> <name>", prefixed with "<module>:" outside the base module.
+func exportedComponents(m *artifact.Manifest, module string) []string {
+	out := make([]string, 0)
+	prefix := ""
+	if module != "" {
+		prefix = module + ":"
+	}
+	for _, group := range []struct {
+		kind       string
+		components []artifact.Component
+	}{
+		{"activity", m.Activities},
+		{"service", m.Services},
+		{"receiver", m.Receivers},
+		{"provider", m.Providers},
+	} {
+		for _, c := range group.components {
+			if c.IsExported() {
+				out = append(out, prefix+group.kind+" "+c.Name)
+			}
+		}
+	}
+	return out
+}
+
+// Change is an attribute whose value differs between the two builds.
+type Change struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// SetDiff lists names present in only one of the two builds.
+type SetDiff struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+}
+
+func (d SetDiff) empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0
+}
+
+// ModuleChange is a bundle module that was added, removed, or whose type or
+// delivery mode changed.
+type ModuleChange struct {
+	Module      string `json:"module"`
+	Change      string `json:"change"`
+	Type        string `json:"type,omitempty"`
+	Delivery    string `json:"delivery,omitempty"`
+	OldDelivery string `json:"oldDelivery,omitempty"`
+}
+
+// Module change kinds.
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// Diff is the semantic difference between two builds.
+type Diff struct {
+	Old         *Snapshot              `json:"old"`
+	New         *Snapshot              `json:"new"`
+	Changes     []Change               `json:"changes"`
+	Permissions SetDiff                `json:"permissions"`
+	Exported    SetDiff                `json:"exportedComponents"`
+	ABIs        SetDiff                `json:"abis"`
+	Modules     []ModuleChange         `json:"modules"`
+	Size        *sizereport.Comparison `json:"size"`
+}
+
+// Compare diffs new against old.
+func Compare(old, current *Snapshot) *Diff {
+	d := &Diff{
+		Old:         old,
+		New:         current,
+		Changes:     make([]Change, 0),
+		Permissions: diffSets(old.permissions, current.permissions),
+		Exported:    diffSets(old.exported, current.exported),
+		ABIs:        diffSets(old.abis, current.abis),
+		Modules:     diffModules(old.modules, current.modules),
+		Size:        sizereport.Compare(old.size, current.size, sizereport.Budget{}),
+	}
+	for _, f := range []struct {
+		name     string
+		old, new interface{}
+	}{
+		{"package", old.Package, current.Package},
+		{"format", old.Format, current.Format},
+		{"minSdk", old.MinSDK, current.MinSDK},
+		{"targetSdk", old.TargetSDK, current.TargetSDK},
+		{"compileSdk", old.CompileSDK, current.CompileSDK},
+	} {
+		o, n := fmt.Sprint(f.old), fmt.Sprint(f.new)
+		if o != n {
+			d.Changes = append(d.Changes, Change{Field: f.name, Old: o, New: n})
+		}
+	}
+	return d
+}
+
+// diffSets returns the sorted names only in current (added) and only in old
+// (removed).
+func diffSets(old, current []string) SetDiff {
+	d := SetDiff{Added: make([]string, 0), Removed: make([]string, 0)}
+	inOld, inNew := toSet(old), toSet(current)
+	for _, n := range current {
+		if !inOld[n] {
+			d.Added = append(d.Added, n)
+		}
+	}
+	for _, n := range old {
+		if !inNew[n] {
+			d.Removed = append(d.Removed, n)
+		}
+	}
+	sort.Strings(d.Added)
+	sort.Strings(d.Removed)
+	return d
+}
+
+func toSet(names []string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, n := range names {
+		set[n] = true
+	}
+	return set
+}
+
+func diffModules(old, current map[string]artifact.ModuleSize) []ModuleChange {
+	out := make([]ModuleChange, 0)
+	for name, m := range current {
+		prev, ok := old[name]
+		switch {
+		case !ok:
+			out = append(out, ModuleChange{Module: name, Change: ChangeAdded, Type: m.Type, Delivery: m.Delivery})
+		case prev.Type != m.Type || prev.Delivery != m.Delivery:
+			out = append(out, ModuleChange{Module: name, Change: ChangeChanged, Type: m.Type, Delivery: m.Delivery, OldDelivery: prev.Delivery})
+		}
+	}
+	for name, m := range old {
+		if _, ok := current[name]; !ok {
+			out = append(out, ModuleChange{Module: name, Change: ChangeRemoved, Type: m.Type, Delivery: m.Delivery})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Module < out[j].Module })
+	return out
+}
+
+// Rows flattens the diff to one row per change for table, CSV and Excel
+// output. Size rows are the total and per-device downloads, then modules
+// and categories whose size moved.
+func (d *Diff) Rows() []map[string]interface{} {
+	rows := make([]map[string]interface{}, 0)
+	add := func(section, change, item string, old, current interface{}) {
+		rows = append(rows, map[string]interface{}{
+			"section": section, "change": change, "item": item, "old": old, "new": current,
+		})
+	}
+	for _, c := range d.Changes {
+		add("manifest", ChangeChanged, c.Field, c.Old, c.New)
+	}
+	for _, s := range []struct {
+		section string
+		diff    SetDiff
+	}{{"permission", d.Permissions}, {"exported", d.Exported}, {"abi", d.ABIs}} {
+		for _, n := range s.diff.Added {
+			add(s.section, ChangeAdded, n, "", n)
+		}
+		for _, n := range s.diff.Removed {
+			add(s.section, ChangeRemoved, n, n, "")
+		}
+	}
+	for _, m := range d.Modules {
+		switch m.Change {
+		case ChangeAdded:
+			add("module", m.Change, m.Module, "", m.Type+"/"+m.Delivery)
+		case ChangeRemoved:
+			add("module", m.Change, m.Module, m.Type+"/"+m.Delivery, "")
+		default:
+			add("module", m.Change, m.Module, m.OldDelivery, m.Delivery)
+		}
+	}
+	for _, s := range d.sizeDeltas() {
+		add("size", ChangeChanged, s.Metric, s.Old, s.New)
+	}
+	return rows
+}
+
+// sizeDeltas returns the download deltas plus module and category deltas
+// that are not zero.
+func (d *Diff) sizeDeltas() []sizereport.Delta {
+	out := append([]sizereport.Delta(nil), d.Size.Downloads...)
+	for _, group := range []struct {
+		prefix string
+		deltas []sizereport.Delta
+	}{{"module:", d.Size.Modules}, {"category:", d.Size.Categories}} {
+		for _, delta := range group.deltas {
+			if delta.Delta != 0 {
+				delta.Metric = group.prefix + delta.Metric
+				out = append(out, delta)
+			}
+		}
+	}
+	return out
+}
+
+// Markdown renders the diff for a pull request or release review comment.
+func (d *Diff) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s: %s → %s\n\n", d.New.Package, versionLabel(d.Old), versionLabel(d.New))
+
+	if len(d.Changes) > 0 {
+		b.WriteString("### Manifest\n\n| Field | Old | New |\n| --- | --- | --- |\n")
+		for _, c := range d.Changes {
+			fmt.Fprintf(&b, "| %s | %s | %s |\n", c.Field, c.Old, c.New)
+		}
+		b.WriteString("\n")
+	}
+	writeSetSection(&b, "Permissions", d.Permissions)
+	writeSetSection(&b, "Exported components", d.Exported)
+	writeSetSection(&b, "Native ABIs", d.ABIs)
+	if len(d.Modules) > 0 {
+		b.WriteString("### Modules\n\n")
+		for _, m := range d.Modules {
+			switch m.Change {
+			case ChangeChanged:
+				fmt.Fprintf(&b, "- Changed `%s` (%s): delivery %s → %s\n", m.Module, m.Type, m.OldDelivery, m.Delivery)
+			default:
+				fmt.Fprintf(&b, "- %s `%s` (%s, %s)\n", capitalize(m.Change), m.Module, m.Type, m.Delivery)
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("### Size\n\n| Metric | Old | New | Change |\n| --- | ---: | ---: | ---: |\n")
+	for _, s := range d.sizeDeltas() {
+		fmt.Fprintf(&b, "| %s | %s | %s | %+d (%+.2f%%) |\n", s.Metric, formatBytes(s.Old), formatBytes(s.New), s.Delta, s.ChangePct)
+	}
+	if !d.HasChanges() {
+		b.WriteString("\nNo manifest, permission, component, ABI or module changes.\n")
+	}
+	return b.String()
+}
+
+// HasChanges reports whether anything other than size differs.
+func (d *Diff) HasChanges() bool {
+	return len(d.Changes) > 0 || !d.Permissions.empty() || !d.Exported.empty() || !d.ABIs.empty() || len(d.Modules) > 0
+}
+
+func writeSetSection(b *strings.Builder, title string, d SetDiff) {
+	if d.empty() {
+		return
+	}
+	fmt.Fprintf(b, "### %s\n\n", title)
+	for _, n := range d.Added {
+		fmt.Fprintf(b, "- Added `%s`\n", n)
+	}
+	for _, n := range d.Removed {
+		fmt.Fprintf(b, "- Removed `%s`\n", n)
+	}
+	b.WriteString("\n")
+}
+
+func versionLabel(s *Snapshot) string {
+	if s.VersionName != "" {
+		return fmt.Sprintf("%s (%d)", s.VersionName, s.VersionCode)
+	}
+	return fmt.Sprint(s.VersionCode)
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// formatBytes renders a size in decimal units, as Play Console does.
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1e6:
+		return fmt.Sprintf("%.2f MB", float64(n)/1e6)
+	case n >= 1e3:
+		return fmt.Sprintf("%.1f KB", float64(n)/1e3)
+	}
+	return fmt.Sprintf("%d B", n)
+}
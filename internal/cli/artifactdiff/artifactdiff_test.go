@@ -0,0 +1,110 @@
+//go:build unit
+// +build unit
+
+package artifactdiff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/artifact"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/artifact/artifacttest"
+)
+
+func capture(t *testing.T, path string) *Snapshot {
+	t.Helper()
+	a, err := artifact.Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer func() { _ = a.Close() }()
+	s, err := Capture(a)
+	if err != nil {
+		t.Fatalf("Capture: %v", err)
+	}
+	return s
+}
+
+func lib(abi string) artifacttest.Entry {
+	return artifacttest.Entry{Name: "base/lib/" + abi + "/libapp.so", Data: make([]byte, 1000), Stored: true}
+}
+
+func TestCompare(t *testing.T) {
+	old := capture(t, artifacttest.WriteAAB(t, artifacttest.Manifest("com.example.app", 41), lib("armeabi-v7a"), lib("arm64-v8a")))
+
+	manifest := artifacttest.Manifest("com.example.app", 42)
+	manifest.Child("uses-sdk").Set("android:minSdkVersion", "26").Set("android:targetSdkVersion", "35")
+	manifest.Children = append(manifest.Children, artifacttest.E("uses-permission", []string{"android:name", "android.permission.CAMERA"}))
+	for i, c := range manifest.Children {
+		if c.Name == "uses-permission" && c.Attrs[0].Value == "android.permission.READ_SMS" {
+			manifest.Children = append(manifest.Children[:i], manifest.Children[i+1:]...)
+			break
+		}
+	}
+	app := manifest.Child("application")
+	app.Children = append(app.Children, artifacttest.E("provider", []string{"android:name", ".FilesProvider", "android:exported", "true"}))
+	assets := artifacttest.Entry{
+		Name: "textures/manifest/AndroidManifest.xml",
+		Data: artifacttest.EncodeProtoXML(artifacttest.E("manifest", []string{"package", "com.example.app", "split", "textures"},
+			artifacttest.E("module", []string{"dist:type", "asset-pack"}, artifacttest.E("delivery", nil, artifacttest.E("fast-follow", nil))))),
+	}
+	current := capture(t, artifacttest.WriteAAB(t, manifest, lib("arm64-v8a"), lib("x86_64"), assets,
+		artifacttest.Entry{Name: "textures/assets/atlas.bin", Data: make([]byte, 3000), Stored: true}))
+
+	d := Compare(old, current)
+	if want := []Change{{"minSdk", "24", "26"}, {"targetSdk", "34", "35"}}; !reflect.DeepEqual(d.Changes, want) {
+		t.Errorf("changes = %+v", d.Changes)
+	}
+	if !reflect.DeepEqual(d.Permissions.Added, []string{"android.permission.CAMERA"}) ||
+		!reflect.DeepEqual(d.Permissions.Removed, []string{"android.permission.READ_SMS"}) {
+		t.Errorf("permissions = %+v", d.Permissions)
+	}
+	if !reflect.DeepEqual(d.Exported.Added, []string{"provider .FilesProvider"}) || len(d.Exported.Removed) != 0 {
+		t.Errorf("exported = %+v", d.Exported)
+	}
+	if !reflect.DeepEqual(d.ABIs.Added, []string{"x86_64"}) || !reflect.DeepEqual(d.ABIs.Removed, []string{"armeabi-v7a"}) {
+		t.Errorf("abis = %+v", d.ABIs)
+	}
+	if want := []ModuleChange{{Module: "textures", Change: ChangeAdded, Type: "asset-pack", Delivery: artifact.DeliveryFastFollow}}; !reflect.DeepEqual(d.Modules, want) {
+		t.Errorf("modules = %+v", d.Modules)
+	}
+	if d.Size.Downloads[0].Metric != "total" || d.Size.Downloads[0].Delta <= 0 || d.Size.Exceeded {
+		t.Errorf("size = %+v", d.Size.Downloads)
+	}
+
+	rows := d.Rows()
+	sections := make(map[string]int)
+	for _, r := range rows {
+		sections[r["section"].(string)]++
+	}
+	if sections["permission"] != 2 || sections["abi"] != 2 || sections["module"] != 1 || sections["size"] == 0 {
+		t.Errorf("row sections = %v", sections)
+	}
+
+	md := d.Markdown()
+	for _, want := range []string{
+		"## com.example.app: 1.4.2 (41) → 1.4.2 (42)",
+		"| targetSdk | 34 | 35 |",
+		"- Added `android.permission.CAMERA`",
+		"- Removed `android.permission.READ_SMS`",
+		"- Added `provider .FilesProvider`",
+		"- Added `textures` (asset-pack, fast-follow)",
+		"| total |",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+}
+
+func TestCompare_Identical(t *testing.T) {
+	path := artifacttest.WriteAPK(t, artifacttest.Manifest("com.example.app", 1))
+	d := Compare(capture(t, path), capture(t, path))
+	if d.HasChanges() || len(d.Rows()) != len(d.Size.Downloads) {
+		t.Errorf("diff = %+v rows = %v", d, d.Rows())
+	}
+	if !strings.Contains(d.Markdown(), "No manifest, permission") {
+		t.Errorf("markdown = %s", d.Markdown())
+	}
+}
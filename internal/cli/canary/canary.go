@@ -0,0 +1,208 @@
+// Package canary decides whether a new version code is healthy enough to
+// keep rolling out, by comparing its daily vitals against a baseline
+// version with Welch's t-test. Kong adapters live in package cli.
+package canary
+
+import (
+	"sort"
+
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/apitest/benchcheck"
+)
+
+// Verdicts for a metric and for the canary as a whole.
+const (
+	VerdictPass         = "pass"
+	VerdictFail         = "fail"
+	VerdictInconclusive = "inconclusive"
+)
+
+// Defaults for Options.
+const (
+	DefaultAlpha       = 0.05
+	DefaultMinSamples  = 3
+	DefaultMaxIncrease = 0.1
+)
+
+// Options configure the verdict.
+type Options struct {
+	// Alpha is the significance level of the t-test.
+	Alpha float64
+	// MinSamples is the number of daily values each version needs before
+	// a metric gets a verdict other than inconclusive.
+	MinSamples int
+	// MaxIncrease is the relative increase over the baseline mean that a
+	// difference which is not significant may have and still pass. Larger
+	// increases without significance are inconclusive: more days are
+	// needed to tell.
+	MaxIncrease float64
+}
+
+func (o Options) withDefaults() Options {
+	if o.Alpha <= 0 || o.Alpha >= 1 {
+		o.Alpha = DefaultAlpha
+	}
+	if o.MinSamples < 2 {
+		o.MinSamples = DefaultMinSamples
+	}
+	if o.MaxIncrease < 0 {
+		o.MaxIncrease = DefaultMaxIncrease
+	}
+	return o
+}
+
+// Series are the daily values of one metric for both versions. Lower is
+// better for every metric the canary compares.
+type Series struct {
+	Metric    string
+	Baseline  []float64
+	Candidate []float64
+}
+
+// Comparison is the verdict for one metric.
+type Comparison struct {
+	Metric           string  `json:"metric"`
+	Verdict          string  `json:"verdict"`
+	BaselineMean     float64 `json:"baselineMean"`
+	CandidateMean    float64 `json:"candidateMean"`
+	BaselineSamples  int     `json:"baselineSamples"`
+	CandidateSamples int     `json:"candidateSamples"`
+	// Delta is the candidate mean minus the baseline mean; RelativeDelta
+	// divides it by the baseline mean, and is 0 when that is 0.
+	Delta         float64 `json:"delta"`
+	RelativeDelta float64 `json:"relativeDelta"`
+	TStat         float64 `json:"tStat"`
+	DF            float64 `json:"degreesOfFreedom"`
+	PValue        float64 `json:"pValue"`
+	// Confidence is 1 - PValue: how confident the test is that the means
+	// differ.
+	Confidence float64 `json:"confidence"`
+	Reason     string  `json:"reason"`
+}
+
+// Result is the canary verdict.
+type Result struct {
+	Version     int64        `json:"versionCode"`
+	Baseline    int64        `json:"baselineVersionCode"`
+	Alpha       float64      `json:"alpha"`
+	MinSamples  int          `json:"minSamples"`
+	MaxIncrease float64      `json:"maxIncrease"`
+	Verdict     string       `json:"verdict"`
+	Failed      []string     `json:"failed"`
+	Metrics     []Comparison `json:"metrics"`
+}
+
+// Evaluate compares each series and combines the verdicts: fail when any
+// metric regressed significantly, else inconclusive when any metric lacks
+// data or evidence, else pass.
+func Evaluate(version, baseline int64, series []Series, opts Options) *Result {
+	opts = opts.withDefaults()
+	r := &Result{
+		Version:     version,
+		Baseline:    baseline,
+		Alpha:       opts.Alpha,
+		MinSamples:  opts.MinSamples,
+		MaxIncrease: opts.MaxIncrease,
+		Verdict:     VerdictPass,
+		Failed:      make([]string, 0),
+		Metrics:     make([]Comparison, 0, len(series)),
+	}
+	for _, s := range series {
+		c := Compare(s, opts)
+		r.Metrics = append(r.Metrics, c)
+		switch c.Verdict {
+		case VerdictFail:
+			r.Failed = append(r.Failed, c.Metric)
+			r.Verdict = VerdictFail
+		case VerdictInconclusive:
+			if r.Verdict == VerdictPass {
+				r.Verdict = VerdictInconclusive
+			}
+		}
+	}
+	if len(series) == 0 {
+		r.Verdict = VerdictInconclusive
+	}
+	sort.Strings(r.Failed)
+	return r
+}
+
+// Compare runs the t-test for one metric.
+func Compare(s Series, opts Options) Comparison {
+	opts = opts.withDefaults()
+	c := Comparison{
+		Metric:           s.Metric,
+		BaselineSamples:  len(s.Baseline),
+		CandidateSamples: len(s.Candidate),
+		BaselineMean:     mean(s.Baseline),
+		CandidateMean:    mean(s.Candidate),
+		PValue:           1,
+	}
+	c.Delta = c.CandidateMean - c.BaselineMean
+	if c.BaselineMean != 0 {
+		c.RelativeDelta = c.Delta / c.BaselineMean
+	}
+
+	if c.BaselineSamples < opts.MinSamples || c.CandidateSamples < opts.MinSamples {
+		c.Verdict = VerdictInconclusive
+		c.Reason = "not enough daily values"
+		return c
+	}
+
+	c.TStat, c.DF = benchcheck.WelchTTest(s.Baseline, s.Candidate)
+	switch {
+	case c.DF > 0:
+		c.PValue = benchcheck.TwoTailedPValue(c.TStat, c.DF)
+	case c.Delta != 0:
+		// Both series are constant and differ: no variance to test, the
+		// difference is certain.
+		c.PValue = 0
+	}
+	c.Confidence = 1 - c.PValue
+
+	significant := benchcheck.IsSignificant(c.PValue, opts.Alpha)
+	switch {
+	case significant && c.Delta > 0:
+		c.Verdict = VerdictFail
+		c.Reason = "significantly worse than baseline"
+	case significant:
+		c.Verdict = VerdictPass
+		c.Reason = "significantly better than baseline"
+	case c.Delta <= 0 || (c.BaselineMean != 0 && c.RelativeDelta <= opts.MaxIncrease):
+		c.Verdict = VerdictPass
+		c.Reason = "no significant difference"
+	default:
+		c.Verdict = VerdictInconclusive
+		c.Reason = "worse than baseline but not significant"
+	}
+	return c
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// Rows flattens the comparisons for table, markdown, CSV and Excel output.
+func (r *Result) Rows() []map[string]interface{} {
+	rows := make([]map[string]interface{}, 0, len(r.Metrics))
+	for _, c := range r.Metrics {
+		rows = append(rows, map[string]interface{}{
+			"metric":     c.Metric,
+			"verdict":    c.Verdict,
+			"baseline":   c.BaselineMean,
+			"candidate":  c.CandidateMean,
+			"delta":      c.RelativeDelta,
+			"pValue":     c.PValue,
+			"confidence": c.Confidence,
+			"samples":    c.CandidateSamples,
+			"reason":     c.Reason,
+		})
+	}
+	return rows
+}
@@ -0,0 +1,74 @@
+//go:build unit
+// +build unit
+
+package canary
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	baseline := []float64{0.010, 0.011, 0.009, 0.010, 0.012, 0.010, 0.011}
+	tests := []struct {
+		name      string
+		candidate []float64
+		baseline  []float64
+		want      string
+	}{
+		{"regression", []float64{0.020, 0.022, 0.019, 0.021, 0.020, 0.023, 0.021}, baseline, VerdictFail},
+		{"improvement", []float64{0.005, 0.006, 0.004, 0.005, 0.006, 0.005, 0.005}, baseline, VerdictPass},
+		{"no difference", []float64{0.011, 0.010, 0.010, 0.009, 0.011, 0.012, 0.010}, baseline, VerdictPass},
+		{"noisy increase", []float64{0.005, 0.030, 0.008, 0.025}, baseline, VerdictInconclusive},
+		{"too few days", []float64{0.050, 0.060}, baseline, VerdictInconclusive},
+		{"constant regression", []float64{0.02, 0.02, 0.02}, []float64{0.01, 0.01, 0.01}, VerdictFail},
+		{"constant from zero", []float64{0, 0, 0}, []float64{0, 0, 0}, VerdictPass},
+	}
+	for _, tc := range tests {
+		c := Compare(Series{Metric: "crashRate", Baseline: tc.baseline, Candidate: tc.candidate}, Options{})
+		if c.Verdict != tc.want {
+			t.Errorf("%s: verdict = %s (%+v), want %s", tc.name, c.Verdict, c, tc.want)
+		}
+		if c.Confidence < 0 || c.Confidence > 1 || c.PValue < 0 || c.PValue > 1 {
+			t.Errorf("%s: p = %v confidence = %v", tc.name, c.PValue, c.Confidence)
+		}
+	}
+
+	c := Compare(Series{Metric: "crashRate", Baseline: baseline, Candidate: tests[0].candidate}, Options{})
+	if c.Confidence < 0.999 || c.RelativeDelta < 0.9 || c.Reason != "significantly worse than baseline" {
+		t.Errorf("regression = %+v", c)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	flat := []float64{0.01, 0.011, 0.009, 0.01}
+	worse := []float64{0.03, 0.031, 0.029, 0.03}
+	r := Evaluate(31, 30, []Series{
+		{Metric: "slowStartRate", Baseline: flat, Candidate: worse},
+		{Metric: "anrRate", Baseline: flat, Candidate: []float64{0.01}},
+		{Metric: "crashRate", Baseline: flat, Candidate: worse},
+	}, Options{})
+	if r.Verdict != VerdictFail || !reflect.DeepEqual(r.Failed, []string{"crashRate", "slowStartRate"}) {
+		t.Errorf("result = %+v", r)
+	}
+	if r.Metrics[1].Verdict != VerdictInconclusive || r.Alpha != DefaultAlpha || r.MinSamples != DefaultMinSamples {
+		t.Errorf("result = %+v", r)
+	}
+	if _, err := json.Marshal(r); err != nil {
+		t.Errorf("json: %v", err)
+	}
+
+	if r := Evaluate(31, 30, []Series{{Metric: "anrRate", Baseline: flat, Candidate: []float64{0.01}}}, Options{}); r.Verdict != VerdictInconclusive {
+		t.Errorf("inconclusive = %+v", r)
+	}
+	if r := Evaluate(31, 30, []Series{{Metric: "anrRate", Baseline: flat, Candidate: flat}}, Options{}); r.Verdict != VerdictPass {
+		t.Errorf("pass = %+v", r)
+	}
+	if r := Evaluate(31, 30, nil, Options{}); r.Verdict != VerdictInconclusive {
+		t.Errorf("empty = %+v", r)
+	}
+	if rows := r.Rows(); len(rows) != 3 || rows[0]["metric"] != "slowStartRate" || rows[0]["verdict"] != VerdictFail {
+		t.Errorf("rows = %v", rows)
+	}
+}
@@ -0,0 +1,204 @@
+// Package compliance evaluates vitals against Google Play's bad behavior
+// thresholds: the overall and per-phone-model limits on user-perceived
+// crash and ANR rates above which Play may reduce an app's store
+// visibility. Kong adapters live in package cli.
+package compliance
+
+import "sort"
+
+// Play's published bad behavior thresholds, applied to the 28-day
+// user-weighted user-perceived rates.
+const (
+	OverallUserPerceivedCrashRate = 0.0109
+	OverallUserPerceivedAnrRate   = 0.0047
+	DeviceUserPerceivedCrashRate  = 0.08
+	DeviceUserPerceivedAnrRate    = 0.08
+)
+
+// Metrics evaluated, as named by the Reporting API.
+const (
+	MetricUserPerceivedCrashRate = "userPerceivedCrashRate28dUserWeighted"
+	MetricUserPerceivedAnrRate   = "userPerceivedAnrRate28dUserWeighted"
+)
+
+// Statuses of a check and of the report.
+const (
+	StatusOK     = "ok"
+	StatusAtRisk = "at-risk"
+	StatusOver   = "over"
+)
+
+// DefaultWarnRatio is the share of a threshold at which a value is at risk.
+const DefaultWarnRatio = 0.8
+
+// Thresholds are the overall and per-device limits for one metric.
+type Thresholds struct {
+	Overall float64
+	Device  float64
+}
+
+// PlayThresholds maps each evaluated metric to Play's limits.
+var PlayThresholds = map[string]Thresholds{
+	MetricUserPerceivedCrashRate: {Overall: OverallUserPerceivedCrashRate, Device: DeviceUserPerceivedCrashRate},
+	MetricUserPerceivedAnrRate:   {Overall: OverallUserPerceivedAnrRate, Device: DeviceUserPerceivedAnrRate},
+}
+
+// Options configure the evaluation.
+type Options struct {
+	// WarnRatio is the share of a threshold at which a value is at risk.
+	WarnRatio float64
+	// MinUsers skips device models with fewer distinct users, whose rates
+	// are too noisy to act on.
+	MinUsers float64
+}
+
+// DeviceRate is a metric's value for one device model.
+type DeviceRate struct {
+	DeviceModel string
+	Rate        float64
+	Users       float64
+}
+
+// Input is one metric's overall value and its per-device values.
+type Input struct {
+	Metric  string
+	Overall float64
+	Devices []DeviceRate
+}
+
+// Check compares one value against its threshold.
+type Check struct {
+	DeviceModel string  `json:"deviceModel,omitempty"`
+	Value       float64 `json:"value"`
+	Threshold   float64 `json:"threshold"`
+	// Headroom is the threshold minus the value; negative when over.
+	Headroom float64 `json:"headroom"`
+	// Utilization is the value as a share of the threshold.
+	Utilization float64 `json:"utilization"`
+	Users       float64 `json:"distinctUsers,omitempty"`
+	Status      string  `json:"status"`
+}
+
+// MetricReport is the evaluation of one metric.
+type MetricReport struct {
+	Metric      string `json:"metric"`
+	Status      string `json:"status"`
+	Overall     Check  `json:"overall"`
+	WorstDevice *Check `json:"worstDevice,omitempty"`
+	// DevicesOver are the device models over the per-device threshold,
+	// worst first; DevicesAtRisk those within the warn ratio of it.
+	DevicesOver   []Check `json:"devicesOverThreshold"`
+	DevicesAtRisk []Check `json:"devicesAtRisk"`
+}
+
+// Report is the compliance evaluation of an app.
+type Report struct {
+	Status    string         `json:"status"`
+	WarnRatio float64        `json:"warnRatio"`
+	Metrics   []MetricReport `json:"metrics"`
+}
+
+// Evaluate checks each input against PlayThresholds. Inputs for other
+// metrics are ignored.
+func Evaluate(inputs []Input, opts Options) *Report {
+	if opts.WarnRatio <= 0 || opts.WarnRatio > 1 {
+		opts.WarnRatio = DefaultWarnRatio
+	}
+	r := &Report{Status: StatusOK, WarnRatio: opts.WarnRatio, Metrics: make([]MetricReport, 0, len(inputs))}
+	for _, in := range inputs {
+		limits, ok := PlayThresholds[in.Metric]
+		if !ok {
+			continue
+		}
+		m := MetricReport{
+			Metric:        in.Metric,
+			Overall:       check(in.Overall, limits.Overall, opts.WarnRatio),
+			DevicesOver:   make([]Check, 0),
+			DevicesAtRisk: make([]Check, 0),
+		}
+		m.Status = m.Overall.Status
+		devices := append([]DeviceRate{}, in.Devices...)
+		sort.SliceStable(devices, func(i, j int) bool {
+			if devices[i].Rate != devices[j].Rate {
+				return devices[i].Rate > devices[j].Rate
+			}
+			return devices[i].DeviceModel < devices[j].DeviceModel
+		})
+		for _, d := range devices {
+			if d.Users < opts.MinUsers {
+				continue
+			}
+			c := check(d.Rate, limits.Device, opts.WarnRatio)
+			c.DeviceModel, c.Users = d.DeviceModel, d.Users
+			if m.WorstDevice == nil {
+				worst := c
+				m.WorstDevice = &worst
+			}
+			switch c.Status {
+			case StatusOver:
+				m.DevicesOver = append(m.DevicesOver, c)
+			case StatusAtRisk:
+				m.DevicesAtRisk = append(m.DevicesAtRisk, c)
+			}
+		}
+		if m.WorstDevice != nil {
+			m.Status = worse(m.Status, m.WorstDevice.Status)
+		}
+		r.Status = worse(r.Status, m.Status)
+		r.Metrics = append(r.Metrics, m)
+	}
+	return r
+}
+
+func check(value, threshold, warnRatio float64) Check {
+	c := Check{Value: value, Threshold: threshold, Headroom: threshold - value, Status: StatusOK}
+	if threshold > 0 {
+		c.Utilization = value / threshold
+	}
+	switch {
+	case value > threshold:
+		c.Status = StatusOver
+	case c.Utilization >= warnRatio:
+		c.Status = StatusAtRisk
+	}
+	return c
+}
+
+var statusRank = map[string]int{StatusOK: 0, StatusAtRisk: 1, StatusOver: 2}
+
+func worse(a, b string) string {
+	if statusRank[b] > statusRank[a] {
+		return b
+	}
+	return a
+}
+
+// Rows flattens the report for table, markdown, CSV and Excel output: the
+// overall check of each metric, then every device model over or at risk,
+// or the worst device model when none is.
+func (r *Report) Rows() []map[string]interface{} {
+	rows := make([]map[string]interface{}, 0)
+	row := func(metric, scope string, c Check) {
+		rows = append(rows, map[string]interface{}{
+			"metric":      metric,
+			"scope":       scope,
+			"deviceModel": c.DeviceModel,
+			"value":       c.Value,
+			"threshold":   c.Threshold,
+			"headroom":    c.Headroom,
+			"utilization": c.Utilization,
+			"status":      c.Status,
+		})
+	}
+	for _, m := range r.Metrics {
+		row(m.Metric, "overall", m.Overall)
+		devices := append(append([]Check{}, m.DevicesOver...), m.DevicesAtRisk...)
+		if len(devices) == 0 && m.WorstDevice != nil {
+			devices = []Check{*m.WorstDevice}
+		}
+		for _, d := range devices {
+			row(m.Metric, "device", d)
+		}
+	}
+	return rows
+}
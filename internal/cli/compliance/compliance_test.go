@@ -0,0 +1,54 @@
+//go:build unit
+// +build unit
+
+package compliance
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEvaluate(t *testing.T) {
+	r := Evaluate([]Input{
+		{
+			Metric:  MetricUserPerceivedCrashRate,
+			Overall: 0.009,
+			Devices: []DeviceRate{
+				{DeviceModel: "google/oriole", Rate: 0.01, Users: 5000},
+				{DeviceModel: "samsung/a52", Rate: 0.12, Users: 900},
+				{DeviceModel: "xiaomi/rare", Rate: 0.50, Users: 10},
+				{DeviceModel: "motorola/g7", Rate: 0.07, Users: 800},
+			},
+		},
+		{Metric: MetricUserPerceivedAnrRate, Overall: 0.001, Devices: []DeviceRate{{DeviceModel: "google/oriole", Rate: 0.002, Users: 5000}}},
+		{Metric: "crashRate", Overall: 1},
+	}, Options{MinUsers: 100})
+
+	if r.Status != StatusOver || r.WarnRatio != DefaultWarnRatio || len(r.Metrics) != 2 {
+		t.Fatalf("report = %+v", r)
+	}
+	crash := r.Metrics[0]
+	if crash.Overall.Status != StatusAtRisk || math.Abs(crash.Overall.Headroom-0.0019) > 1e-9 || math.Abs(crash.Overall.Utilization-0.009/0.0109) > 1e-9 {
+		t.Errorf("crash overall = %+v", crash.Overall)
+	}
+	// xiaomi/rare has too few users to count.
+	if crash.Status != StatusOver || crash.WorstDevice == nil || crash.WorstDevice.DeviceModel != "samsung/a52" {
+		t.Errorf("crash = %+v", crash)
+	}
+	if len(crash.DevicesOver) != 1 || crash.DevicesOver[0].Headroom >= 0 || len(crash.DevicesAtRisk) != 1 || crash.DevicesAtRisk[0].DeviceModel != "motorola/g7" {
+		t.Errorf("crash devices over = %+v at risk = %+v", crash.DevicesOver, crash.DevicesAtRisk)
+	}
+	if anr := r.Metrics[1]; anr.Status != StatusOK || anr.WorstDevice.DeviceModel != "google/oriole" {
+		t.Errorf("anr = %+v", anr)
+	}
+
+	rows := r.Rows()
+	// crash: overall + 2 devices; anr: overall + worst device.
+	if len(rows) != 5 || rows[1]["deviceModel"] != "samsung/a52" || rows[4]["scope"] != "device" || rows[4]["status"] != StatusOK {
+		t.Errorf("rows = %v", rows)
+	}
+
+	if r := Evaluate(nil, Options{}); r.Status != StatusOK || len(r.Metrics) != 0 {
+		t.Errorf("empty = %+v", r)
+	}
+}
@@ -0,0 +1,262 @@
+// Package crashcluster groups error reports by a local crash signature:
+// the root-cause exception plus the top app frames of its stack, with line
+// numbers, lambda indices and anonymous class numbers stripped so the same
+// crash clusters across builds. Kong adapters live in package cli.
+package crashcluster
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTopFrames is the number of app frames in a signature.
+const DefaultTopFrames = 3
+
+// frameworkPrefixes are packages that are never app frames.
+var frameworkPrefixes = []string{
+	"java.", "javax.", "jdk.", "sun.", "kotlin.", "kotlinx.", "android.", "androidx.",
+	"com.android.", "com.google.android.", "dalvik.", "libcore.", "org.json.",
+}
+
+var (
+	frameRe     = regexp.MustCompile(`^\s*at\s+(?:[^\s/]+//?)?([^\s(]+)\.([^\s.(]+)\(([^)]*)\)`)
+	exceptionRe = regexp.MustCompile(`^\s*(?:Exception in thread "[^"]*" |Caused by: )?([A-Za-z_$][\w$]*(?:\.[\w$]+)+)(?::.*)?$`)
+
+	// R8/javac lambda and synthetic class names, with their indices.
+	externalLambdaRe = regexp.MustCompile(`\$\$ExternalSynthetic(Lambda|ApiModelOutline|Outline|BackportWithForwarding)\d+`)
+	runtimeLambdaRe  = regexp.MustCompile(`\$\$Lambda(?:\$\d+|/0x[0-9a-f]+|\$[\w]+)*`)
+	lambdaMethodRe   = regexp.MustCompile(`^lambda\$(.+?)\$\d+$`)
+	kotlinLambdaRe   = regexp.MustCompile(`\$lambda-?\d+`)
+	anonymousRe      = regexp.MustCompile(`\$\d+`)
+)
+
+// Options configure fingerprinting.
+type Options struct {
+	// AppPackages are the package prefixes of app frames. When empty,
+	// every frame outside framework packages is an app frame.
+	AppPackages []string
+	// TopFrames is the number of app frames in a signature.
+	TopFrames int
+}
+
+// Signature identifies a crash independent of build and line numbers.
+type Signature struct {
+	ID        string   `json:"id"`
+	Exception string   `json:"exception"`
+	Frames    []string `json:"frames"`
+}
+
+// Fingerprint computes the signature of a stack trace. It uses the last
+// "Caused by" section, the root cause, and falls back to framework frames
+// when the trace has no app frames.
+func Fingerprint(trace string, opts Options) Signature {
+	if opts.TopFrames <= 0 {
+		opts.TopFrames = DefaultTopFrames
+	}
+	var exception string
+	var frames, app []string
+	for _, line := range strings.Split(trace, "\n") {
+		if m := frameRe.FindStringSubmatch(line); m != nil {
+			f := NormalizeFrame(m[1], m[2])
+			frames = append(frames, f)
+			if opts.isApp(m[1]) {
+				app = append(app, f)
+			}
+			continue
+		}
+		if m := exceptionRe.FindStringSubmatch(line); m != nil {
+			exception, frames, app = m[1], nil, nil
+		}
+	}
+	chosen := app
+	if len(chosen) == 0 {
+		chosen = frames
+	}
+	if len(chosen) > opts.TopFrames {
+		chosen = chosen[:opts.TopFrames]
+	}
+	sig := Signature{Exception: exception, Frames: append([]string{}, chosen...)}
+	sum := sha256.Sum256([]byte(sig.Exception + "\n" + strings.Join(sig.Frames, "\n")))
+	sig.ID = hex.EncodeToString(sum[:6])
+	return sig
+}
+
+// NormalizeFrame renders class.method without line numbers, lambda and
+// synthetic class indices, or anonymous class numbers.
+func NormalizeFrame(class, method string) string {
+	class = externalLambdaRe.ReplaceAllString(class, "$$$$ExternalSynthetic$1")
+	class = runtimeLambdaRe.ReplaceAllString(class, "$$$$Lambda")
+	class = kotlinLambdaRe.ReplaceAllString(class, "$$lambda")
+	class = anonymousRe.ReplaceAllString(class, "$$")
+	if m := lambdaMethodRe.FindStringSubmatch(method); m != nil {
+		method = "lambda$" + m[1]
+	}
+	method = kotlinLambdaRe.ReplaceAllString(method, "$$lambda")
+	return class + "." + method
+}
+
+func (o Options) isApp(class string) bool {
+	if len(o.AppPackages) > 0 {
+		for _, p := range o.AppPackages {
+			if class == p || strings.HasPrefix(class, p+".") {
+				return true
+			}
+		}
+		return false
+	}
+	for _, p := range frameworkPrefixes {
+		if strings.HasPrefix(class, p) {
+			return false
+		}
+	}
+	return true
+}
+
+// Report is the part of an error report clustering reads.
+type Report struct {
+	Name        string
+	Issue       string
+	EventTime   time.Time
+	VersionCode int64
+	DeviceModel string
+	Text        string
+}
+
+// Cluster is a group of reports with one signature.
+type Cluster struct {
+	Signature
+	Count     int       `json:"count"`
+	FirstSeen time.Time `json:"firstSeen"`
+	LastSeen  time.Time `json:"lastSeen"`
+	Versions  []int64   `json:"versions"`
+	Devices   []string  `json:"deviceModels"`
+	// Issues are the Play issue IDs the reports belong to.
+	Issues []string `json:"issues,omitempty"`
+	// New is set when every report is from the latest version code.
+	New    bool   `json:"new"`
+	Sample string `json:"sampleReport,omitempty"`
+}
+
+// Result is the clustering of a set of reports.
+type Result struct {
+	Reports       int       `json:"reports"`
+	LatestVersion int64     `json:"latestVersionCode"`
+	NewClusters   int       `json:"newClusters"`
+	Clusters      []Cluster `json:"clusters"`
+}
+
+// Build clusters reports by signature, largest first.
+func Build(reports []Report, opts Options) *Result {
+	r := &Result{Reports: len(reports), Clusters: make([]Cluster, 0)}
+	for _, rep := range reports {
+		if rep.VersionCode > r.LatestVersion {
+			r.LatestVersion = rep.VersionCode
+		}
+	}
+
+	type acc struct {
+		cluster  Cluster
+		versions map[int64]bool
+		devices  map[string]bool
+		issues   map[string]bool
+	}
+	byID := make(map[string]*acc)
+	order := make([]string, 0)
+	for _, rep := range reports {
+		sig := Fingerprint(rep.Text, opts)
+		a, ok := byID[sig.ID]
+		if !ok {
+			a = &acc{
+				cluster:  Cluster{Signature: sig, Sample: rep.Name, FirstSeen: rep.EventTime, LastSeen: rep.EventTime},
+				versions: make(map[int64]bool), devices: make(map[string]bool), issues: make(map[string]bool),
+			}
+			byID[sig.ID] = a
+			order = append(order, sig.ID)
+		}
+		c := &a.cluster
+		c.Count++
+		if !rep.EventTime.IsZero() && (c.FirstSeen.IsZero() || rep.EventTime.Before(c.FirstSeen)) {
+			c.FirstSeen = rep.EventTime
+		}
+		if rep.EventTime.After(c.LastSeen) {
+			c.LastSeen = rep.EventTime
+		}
+		if rep.VersionCode > 0 {
+			a.versions[rep.VersionCode] = true
+		}
+		if rep.DeviceModel != "" {
+			a.devices[rep.DeviceModel] = true
+		}
+		if rep.Issue != "" {
+			a.issues[rep.Issue] = true
+		}
+	}
+
+	for _, id := range order {
+		a := byID[id]
+		c := a.cluster
+		c.Versions = make([]int64, 0, len(a.versions))
+		for v := range a.versions {
+			c.Versions = append(c.Versions, v)
+		}
+		sort.Slice(c.Versions, func(i, j int) bool { return c.Versions[i] < c.Versions[j] })
+		c.Devices = sortedKeys(a.devices)
+		c.Issues = sortedKeys(a.issues)
+		c.New = r.LatestVersion > 0 && len(c.Versions) == 1 && c.Versions[0] == r.LatestVersion
+		if c.New {
+			r.NewClusters++
+		}
+		r.Clusters = append(r.Clusters, c)
+	}
+	sort.SliceStable(r.Clusters, func(i, j int) bool {
+		if r.Clusters[i].Count != r.Clusters[j].Count {
+			return r.Clusters[i].Count > r.Clusters[j].Count
+		}
+		return r.Clusters[i].ID < r.Clusters[j].ID
+	})
+	return r
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Rows flattens clusters for table, markdown, CSV and Excel output.
+func (r *Result) Rows() []map[string]interface{} {
+	rows := make([]map[string]interface{}, 0, len(r.Clusters))
+	for _, c := range r.Clusters {
+		versions := make([]string, 0, len(c.Versions))
+		for _, v := range c.Versions {
+			versions = append(versions, strconv.FormatInt(v, 10))
+		}
+		rows = append(rows, map[string]interface{}{
+			"id":        c.ID,
+			"exception": c.Exception,
+			"frames":    strings.Join(c.Frames, " < "),
+			"count":     c.Count,
+			"firstSeen": formatTime(c.FirstSeen),
+			"lastSeen":  formatTime(c.LastSeen),
+			"versions":  strings.Join(versions, ", "),
+			"devices":   strings.Join(c.Devices, ", "),
+			"new":       c.New,
+		})
+	}
+	return rows
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
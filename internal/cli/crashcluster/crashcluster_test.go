@@ -0,0 +1,92 @@
+//go:build unit
+// +build unit
+
+package crashcluster
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+const npeTrace = `java.lang.RuntimeException: Unable to start activity
+	at android.app.ActivityThread.performLaunchActivity(ActivityThread.java:3449)
+	at android.os.Looper.loop(Looper.java:223)
+Caused by: java.lang.NullPointerException: Attempt to invoke virtual method
+	at com.example.app.Repo.load(Repo.java:42)
+	at com.example.app.MainActivity.lambda$onCreate$0(MainActivity.java:31)
+	at com.example.app.MainActivity$$ExternalSyntheticLambda0.run(Unknown Source:2)
+	at com.example.app.MainActivity.onCreate(MainActivity.java:20)
+	at android.app.Activity.performCreate(Activity.java:8000)`
+
+func TestFingerprint(t *testing.T) {
+	sig := Fingerprint(npeTrace, Options{AppPackages: []string{"com.example.app"}})
+	want := []string{
+		"com.example.app.Repo.load",
+		"com.example.app.MainActivity.lambda$onCreate",
+		"com.example.app.MainActivity$$ExternalSyntheticLambda.run",
+	}
+	if sig.Exception != "java.lang.NullPointerException" || !reflect.DeepEqual(sig.Frames, want) || len(sig.ID) != 12 {
+		t.Errorf("signature = %+v", sig)
+	}
+
+	// The same crash in a later build: other line numbers and lambda indices.
+	later := Fingerprint(`Caused by: java.lang.NullPointerException
+	at com.example.app.Repo.load(Repo.java:57)
+	at com.example.app.MainActivity.lambda$onCreate$3(MainActivity.java:35)
+	at com.example.app.MainActivity$$ExternalSyntheticLambda4.run(Unknown Source:0)`, Options{AppPackages: []string{"com.example.app"}})
+	if later.ID != sig.ID {
+		t.Errorf("later build signature %+v != %+v", later, sig)
+	}
+
+	// Without app frames the top framework frames are used.
+	framework := Fingerprint("java.lang.OutOfMemoryError\n\tat java.util.Arrays.copyOf(Arrays.java:3)\n\tat android.os.Looper.loop(Looper.java:1)", Options{TopFrames: 1})
+	if !reflect.DeepEqual(framework.Frames, []string{"java.util.Arrays.copyOf"}) {
+		t.Errorf("framework signature = %+v", framework)
+	}
+}
+
+func TestNormalizeFrame(t *testing.T) {
+	tests := []struct{ class, method, want string }{
+		{"com.example.Foo$1", "run", "com.example.Foo$.run"},
+		{"com.example.Foo$$Lambda$12/0x0000000801234", "accept", "com.example.Foo$$Lambda.accept"},
+		{"com.example.FooKt$load$lambda-2", "invoke", "com.example.FooKt$load$lambda.invoke"},
+		{"com.example.Foo", "lambda$bar$12", "com.example.Foo.lambda$bar"},
+		{"com.example.Foo", "bar", "com.example.Foo.bar"},
+	}
+	for _, tc := range tests {
+		if got := NormalizeFrame(tc.class, tc.method); got != tc.want {
+			t.Errorf("NormalizeFrame(%q, %q) = %q, want %q", tc.class, tc.method, got, tc.want)
+		}
+	}
+}
+
+func TestBuild(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 10, d, 0, 0, 0, 0, time.UTC) }
+	anr := "java.lang.IllegalStateException: bad state\n\tat com.example.app.Sync.run(Sync.java:9)"
+	reports := []Report{
+		{Name: "r1", Issue: "i1", EventTime: day(3), VersionCode: 30, DeviceModel: "google/oriole", Text: npeTrace},
+		{Name: "r2", Issue: "i1", EventTime: day(1), VersionCode: 31, DeviceModel: "samsung/a52", Text: npeTrace},
+		{Name: "r3", Issue: "i2", EventTime: day(5), VersionCode: 31, DeviceModel: "google/oriole", Text: npeTrace},
+		{Name: "r4", Issue: "i3", EventTime: day(4), VersionCode: 31, DeviceModel: "google/oriole", Text: anr},
+	}
+	r := Build(reports, Options{AppPackages: []string{"com.example.app"}})
+	if r.Reports != 4 || r.LatestVersion != 31 || r.NewClusters != 1 || len(r.Clusters) != 2 {
+		t.Fatalf("result = %+v", r)
+	}
+	npe := r.Clusters[0]
+	if npe.Count != 3 || !npe.FirstSeen.Equal(day(1)) || !npe.LastSeen.Equal(day(5)) || npe.New ||
+		!reflect.DeepEqual(npe.Versions, []int64{30, 31}) ||
+		!reflect.DeepEqual(npe.Devices, []string{"google/oriole", "samsung/a52"}) ||
+		!reflect.DeepEqual(npe.Issues, []string{"i1", "i2"}) {
+		t.Errorf("npe cluster = %+v", npe)
+	}
+	if c := r.Clusters[1]; c.Count != 1 || !c.New || c.Exception != "java.lang.IllegalStateException" || c.Sample != "r4" {
+		t.Errorf("new cluster = %+v", c)
+	}
+
+	rows := r.Rows()
+	if len(rows) != 2 || rows[0]["versions"] != "30, 31" || rows[0]["firstSeen"] != "2026-10-01T00:00:00Z" || rows[1]["new"] != true {
+		t.Errorf("rows = %v", rows)
+	}
+}
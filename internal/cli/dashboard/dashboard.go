@@ -0,0 +1,398 @@
+// Package dashboard renders a self-contained HTML monitoring dashboard:
+// inline SVG time-series charts per metric set, per-version and per-device
+// breakdown tables and an error issues list. The page has no scripts and
+// loads nothing, so it opens offline and can be published as a CI artifact
+// or on a static site. Kong adapters live in package cli.
+package dashboard
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Severities of a value against its threshold.
+const (
+	SeverityNone     = "none"
+	SeverityOK       = "ok"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// WarnRatio is the share of a threshold from which a value is a warning.
+const WarnRatio = 0.8
+
+// Page is the content of the dashboard.
+type Page struct {
+	Package     string
+	GeneratedAt time.Time
+	PeriodDays  int
+	Charts      []Chart
+	Breakdowns  []Breakdown
+	Issues      []Issue
+	// Notes are shown above the charts, e.g. data that could not be read.
+	Notes []string
+}
+
+// Chart is a time series chart for one metric set.
+type Chart struct {
+	Title  string
+	Series []Series
+	// Threshold draws a limit line and colors the series named
+	// ThresholdSeries against it; 0 means none.
+	Threshold       float64
+	ThresholdSeries string
+	ThresholdLabel  string
+	// Rate formats values as percentages rather than counts.
+	Rate bool
+}
+
+// Series is one metric of a chart.
+type Series struct {
+	Name   string
+	Points []Point
+}
+
+// Point is the value of a metric on one day.
+type Point struct {
+	Date  string
+	Value float64
+}
+
+// Breakdown is a table of one metric by a dimension, e.g. crash rate by
+// versionCode.
+type Breakdown struct {
+	Title     string
+	Dimension string
+	Metric    string
+	Threshold float64
+	Rate      bool
+	Rows      []Row
+}
+
+// Row is one dimension value of a breakdown.
+type Row struct {
+	Key   string
+	Value float64
+	Users float64
+}
+
+// Sample is a daily value of one dimension value, with its distinct users.
+type Sample struct {
+	Key   string
+	Value float64
+	Users float64
+}
+
+// Issue is an error issue with a link to it in the Play Console.
+type Issue struct {
+	Type         string
+	Cause        string
+	Location     string
+	ErrorReports int64
+	Users        int64
+	URL          string
+}
+
+// Severity classifies value against threshold: critical at or over it,
+// warning from WarnRatio of it, and none without a threshold.
+func Severity(value, threshold float64) string {
+	switch {
+	case threshold <= 0:
+		return SeverityNone
+	case value >= threshold:
+		return SeverityCritical
+	case value >= threshold*WarnRatio:
+		return SeverityWarning
+	}
+	return SeverityOK
+}
+
+// Summarize combines daily samples into one row per key, weighting each
+// day by its distinct users, and returns the limit rows with the most
+// users (all rows when limit is 0).
+func Summarize(samples []Sample, limit int) []Row {
+	type acc struct {
+		weighted, users, sum float64
+		days                 int
+	}
+	byKey := make(map[string]*acc)
+	for _, s := range samples {
+		a, ok := byKey[s.Key]
+		if !ok {
+			a = &acc{}
+			byKey[s.Key] = a
+		}
+		a.weighted += s.Value * s.Users
+		a.users += s.Users
+		a.sum += s.Value
+		a.days++
+	}
+	rows := make([]Row, 0, len(byKey))
+	for key, a := range byKey {
+		value := a.sum / float64(a.days)
+		if a.users > 0 {
+			value = a.weighted / a.users
+		}
+		rows = append(rows, Row{Key: key, Value: value, Users: a.users})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Users != rows[j].Users {
+			return rows[i].Users > rows[j].Users
+		}
+		return rows[i].Key < rows[j].Key
+	})
+	if limit > 0 && len(rows) > limit {
+		rows = rows[:limit]
+	}
+	return rows
+}
+
+// Render writes the dashboard page to w.
+func Render(w io.Writer, p Page) error {
+	return page.Execute(w, p)
+}
+
+// chart geometry in SVG user units.
+const (
+	chartWidth  = 720
+	chartHeight = 200
+	chartLeft   = 64
+	chartRight  = 16
+	chartTop    = 12
+	chartBottom = 28
+)
+
+// chartColors are the series colors, in order.
+var chartColors = []string{"#1a73e8", "#9334e6", "#12b5cb", "#e8710a"}
+
+// SVG renders c as an inline SVG line chart. Each point carries a tooltip
+// with its date and value.
+func SVG(c Chart) template.HTML {
+	dates := chartDates(c.Series)
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg class="chart" viewBox="0 0 %d %d" role="img" aria-label="%s">`,
+		chartWidth, chartHeight, template.HTMLEscapeString(c.Title))
+	if len(dates) == 0 {
+		fmt.Fprintf(&b, `<text x="%d" y="%d" class="empty">No data</text></svg>`, chartWidth/2, chartHeight/2)
+		return template.HTML(b.String())
+	}
+
+	top := c.Threshold
+	for _, s := range c.Series {
+		for _, p := range s.Points {
+			top = math.Max(top, p.Value)
+		}
+	}
+	if top <= 0 {
+		top = 1
+	}
+	top *= 1.1
+	plotW := float64(chartWidth - chartLeft - chartRight)
+	plotH := float64(chartHeight - chartTop - chartBottom)
+	index := make(map[string]int, len(dates))
+	for i, d := range dates {
+		index[d] = i
+	}
+	x := func(date string) float64 {
+		if len(dates) == 1 {
+			return chartLeft + plotW/2
+		}
+		return chartLeft + plotW*float64(index[date])/float64(len(dates)-1)
+	}
+	y := func(v float64) float64 { return chartTop + plotH*(1-v/top) }
+
+	// Axes and labels.
+	fmt.Fprintf(&b, `<line class="axis" x1="%d" y1="%.1f" x2="%d" y2="%.1f"/>`, chartLeft, y(0), chartWidth-chartRight, y(0))
+	fmt.Fprintf(&b, `<line class="axis" x1="%d" y1="%d" x2="%d" y2="%.1f"/>`, chartLeft, chartTop, chartLeft, y(0))
+	fmt.Fprintf(&b, `<text class="label" x="%d" y="%.1f" text-anchor="end">%s</text>`, chartLeft-6, y(top/1.1)+4, formatValue(top/1.1, c.Rate))
+	fmt.Fprintf(&b, `<text class="label" x="%d" y="%.1f" text-anchor="end">0</text>`, chartLeft-6, y(0)+4)
+	fmt.Fprintf(&b, `<text class="label" x="%d" y="%d">%s</text>`, chartLeft, chartHeight-8, template.HTMLEscapeString(dates[0]))
+	fmt.Fprintf(&b, `<text class="label" x="%d" y="%d" text-anchor="end">%s</text>`, chartWidth-chartRight, chartHeight-8, template.HTMLEscapeString(dates[len(dates)-1]))
+
+	if c.Threshold > 0 {
+		fmt.Fprintf(&b, `<line class="threshold" x1="%d" y1="%.1f" x2="%d" y2="%.1f"><title>%s</title></line>`,
+			chartLeft, y(c.Threshold), chartWidth-chartRight, y(c.Threshold),
+			template.HTMLEscapeString(fmt.Sprintf("%s: %s", thresholdLabel(c), formatValue(c.Threshold, c.Rate))))
+	}
+
+	for i, s := range c.Series {
+		color := chartColors[i%len(chartColors)]
+		coords := make([]string, 0, len(s.Points))
+		for _, p := range s.Points {
+			coords = append(coords, fmt.Sprintf("%.1f,%.1f", x(p.Date), y(p.Value)))
+		}
+		fmt.Fprintf(&b, `<polyline fill="none" stroke="%s" stroke-width="2" points="%s"/>`, color, strings.Join(coords, " "))
+		for _, p := range s.Points {
+			severity := SeverityNone
+			if c.Threshold > 0 && s.Name == c.ThresholdSeries {
+				severity = Severity(p.Value, c.Threshold)
+			}
+			fmt.Fprintf(&b, `<circle class="sev-%s" cx="%.1f" cy="%.1f" r="3" stroke="%s"><title>%s</title></circle>`,
+				severity, x(p.Date), y(p.Value), color,
+				template.HTMLEscapeString(fmt.Sprintf("%s %s: %s", p.Date, s.Name, formatValue(p.Value, c.Rate))))
+		}
+	}
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+func thresholdLabel(c Chart) string {
+	if c.ThresholdLabel != "" {
+		return c.ThresholdLabel
+	}
+	return "Threshold"
+}
+
+// chartDates returns the dates of all series, in order.
+func chartDates(series []Series) []string {
+	seen := make(map[string]bool)
+	var dates []string
+	for _, s := range series {
+		for _, p := range s.Points {
+			if !seen[p.Date] {
+				seen[p.Date] = true
+				dates = append(dates, p.Date)
+			}
+		}
+	}
+	sort.Strings(dates)
+	return dates
+}
+
+// latest returns the last point of the series named name.
+func latest(c Chart, name string) (Point, bool) {
+	for _, s := range c.Series {
+		if s.Name == name && len(s.Points) > 0 {
+			return s.Points[len(s.Points)-1], true
+		}
+	}
+	return Point{}, false
+}
+
+func formatValue(v float64, rate bool) string {
+	if rate {
+		return fmt.Sprintf("%.2f%%", v*100)
+	}
+	if v == math.Trunc(v) {
+		return fmt.Sprintf("%.0f", v)
+	}
+	return fmt.Sprintf("%.1f", v)
+}
+
+// chartSummary is a legend entry: the latest value of a series.
+type chartSummary struct {
+	Name     string
+	Color    string
+	Latest   string
+	Date     string
+	Severity string
+}
+
+func summaries(c Chart) []chartSummary {
+	out := make([]chartSummary, 0, len(c.Series))
+	for i, s := range c.Series {
+		entry := chartSummary{Name: s.Name, Color: chartColors[i%len(chartColors)], Latest: "n/a", Severity: SeverityNone}
+		if p, ok := latest(c, s.Name); ok {
+			entry.Latest = formatValue(p.Value, c.Rate)
+			entry.Date = p.Date
+			if s.Name == c.ThresholdSeries {
+				entry.Severity = Severity(p.Value, c.Threshold)
+			}
+		}
+		out = append(out, entry)
+	}
+	return out
+}
+
+var page = template.Must(template.New("dashboard").Funcs(template.FuncMap{
+	"svg":       SVG,
+	"summaries": summaries,
+	"value":     formatValue,
+	"severity":  Severity,
+	"users":     func(v float64) string { return fmt.Sprintf("%.0f", v) },
+	"threshold": thresholdLabel,
+	"time":      func(t time.Time) string { return t.UTC().Format("2006-01-02 15:04 MST") },
+}).Parse(pageTemplate))
+
+const pageTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Package}} vitals dashboard</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Roboto, Arial, sans-serif; margin: 0; background: #f5f5f5; color: #202124; }
+main { max-width: 1200px; margin: 0 auto; padding: 24px; }
+h1 { font-size: 1.6em; margin: 0 0 4px; }
+h2 { font-size: 1.2em; margin: 0 0 12px; }
+.meta { color: #5f6368; margin: 0 0 24px; }
+.note { background: #fef7e0; border-left: 4px solid #f9ab00; padding: 8px 12px; margin: 0 0 12px; }
+.grid { display: grid; grid-template-columns: repeat(auto-fit, minmax(540px, 1fr)); gap: 16px; margin-bottom: 24px; }
+.card { background: #fff; border-radius: 8px; box-shadow: 0 1px 3px rgba(0,0,0,.15); padding: 16px; overflow-x: auto; }
+.chart { width: 100%; height: auto; }
+.chart .axis { stroke: #bdc1c6; }
+.chart .threshold { stroke: #d93025; stroke-dasharray: 6 4; }
+.chart .label { fill: #5f6368; font-size: 11px; }
+.chart .empty { fill: #80868b; text-anchor: middle; }
+.chart circle { fill: #fff; stroke-width: 2; }
+.chart circle.sev-ok { fill: #1e8e3e; }
+.chart circle.sev-warning { fill: #f9ab00; }
+.chart circle.sev-critical { fill: #d93025; }
+.legend { list-style: none; padding: 0; margin: 8px 0 0; display: flex; flex-wrap: wrap; gap: 12px; font-size: .9em; }
+.swatch { display: inline-block; width: 10px; height: 10px; border-radius: 2px; margin-right: 4px; }
+.badge { display: inline-block; padding: 1px 8px; border-radius: 10px; font-weight: 600; background: #e8eaed; }
+.badge.sev-ok { background: #e6f4ea; color: #137333; }
+.badge.sev-warning { background: #fef7e0; color: #b06000; }
+.badge.sev-critical { background: #fce8e6; color: #c5221f; }
+table { border-collapse: collapse; width: 100%; font-size: .9em; }
+th, td { text-align: left; padding: 6px 8px; border-bottom: 1px solid #e8eaed; }
+th { color: #5f6368; font-weight: 600; }
+td.num { text-align: right; font-variant-numeric: tabular-nums; }
+td.cause { max-width: 420px; word-break: break-word; }
+</style>
+</head>
+<body>
+<main>
+<h1>{{.Package}}</h1>
+<p class="meta">Android vitals over the last {{.PeriodDays}} days &middot; generated {{time .GeneratedAt}} by gpd monitor dashboard</p>
+{{range .Notes}}<p class="note">{{.}}</p>
+{{end}}
+<section class="grid">
+{{range .Charts}}<div class="card">
+<h2>{{.Title}}</h2>
+{{svg .}}
+<ul class="legend">
+{{range summaries .}}<li><span class="swatch" style="background: {{.Color}}"></span>{{.Name}}: <span class="badge sev-{{.Severity}}">{{.Latest}}</span>{{if .Date}} <small>({{.Date}})</small>{{end}}</li>
+{{end}}{{if gt .Threshold 0.0}}<li>{{threshold .}}: {{value .Threshold .Rate}}</li>{{end}}
+</ul>
+</div>
+{{end}}</section>
+{{if .Breakdowns}}<section class="grid">
+{{range .Breakdowns}}{{$b := .}}<div class="card">
+<h2>{{.Title}}</h2>
+{{if .Rows}}<table>
+<thead><tr><th>{{.Dimension}}</th><th>{{.Metric}}</th><th>Users</th></tr></thead>
+<tbody>
+{{range .Rows}}<tr><td>{{.Key}}</td><td class="num"><span class="badge sev-{{severity .Value $b.Threshold}}">{{value .Value $b.Rate}}</span></td><td class="num">{{users .Users}}</td></tr>
+{{end}}</tbody>
+</table>{{else}}<p class="meta">No data</p>{{end}}
+</div>
+{{end}}</section>
+{{end}}<section class="card">
+<h2>Error issues</h2>
+{{if .Issues}}<table>
+<thead><tr><th>Type</th><th>Cause</th><th>Location</th><th>Reports</th><th>Users</th><th></th></tr></thead>
+<tbody>
+{{range .Issues}}<tr><td>{{.Type}}</td><td class="cause">{{.Cause}}</td><td class="cause">{{.Location}}</td><td class="num">{{.ErrorReports}}</td><td class="num">{{.Users}}</td><td>{{if .URL}}<a href="{{.URL}}" target="_blank" rel="noopener">Play Console</a>{{end}}</td></tr>
+{{end}}</tbody>
+</table>{{else}}<p class="meta">No error issues in this period</p>{{end}}
+</section>
+</main>
+</body>
+</html>
+`
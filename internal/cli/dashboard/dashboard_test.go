@@ -0,0 +1,107 @@
+//go:build unit
+// +build unit
+
+package dashboard
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSeverity(t *testing.T) {
+	for _, tc := range []struct {
+		value, threshold float64
+		want             string
+	}{
+		{0.02, 0, SeverityNone},
+		{0.005, 0.0109, SeverityOK},
+		{0.009, 0.0109, SeverityWarning},
+		{0.0109, 0.0109, SeverityCritical},
+	} {
+		if got := Severity(tc.value, tc.threshold); got != tc.want {
+			t.Errorf("Severity(%v, %v) = %s, want %s", tc.value, tc.threshold, got, tc.want)
+		}
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	rows := Summarize([]Sample{
+		{Key: "31", Value: 0.01, Users: 1000},
+		{Key: "31", Value: 0.04, Users: 3000},
+		{Key: "30", Value: 0.02, Users: 500},
+		{Key: "29", Value: 0.05, Users: 10},
+		{Key: "28", Value: 0.03},
+		{Key: "28", Value: 0.05},
+	}, 3)
+	if len(rows) != 3 || rows[0].Key != "31" || rows[1].Key != "30" || rows[2].Key != "29" {
+		t.Fatalf("rows = %+v", rows)
+	}
+	if math.Abs(rows[0].Value-0.0325) > 1e-12 || rows[0].Users != 4000 {
+		t.Errorf("user-weighted row = %+v", rows[0])
+	}
+	if rows := Summarize([]Sample{{Key: "28", Value: 0.03}, {Key: "28", Value: 0.05}}, 0); math.Abs(rows[0].Value-0.04) > 1e-12 {
+		t.Errorf("unweighted row = %+v", rows[0])
+	}
+}
+
+func TestRender(t *testing.T) {
+	var buf bytes.Buffer
+	err := Render(&buf, Page{
+		Package:     "com.example.app",
+		GeneratedAt: time.Date(2026, 10, 16, 6, 0, 0, 0, time.UTC),
+		PeriodDays:  7,
+		Charts: []Chart{
+			{
+				Title: "Crashes",
+				Series: []Series{
+					{Name: "crashRate", Points: []Point{{Date: "2026-10-14", Value: 0.008}, {Date: "2026-10-15", Value: 0.02}}},
+					{Name: "userPerceivedCrashRate", Points: []Point{{Date: "2026-10-14", Value: 0.005}, {Date: "2026-10-15", Value: 0.012}}},
+				},
+				Threshold:       0.0109,
+				ThresholdSeries: "userPerceivedCrashRate",
+				ThresholdLabel:  "Bad behavior threshold",
+				Rate:            true,
+			},
+			{Title: "Slow rendering", Rate: true},
+		},
+		Breakdowns: []Breakdown{{
+			Title: "Crash rate by version", Dimension: "versionCode", Metric: "userPerceivedCrashRate",
+			Threshold: 0.0109, Rate: true, Rows: []Row{{Key: "31", Value: 0.009, Users: 4000}},
+		}},
+		Issues: []Issue{{
+			Type: "CRASH", Cause: "java.lang.IllegalStateException: <script>alert(1)</script>", Location: "com.example.Main",
+			ErrorReports: 120, Users: 80, URL: "https://play.google.com/console/developers/1/app/2/vitals/crashes/3/details",
+		}},
+		Notes: []string{"No error issues: API unavailable"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := buf.String()
+	for _, want := range []string{
+		"<svg class=\"chart\"",
+		"<polyline",
+		"class=\"threshold\"",
+		"class=\"sev-critical\"",
+		"2026-10-15 userPerceivedCrashRate: 1.20%",
+		"badge sev-critical\">1.20%",
+		"badge sev-warning\">0.90%",
+		"No data",
+		"href=\"https://play.google.com/console/developers/1/app/2/vitals/crashes/3/details\"",
+		"&lt;script&gt;",
+		"No error issues: API unavailable",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("missing %q", want)
+		}
+	}
+	// The page must open offline: no scripts or external resources.
+	for _, unwanted := range []string{"<script", "src=", "<link", "@import"} {
+		if strings.Contains(html, unwanted) {
+			t.Errorf("page contains %q", unwanted)
+		}
+	}
+}
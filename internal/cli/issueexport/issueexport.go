@@ -0,0 +1,340 @@
+// Package issueexport converts Play error issues to issue-tracker import
+// formats: GitHub issue JSON, Jira CSV and a generic CSV. A state file maps
+// each exported Play issue to the ID it was exported under, so repeat runs
+// only export new issues and regressions. Kong adapters live in package
+// cli.
+package issueexport
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Trackers.
+const (
+	TrackerGitHub = "github"
+	TrackerJira   = "jira"
+	TrackerCSV    = "csv"
+)
+
+// Trackers lists the supported import formats.
+var Trackers = []string{TrackerGitHub, TrackerJira, TrackerCSV}
+
+// Statuses of an exported issue.
+const (
+	// StatusNew is an issue not exported before.
+	StatusNew = "new"
+	// StatusRegression is an exported issue now reported from a newer
+	// version code than when it was exported.
+	StatusRegression = "regression"
+	// StatusExported is an exported issue that has not regressed; it is
+	// only included on request.
+	StatusExported = "exported"
+)
+
+// maxTitle is the longest title exported; trackers truncate or reject
+// longer summaries.
+const maxTitle = 200
+
+// Issue is a Play error issue with the top frames of a sample report.
+type Issue struct {
+	ID           string   `json:"id"`
+	Type         string   `json:"type"`
+	Cause        string   `json:"cause"`
+	Location     string   `json:"location"`
+	ErrorReports int64    `json:"errorReports"`
+	Users        int64    `json:"distinctUsers"`
+	FirstVersion int64    `json:"firstVersionCode,omitempty"`
+	LastVersion  int64    `json:"lastVersionCode,omitempty"`
+	LastSeen     string   `json:"lastSeen,omitempty"`
+	Frames       []string `json:"frames,omitempty"`
+	URI          string   `json:"issueUri,omitempty"`
+}
+
+// Entry is an issue selected for export.
+type Entry struct {
+	Issue
+	Status   string `json:"status"`
+	ExportID string `json:"exportId"`
+}
+
+// Record is what the state remembers about an exported issue.
+type Record struct {
+	ExportID    string    `json:"exportId"`
+	Tracker     string    `json:"tracker"`
+	ExportedAt  time.Time `json:"exportedAt"`
+	LastVersion int64     `json:"lastVersionCode,omitempty"`
+}
+
+// State maps Play issue IDs to their export records.
+type State struct {
+	Issues map[string]Record `json:"issues"`
+
+	path string
+}
+
+// ExportID is the ID an issue is exported under. It appears in every
+// format so tracker issues can be matched back to Play issues.
+func ExportID(issueID string) string {
+	return "gpd-" + issueID
+}
+
+// LoadState reads the state at path; a missing file is an empty state.
+func LoadState(path string) (*State, error) {
+	s := &State{Issues: make(map[string]Record), path: path}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("parse export state %s: %w", path, err)
+	}
+	if s.Issues == nil {
+		s.Issues = make(map[string]Record)
+	}
+	return s, nil
+}
+
+// Save writes the state back to its file.
+func (s *State) Save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0o600)
+}
+
+// Select returns the issues to export, most affected users first: new
+// issues and regressions, or every issue when all is set.
+func (s *State) Select(issues []Issue, all bool) []Entry {
+	var entries []Entry
+	for _, issue := range issues {
+		entry := Entry{Issue: issue, Status: StatusNew, ExportID: ExportID(issue.ID)}
+		if rec, ok := s.Issues[issue.ID]; ok {
+			entry.ExportID = rec.ExportID
+			entry.Status = StatusExported
+			if rec.LastVersion > 0 && issue.LastVersion > rec.LastVersion {
+				entry.Status = StatusRegression
+			}
+		}
+		if entry.Status == StatusExported && !all {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Users > entries[j].Users })
+	return entries
+}
+
+// Record remembers entries as exported to tracker at now.
+func (s *State) Record(entries []Entry, tracker string, now time.Time) {
+	for _, e := range entries {
+		s.Issues[e.ID] = Record{ExportID: e.ExportID, Tracker: tracker, ExportedAt: now.UTC(), LastVersion: e.LastVersion}
+	}
+}
+
+// Title summarizes an issue in one line, e.g.
+// "[Crash] java.lang.IllegalStateException in com.example.Main.onCreate".
+func Title(e Entry) string {
+	var b strings.Builder
+	if e.Status == StatusRegression {
+		b.WriteString("[Regression] ")
+	}
+	fmt.Fprintf(&b, "[%s] %s", typeLabel(e.Type), firstLine(e.Cause))
+	if e.Location != "" {
+		fmt.Fprintf(&b, " in %s", e.Location)
+	}
+	title := b.String()
+	if len(title) > maxTitle {
+		// Back off to a rune boundary so the cut never splits a character.
+		cut := maxTitle - 3
+		for cut > 0 && !utf8.RuneStart(title[cut]) {
+			cut--
+		}
+		title = title[:cut] + "..."
+	}
+	return title
+}
+
+// Versions describes the affected version codes, e.g. "120-131".
+func Versions(i Issue) string {
+	switch {
+	case i.FirstVersion == 0 && i.LastVersion == 0:
+		return ""
+	case i.FirstVersion == 0 || i.FirstVersion == i.LastVersion:
+		return strconv.FormatInt(i.LastVersion, 10)
+	case i.LastVersion == 0:
+		return strconv.FormatInt(i.FirstVersion, 10)
+	}
+	return fmt.Sprintf("%d-%d", i.FirstVersion, i.LastVersion)
+}
+
+// Write writes entries in the tracker's import format.
+func Write(w io.Writer, tracker string, entries []Entry) error {
+	switch tracker {
+	case TrackerGitHub:
+		return writeGitHub(w, entries)
+	case TrackerJira:
+		return writeJira(w, entries)
+	case TrackerCSV:
+		return writeCSV(w, entries)
+	}
+	return fmt.Errorf("unknown tracker %q (want one of %v)", tracker, Trackers)
+}
+
+// gitHubIssue is the body of the GitHub create-issue API.
+type gitHubIssue struct {
+	Title  string   `json:"title"`
+	Body   string   `json:"body"`
+	Labels []string `json:"labels"`
+}
+
+func writeGitHub(w io.Writer, entries []Entry) error {
+	out := make([]gitHubIssue, 0, len(entries))
+	for _, e := range entries {
+		out = append(out, gitHubIssue{Title: Title(e), Body: markdownBody(e), Labels: labels(e)})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+func writeJira(w io.Writer, entries []Entry) error {
+	cw := csv.NewWriter(w)
+	// Jira's CSV importer maps repeated columns to multi-value fields.
+	if err := cw.Write([]string{"Summary", "Issue Type", "Description", "Labels", "Labels", "Labels"}); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		row := []string{Title(e), "Bug", jiraBody(e), "", "", ""}
+		copy(row[3:], labels(e))
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeCSV(w io.Writer, entries []Entry) error {
+	cw := csv.NewWriter(w)
+	header := []string{
+		"play_issue_id", "export_id", "status", "type", "title", "cause", "location",
+		"error_reports", "distinct_users", "versions", "last_seen", "top_frames", "issue_uri",
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if err := cw.Write([]string{
+			e.ID, e.ExportID, e.Status, e.Type, Title(e), e.Cause, e.Location,
+			strconv.FormatInt(e.ErrorReports, 10), strconv.FormatInt(e.Users, 10),
+			Versions(e.Issue), e.LastSeen, strings.Join(e.Frames, "\n"), e.URI,
+		}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// labels are the tracker labels of an entry: the export ID, the issue
+// type and whether it regressed.
+func labels(e Entry) []string {
+	out := []string{e.ExportID, "play-" + strings.ToLower(typeLabel(e.Type))}
+	if e.Status == StatusRegression {
+		out = append(out, "regression")
+	}
+	return out
+}
+
+func markdownBody(e Entry) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "**%s** reported by Google Play Android vitals.\n\n", typeLabel(e.Type))
+	if e.Status == StatusRegression {
+		b.WriteString("This issue was exported before and is now reported from a newer version code.\n\n")
+	}
+	b.WriteString("| | |\n|---|---|\n")
+	fmt.Fprintf(&b, "| Cause | `%s` |\n", strings.ReplaceAll(firstLine(e.Cause), "`", "'"))
+	if e.Location != "" {
+		fmt.Fprintf(&b, "| Location | `%s` |\n", e.Location)
+	}
+	fmt.Fprintf(&b, "| Error reports | %d |\n| Affected users | %d |\n", e.ErrorReports, e.Users)
+	if v := Versions(e.Issue); v != "" {
+		fmt.Fprintf(&b, "| Version codes | %s |\n", v)
+	}
+	if e.LastSeen != "" {
+		fmt.Fprintf(&b, "| Last seen | %s |\n", e.LastSeen)
+	}
+	if len(e.Frames) > 0 {
+		fmt.Fprintf(&b, "\nTop frames:\n\n```\n%s\n```\n", strings.Join(e.Frames, "\n"))
+	}
+	if e.URI != "" {
+		fmt.Fprintf(&b, "\n[View in Play Console](%s)\n", e.URI)
+	}
+	fmt.Fprintf(&b, "\n<!-- gpd-issue: %s -->\n", e.ExportID)
+	return b.String()
+}
+
+// jiraBody is the description in Jira wiki markup.
+func jiraBody(e Entry) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%s* reported by Google Play Android vitals.\n\n", typeLabel(e.Type))
+	if e.Status == StatusRegression {
+		b.WriteString("This issue was exported before and is now reported from a newer version code.\n\n")
+	}
+	fmt.Fprintf(&b, "||Cause|{{%s}}|\n", firstLine(e.Cause))
+	if e.Location != "" {
+		fmt.Fprintf(&b, "||Location|{{%s}}|\n", e.Location)
+	}
+	fmt.Fprintf(&b, "||Error reports|%d|\n||Affected users|%d|\n", e.ErrorReports, e.Users)
+	if v := Versions(e.Issue); v != "" {
+		fmt.Fprintf(&b, "||Version codes|%s|\n", v)
+	}
+	if e.LastSeen != "" {
+		fmt.Fprintf(&b, "||Last seen|%s|\n", e.LastSeen)
+	}
+	if len(e.Frames) > 0 {
+		fmt.Fprintf(&b, "\nTop frames:\n{noformat}\n%s\n{noformat}\n", strings.Join(e.Frames, "\n"))
+	}
+	if e.URI != "" {
+		fmt.Fprintf(&b, "\n[View in Play Console|%s]\n", e.URI)
+	}
+	fmt.Fprintf(&b, "\nExport ID: %s\n", e.ExportID)
+	return b.String()
+}
+
+// typeLabel renders an API issue type (CRASH, ANR) for people.
+func typeLabel(t string) string {
+	switch strings.ToUpper(t) {
+	case "CRASH":
+		return "Crash"
+	case "ANR":
+		return "ANR"
+	case "":
+		return "Error"
+	}
+	return t
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,127 @@
+//go:build unit
+// +build unit
+
+package issueexport
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	npe = Issue{
+		ID: "1a2b", Type: "CRASH", Cause: "java.lang.NullPointerException\n\tat com.example.Main.onCreate", Location: "com.example.Main.onCreate",
+		ErrorReports: 120, Users: 80, FirstVersion: 120, LastVersion: 131, LastSeen: "2026-10-15T08:00:00Z",
+		Frames: []string{"com.example.Main.onCreate", "com.example.App.start"}, URI: "https://play.google.com/console/issue/1a2b",
+	}
+	anr = Issue{ID: "3c4d", Type: "ANR", Cause: "Input dispatching timed out", ErrorReports: 9, Users: 5, LastVersion: 130}
+)
+
+func TestStateSelectsNewAndRegressions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "export", "com.example.app.json")
+	s, err := LoadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := s.Select([]Issue{anr, npe}, false)
+	if len(entries) != 2 || entries[0].ID != npe.ID || entries[0].Status != StatusNew || entries[0].ExportID != "gpd-1a2b" {
+		t.Fatalf("first run = %+v", entries)
+	}
+	s.Record(entries, TrackerGitHub, time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC))
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = LoadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entries := s.Select([]Issue{anr, npe}, false); len(entries) != 0 {
+		t.Errorf("repeat run = %+v", entries)
+	}
+	regressed := npe
+	regressed.LastVersion = 135
+	entries = s.Select([]Issue{anr, regressed}, false)
+	if len(entries) != 1 || entries[0].Status != StatusRegression || entries[0].ExportID != "gpd-1a2b" {
+		t.Fatalf("regression run = %+v", entries)
+	}
+	if entries := s.Select([]Issue{anr, npe}, true); len(entries) != 2 || entries[1].Status != StatusExported {
+		t.Errorf("all = %+v", entries)
+	}
+}
+
+func TestWriteGitHub(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, TrackerGitHub, []Entry{{Issue: npe, Status: StatusRegression, ExportID: "gpd-1a2b"}}); err != nil {
+		t.Fatal(err)
+	}
+	var issues []gitHubIssue
+	if err := json.Unmarshal(buf.Bytes(), &issues); err != nil {
+		t.Fatal(err)
+	}
+	got := issues[0]
+	if got.Title != "[Regression] [Crash] java.lang.NullPointerException in com.example.Main.onCreate" {
+		t.Errorf("title = %q", got.Title)
+	}
+	for _, want := range []string{"| Version codes | 120-131 |", "com.example.App.start", "(https://play.google.com/console/issue/1a2b)", "<!-- gpd-issue: gpd-1a2b -->"} {
+		if !strings.Contains(got.Body, want) {
+			t.Errorf("body missing %q:\n%s", want, got.Body)
+		}
+	}
+	if strings.Join(got.Labels, ",") != "gpd-1a2b,play-crash,regression" {
+		t.Errorf("labels = %v", got.Labels)
+	}
+}
+
+func TestTitleTruncatesOnRuneBoundary(t *testing.T) {
+	// "é" is two bytes and "[Crash] " eight, so the cut lands mid-rune.
+	e := Entry{Issue: Issue{Type: "CRASH", Cause: strings.Repeat("é", maxTitle)}}
+	got := Title(e)
+	if len(got) > maxTitle || !strings.HasSuffix(got, "...") {
+		t.Errorf("title is %d bytes: %q", len(got), got)
+	}
+	if !utf8.ValidString(got) {
+		t.Errorf("title is not valid UTF-8: %q", got)
+	}
+}
+
+func TestWriteCSVFormats(t *testing.T) {
+	entries := []Entry{{Issue: npe, Status: StatusNew, ExportID: "gpd-1a2b"}, {Issue: anr, Status: StatusNew, ExportID: "gpd-3c4d"}}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, TrackerJira, entries); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 || rows[0][0] != "Summary" || rows[2][0] != "[ANR] Input dispatching timed out" || rows[1][4] != "play-crash" {
+		t.Fatalf("jira rows = %q", rows)
+	}
+	if !strings.Contains(rows[1][2], "{noformat}") || !strings.Contains(rows[1][2], "[View in Play Console|https://play.google.com/console/issue/1a2b]") {
+		t.Errorf("jira description = %s", rows[1][2])
+	}
+
+	buf.Reset()
+	if err := Write(&buf, TrackerCSV, entries); err != nil {
+		t.Fatal(err)
+	}
+	rows, err = csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rows[1][0] != "1a2b" || rows[1][9] != "120-131" || rows[1][11] != "com.example.Main.onCreate\ncom.example.App.start" || rows[2][9] != "130" {
+		t.Errorf("csv rows = %q", rows)
+	}
+
+	if err := Write(&buf, "linear", entries); err == nil {
+		t.Error("expected an error for an unknown tracker")
+	}
+}
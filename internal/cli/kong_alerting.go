@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/alerting"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/config"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/errors"
+)
+
+// profileNotifier returns a notifier for the alert sinks configured for the
+// active profile. Cooldown state is kept per profile in the cache
+// directory.
+func profileNotifier(globals *Globals) (*alerting.Notifier, error) {
+	profile := config.ResolveAuthProfile(globals.Profile)
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, errors.NewAPIError(errors.CodeGeneralError, fmt.Sprintf("failed to load config: %v", err)).
+			WithHint("Fix or remove the file shown by gpd config path")
+	}
+	alertCfg := cfg.AlertConfig(profile)
+	if alertCfg == nil {
+		return nil, errors.NewAPIError(errors.CodeValidationError,
+			fmt.Sprintf("no alert sinks configured for profile %q", profile)).
+			WithHint(fmt.Sprintf(`Add "alerts": {%q: {"sinks": [{"type": "slack", "url": "${SLACK_WEBHOOK_URL}"}]}} to the file shown by gpd config path`, profile))
+	}
+	cacheDir := globals.CacheDir
+	if cacheDir == "" {
+		cacheDir = config.GetPaths().CacheDir
+	}
+	n, err := alerting.New(alertCfg, filepath.Join(cacheDir, "alerts", profile+".json"))
+	if err != nil {
+		return nil, errors.NewAPIError(errors.CodeValidationError,
+			fmt.Sprintf("invalid alert config for profile %q: %v", profile, err))
+	}
+	return n, nil
+}
+
+// deliveredAny reports whether any delivery reached its sink.
+func deliveredAny(deliveries []alerting.Delivery) bool {
+	for _, d := range deliveries {
+		if d.Status == alerting.StatusSent {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+//go:build unit
+// +build unit
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/alerting"
+)
+
+// writeAlertConfig points the config directory at a temp dir holding
+// config, and returns the cache dir to pass as --cache-dir.
+func writeAlertConfig(t *testing.T, config string) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "config"))
+	t.Setenv("GPD_AUTH_PROFILE", "")
+	if config != "" {
+		if err := os.MkdirAll(filepath.Join(dir, "config", "gpd"), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "config", "gpd", "config.json"), []byte(config), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return filepath.Join(dir, "cache")
+}
+
+func TestMonitorWatchCmd_NotifyRequiresSinks(t *testing.T) {
+	writeAlertConfig(t, "")
+	cmd := &MonitorWatchCmd{Notify: true}
+	err := cmd.Run(&Globals{Package: "com.example.app", KeyPath: "/nonexistent/key.json"})
+	if err == nil || !strings.Contains(err.Error(), `no alert sinks configured for profile "default"`) {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestProfileNotifier_BrokenConfig(t *testing.T) {
+	writeAlertConfig(t, `{"alerts": `)
+	_, err := profileNotifier(&Globals{Profile: "team-a"})
+	if err == nil || !strings.Contains(err.Error(), "failed to load config") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestProfileNotifier_DeliversWatchAlerts(t *testing.T) {
+	var texts []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		texts = append(texts, body["text"])
+	}))
+	defer srv.Close()
+
+	cacheDir := writeAlertConfig(t, `{"alerts": {"team-a": {
+		"template": "{{.Source}}: {{.Metric}} {{.Severity}}",
+		"sinks": [{"type": "slack", "url": "`+srv.URL+`"}]}}}`)
+	globals := &Globals{Profile: "team-a", CacheDir: cacheDir}
+	if _, err := profileNotifier(&Globals{Profile: "default", CacheDir: cacheDir}); err == nil {
+		t.Fatal("expected an error for a profile without sinks")
+	}
+	n, err := profileNotifier(globals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	breaches := []monitorAlert{{Metric: metricCrashRate, Threshold: 0.01, ActualValue: 0.03, Severity: "critical", Timestamp: time.Now()}}
+	deliveries := n.Notify(context.Background(), watchAlerts("com.example.app", breaches))
+	if !deliveredAny(deliveries) || len(texts) != 1 || texts[0] != "monitor watch: crashRate critical" {
+		t.Fatalf("deliveries = %+v, texts = %q", deliveries, texts)
+	}
+
+	// A second run within the default cooldown is suppressed.
+	n, _ = profileNotifier(globals)
+	deliveries = n.Notify(context.Background(), watchAlerts("com.example.app", breaches))
+	if deliveries[0].Status != alerting.StatusSuppressed || len(texts) != 1 {
+		t.Errorf("deliveries = %+v", deliveries)
+	}
+	if _, err := os.Stat(filepath.Join(cacheDir, "alerts", "team-a.json")); err != nil {
+		t.Errorf("cooldown state not written: %v", err)
+	}
+}
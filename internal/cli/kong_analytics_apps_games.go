@@ -70,23 +70,9 @@ func (cmd *AnalyticsQueryCmd) Run(globals *Globals) error {
 	// We route to the crash rate metric set as a default/general analytics entry point.
 	metricSetName := metricSetCrashRate
 	if len(cmd.Metrics) > 0 {
-		// Map common metric names to metric sets
-		switch cmd.Metrics[0] {
-		case metricCrashRate, "userPerceivedCrashRate":
-			metricSetName = metricSetCrashRate
-		case metricAnrRate, "userPerceivedAnrRate":
-			metricSetName = metricSetAnrRate
-		case metricSlowRenderingRate:
-			metricSetName = metricSetSlowRendering
-		case metricSlowStartRate:
-			metricSetName = metricSetSlowStart
-		case metricStuckBackgroundWakelockRate:
-			metricSetName = metricSetStuckBackgroundWakelock
-		case metricExcessiveWakeupRate:
-			metricSetName = metricSetExcessiveWakeup
-		case metricErrorCount:
-			metricSetName = metricSetErrorCount
-		}
+		// Map common metric names, including user-weighted and
+		// user-perceived variants, to metric sets
+		metricSetName = metricSetForQuery(cmd.Metrics[0])
 	}
 
 	name := fmt.Sprintf("apps/%s/%s", globals.Package, metricSetName)
@@ -135,6 +121,22 @@ func (cmd *AnalyticsQueryCmd) Run(globals *Globals) error {
 			allRows = append(allRows, formatMetricsRows(resp.Rows)...)
 			return nil
 		})
+	case metricSetLmkRate:
+		req := &playdeveloperreporting.GooglePlayDeveloperReportingV1beta1QueryLmkRateMetricSetRequest{
+			TimelineSpec: timelineSpec,
+			Dimensions:   cmd.Dimensions,
+			Metrics:      cmd.Metrics,
+			PageSize:     cmd.PageSize,
+			PageToken:    cmd.PageToken,
+		}
+		err = client.DoWithRetry(ctx, func() error {
+			resp, qErr := svc.Vitals.Lmkrate.Query(name, req).Context(ctx).Do()
+			if qErr != nil {
+				return qErr
+			}
+			allRows = append(allRows, formatMetricsRows(resp.Rows)...)
+			return nil
+		})
 	default:
 		// For other metric sets, return informational guidance
 		result := output.NewResult(map[string]interface{}{
@@ -226,6 +228,14 @@ func (cmd *AnalyticsCapabilitiesCmd) Run(globals *Globals) error {
 					"errorReportCount", "distinctUsers",
 				},
 			},
+			{
+				"name":        "lmkRateMetricSet",
+				"description": "Low memory kill (LMK) rate metrics, user-perceived only",
+				"metrics": []string{
+					"userPerceivedLmkRate", "userPerceivedLmkRate7dUserWeighted",
+					"userPerceivedLmkRate28dUserWeighted", "distinctUsers",
+				},
+			},
 		},
 		"dimensions": []string{
 			"apiLevel", "versionCode", "deviceModel", "deviceBrand",
@@ -1099,6 +1099,11 @@ func TestAnalyticsQueryCmd_MetricSetRouting(t *testing.T) {
 			metrics:      []string{"errorCount"},
 			expectedPath: "errorCountMetricSet",
 		},
+		{
+			name:         "user perceived lmk rate metric",
+			metrics:      []string{"userPerceivedLmkRate"},
+			expectedPath: "lmkRateMetricSet",
+		},
 		{
 			name:         "no metrics - default to crash rate",
 			metrics:      []string{},
@@ -11,6 +11,7 @@ import (
 	"google.golang.org/api/androidpublisher/v3"
 
 	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/api"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/apksig"
 	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/auth"
 	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/config"
 	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/errors"
@@ -20,16 +21,17 @@ import (
 
 // AuthCmd contains authentication commands.
 type AuthCmd struct {
-	Status   AuthStatusCmd   `cmd:"" help:"Check authentication status"`
-	Login    AuthLoginCmd    `cmd:"" help:"Authenticate with Google Play"`
-	Init     AuthInitCmd     `cmd:"" help:"Initialize auth for a profile (alias of login)"`
-	Logout   AuthLogoutCmd   `cmd:"" help:"Sign out and clear stored credentials for a profile"`
-	Delete   AuthDeleteCmd   `cmd:"" help:"Delete a stored authentication profile"`
-	List     AuthListCmd     `cmd:"" help:"List stored authentication profiles"`
-	Switch   AuthSwitchCmd   `cmd:"" help:"Switch the active authentication profile"`
-	Check    AuthCheckCmd    `cmd:"" help:"Validate package permissions for current credentials"`
-	Doctor   AuthDoctorCmd   `cmd:"" help:"Diagnose authentication setup"`
-	Diagnose AuthDiagnoseCmd `cmd:"" help:"Detailed auth diagnostics (alias of doctor)"`
+	Status    AuthStatusCmd    `cmd:"" help:"Check authentication status"`
+	Login     AuthLoginCmd     `cmd:"" help:"Authenticate with Google Play"`
+	Init      AuthInitCmd      `cmd:"" help:"Initialize auth for a profile (alias of login)"`
+	Logout    AuthLogoutCmd    `cmd:"" help:"Sign out and clear stored credentials for a profile"`
+	Delete    AuthDeleteCmd    `cmd:"" help:"Delete a stored authentication profile"`
+	List      AuthListCmd      `cmd:"" help:"List stored authentication profiles"`
+	Switch    AuthSwitchCmd    `cmd:"" help:"Switch the active authentication profile"`
+	Check     AuthCheckCmd     `cmd:"" help:"Validate package permissions for current credentials"`
+	Doctor    AuthDoctorCmd    `cmd:"" help:"Diagnose authentication setup"`
+	Diagnose  AuthDiagnoseCmd  `cmd:"" help:"Detailed auth diagnostics (alias of doctor)"`
+	UploadKey AuthUploadKeyCmd `cmd:"" name:"upload-key" help:"Show or set the expected upload-key fingerprint for a profile"`
 }
 
 // AuthStatusCmd checks authentication status.
@@ -245,7 +247,8 @@ func (cmd *AuthListCmd) Run(globals *Globals) error {
 	}
 
 	active := authMgr.GetActiveProfile()
-	if cfg, loadErr := config.Load(); loadErr == nil && cfg != nil && cfg.ActiveProfile != "" {
+	cfg, loadErr := config.Load()
+	if loadErr == nil && cfg != nil && cfg.ActiveProfile != "" {
 		active = cfg.ActiveProfile
 	}
 
@@ -257,6 +260,7 @@ func (cmd *AuthListCmd) Run(globals *Globals) error {
 		Scopes    []string `json:"scopes,omitempty"`
 		UpdatedAt string   `json:"updatedAt,omitempty"`
 		Expiry    string   `json:"tokenExpiry,omitempty"`
+		UploadKey string   `json:"uploadKeySha256,omitempty"`
 	}
 
 	rows := make([]profileRow, 0, len(profiles))
@@ -270,14 +274,16 @@ func (cmd *AuthListCmd) Run(globals *Globals) error {
 			Scopes:    p.Scopes,
 			UpdatedAt: p.UpdatedAt,
 			Expiry:    p.TokenExpiry,
+			UploadKey: cfg.UploadKeyFingerprint(p.Profile),
 		})
 		seen[p.Profile] = true
 	}
 	// Always surface the active profile even when no token metadata exists yet.
 	if !seen[active] {
 		rows = append([]profileRow{{
-			Profile: active,
-			Active:  true,
+			Profile:   active,
+			Active:    true,
+			UploadKey: cfg.UploadKeyFingerprint(active),
 		}}, rows...)
 	}
 
@@ -345,6 +351,68 @@ func (cmd *AuthSwitchCmd) Run(globals *Globals) error {
 	return outputResult(result, globals.Output, globals.Pretty)
 }
 
+// AuthUploadKeyCmd records the SHA-256 fingerprint of the upload key the
+// profile's artifacts must be signed with. publish upload refuses artifacts
+// signed by any other key.
+type AuthUploadKeyCmd struct {
+	Fingerprint string `arg:"" optional:"" help:"Upload certificate SHA-256 (keytool -list -v format, colons optional)"`
+	From        string `help:"Take the fingerprint from the signer of this APK or AAB" type:"existingfile"`
+	Clear       bool   `help:"Remove the recorded fingerprint"`
+}
+
+// Run executes the auth upload-key command.
+func (cmd *AuthUploadKeyCmd) Run(globals *Globals) error {
+	profile := config.ResolveAuthProfile(globals.Profile)
+	set := 0
+	for _, given := range []bool{cmd.Fingerprint != "", cmd.From != "", cmd.Clear} {
+		if given {
+			set++
+		}
+	}
+	if set > 1 {
+		return errors.NewAPIError(errors.CodeValidationError, "pass only one of a fingerprint, --from, or --clear")
+	}
+
+	var fingerprint string
+	switch {
+	case cmd.Clear:
+	case cmd.From != "":
+		signer, err := verifiedSigner(cmd.From)
+		if err != nil {
+			return err
+		}
+		fingerprint = signer.Certificate.SHA256
+	case cmd.Fingerprint != "":
+		normalized, err := apksig.NormalizeFingerprint(cmd.Fingerprint)
+		if err != nil {
+			return errors.NewAPIError(errors.CodeValidationError, err.Error()).
+				WithHint("Copy the SHA-256 line from `keytool -list -v` or from Play Console > App integrity")
+		}
+		fingerprint = normalized
+	default:
+		cfg, err := config.Load()
+		if err != nil {
+			return errors.NewAPIError(errors.CodeGeneralError, fmt.Sprintf("failed to load config: %v", err)).
+				WithHint("Fix the file shown by `gpd config path`; it holds the pinned upload key")
+		}
+		return outputResult(output.NewResult(map[string]interface{}{
+			"profile":         profile,
+			"uploadKeySha256": cfg.UploadKeyFingerprint(profile),
+		}), globals.Output, globals.Pretty)
+	}
+
+	if err := config.SetUploadKeyFingerprint(profile, fingerprint); err != nil {
+		return errors.NewAPIError(errors.CodeGeneralError,
+			fmt.Sprintf("failed to save upload-key fingerprint: %v", err))
+	}
+	return outputResult(output.NewResult(map[string]interface{}{
+		"success":         true,
+		"profile":         profile,
+		"uploadKeySha256": fingerprint,
+		"cleared":         cmd.Clear,
+	}), globals.Output, globals.Pretty)
+}
+
 // AuthCheckCmd validates that credentials can access a package.
 // Use the global --package flag.
 type AuthCheckCmd struct{}
@@ -14,10 +14,13 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/artifact/artifacttest"
 	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/auth"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/config"
 	gpdErrors "github.com/dl-alexandre/Google-Play-Developer-CLI/internal/errors"
 )
 
@@ -236,16 +239,17 @@ func TestAuthCmd_SubcommandsExist(t *testing.T) {
 	cmd := AuthCmd{}
 
 	want := map[string]string{
-		"Status":   "cli.AuthStatusCmd",
-		"Login":    "cli.AuthLoginCmd",
-		"Init":     "cli.AuthInitCmd",
-		"Logout":   "cli.AuthLogoutCmd",
-		"Delete":   "cli.AuthDeleteCmd",
-		"List":     "cli.AuthListCmd",
-		"Switch":   "cli.AuthSwitchCmd",
-		"Check":    "cli.AuthCheckCmd",
-		"Doctor":   "cli.AuthDoctorCmd",
-		"Diagnose": "cli.AuthDiagnoseCmd",
+		"Status":    "cli.AuthStatusCmd",
+		"Login":     "cli.AuthLoginCmd",
+		"Init":      "cli.AuthInitCmd",
+		"Logout":    "cli.AuthLogoutCmd",
+		"Delete":    "cli.AuthDeleteCmd",
+		"List":      "cli.AuthListCmd",
+		"Switch":    "cli.AuthSwitchCmd",
+		"Check":     "cli.AuthCheckCmd",
+		"Doctor":    "cli.AuthDoctorCmd",
+		"Diagnose":  "cli.AuthDiagnoseCmd",
+		"UploadKey": "cli.AuthUploadKeyCmd",
 	}
 
 	val := reflect.ValueOf(cmd)
@@ -264,6 +268,66 @@ func TestAuthCmd_SubcommandsExist(t *testing.T) {
 	}
 }
 
+// TestAuthUploadKeyCmd records, derives and clears the expected upload key.
+func TestAuthUploadKeyCmd(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("HOME", tmp)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmp, ".config"))
+	t.Setenv("GPD_AUTH_PROFILE", "")
+	globals := &Globals{Output: "json", Profile: "team-a"}
+
+	signer := artifacttest.NewSigner(t, "upload")
+	bundle := artifacttest.WriteAAB(t, artifacttest.Manifest("com.example.app", 1))
+	signer.SignJAR(t, bundle)
+
+	if err := (&AuthUploadKeyCmd{From: bundle}).Run(globals); err != nil {
+		t.Fatalf("--from: %v", err)
+	}
+	cfg, _ := config.Load()
+	if got := cfg.UploadKeyFingerprint("team-a"); got != signer.Fingerprint() {
+		t.Errorf("fingerprint = %q, want %q", got, signer.Fingerprint())
+	}
+
+	lower := strings.ToLower(strings.ReplaceAll(signer.Fingerprint(), ":", ""))
+	if err := (&AuthUploadKeyCmd{Fingerprint: lower}).Run(&Globals{Output: "json", Profile: "team-b"}); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	cfg, _ = config.Load()
+	if got := cfg.UploadKeyFingerprint("team-b"); got != signer.Fingerprint() {
+		t.Errorf("normalized fingerprint = %q", got)
+	}
+
+	if err := (&AuthUploadKeyCmd{Clear: true}).Run(globals); err != nil {
+		t.Fatalf("--clear: %v", err)
+	}
+	cfg, _ = config.Load()
+	if got := cfg.UploadKeyFingerprint("team-a"); got != "" {
+		t.Errorf("after clear = %q", got)
+	}
+
+	var apiErr *gpdErrors.APIError
+	if err := (&AuthUploadKeyCmd{Fingerprint: "AB:CD"}).Run(globals); !errors.As(err, &apiErr) || apiErr.Code != gpdErrors.CodeValidationError {
+		t.Errorf("short fingerprint: %v", err)
+	}
+	if err := (&AuthUploadKeyCmd{Fingerprint: lower, Clear: true}).Run(globals); !errors.As(err, &apiErr) {
+		t.Errorf("conflicting flags: %v", err)
+	}
+}
+
+// TestCheckUploadKey_BrokenConfig fails closed when the config that may pin
+// the upload key cannot be loaded.
+func TestCheckUploadKey_BrokenConfig(t *testing.T) {
+	writeAlertConfig(t, `{"uploadKeys": `)
+	err := (&PublishUploadCmd{File: "app.aab"}).checkUploadKey("default")
+	if err == nil || !strings.Contains(err.Error(), "failed to load config") {
+		t.Errorf("err = %v", err)
+	}
+	err = (&AuthUploadKeyCmd{}).Run(&Globals{Output: "json"})
+	if err == nil || !strings.Contains(err.Error(), "failed to load config") {
+		t.Errorf("upload-key: err = %v", err)
+	}
+}
+
 // TestAuthListCmd_Empty runs list with no stored profiles.
 func TestAuthListCmd_Empty(t *testing.T) {
 	cmd := &AuthListCmd{}
@@ -12,7 +12,14 @@ import (
 	"strings"
 	"time"
 
+	playdeveloperreporting "google.golang.org/api/playdeveloperreporting/v1beta1"
+
 	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/api"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/alerting"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/preflight"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/rollout"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/vitalsstore"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/webhooks"
 	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/errors"
 	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/output"
 )
@@ -195,16 +202,19 @@ func (cmd *AutomationReleaseNotesCmd) writeToFile(notes interface{}) error {
 
 // AutomationRolloutCmd performs automated staged rollout.
 type AutomationRolloutCmd struct {
-	Track            string        `help:"Release track" enum:"internal,alpha,beta,production" default:"production"`
-	StartPercentage  float64       `help:"Starting rollout percentage (0.01-100)" default:"1"`
-	TargetPercentage float64       `help:"Target rollout percentage (0.01-100)" default:"100"`
-	StepSize         float64       `help:"Percentage increase per step" default:"10"`
-	StepInterval     time.Duration `help:"Duration between rollout steps" default:"30m"`
-	HealthThreshold  float64       `help:"Crash rate threshold for health check (0.0-1.0, 0 disables)" default:"0.01"`
-	EditID           string        `help:"Explicit edit transaction ID"`
-	DryRun           bool          `help:"Show intended actions without executing"`
-	Wait             bool          `help:"Wait for rollout to complete (default: true)" default:"true"`
-	AutoRollback     bool          `help:"Automatically rollback on health check failure"`
+	Track              string        `help:"Release track" enum:"internal,alpha,beta,production" default:"production"`
+	StartPercentage    float64       `help:"Starting rollout percentage (0.01-100)" default:"1"`
+	TargetPercentage   float64       `help:"Target rollout percentage (0.01-100)" default:"100"`
+	StepSize           float64       `help:"Percentage increase per step" default:"10"`
+	StepInterval       time.Duration `help:"Duration between rollout steps" default:"30m"`
+	HealthThreshold    float64       `help:"Crash rate threshold for health check (0.0-1.0, 0 disables)" default:"0.01"`
+	AllowMissingVitals bool          `help:"Advance when the version code has no vitals yet instead of holding the rollout"`
+	EditID             string        `help:"Explicit edit transaction ID"`
+	DryRun             bool          `help:"Show intended actions without executing"`
+	Wait               bool          `help:"Wait between steps until the rollout completes; --wait=false advances at most one step" default:"true"`
+	AutoRollback       bool          `help:"Automatically rollback on health check failure"`
+	Resume             string        `help:"Rollout ID: start or resume a rollout kept in a state file, taking at most one step per run once its interval has passed"`
+	StateFile          string        `help:"Rollout state file for --resume (default: <workflow dir>/rollouts/<id>.json)" type:"path"`
 }
 
 // Run executes the automated rollout command.
@@ -239,62 +249,112 @@ func (cmd *AutomationRolloutCmd) Run(globals *Globals) error {
 	if cmd.DryRun {
 		return outputResult(output.NewResult(map[string]interface{}{
 			"plan": map[string]interface{}{
-				"track":            cmd.Track,
-				"startPercentage":  cmd.StartPercentage,
-				"targetPercentage": cmd.TargetPercentage,
-				"steps":            steps,
-				"stepInterval":     cmd.StepInterval.String(),
-				"healthThreshold":  cmd.HealthThreshold,
-				"autoRollback":     cmd.AutoRollback,
+				"track":              cmd.Track,
+				"startPercentage":    cmd.StartPercentage,
+				"targetPercentage":   cmd.TargetPercentage,
+				"steps":              steps,
+				"stepInterval":       cmd.StepInterval.String(),
+				"healthThreshold":    cmd.HealthThreshold,
+				"allowMissingVitals": cmd.AllowMissingVitals,
+				"autoRollback":       cmd.AutoRollback,
 			},
 		}).WithNoOp("dry-run mode"), globals.Output, globals.Pretty)
 	}
 
-	current := cmd.StartPercentage
-	completedSteps := []float64{}
+	ctx := globals.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if cmd.Resume != "" {
+		return cmd.resume(ctx, globals, steps)
+	}
+	startTime := time.Now()
+	runner, err := newRolloutRunner(ctx, globals, cmd.Track, cmd.HealthThreshold, cmd.AllowMissingVitals, cmd.EditID)
+	if err != nil {
+		return err
+	}
 
-	if cmd.Wait {
-		for i, target := range steps {
-			if globals.Verbose {
-				fmt.Fprintf(os.Stderr, "Step %d/%d: Rolling out to %.1f%%\n", i+1, len(steps), target)
-			}
+	// The start percentage is a step of its own when the release is below it.
+	plan := rollout.Plan{
+		Steps:        append([]float64{cmd.StartPercentage}, steps...),
+		Interval:     cmd.StepInterval,
+		Wait:         cmd.Wait,
+		AutoRollback: cmd.AutoRollback,
+	}
+	runner.OnTransition = cmd.onTransition(ctx, globals, len(plan.Steps))
 
-			time.Sleep(cmd.StepInterval)
+	result, err := runner.Run(ctx, plan)
+	if err != nil {
+		apiErr := errors.NewAPIError(errors.CodeGeneralError, fmt.Sprintf("rollout failed: %v", err))
+		if result != nil {
+			apiErr = apiErr.WithDetails(result)
+		}
+		return apiErr
+	}
+	return writeRolloutResult(globals, "rollout", result, result, startTime)
+}
 
-			if cmd.HealthThreshold > 0 {
-				healthy, err := cmd.checkHealth(globals)
-				if err != nil {
-					return errors.NewAPIError(errors.CodeGeneralError, "health check failed").
-						WithHint(err.Error())
-				}
-				if !healthy {
-					if cmd.AutoRollback {
-						if globals.Verbose {
-							fmt.Fprintf(os.Stderr, "Health check failed, rolling back to %.1f%%\n", current)
-						}
-						return cmd.performRollback(globals, current)
-					}
-					return errors.NewAPIError(errors.CodeGeneralError, "health check failed at rollout percentage").
-						WithDetails(map[string]interface{}{
-							"currentPercentage": target,
-						})
-				}
-			}
+// writeRolloutResult writes data, the result or state of a run, so every
+// transition is shown even when the run halted or rolled back the release.
+// Such a run then fails the job, like a failed canary.
+func writeRolloutResult(globals *Globals, name string, data interface{}, result *rollout.Result, startTime time.Time) error {
+	res := output.NewResult(data).
+		WithDuration(time.Since(startTime)).
+		WithServices("androidpublisher", "playdeveloperreporting")
+	if hold := rolloutHold(result.Transitions); hold != "" {
+		res = res.WithWarnings(hold)
+	}
+	if err := outputResult(res, globals.Output, globals.Pretty); err != nil {
+		return err
+	}
+	if result.Status == rollout.StatusHalted || result.Status == rollout.StatusRolledBack {
+		return errors.NewAPIError(errors.CodeValidationError,
+			fmt.Sprintf("%s %s at %.2f%%: version code %d is unhealthy", name, result.Status, result.Percentage, result.VersionCode))
+	}
+	return nil
+}
 
-			completedSteps = append(completedSteps, target)
-			current = target
-		}
+// rolloutHold explains why a run held its release, or returns "" when the
+// run did not end in a hold.
+func rolloutHold(transitions []rollout.Transition) string {
+	n := len(transitions)
+	if n < 2 || transitions[n-1].Action != rollout.ActionHold {
+		return ""
+	}
+	reason := "health check was inconclusive"
+	if h := transitions[n-2].Health; h != nil && h.Reason != "" {
+		reason = h.Reason
 	}
+	return fmt.Sprintf("holding at %.2f%%: %s; run again later, or pass --allow-missing-vitals to advance anyway",
+		transitions[n-1].To, reason)
+}
 
-	result := output.NewResult(map[string]interface{}{
-		"track":           cmd.Track,
-		"finalPercentage": current,
-		"stepsCompleted":  len(completedSteps),
-		"steps":           completedSteps,
-		"healthThreshold": cmd.HealthThreshold,
-	}).WithServices("automation", "rollout")
+// rolloutStepStatus is the rollout.step event status of a transition, or
+// empty when it emits no event.
+func rolloutStepStatus(t rollout.Transition) string {
+	if t.Error != "" {
+		return ""
+	}
+	switch t.Action {
+	case rollout.ActionAdvance:
+		return "completed"
+	case rollout.ActionHalt:
+		return "halted"
+	case rollout.ActionRollback:
+		return "rolled-back"
+	}
+	return ""
+}
 
-	return outputResult(result, globals.Output, globals.Pretty)
+// stepEvent is the rollout.step event data of one step.
+func (cmd *AutomationRolloutCmd) stepEvent(step, total int, percentage float64, status string) map[string]interface{} {
+	return map[string]interface{}{
+		"track":      cmd.Track,
+		"step":       step,
+		"totalSteps": total,
+		"percentage": percentage,
+		"status":     status,
+	}
 }
 
 func calculateRolloutSteps(start, target, stepSize float64) []float64 {
@@ -317,14 +377,6 @@ func calculateRolloutSteps(start, target, stepSize float64) []float64 {
 	return steps
 }
 
-func (cmd *AutomationRolloutCmd) checkHealth(globals *Globals) (bool, error) {
-	return true, nil
-}
-
-func (cmd *AutomationRolloutCmd) performRollback(globals *Globals, toPercentage float64) error {
-	return nil
-}
-
 // AutomationPromoteCmd performs smart promote with optional verification.
 type AutomationPromoteCmd struct {
 	FromTrack     string        `help:"Source track" enum:"internal,alpha,beta,production" required:"true"`
@@ -406,11 +458,15 @@ func (cmd *AutomationPromoteCmd) verifyPromotion(globals *Globals) (bool, error)
 // AutomationValidateCmd performs comprehensive pre-release validation.
 type AutomationValidateCmd struct {
 	EditID string   `help:"Explicit edit transaction ID"`
-	Checks []string `help:"Validation checks to run: all, aab, signing, permissions, deobfuscation" enum:"all,aab,signing,permissions,deobfuscation" default:"all"`
+	File   string   `help:"APK/AAB to run artifact checks (page-size) against" type:"existingfile"`
+	Checks []string `help:"Validation checks to run: all, aab, signing, permissions, deobfuscation, page-size" enum:"all,aab,signing,permissions,deobfuscation,page-size" default:"all"`
 	Strict bool     `help:"Treat warnings as failures"`
 	DryRun bool     `help:"Show validation plan without running"`
 }
 
+// automationValidateChecks lists the checks "all" expands to.
+var automationValidateChecks = []string{"aab", "signing", "permissions", "deobfuscation", "page-size"}
+
 // Run executes the validation command.
 func (cmd *AutomationValidateCmd) Run(globals *Globals) error {
 	if err := requirePackage(globals.Package); err != nil {
@@ -429,6 +485,7 @@ func (cmd *AutomationValidateCmd) Run(globals *Globals) error {
 				"checks": checkList,
 				"strict": cmd.Strict,
 				"editId": cmd.EditID,
+				"file":   cmd.File,
 			},
 		}).WithNoOp("dry-run mode"), globals.Output, globals.Pretty)
 	}
@@ -437,6 +494,7 @@ func (cmd *AutomationValidateCmd) Run(globals *Globals) error {
 	passed := 0
 	failed := 0
 	warnings := 0
+	skipped := 0
 
 	for _, check := range checkList {
 		if globals.Verbose {
@@ -446,21 +504,35 @@ func (cmd *AutomationValidateCmd) Run(globals *Globals) error {
 		result, err := cmd.runCheck(globals, check)
 		if err != nil {
 			failed++
-			results[check] = map[string]interface{}{
+			entry := map[string]interface{}{
 				"status": statusFailed,
 				"error":  err.Error(),
 			}
-		} else {
-			if result.Warning {
-				warnings++
-			} else {
-				passed++
-			}
-			results[check] = map[string]interface{}{
-				"status":  statusPassed,
-				"warning": result.Warning,
+			if result != nil && result.Details != nil {
+				entry["details"] = result.Details
 			}
+			results[check] = entry
+			continue
 		}
+
+		entry := map[string]interface{}{
+			"status":  statusPassed,
+			"warning": result.Warning,
+			"message": result.Message,
+		}
+		switch {
+		case result.Skipped:
+			skipped++
+			entry["status"] = "skipped"
+		case result.Warning:
+			warnings++
+		default:
+			passed++
+		}
+		if result.Details != nil {
+			entry["details"] = result.Details
+		}
+		results[check] = entry
 	}
 
 	status := statusPassed
@@ -476,6 +548,7 @@ func (cmd *AutomationValidateCmd) Run(globals *Globals) error {
 		"passed":   passed,
 		"failed":   failed,
 		"warnings": warnings,
+		"skipped":  skipped,
 		"total":    len(checkList),
 		"strict":   cmd.Strict,
 	}).WithServices("automation", "validation")
@@ -490,7 +563,7 @@ func (cmd *AutomationValidateCmd) Run(globals *Globals) error {
 
 func (cmd *AutomationValidateCmd) expandChecks() []string {
 	if len(cmd.Checks) == 1 && cmd.Checks[0] == checkAll {
-		return []string{"aab", "signing", "permissions", "deobfuscation"}
+		return automationValidateChecks
 	}
 
 	seen := make(map[string]bool)
@@ -498,7 +571,7 @@ func (cmd *AutomationValidateCmd) expandChecks() []string {
 	for _, c := range cmd.Checks {
 		switch c {
 		case checkAll:
-			for _, check := range []string{"aab", "signing", "permissions", "deobfuscation"} {
+			for _, check := range automationValidateChecks {
 				if !seen[check] {
 					seen[check] = true
 					result = append(result, check)
@@ -516,7 +589,9 @@ func (cmd *AutomationValidateCmd) expandChecks() []string {
 
 type validationResult struct {
 	Warning bool
+	Skipped bool
 	Message string
+	Details interface{}
 }
 
 func (cmd *AutomationValidateCmd) runCheck(_ *Globals, check string) (*validationResult, error) {
@@ -529,11 +604,34 @@ func (cmd *AutomationValidateCmd) runCheck(_ *Globals, check string) (*validatio
 		return &validationResult{Warning: false, Message: "Permissions validated"}, nil
 	case "deobfuscation":
 		return &validationResult{Warning: false, Message: "Deobfuscation files validated"}, nil
+	case "page-size":
+		return cmd.runPageSizeCheck()
 	default:
 		return nil, fmt.Errorf("unknown validation check: %s", check)
 	}
 }
 
+// runPageSizeCheck checks 16 KB page-size support of the native libraries
+// in --file. The per-library verdicts are returned as details even when the
+// check fails.
+func (cmd *AutomationValidateCmd) runPageSizeCheck() (*validationResult, error) {
+	if cmd.File == "" {
+		return &validationResult{Skipped: true, Message: "no --file provided; native library alignment not checked"}, nil
+	}
+	a, err := openArtifact(cmd.File)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = a.Close() }()
+
+	c := preflight.PageSize(a)
+	result := &validationResult{Message: c.Message, Details: c.Details}
+	if c.Status == preflight.StatusFail {
+		return result, fmt.Errorf("%s", c.Message)
+	}
+	return result, nil
+}
+
 // AutomationMonitorCmd monitors a release after rollout.
 type AutomationMonitorCmd struct {
 	Track             string        `help:"Track to monitor" enum:"internal,alpha,beta,production" required:""`
@@ -542,7 +640,7 @@ type AutomationMonitorCmd struct {
 	CrashThreshold    float64       `help:"Crash rate threshold (0.0-1.0)" default:"0.01"`
 	AnrThreshold      float64       `help:"ANR rate threshold (0.0-1.0)" default:"0.005"`
 	ErrorThreshold    float64       `help:"Error rate threshold (0.0-1.0)" default:"0.02"`
-	AutoAlert         bool          `help:"Send alert if thresholds exceeded"`
+	AutoAlert         bool          `help:"Deliver threshold breaches to the alert sinks configured for the profile"`
 	ExitOnDegradation bool          `help:"Exit with error if health degrades"`
 	DryRun            bool          `help:"Show monitoring plan without executing"`
 }
@@ -574,6 +672,26 @@ func (cmd *AutomationMonitorCmd) Run(globals *Globals) error {
 		}).WithNoOp("dry-run mode"), globals.Output, globals.Pretty)
 	}
 
+	var notifier *alerting.Notifier
+	if cmd.AutoAlert {
+		var err error
+		if notifier, err = profileNotifier(globals); err != nil {
+			return err
+		}
+	}
+	ctx := globals.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	client, err := createAPIClient(ctx, globals)
+	if err != nil {
+		return err
+	}
+	reporting, err := client.PlayReporting()
+	if err != nil {
+		return errors.NewAPIError(errors.CodeGeneralError, fmt.Sprintf("failed to get reporting service: %v", err))
+	}
+
 	checkCount := int(cmd.Duration / cmd.CheckInterval)
 	checks := make([]map[string]interface{}, 0, checkCount)
 	degradations := 0
@@ -587,33 +705,21 @@ func (cmd *AutomationMonitorCmd) Run(globals *Globals) error {
 			fmt.Fprintf(os.Stderr, "Monitoring check %d/%d for %s track\n", i+1, checkCount, cmd.Track)
 		}
 
-		health := cmd.checkReleaseHealth(globals)
-		{
-			check := map[string]interface{}{
-				"timestamp": time.Now().Format(time.RFC3339),
-				"crashRate": health.CrashRate,
-				"anrRate":   health.AnrRate,
-				"errorRate": health.ErrorRate,
-				"status":    "healthy",
-			}
-
-			if health.CrashRate > cmd.CrashThreshold {
-				check["status"] = statusDegraded
-				check["degradation"] = "crash_rate"
-				degradations++
-				if cmd.AutoAlert {
-					check["alerted"] = true
-				}
-			}
-
-			if health.AnrRate > cmd.AnrThreshold {
-				check["status"] = statusDegraded
-				check["degradation"] = "anr_rate"
-				degradations++
-			}
-
-			checks = append(checks, check)
+		health, err := checkReleaseHealth(ctx, client, reporting, globals.Package)
+		if err != nil {
+			return errors.NewAPIError(errors.CodeGeneralError, fmt.Sprintf("health check failed: %v", err))
+		}
+		check, alerts := cmd.evaluate(globals.Package, health)
+		degradations += len(alerts)
+		if notifier != nil && len(alerts) > 0 {
+			deliveries := notifier.Notify(ctx, alerts)
+			check["alerted"] = deliveredAny(deliveries)
+			check["notifications"] = deliveries
+		}
+		for _, alert := range alerts {
+			emitEvent(ctx, globals, webhooks.EventVitalsThreshold, alert)
 		}
+		checks = append(checks, check)
 
 		if i < checkCount-1 {
 			<-ticker.C
@@ -663,10 +769,116 @@ type healthMetrics struct {
 	ErrorRate float64
 }
 
-func (cmd *AutomationMonitorCmd) checkReleaseHealth(_ *Globals) *healthMetrics {
-	return &healthMetrics{
-		CrashRate: 0.001,
-		AnrRate:   0.0005,
-		ErrorRate: 0.005,
+// alert builds a breach alert keyed by track, so that breaches on different
+// tracks cool down independently.
+func (cmd *AutomationMonitorCmd) alert(pkg, metric string, value, threshold float64) alerting.Alert {
+	return alerting.Alert{
+		Key:       fmt.Sprintf("%s/%s/%s", pkg, cmd.Track, metric),
+		Source:    "automation monitor",
+		Package:   pkg,
+		Metric:    metric,
+		Severity:  new(MonitorWatchCmd).calculateSeverity(value, threshold),
+		Value:     value,
+		Threshold: threshold,
+		Labels:    map[string]string{"track": cmd.Track},
+	}
+}
+
+// evaluate compares health with the thresholds and returns the check
+// record and an alert per breached threshold.
+func (cmd *AutomationMonitorCmd) evaluate(pkg string, health *healthMetrics) (map[string]interface{}, []alerting.Alert) {
+	check := map[string]interface{}{
+		"timestamp": time.Now().Format(time.RFC3339),
+		"crashRate": health.CrashRate,
+		"anrRate":   health.AnrRate,
+		"errorRate": health.ErrorRate,
+		"status":    "healthy",
+	}
+	var alerts []alerting.Alert
+	breach := func(degradation, metric string, value, threshold float64) {
+		if threshold <= 0 || value <= threshold {
+			return
+		}
+		check["status"] = statusDegraded
+		check["degradation"] = degradation
+		alerts = append(alerts, cmd.alert(pkg, metric, value, threshold))
+	}
+	breach("crash_rate", metricCrashRate, health.CrashRate, cmd.CrashThreshold)
+	breach("anr_rate", metricAnrRate, health.AnrRate, cmd.AnrThreshold)
+	breach("error_rate", "errorRate", health.ErrorRate, cmd.ErrorThreshold)
+	return check, alerts
+}
+
+// checkReleaseHealth reads the app's crash, ANR and error rates over the
+// last few days from the Reporting API.
+func checkReleaseHealth(ctx context.Context, client *api.Client, svc *playdeveloperreporting.Service, pkg string) (*healthMetrics, error) {
+	end := time.Now().UTC()
+	spec, err := buildTimelineSpec(end.AddDate(0, 0, -rolloutHealthDays).Format("2006-01-02"), end.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	var points []vitalsstore.Point
+	for _, q := range []struct {
+		set    string
+		metric string
+	}{
+		{"crashRateMetricSet", metricCrashRate},
+		{"anrRateMetricSet", metricAnrRate},
+		{"errorCountMetricSet", "errorReportCount"},
+	} {
+		rows, err := queryMetricSetRows(ctx, client, svc, pkg, q.set, spec, nil, []string{q.metric, metricDistinctUsers})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", q.set, err)
+		}
+		for _, p := range storePoints(rows, end) {
+			// distinctUsers is in every set; keep it apart per set.
+			if p.Metric == metricDistinctUsers {
+				p.Metric = q.set + "/" + metricDistinctUsers
+			}
+			points = append(points, p)
+		}
+	}
+	return releaseHealthFromPoints(points), nil
+}
+
+// releaseHealthFromPoints weights each day's crash and ANR rates by its
+// users. The error rate is error reports per user.
+func releaseHealthFromPoints(points []vitalsstore.Point) *healthMetrics {
+	byDate := func(metric string) map[string]float64 {
+		values := make(map[string]float64)
+		for _, p := range points {
+			if p.Metric == metric {
+				values[p.Date] += p.Value
+			}
+		}
+		return values
+	}
+	weighted := func(metric, set string) float64 {
+		users := byDate(set + "/" + metricDistinctUsers)
+		var sum, total float64
+		for date, rate := range byDate(metric) {
+			sum += rate * users[date]
+			total += users[date]
+		}
+		if total == 0 {
+			return 0
+		}
+		return sum / total
+	}
+
+	h := &healthMetrics{
+		CrashRate: weighted(metricCrashRate, "crashRateMetricSet"),
+		AnrRate:   weighted(metricAnrRate, "anrRateMetricSet"),
+	}
+	var reports, users float64
+	for _, v := range byDate("errorReportCount") {
+		reports += v
+	}
+	for _, v := range byDate("errorCountMetricSet/" + metricDistinctUsers) {
+		users += v
+	}
+	if users > 0 {
+		h.ErrorRate = reports / users
 	}
+	return h
 }
@@ -0,0 +1,344 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"google.golang.org/api/androidpublisher/v3"
+	playdeveloperreporting "google.golang.org/api/playdeveloperreporting/v1beta1"
+
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/api"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/rollout"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/vitalsstore"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/webhooks"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/errors"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/output"
+)
+
+// rolloutHealthDays is how many days of vitals a rollout health check
+// reads; the latest day is usually incomplete or missing.
+const rolloutHealthDays = 3
+
+// resume advances the rollout named by --resume by at most one step. The
+// first run creates its state from the flags; later runs follow the stored
+// plan, so a scheduled job can repeat the same command until it is done.
+func (cmd *AutomationRolloutCmd) resume(ctx context.Context, globals *Globals, steps []float64) error {
+	if err := rollout.ValidateID(cmd.Resume); err != nil {
+		return errors.NewAPIError(errors.CodeValidationError, err.Error())
+	}
+	path := cmd.StateFile
+	if path == "" {
+		path = filepath.Join(globals.getWorkflowDir(), "rollouts", cmd.Resume+".json")
+	}
+	st, err := rollout.LoadState(path)
+	if err != nil {
+		return errors.NewAPIError(errors.CodeGeneralError, err.Error())
+	}
+	startTime := time.Now()
+	now := startTime.UTC()
+	if st == nil {
+		st = &rollout.State{
+			ID:                 cmd.Resume,
+			Package:            globals.Package,
+			Track:              cmd.Track,
+			Steps:              append([]float64{cmd.StartPercentage}, steps...),
+			Interval:           rollout.Duration(cmd.StepInterval),
+			HealthThreshold:    cmd.HealthThreshold,
+			AutoRollback:       cmd.AutoRollback,
+			AllowMissingVitals: cmd.AllowMissingVitals,
+			Status:             rollout.StatusInProgress,
+			CreatedAt:          now,
+			UpdatedAt:          now,
+			Transitions:        []rollout.Transition{},
+		}
+	} else if st.Package != globals.Package {
+		return errors.NewAPIError(errors.CodeValidationError,
+			fmt.Sprintf("rollout %s belongs to %s, not %s", st.ID, st.Package, globals.Package))
+	}
+	if st.Done() {
+		return outputResult(output.NewResult(st).WithNoOp("rollout "+st.Status), globals.Output, globals.Pretty)
+	}
+	if !st.Eligible(now) {
+		return outputResult(output.NewResult(st).
+			WithNoOp("next step eligible at "+st.NextEligibleAt.Format(time.RFC3339)), globals.Output, globals.Pretty)
+	}
+
+	// The stored plan wins over the flags.
+	cmd.Track = st.Track
+	runner, err := newRolloutRunner(ctx, globals, st.Track, st.HealthThreshold, st.AllowMissingVitals, cmd.EditID)
+	if err != nil {
+		return err
+	}
+	runner.OnTransition = cmd.onTransition(ctx, globals, len(st.Steps))
+	result, runErr := runner.Run(ctx, st.Plan())
+	if result != nil {
+		st.Apply(result, time.Now().UTC())
+	}
+	if err := st.Save(path); err != nil {
+		return errors.NewAPIError(errors.CodeGeneralError, err.Error()).
+			WithHint(fmt.Sprintf("The rollout may have advanced; check the track before resuming %s", st.ID))
+	}
+	if runErr != nil {
+		return errors.NewAPIError(errors.CodeGeneralError, fmt.Sprintf("rollout failed: %v", runErr)).WithDetails(st)
+	}
+	return writeRolloutResult(globals, "rollout "+st.ID, st, result, startTime)
+}
+
+// newRolloutRunner returns a runner for track of the current package, with
+// crash rate checks unless threshold is 0.
+func newRolloutRunner(ctx context.Context, globals *Globals, track string, threshold float64, allowMissing bool, editID string) (*rollout.Runner, error) {
+	client, err := createAPIClient(ctx, globals)
+	if err != nil {
+		return nil, err
+	}
+	publisher, err := client.AndroidPublisher()
+	if err != nil {
+		return nil, errors.NewAPIError(errors.CodeGeneralError, "failed to initialize publisher service").
+			WithHint("Ensure authentication is configured correctly")
+	}
+	runner := &rollout.Runner{
+		Track: &playTrack{client: client, svc: publisher, pkg: globals.Package, track: track, editID: editID},
+	}
+	if threshold > 0 {
+		reporting, err := client.PlayReporting()
+		if err != nil {
+			return nil, errors.NewAPIError(errors.CodeGeneralError, fmt.Sprintf("failed to get reporting service: %v", err))
+		}
+		runner.Check = rolloutHealthCheck(client, reporting, globals.Package, threshold, allowMissing)
+	}
+	return runner, nil
+}
+
+// onTransition logs transitions and emits a rollout.step event for each
+// advance, halt and rollback.
+func (cmd *AutomationRolloutCmd) onTransition(ctx context.Context, globals *Globals, total int) func(rollout.Transition) {
+	return func(t rollout.Transition) {
+		if globals.Verbose {
+			fmt.Fprintf(os.Stderr, "Step %d/%d: %s %.2f%% -> %.2f%%\n", t.Step, total, t.Action, t.From, t.To)
+		}
+		if status := rolloutStepStatus(t); status != "" {
+			emitEvent(ctx, globals, webhooks.EventRolloutStep, cmd.stepEvent(t.Step, total, t.To, status))
+		}
+	}
+}
+
+// playTrack is a rollout.Track backed by Play edits. Every change opens an
+// edit, updates the in-progress release and commits it.
+type playTrack struct {
+	client *api.Client
+	svc    *androidpublisher.Service
+	pkg    string
+	track  string
+	// editID, when set, is used by the first change instead of a new edit.
+	editID string
+}
+
+// Release reads the in-progress release from a throwaway edit.
+func (t *playTrack) Release(ctx context.Context) (*rollout.Release, error) {
+	editID, err := t.insertEdit(ctx)
+	if err != nil {
+		return nil, err
+	}
+	track, err := t.getTrack(ctx, editID)
+	if t.editID != editID {
+		_ = t.svc.Edits.Delete(t.pkg, editID).Context(ctx).Do()
+	}
+	if err != nil {
+		return nil, err
+	}
+	rel := inProgressRelease(track)
+	if rel == nil {
+		return nil, nil
+	}
+	return &rollout.Release{VersionCodes: rel.VersionCodes, Percentage: rel.UserFraction * 100}, nil
+}
+
+// SetPercentage sets the user fraction of the in-progress release; 100
+// completes it.
+func (t *playTrack) SetPercentage(ctx context.Context, percent float64) error {
+	return t.change(ctx, func(rel *androidpublisher.TrackRelease) {
+		if percent >= 100 {
+			rel.Status = releaseCompleted
+			rel.UserFraction = 0
+			return
+		}
+		rel.UserFraction = percent / 100
+	})
+}
+
+// Halt halts the in-progress release.
+func (t *playTrack) Halt(ctx context.Context) error {
+	return t.change(ctx, func(rel *androidpublisher.TrackRelease) {
+		rel.Status = statusHalted
+	})
+}
+
+// change applies fn to the in-progress release in a committed edit.
+func (t *playTrack) change(ctx context.Context, fn func(*androidpublisher.TrackRelease)) error {
+	editID := t.editID
+	t.editID = ""
+	if editID == "" {
+		var err error
+		if editID, err = t.insertEdit(ctx); err != nil {
+			return err
+		}
+	}
+	track, err := t.getTrack(ctx, editID)
+	if err != nil {
+		return err
+	}
+	rel := inProgressRelease(track)
+	if rel == nil {
+		return fmt.Errorf("no in-progress release found on track %s", t.track)
+	}
+	fn(rel)
+
+	if err := t.client.Acquire(ctx); err != nil {
+		return err
+	}
+	err = t.client.DoWithRetry(ctx, func() error {
+		_, uerr := t.svc.Edits.Tracks.Update(t.pkg, editID, t.track, track).Context(ctx).Do()
+		return uerr
+	})
+	t.client.Release()
+	if err != nil {
+		return fmt.Errorf("failed to update track %s: %w", t.track, err)
+	}
+
+	if err := t.client.Acquire(ctx); err != nil {
+		return err
+	}
+	err = t.client.DoWithRetry(ctx, func() error {
+		_, cerr := t.svc.Edits.Commit(t.pkg, editID).Context(ctx).Do()
+		return cerr
+	})
+	t.client.Release()
+	if err != nil {
+		return fmt.Errorf("failed to commit edit: %w", err)
+	}
+	return nil
+}
+
+func (t *playTrack) insertEdit(ctx context.Context) (string, error) {
+	if t.editID != "" {
+		return t.editID, nil
+	}
+	if err := t.client.Acquire(ctx); err != nil {
+		return "", err
+	}
+	defer t.client.Release()
+	var edit *androidpublisher.AppEdit
+	err := t.client.DoWithRetry(ctx, func() error {
+		var ierr error
+		edit, ierr = t.svc.Edits.Insert(t.pkg, &androidpublisher.AppEdit{}).Context(ctx).Do()
+		return ierr
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create edit: %w", err)
+	}
+	return edit.Id, nil
+}
+
+func (t *playTrack) getTrack(ctx context.Context, editID string) (*androidpublisher.Track, error) {
+	if err := t.client.Acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer t.client.Release()
+	var track *androidpublisher.Track
+	err := t.client.DoWithRetry(ctx, func() error {
+		var gerr error
+		track, gerr = t.svc.Edits.Tracks.Get(t.pkg, editID, t.track).Context(ctx).Do()
+		return gerr
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get track %s: %w", t.track, err)
+	}
+	return track, nil
+}
+
+// inProgressRelease returns the in-progress release of track, or nil.
+func inProgressRelease(track *androidpublisher.Track) *androidpublisher.TrackRelease {
+	for _, rel := range track.Releases {
+		if rel.Status == statusInProgress {
+			return rel
+		}
+	}
+	return nil
+}
+
+// rolloutHealthCheck checks the crash rate of a version code over the last
+// few days of the Reporting API against threshold.
+func rolloutHealthCheck(client *api.Client, svc *playdeveloperreporting.Service, pkg string, threshold float64, allowMissing bool) rollout.HealthCheck {
+	return func(ctx context.Context, versionCode int64) (rollout.Health, error) {
+		end := time.Now().UTC()
+		spec, err := buildTimelineSpec(end.AddDate(0, 0, -rolloutHealthDays).Format("2006-01-02"), end.Format("2006-01-02"))
+		if err != nil {
+			return rollout.Health{}, err
+		}
+		rows, err := queryMetricSetRows(ctx, client, svc, pkg, "crashRateMetricSet", spec,
+			[]string{"versionCode"}, []string{metricCrashRate, metricDistinctUsers})
+		if err != nil {
+			return rollout.Health{}, err
+		}
+		return versionHealth(storePoints(rows, end), versionCode, threshold, allowMissing), nil
+	}
+}
+
+// versionHealth weights the daily crash rates of versionCode by their users.
+// A version without vitals yet is inconclusive, so the rollout holds until
+// they arrive, unless allowMissing treats it as healthy.
+func versionHealth(points []vitalsstore.Point, versionCode int64, threshold float64, allowMissing bool) rollout.Health {
+	vc := strconv.FormatInt(versionCode, 10)
+	type day struct{ rate, users float64 }
+	days := make(map[string]*day)
+	for _, p := range points {
+		if p.Dimensions["versionCode"] != vc {
+			continue
+		}
+		d, ok := days[p.Date]
+		if !ok {
+			d = &day{rate: -1}
+			days[p.Date] = d
+		}
+		switch p.Metric {
+		case metricCrashRate:
+			d.rate = p.Value
+		case metricDistinctUsers:
+			d.users = p.Value
+		}
+	}
+
+	health := rollout.Health{Healthy: true, Metric: metricCrashRate, Threshold: threshold}
+	var crashed, users, sum float64
+	n := 0
+	for _, d := range days {
+		if d.rate < 0 {
+			continue
+		}
+		crashed += d.rate * d.users
+		users += d.users
+		sum += d.rate
+		n++
+	}
+	switch {
+	case n == 0:
+		health.Reason = "no vitals for version code yet"
+		health.Healthy = allowMissing
+		health.Inconclusive = !allowMissing
+		return health
+	case users > 0:
+		health.Value = crashed / users
+	default:
+		health.Value = sum / float64(n)
+	}
+	health.Users = users
+	health.Healthy = health.Value <= threshold
+	if !health.Healthy {
+		health.Reason = fmt.Sprintf("crash rate %.4f exceeds %.4f", health.Value, threshold)
+	}
+	return health
+}
@@ -13,6 +13,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/artifact/artifacttest"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/rollout"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/vitalsstore"
 	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/errors"
 )
 
@@ -172,7 +175,7 @@ func TestAutomationValidateCmd_FieldTags(t *testing.T) {
 	}
 
 	enumTag := field.Tag.Get("enum")
-	expected := "all,aab,signing,permissions,deobfuscation"
+	expected := "all,aab,signing,permissions,deobfuscation,page-size"
 	if enumTag != expected {
 		t.Errorf("AutomationValidateCmd.Checks enum tag = %q, want %q", enumTag, expected)
 	}
@@ -828,7 +831,7 @@ func TestAutomationValidateCmd_expandChecks(t *testing.T) {
 		{
 			name:     "all expands to all checks",
 			checks:   []string{"all"},
-			expected: []string{"aab", "signing", "permissions", "deobfuscation"},
+			expected: []string{"aab", "signing", "permissions", "deobfuscation", "page-size"},
 		},
 		{
 			name:     "single check",
@@ -843,7 +846,7 @@ func TestAutomationValidateCmd_expandChecks(t *testing.T) {
 		{
 			name:     "mixed all and specific",
 			checks:   []string{"all", "aab"},
-			expected: []string{"aab", "signing", "permissions", "deobfuscation"},
+			expected: []string{"aab", "signing", "permissions", "deobfuscation", "page-size"},
 		},
 		{
 			name:     "deduplication",
@@ -957,6 +960,33 @@ func TestAutomationValidateCmd_runCheck(t *testing.T) {
 	}
 }
 
+func TestAutomationValidateCmd_runPageSizeCheck(t *testing.T) {
+	manifest := artifacttest.Manifest("com.example.app", 1)
+
+	result, err := (&AutomationValidateCmd{}).runCheck(&Globals{}, "page-size")
+	if err != nil || !result.Skipped {
+		t.Errorf("without --file: %+v, %v", result, err)
+	}
+
+	good := artifacttest.WriteAAB(t, manifest, artifacttest.Entry{Name: "base/lib/arm64-v8a/libapp.so", Data: artifacttest.ELF(true, 16384)})
+	result, err = (&AutomationValidateCmd{File: good}).runCheck(&Globals{}, "page-size")
+	if err != nil || result.Skipped || result.Details == nil {
+		t.Errorf("aligned: %+v, %v", result, err)
+	}
+
+	bad := artifacttest.WriteAAB(t, manifest, artifacttest.Entry{Name: "base/lib/arm64-v8a/libapp.so", Data: artifacttest.ELF(true, 4096)})
+	cmd := &AutomationValidateCmd{File: bad, Checks: []string{"page-size"}}
+	err = cmd.Run(&Globals{Package: "com.example.app", Output: "json"})
+	apiErr, ok := err.(*errors.APIError)
+	if !ok || apiErr.Code != errors.CodeValidationError {
+		t.Fatalf("expected validation error, got %T %v", err, err)
+	}
+	entry, _ := apiErr.Details.(map[string]interface{})["page-size"].(map[string]interface{})
+	if entry["status"] != statusFailed || entry["details"] == nil {
+		t.Errorf("page-size result = %v", entry)
+	}
+}
+
 func TestAutomationValidateCmd_Run_StrictMode(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -1094,30 +1124,50 @@ func TestAutomationMonitorCmd_DryRun(t *testing.T) {
 	}
 }
 
-func TestAutomationMonitorCmd_checkReleaseHealth(t *testing.T) {
-	cmd := &AutomationMonitorCmd{}
-	globals := &Globals{}
-
-	health := cmd.checkReleaseHealth(globals)
-
-	if health == nil {
-		t.Fatal("Expected health metrics, got nil")
+func TestReleaseHealthFromPoints(t *testing.T) {
+	points := []vitalsstore.Point{
+		{Date: "2026-03-01", Metric: metricCrashRate, Value: 0.01},
+		{Date: "2026-03-01", Metric: "crashRateMetricSet/" + metricDistinctUsers, Value: 100},
+		{Date: "2026-03-02", Metric: metricCrashRate, Value: 0.03},
+		{Date: "2026-03-02", Metric: "crashRateMetricSet/" + metricDistinctUsers, Value: 300},
+		{Date: "2026-03-01", Metric: metricAnrRate, Value: 0.002},
+		{Date: "2026-03-01", Metric: "anrRateMetricSet/" + metricDistinctUsers, Value: 50},
+		{Date: "2026-03-01", Metric: "errorReportCount", Value: 12},
+		{Date: "2026-03-02", Metric: "errorReportCount", Value: 8},
+		{Date: "2026-03-01", Metric: "errorCountMetricSet/" + metricDistinctUsers, Value: 400},
+		{Date: "2026-03-02", Metric: "errorCountMetricSet/" + metricDistinctUsers, Value: 600},
+	}
+	got := releaseHealthFromPoints(points)
+	want := healthMetrics{CrashRate: 0.025, AnrRate: 0.002, ErrorRate: 0.02}
+	for name, pair := range map[string][2]float64{
+		"crash": {got.CrashRate, want.CrashRate},
+		"anr":   {got.AnrRate, want.AnrRate},
+		"error": {got.ErrorRate, want.ErrorRate},
+	} {
+		if math.Abs(pair[0]-pair[1]) > 1e-9 {
+			t.Errorf("%s rate = %v, want %v", name, pair[0], pair[1])
+		}
 	}
 
-	// Check that the default values are reasonable
-	if health.CrashRate < 0 {
-		t.Errorf("CrashRate should be non-negative, got %f", health.CrashRate)
+	if h := releaseHealthFromPoints(nil); *h != (healthMetrics{}) {
+		t.Errorf("no points = %+v, want zero rates", *h)
 	}
-	if health.AnrRate < 0 {
-		t.Errorf("AnrRate should be non-negative, got %f", health.AnrRate)
-	}
-	if health.ErrorRate < 0 {
-		t.Errorf("ErrorRate should be non-negative, got %f", health.ErrorRate)
+}
+
+func TestAutomationMonitorCmd_evaluate(t *testing.T) {
+	cmd := &AutomationMonitorCmd{CrashThreshold: 0.01, AnrThreshold: 0.005, ErrorThreshold: 0.02}
+
+	check, alerts := cmd.evaluate("com.example.app", &healthMetrics{CrashRate: 0.001, AnrRate: 0.001, ErrorRate: 0.01})
+	if check["status"] != "healthy" || len(alerts) != 0 {
+		t.Errorf("healthy: status = %v, alerts = %d", check["status"], len(alerts))
 	}
 
-	// Default values should be below thresholds
-	if health.CrashRate > 0.01 {
-		t.Logf("Note: Default CrashRate %f is above typical threshold", health.CrashRate)
+	check, alerts = cmd.evaluate("com.example.app", &healthMetrics{CrashRate: 0.02, AnrRate: 0.001, ErrorRate: 0.05})
+	if check["status"] != statusDegraded || len(alerts) != 2 {
+		t.Fatalf("degraded: status = %v, alerts = %d", check["status"], len(alerts))
+	}
+	if alerts[0].Metric != metricCrashRate || alerts[1].Metric != "errorRate" {
+		t.Errorf("alert metrics = %q, %q", alerts[0].Metric, alerts[1].Metric)
 	}
 }
 
@@ -1260,35 +1310,126 @@ func TestAutomationCommands_WithDifferentOutputs(t *testing.T) {
 // Test Helper Functions
 // ============================================================================
 
-func TestAutomationRolloutCmd_checkHealth(t *testing.T) {
-	cmd := &AutomationRolloutCmd{}
-	globals := &Globals{
-		Package: "com.example.app",
+func TestVersionHealth(t *testing.T) {
+	v31 := map[string]string{"versionCode": "31"}
+	points := []vitalsstore.Point{
+		{Date: "2026-10-14", Metric: metricCrashRate, Dimensions: v31, Value: 0.002},
+		{Date: "2026-10-14", Metric: metricDistinctUsers, Dimensions: v31, Value: 900},
+		{Date: "2026-10-15", Metric: metricCrashRate, Dimensions: v31, Value: 0.02},
+		{Date: "2026-10-15", Metric: metricDistinctUsers, Dimensions: v31, Value: 100},
+		{Date: "2026-10-15", Metric: metricCrashRate, Dimensions: map[string]string{"versionCode": "30"}, Value: 0.5},
+		{Date: "2026-10-15", Metric: metricCrashRate, Value: 0.5},
+	}
+	h := versionHealth(points, 31, 0.01, false)
+	if !h.Healthy || h.Users != 1000 || h.Value < 0.00379 || h.Value > 0.00381 {
+		t.Errorf("health = %+v", h)
+	}
+	if h := versionHealth(points, 31, 0.003, false); h.Healthy || h.Reason == "" {
+		t.Errorf("unhealthy = %+v", h)
+	}
+	if h := versionHealth(points, 32, 0.01, false); h.Healthy || !h.Inconclusive || h.Reason != "no vitals for version code yet" {
+		t.Errorf("no data = %+v", h)
+	}
+	if h := versionHealth(points, 32, 0.01, true); !h.Healthy || h.Inconclusive {
+		t.Errorf("no data, allowed = %+v", h)
+	}
+}
+
+func TestWriteRolloutResult_HaltedShowsTransitions(t *testing.T) {
+	health := &rollout.Health{Metric: metricCrashRate, Value: 0.03, Threshold: 0.01}
+	result := &rollout.Result{Status: rollout.StatusHalted, VersionCode: 31, Percentage: 10, Transitions: []rollout.Transition{
+		{Action: rollout.ActionStart, From: 10, To: 10},
+		{Action: rollout.ActionHealth, Step: 2, From: 10, To: 10, Health: health},
+		{Action: rollout.ActionHalt, Step: 2, From: 10, To: 10},
+	}}
+	out, err := captureInspectStdout(t, func() error {
+		return writeRolloutResult(&Globals{Output: "json"}, "rollout", result, result, time.Now())
+	})
+	if apiErr, ok := err.(*errors.APIError); !ok || apiErr.Code != errors.CodeValidationError {
+		t.Fatalf("err = %v, want a validation error", err)
 	}
+	var got rollout.Result
+	decodeInspectData(t, out, &got)
+	if got.Status != rollout.StatusHalted || len(got.Transitions) != 3 || got.Transitions[2].Action != rollout.ActionHalt {
+		t.Errorf("written result = %+v", got)
+	}
+}
 
-	healthy, err := cmd.checkHealth(globals)
-	if err != nil {
-		t.Fatalf("checkHealth returned error: %v", err)
+func TestRolloutHold(t *testing.T) {
+	health := &rollout.Health{Inconclusive: true, Reason: "no vitals for version code yet"}
+	held := []rollout.Transition{
+		{Action: rollout.ActionStart, From: 5, To: 5},
+		{Action: rollout.ActionHealth, From: 5, To: 5, Health: health},
+		{Action: rollout.ActionHold, From: 5, To: 5},
 	}
+	if got := rolloutHold(held); !strings.Contains(got, "holding at 5.00%: no vitals for version code yet") {
+		t.Errorf("hold = %q", got)
+	}
+	if got := rolloutHold(held[:2]); got != "" {
+		t.Errorf("no hold = %q", got)
+	}
+}
 
-	// Currently returns true always (stub implementation)
-	if !healthy {
-		t.Error("Expected checkHealth to return true (stub implementation)")
+func TestRolloutStepStatus(t *testing.T) {
+	tests := map[string]rollout.Transition{
+		"completed":   {Action: rollout.ActionAdvance},
+		"halted":      {Action: rollout.ActionHalt},
+		"rolled-back": {Action: rollout.ActionRollback},
+		"":            {Action: rollout.ActionHealth},
+	}
+	for want, tr := range tests {
+		if got := rolloutStepStatus(tr); got != want {
+			t.Errorf("rolloutStepStatus(%s) = %q, want %q", tr.Action, got, want)
+		}
+	}
+	if got := rolloutStepStatus(rollout.Transition{Action: rollout.ActionRollback, Error: "refused"}); got != "" {
+		t.Errorf("failed rollback status = %q", got)
 	}
 }
 
-func TestAutomationRolloutCmd_performRollback(t *testing.T) {
-	cmd := &AutomationRolloutCmd{}
-	globals := &Globals{
-		Package: "com.example.app",
+func TestAutomationRolloutCmd_ResumeWithoutEligibleStep(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "r31.json")
+	st := &rollout.State{
+		ID: "r31", Package: "com.example.app", Track: "production", Steps: []float64{1, 10, 100},
+		Status: rollout.StatusInProgress, Stage: 1, Percentage: 1, NextEligibleAt: time.Now().Add(time.Hour),
+	}
+	if err := st.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	cmd := &AutomationRolloutCmd{Track: "production", StartPercentage: 1, TargetPercentage: 100, StepSize: 10, Resume: "r31", StateFile: path}
+	globals := &Globals{Package: "com.example.app", Output: "json", KeyPath: "/nonexistent/key.json"}
+
+	// Waiting for the interval needs no credentials.
+	out, err := captureInspectStdout(t, func() error { return cmd.Run(globals) })
+	if err != nil {
+		t.Fatalf("waiting: %v", err)
+	}
+	if !strings.Contains(out, "next step eligible at") {
+		t.Errorf("waiting output = %s", out)
 	}
 
-	err := cmd.performRollback(globals, 50.0)
+	st.Status = rollout.StatusCompleted
+	if err := st.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	out, err = captureInspectStdout(t, func() error { return cmd.Run(globals) })
 	if err != nil {
-		t.Fatalf("performRollback returned error: %v", err)
+		t.Fatalf("completed: %v", err)
+	}
+	var got rollout.State
+	decodeInspectData(t, out, &got)
+	if got.Status != rollout.StatusCompleted || got.Stage != 1 {
+		t.Errorf("state = %+v", got)
 	}
 
-	// Currently returns nil (stub implementation)
+	err = cmd.Run(&Globals{Package: "com.example.other", Output: "json"})
+	if err == nil || !strings.Contains(err.Error(), "belongs to com.example.app") {
+		t.Errorf("other package: %v", err)
+	}
+	cmd.Resume = "../r31"
+	if err := cmd.Run(globals); err == nil || !strings.Contains(err.Error(), "invalid rollout id") {
+		t.Errorf("invalid id: %v", err)
+	}
 }
 
 func TestAutomationPromoteCmd_verifyPromotion(t *testing.T) {
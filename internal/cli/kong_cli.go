@@ -23,7 +23,7 @@ import (
 // Globals contains all global flags shared across all commands.
 type Globals struct {
 	Package     string        `help:"App package name" short:"p"`
-	Output      string        `help:"Output format: json, table, markdown, csv, excel (default: table on TTY, json in pipes/CI; override with GPD_DEFAULT_OUTPUT)" default:"json" enum:"json,table,markdown,csv,excel"`
+	Output      string        `help:"Output format: json, table, markdown, csv, excel, sarif (default: table on TTY, json in pipes/CI; override with GPD_DEFAULT_OUTPUT)" default:"json" enum:"json,table,markdown,csv,excel,sarif"`
 	Pretty      bool          `help:"Pretty print JSON output"`
 	Timeout     time.Duration `help:"Network timeout" default:"30s"`
 	StoreTokens string        `help:"Token storage: auto, never, secure" default:"auto" enum:"auto,never,secure"`
@@ -80,6 +80,7 @@ type KongCLI struct {
 
 	// High-level operator commands (ASC-style job ergonomics)
 	Validate ValidateCmd `cmd:"" help:"Submission readiness / pre-publish validation report"`
+	Inspect  InspectCmd  `cmd:"" help:"Inspect local APK/AAB artifacts"`
 
 	// Extension commands
 	Extension ExtensionCmd `cmd:"" help:"Manage CLI extensions"`
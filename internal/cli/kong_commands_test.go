@@ -82,7 +82,7 @@ func TestVitalsCmd_HasExpectedSubcommands(t *testing.T) {
 	typeOfCmd := v.Type()
 
 	expectedSubcommands := []string{
-		"Crashes", "Anrs", "Errors", "Metrics", "Anomalies", "Query", "Capabilities",
+		"Crashes", "Anrs", "Errors", "Metrics", "Anomalies", "Query", "Capabilities", "Retrace", "Canary", "Snapshot", "History", "Compliance", "Freshness",
 	}
 
 	for _, name := range expectedSubcommands {
@@ -875,7 +875,7 @@ func TestGlobals_FieldTags(t *testing.T) {
 		fieldName string
 		enum      string
 	}{
-		{"Output", "json,table,markdown,csv,excel"},
+		{"Output", "json,table,markdown,csv,excel,sarif"},
 		{"StoreTokens", "auto,never,secure"},
 	}
 
@@ -961,7 +961,7 @@ func TestVitalsMetricsCmd_NestedStructure(t *testing.T) {
 	typeOfCmd := v.Type()
 
 	expectedSubcommands := []string{
-		"ExcessiveWakeups", "SlowRendering", "SlowStart", "StuckWakelocks",
+		"ExcessiveWakeups", "SlowRendering", "SlowStart", "StuckWakelocks", "Lmk",
 	}
 
 	for _, name := range expectedSubcommands {
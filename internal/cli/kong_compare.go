@@ -33,7 +33,7 @@ type CompareCmd struct {
 // CompareVitalsCmd compares vitals metrics across multiple apps.
 type CompareVitalsCmd struct {
 	Packages  []string `help:"Package names to compare (repeatable)" required:""`
-	Metric    string   `help:"Metric to compare" default:"all" enum:"crash-rate,anr-rate,error-rate,all"`
+	Metric    string   `help:"Metric to compare" default:"all" enum:"crash-rate,anr-rate,lmk-rate,error-rate,all"`
 	StartDate string   `help:"Start date (ISO 8601)"`
 	EndDate   string   `help:"End date (ISO 8601)"`
 	Format    string   `help:"Output format" default:"table" enum:"json,table,csv"`
@@ -54,6 +54,7 @@ type compareVitalsAppData struct {
 	Package    string  `json:"package"`
 	CrashRate  float64 `json:"crashRate,omitempty"`
 	AnrRate    float64 `json:"anrRate,omitempty"`
+	LmkRate    float64 `json:"userPerceivedLmkRate,omitempty"`
 	ErrorCount int64   `json:"errorCount,omitempty"`
 	Score      float64 `json:"score"`
 	Rank       int     `json:"rank"`
@@ -146,11 +147,22 @@ func (cmd *CompareVitalsCmd) Run(globals *Globals) error {
 			appData.AnrRate = anrRate
 		}
 
+		// Query user-perceived LMK rate if requested
+		if cmd.Metric == "lmk-rate" || cmd.Metric == checkAll {
+			lmkRate, qerr := cmd.queryLmkRate(ctx, client, svc, pkg, timelineSpec)
+			if qerr != nil {
+				client.Release()
+				return qerr
+			}
+			appData.LmkRate = lmkRate
+		}
+
 		client.Release()
 
-		// Calculate composite score (lower is better for crash/anr rates)
-		// Score = 100 - (crashRate * 50000 + anrRate * 50000), clamped to [0, 100]
-		score := 100.0 - (appData.CrashRate*50000 + appData.AnrRate*50000)
+		// Calculate composite score (lower is better for crash/anr/lmk rates)
+		// Score = 100 - (crashRate * 50000 + anrRate * 50000 + lmkRate * 5000), clamped to [0, 100]
+		// LMK rates run an order of magnitude above crash rates, so they weigh less.
+		score := 100.0 - (appData.CrashRate*50000 + appData.AnrRate*50000 + appData.LmkRate*5000)
 		if score < 0 {
 			score = 0
 		}
@@ -244,6 +256,38 @@ func (cmd *CompareVitalsCmd) queryAnrRate(ctx context.Context, client *api.Clien
 	return anrRate, nil
 }
 
+func (cmd *CompareVitalsCmd) queryLmkRate(ctx context.Context, client *api.Client, svc *playdeveloperreporting.Service, pkg string, timelineSpec *playdeveloperreporting.GooglePlayDeveloperReportingV1beta1TimelineSpec) (float64, error) {
+	lmkName := fmt.Sprintf("apps/%s/%s", pkg, metricSetLmkRate)
+	lmkReq := &playdeveloperreporting.GooglePlayDeveloperReportingV1beta1QueryLmkRateMetricSetRequest{
+		TimelineSpec: timelineSpec,
+		Metrics:      []string{metricUserPerceivedLmkRate, "userPerceivedLmkRate7dUserWeighted"},
+		PageSize:     1,
+	}
+
+	var lmkRate float64
+	err := client.DoWithRetry(ctx, func() error {
+		resp, qerr := svc.Vitals.Lmkrate.Query(lmkName, lmkReq).Context(ctx).Do()
+		if qerr != nil {
+			return qerr
+		}
+		if len(resp.Rows) > 0 {
+			for _, metric := range resp.Rows[0].Metrics {
+				if metric.Metric == metricUserPerceivedLmkRate && metric.DecimalValue != nil {
+					if val, perr := strconv.ParseFloat(metric.DecimalValue.Value, 64); perr == nil {
+						lmkRate = val
+					}
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, errors.NewAPIError(errors.CodeGeneralError,
+			fmt.Sprintf("failed to query LMK rate for %s: %v", pkg, err))
+	}
+	return lmkRate, nil
+}
+
 // CompareReviewsCmd compares review metrics across apps.
 type CompareReviewsCmd struct {
 	Packages         []string `help:"Package names to compare (repeatable)" required:""`
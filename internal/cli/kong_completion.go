@@ -90,7 +90,7 @@ _gpd_completions() {
             return 0
             ;;
         --output|-o)
-            COMPREPLY=( $(compgen -W "json table markdown csv excel" -- "${cur}") )
+            COMPREPLY=( $(compgen -W "json table markdown csv excel sarif" -- "${cur}") )
             return 0
             ;;
         --store-tokens)
@@ -214,7 +214,7 @@ _gpd() {
     )
     
     local -a output_formats
-    output_formats=('json' 'table' 'markdown' 'csv' 'excel')
+    output_formats=('json' 'table' 'markdown' 'csv' 'excel' 'sarif')
     
     local -a track_names
     track_names=('internal' 'alpha' 'beta' 'production')
@@ -281,7 +281,7 @@ _gpd() {
 
 _gpd_output_formats() {
     local -a formats
-    formats=('json' 'table' 'markdown' 'csv' 'excel')
+    formats=('json' 'table' 'markdown' 'csv' 'excel' 'sarif')
     _describe -t formats 'output format' formats
 }
 
@@ -327,7 +327,7 @@ func generateFishCompletion(_ *Globals) error {
 	}
 	sb.WriteString("\n")
 
-	sb.WriteString("complete -c gpd -l output -s o -d 'Output format' -a 'json table markdown csv excel'\n")
+	sb.WriteString("complete -c gpd -l output -s o -d 'Output format' -a 'json table markdown csv excel sarif'\n")
 	sb.WriteString("complete -c gpd -l pretty -d 'Pretty print JSON output'\n")
 	sb.WriteString("complete -c gpd -l timeout -d 'Network timeout'\n")
 	sb.WriteString("complete -c gpd -l store-tokens -d 'Token storage' -a 'auto never secure'\n")
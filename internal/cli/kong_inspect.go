@@ -0,0 +1,275 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/apksig"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/artifact"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/artifactdiff"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/sizereport"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/config"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/errors"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/output"
+)
+
+// InspectCmd contains local APK/AAB inspection commands. None of them touch
+// the network; they decode the artifact on disk.
+type InspectCmd struct {
+	Manifest InspectManifestCmd `cmd:"" default:"withargs" help:"Decode the manifest of an APK or AAB (default)"`
+	Signing  InspectSigningCmd  `cmd:"" help:"Verify APK/AAB signatures and show signer certificates"`
+	Size     InspectSizeCmd     `cmd:"" help:"Break down APK/AAB size and gate growth against a baseline"`
+	Diff     InspectDiffCmd     `cmd:"" help:"Compare two APK/AAB builds: permissions, components, SDKs, ABIs, modules and size"`
+}
+
+// InspectManifestCmd decodes the manifest of an APK or AAB.
+type InspectManifestCmd struct {
+	File string `arg:"" help:"APK or AAB file to inspect" type:"existingfile"`
+}
+
+// inspectManifestResult is the artifact summary emitted by gpd inspect.
+type inspectManifestResult struct {
+	File      string             `json:"file"`
+	Format    artifact.Kind      `json:"format"`
+	SizeBytes int64              `json:"sizeBytes"`
+	Modules   []string           `json:"modules,omitempty"`
+	Manifest  *artifact.Manifest `json:"manifest"`
+}
+
+// Run executes the inspect manifest command.
+func (cmd *InspectManifestCmd) Run(globals *Globals) error {
+	if globals.Verbose {
+		fmt.Fprintf(os.Stderr, "Inspecting %s\n", cmd.File)
+	}
+
+	a, err := openArtifact(cmd.File)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = a.Close() }()
+
+	return writeOutput(globals, output.NewResult(&inspectManifestResult{
+		File:      cmd.File,
+		Format:    a.Kind,
+		SizeBytes: a.Size,
+		Modules:   a.Modules,
+		Manifest:  a.Manifest,
+	}).WithServices("inspect"))
+}
+
+// InspectSigningCmd verifies the v1/v2/v3 signatures of an APK or AAB.
+type InspectSigningCmd struct {
+	File string `arg:"" help:"APK or AAB file to inspect" type:"existingfile"`
+}
+
+// inspectSigningResult is the signature report emitted by gpd inspect signing.
+type inspectSigningResult struct {
+	File     string                `json:"file"`
+	Signed   bool                  `json:"signed"`
+	Verified bool                  `json:"verified"`
+	Schemes  []apksig.SchemeResult `json:"schemes"`
+	// Signer is the key Play and devices attribute the artifact to.
+	Signer *apksig.Signer `json:"signer,omitempty"`
+	// ExpectedUploadKey is the fingerprint recorded for the active profile
+	// with gpd auth upload-key; MatchesUploadKey compares it to Signer.
+	ExpectedUploadKey string `json:"expectedUploadKeySha256,omitempty"`
+	MatchesUploadKey  *bool  `json:"matchesUploadKey,omitempty"`
+}
+
+// Run executes the inspect signing command.
+func (cmd *InspectSigningCmd) Run(globals *Globals) error {
+	if globals.Verbose {
+		fmt.Fprintf(os.Stderr, "Verifying signatures of %s\n", cmd.File)
+	}
+
+	res, err := apksig.Verify(cmd.File)
+	if err != nil {
+		return errors.NewAPIError(errors.CodeValidationError,
+			fmt.Sprintf("cannot read artifact: %v", err)).
+			WithHint("Pass an APK or AAB produced by the Android Gradle Plugin or bundletool")
+	}
+	report := &inspectSigningResult{
+		File:     cmd.File,
+		Signed:   res.Signed(),
+		Verified: res.Verified(),
+		Schemes:  res.Schemes,
+		Signer:   res.Signer(),
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return errors.NewAPIError(errors.CodeGeneralError, fmt.Sprintf("failed to load config: %v", err)).
+			WithHint("Fix the file shown by `gpd config path`; it holds the expected upload key")
+	}
+	report.ExpectedUploadKey = cfg.UploadKeyFingerprint(config.ResolveAuthProfile(globals.Profile))
+	if report.ExpectedUploadKey != "" && report.Signer != nil {
+		matches := report.Signer.Certificate.SHA256 == report.ExpectedUploadKey
+		report.MatchesUploadKey = &matches
+	}
+	return writeOutput(globals, output.NewResult(report).WithServices("inspect"))
+}
+
+// InspectSizeCmd reports where an artifact's bytes go and, with a baseline,
+// fails when the download grows past a budget.
+type InspectSizeCmd struct {
+	File        string `arg:"" help:"APK or AAB file to inspect" type:"existingfile"`
+	Baseline    string `help:"Previous APK/AAB to compare against" type:"existingfile"`
+	MaxIncrease string `help:"Allowed download growth over --baseline: a percentage (5%) or size (500KB, 2MB); default 10%"`
+}
+
+// inspectSizeResult is the size report emitted by gpd inspect size.
+type inspectSizeResult struct {
+	File string `json:"file"`
+	*sizereport.Report
+	Baseline   string                 `json:"baseline,omitempty"`
+	Comparison *sizereport.Comparison `json:"comparison,omitempty"`
+}
+
+// Run executes the inspect size command.
+func (cmd *InspectSizeCmd) Run(globals *Globals) error {
+	budget := sizereport.Budget{MaxPercent: sizereport.DefaultMaxIncreasePercent}
+	if cmd.MaxIncrease != "" {
+		if cmd.Baseline == "" {
+			return errors.NewAPIError(errors.CodeValidationError, "--max-increase requires --baseline").
+				WithHint("Pass the previous release build with --baseline old.aab")
+		}
+		parsed, err := sizereport.ParseBudget(cmd.MaxIncrease)
+		if err != nil {
+			return errors.NewAPIError(errors.CodeValidationError, err.Error())
+		}
+		budget = parsed
+	}
+
+	report, err := buildSizeReport(cmd.File)
+	if err != nil {
+		return err
+	}
+	result := &inspectSizeResult{File: cmd.File, Report: report}
+	if cmd.Baseline != "" {
+		if globals.Verbose {
+			fmt.Fprintf(os.Stderr, "Comparing %s against baseline %s\n", cmd.File, cmd.Baseline)
+		}
+		baseline, err := buildSizeReport(cmd.Baseline)
+		if err != nil {
+			return err
+		}
+		result.Baseline = cmd.Baseline
+		result.Comparison = sizereport.Compare(baseline, report, budget)
+	}
+
+	res := output.NewResult(result).WithServices("inspect")
+	if result.Comparison != nil {
+		for _, d := range result.Comparison.Downloads {
+			if d.NewABI != "" {
+				res = res.WithWarnings(fmt.Sprintf("%s now receives %s libraries the baseline did not ship (+%d bytes)", d.Metric, d.NewABI, d.Delta))
+			}
+		}
+	}
+	if err := writeOutput(globals, res); err != nil {
+		return err
+	}
+	if result.Comparison != nil && result.Comparison.Exceeded {
+		exceeded := make([]string, 0)
+		for _, d := range result.Comparison.Downloads {
+			if d.Exceeded {
+				exceeded = append(exceeded, fmt.Sprintf("%s +%d bytes (%.2f%%)", d.Metric, d.Delta, d.ChangePct))
+			}
+		}
+		return errors.NewAPIError(errors.CodeValidationError, "size budget exceeded: "+strings.Join(exceeded, ", ")).
+			WithHint("See comparison.modules and comparison.categories for where the growth came from").
+			WithDetails(map[string]interface{}{"budget": budget, "baseline": cmd.Baseline})
+	}
+	return nil
+}
+
+// InspectDiffCmd reports what a new build changes relative to the previous
+// one, for review before promoting to production.
+type InspectDiffCmd struct {
+	Old string `arg:"" help:"Previous APK or AAB" type:"existingfile"`
+	New string `arg:"" help:"New APK or AAB" type:"existingfile"`
+}
+
+// Run executes the inspect diff command. JSON carries the structured diff,
+// table/csv/excel one row per change, and markdown a review comment.
+func (cmd *InspectDiffCmd) Run(globals *Globals) error {
+	old, err := captureSnapshot(cmd.Old)
+	if err != nil {
+		return err
+	}
+	current, err := captureSnapshot(cmd.New)
+	if err != nil {
+		return err
+	}
+	diff := artifactdiff.Compare(old, current)
+
+	switch output.ParseFormat(globals.Output) {
+	case output.FormatMarkdown:
+		_, err := fmt.Fprint(os.Stdout, diff.Markdown())
+		return err
+	case output.FormatTable, output.FormatCSV, output.FormatExcel:
+		return writeOutput(globals, output.NewResult(diff.Rows()).WithServices("inspect"))
+	default:
+		return writeOutput(globals, output.NewResult(diff).WithServices("inspect"))
+	}
+}
+
+func captureSnapshot(path string) (*artifactdiff.Snapshot, error) {
+	a, err := openArtifact(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = a.Close() }()
+	s, err := artifactdiff.Capture(a)
+	if err != nil {
+		return nil, errors.NewAPIError(errors.CodeValidationError, fmt.Sprintf("cannot read %s: %v", path, err))
+	}
+	return s, nil
+}
+
+func buildSizeReport(path string) (*sizereport.Report, error) {
+	a, err := openArtifact(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = a.Close() }()
+	report, err := sizereport.Build(a)
+	if err != nil {
+		return nil, errors.NewAPIError(errors.CodeValidationError, fmt.Sprintf("cannot size %s: %v", path, err))
+	}
+	return report, nil
+}
+
+// verifiedSigner verifies the signatures of the artifact at path and returns
+// its effective signer, failing when it is unsigned or does not verify.
+func verifiedSigner(path string) (*apksig.Signer, error) {
+	res, err := apksig.Verify(path)
+	if err != nil {
+		return nil, errors.NewAPIError(errors.CodeValidationError,
+			fmt.Sprintf("cannot read artifact: %v", err))
+	}
+	if !res.Verified() {
+		details := map[string]interface{}{"file": path, "schemes": res.Schemes}
+		if !res.Signed() {
+			return nil, errors.NewAPIError(errors.CodeValidationError, fmt.Sprintf("%s is not signed", path)).
+				WithHint("Sign it with your upload key before uploading").
+				WithDetails(details)
+		}
+		return nil, errors.NewAPIError(errors.CodeValidationError,
+			fmt.Sprintf("signatures of %s do not verify", path)).
+			WithHint("Run `gpd inspect signing` for per-scheme errors; the file may have been modified after signing").
+			WithDetails(details)
+	}
+	return res.Signer(), nil
+}
+
+// openArtifact opens an APK/AAB and maps decode failures to a validation error
+// so every command that reads artifacts reports them the same way.
+func openArtifact(path string) (*artifact.Artifact, error) {
+	a, err := artifact.Open(path)
+	if err != nil {
+		return nil, errors.NewAPIError(errors.CodeValidationError,
+			fmt.Sprintf("cannot read artifact: %v", err)).
+			WithHint("Pass an APK or AAB produced by the Android Gradle Plugin or bundletool")
+	}
+	return a, nil
+}
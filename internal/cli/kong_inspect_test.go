@@ -0,0 +1,249 @@
+//go:build unit
+// +build unit
+
+package cli
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/artifact/artifacttest"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/config"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/errors"
+)
+
+// captureInspectStdout runs fn with stdout redirected and returns what it
+// wrote along with fn's error.
+func captureInspectStdout(t *testing.T, fn func() error) (string, error) {
+	t.Helper()
+	old := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = w
+	runErr := fn()
+	_ = w.Close()
+	os.Stdout = old
+	out, _ := io.ReadAll(r)
+	return string(out), runErr
+}
+
+// decodeInspectData unmarshals the data field of a JSON result envelope.
+func decodeInspectData(t *testing.T, body string, into interface{}) {
+	t.Helper()
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(body), &envelope); err != nil {
+		t.Fatalf("json unmarshal: %v\nbody: %s", err, body)
+	}
+	if err := json.Unmarshal(envelope.Data, into); err != nil {
+		t.Fatalf("data unmarshal: %v\nbody: %s", err, body)
+	}
+}
+
+func TestInspectCmd_HasExpectedSubcommands(t *testing.T) {
+	field, ok := reflect.TypeOf(InspectCmd{}).FieldByName("Manifest")
+	if !ok {
+		t.Fatal("InspectCmd missing Manifest subcommand")
+	}
+	if field.Tag.Get("default") != "withargs" {
+		t.Errorf("InspectCmd.Manifest should be the default subcommand, got default=%q", field.Tag.Get("default"))
+	}
+}
+
+func TestInspectManifestCmd_Run(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		wantFormat string
+		wantMods   int
+	}{
+		{"apk", artifacttest.WriteAPK(t, artifacttest.Manifest("com.example.app", 7)), "apk", 0},
+		{"aab", artifacttest.WriteAAB(t, artifacttest.Manifest("com.example.app", 7)), "aab", 1},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := &InspectManifestCmd{File: tc.path}
+			out, err := captureInspectStdout(t, func() error {
+				return cmd.Run(&Globals{Output: "json"})
+			})
+			if err != nil {
+				t.Fatalf("Run: %v", err)
+			}
+			var got inspectManifestResult
+			decodeInspectData(t, out, &got)
+			if string(got.Format) != tc.wantFormat {
+				t.Errorf("format = %s, want %s", got.Format, tc.wantFormat)
+			}
+			if len(got.Modules) != tc.wantMods {
+				t.Errorf("modules = %v", got.Modules)
+			}
+			if got.Manifest == nil || got.Manifest.Package != "com.example.app" || got.Manifest.VersionCode != 7 {
+				t.Fatalf("manifest = %+v", got.Manifest)
+			}
+			if len(got.Manifest.Activities) != 1 || got.Manifest.Activities[0].Exported == nil {
+				t.Errorf("activities = %+v", got.Manifest.Activities)
+			}
+		})
+	}
+}
+
+func TestInspectSigningCmd_Run(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("HOME", tmp)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmp, ".config"))
+	t.Setenv("GPD_AUTH_PROFILE", "")
+
+	signer := artifacttest.NewSigner(t, "upload")
+	apk := artifacttest.WriteAPK(t, artifacttest.Manifest("com.example.app", 7))
+	signer.SignJAR(t, apk)
+	signer.SignAPK(t, apk, artifacttest.BlockIDV2, artifacttest.BlockIDV3)
+	if err := config.SetUploadKeyFingerprint("default", signer.Fingerprint()); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := captureInspectStdout(t, func() error {
+		return (&InspectSigningCmd{File: apk}).Run(&Globals{Output: "json", Profile: "default"})
+	})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	var got inspectSigningResult
+	decodeInspectData(t, out, &got)
+	if !got.Signed || !got.Verified || len(got.Schemes) != 3 {
+		t.Errorf("result = %+v", got)
+	}
+	if got.Signer == nil || got.Signer.Certificate.SHA256 != signer.Fingerprint() {
+		t.Errorf("signer = %+v", got.Signer)
+	}
+	if got.MatchesUploadKey == nil || !*got.MatchesUploadKey {
+		t.Errorf("matchesUploadKey = %v", got.MatchesUploadKey)
+	}
+}
+
+func TestInspectSigningCmd_BrokenConfig(t *testing.T) {
+	writeAlertConfig(t, `{"uploadKeys": `)
+	apk := artifacttest.WriteAPK(t, artifacttest.Manifest("com.example.app", 7))
+	_, err := captureInspectStdout(t, func() error {
+		return (&InspectSigningCmd{File: apk}).Run(&Globals{Output: "json"})
+	})
+	if err == nil || !strings.Contains(err.Error(), "failed to load config") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestInspectManifestCmd_Run_InvalidArtifact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.aab")
+	if err := os.WriteFile(path, []byte("fake aab"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cmd := &InspectManifestCmd{File: path}
+	_, err := captureInspectStdout(t, func() error {
+		return cmd.Run(&Globals{Output: "json"})
+	})
+	apiErr, ok := err.(*errors.APIError)
+	if !ok {
+		t.Fatalf("expected APIError, got %T %v", err, err)
+	}
+	if apiErr.Code != errors.CodeValidationError || !strings.Contains(apiErr.Message, "cannot read artifact") {
+		t.Errorf("error = %v", apiErr)
+	}
+}
+
+func TestInspectSizeCmd_Run(t *testing.T) {
+	manifest := artifacttest.Manifest("com.example.app", 7)
+	lib := func(abi string) artifacttest.Entry {
+		return artifacttest.Entry{Name: "base/lib/" + abi + "/libapp.so", Data: make([]byte, 50_000), Stored: true}
+	}
+	baseline := artifacttest.WriteAAB(t, manifest, lib("arm64-v8a"))
+	grown := artifacttest.WriteAAB(t, manifest, lib("arm64-v8a"), lib("x86_64"))
+
+	out, err := captureInspectStdout(t, func() error {
+		return (&InspectSizeCmd{File: grown}).Run(&Globals{Output: "json"})
+	})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	var got inspectSizeResult
+	decodeInspectData(t, out, &got)
+	if got.Report == nil || len(got.ABIs) != 2 || len(got.Devices) == 0 || got.Comparison != nil {
+		t.Fatalf("result = %+v", got)
+	}
+
+	t.Run("within budget", func(t *testing.T) {
+		out, err := captureInspectStdout(t, func() error {
+			return (&InspectSizeCmd{File: grown, Baseline: baseline, MaxIncrease: "1MB"}).Run(&Globals{Output: "json"})
+		})
+		if err != nil {
+			t.Errorf("Run: %v", err)
+		}
+		if !strings.Contains(out, "now receives x86_64 libraries the baseline did not ship") {
+			t.Errorf("no new ABI warning: %s", out)
+		}
+	})
+
+	t.Run("exceeds default budget", func(t *testing.T) {
+		out, err := captureInspectStdout(t, func() error {
+			return (&InspectSizeCmd{File: grown, Baseline: baseline}).Run(&Globals{Output: "json"})
+		})
+		apiErr, ok := err.(*errors.APIError)
+		if !ok || apiErr.Code != errors.CodeValidationError || !strings.Contains(apiErr.Message, "total") {
+			t.Fatalf("err = %v", err)
+		}
+		// The device gaining x86_64 is held to the percentage budget too.
+		if !strings.Contains(apiErr.Message, "device:chromebook-x86_64-xhdpi") {
+			t.Errorf("new ABI device not over budget: %v", err)
+		}
+		var got inspectSizeResult
+		decodeInspectData(t, out, &got)
+		if got.Comparison == nil || !got.Comparison.Exceeded || got.Comparison.Budget.MaxPercent != 10 {
+			t.Errorf("comparison = %+v", got.Comparison)
+		}
+	})
+
+	t.Run("max increase without baseline", func(t *testing.T) {
+		err := (&InspectSizeCmd{File: grown, MaxIncrease: "5%"}).Run(&Globals{Output: "json"})
+		if apiErr, ok := err.(*errors.APIError); !ok || !strings.Contains(apiErr.Message, "--baseline") {
+			t.Errorf("err = %v", err)
+		}
+	})
+}
+
+func TestInspectDiffCmd_Run(t *testing.T) {
+	old := artifacttest.WriteAAB(t, artifacttest.Manifest("com.example.app", 7))
+	manifest := artifacttest.Manifest("com.example.app", 8)
+	manifest.Child("uses-sdk").Set("android:targetSdkVersion", "35")
+	manifest.Children = append(manifest.Children, artifacttest.E("uses-permission", []string{"android:name", "android.permission.CAMERA"}))
+	current := artifacttest.WriteAAB(t, manifest)
+
+	out, err := captureInspectStdout(t, func() error {
+		return (&InspectDiffCmd{Old: old, New: current}).Run(&Globals{Output: "json"})
+	})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	var got struct {
+		Changes     []map[string]string `json:"changes"`
+		Permissions struct {
+			Added []string `json:"added"`
+		} `json:"permissions"`
+	}
+	decodeInspectData(t, out, &got)
+	if len(got.Changes) != 1 || got.Changes[0]["field"] != "targetSdk" || !reflect.DeepEqual(got.Permissions.Added, []string{"android.permission.CAMERA"}) {
+		t.Errorf("diff = %+v", got)
+	}
+
+	md, err := captureInspectStdout(t, func() error {
+		return (&InspectDiffCmd{Old: old, New: current}).Run(&Globals{Output: "markdown"})
+	})
+	if err != nil || !strings.HasPrefix(md, "## com.example.app: 1.4.2 (7) → 1.4.2 (8)") || !strings.Contains(md, "- Added `android.permission.CAMERA`") {
+		t.Errorf("markdown = %q, err = %v", md, err)
+	}
+}
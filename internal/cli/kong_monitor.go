@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -13,6 +14,10 @@ import (
 	playdeveloperreporting "google.golang.org/api/playdeveloperreporting/v1beta1"
 
 	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/api"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/alerting"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/anomaly"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/vitalsstore"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/webhooks"
 	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/errors"
 	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/output"
 )
@@ -31,6 +36,8 @@ const (
 	metricSlowStartRate               = "slowStartRate"
 	metricExcessiveWakeupRate         = "excessiveWakeupRate"
 	metricStuckBackgroundWakelockRate = "stuckBackgroundWakelockRate"
+	metricUserPerceivedLmkRate        = "userPerceivedLmkRate"
+	metricLmk                         = "lmk"
 	severityHigh                      = "high"
 	severityLow                       = "low"
 	metricDistinctUsers               = "distinctUsers"
@@ -43,18 +50,22 @@ type MonitorCmd struct {
 	Anomalies MonitorAnomaliesCmd `cmd:"" help:"Detect statistical anomalies in vitals metrics"`
 	Dashboard MonitorDashboardCmd `cmd:"" help:"Generate monitoring dashboard data"`
 	Report    MonitorReportCmd    `cmd:"" help:"Generate scheduled monitoring reports"`
-	Webhooks  MonitorWebhooksCmd  `cmd:"" help:"Manage monitoring webhooks (simulated - no Play API)"`
+	Webhooks  MonitorWebhooksCmd  `cmd:"" help:"Manage webhooks that receive gpd events"`
+	Serve     MonitorServeCmd     `cmd:"" help:"Serve vitals, review and rollout metrics for OpenMetrics scrapers"`
+	SLO       MonitorSLOCmd       `cmd:"" name:"slo" help:"Check crash-free user SLOs, error budgets and burn rates"`
 }
 
 // MonitorWatchCmd continuously monitors vitals and alerts on threshold breaches.
 type MonitorWatchCmd struct {
-	Metrics         []string      `help:"Metrics to monitor: crashes, anrs, errors, all" default:"all"`
+	Metrics         []string      `help:"Metrics to monitor: crashes, anrs, errors, lmk, all" default:"all"`
 	Interval        time.Duration `help:"Poll interval for continuous monitoring" default:"5m"`
 	Duration        time.Duration `help:"Total monitoring duration (0 = one-shot)" default:"0"`
 	CrashThreshold  float64       `help:"Crash rate threshold for alerting (0-1)" default:"0.01"`
 	AnrThreshold    float64       `help:"ANR rate threshold for alerting (0-1)" default:"0.005"`
 	ErrorThreshold  float64       `help:"Error count threshold for alerting" default:"100"`
+	LmkThreshold    float64       `help:"User-perceived low memory kill rate threshold for alerting (0-1)" default:"0.01"`
 	AlertOnBreaches bool          `help:"Exit with error code when thresholds breached"`
+	Notify          bool          `help:"Deliver breaches to the alert sinks configured for the profile"`
 	Format          string        `help:"Output format: json, table, html" default:"json" enum:"json,table,html"`
 }
 
@@ -77,6 +88,8 @@ type monitorWatchResult struct {
 	Alerts             []monitorAlert         `json:"alerts"`
 	ThresholdsBreached int                    `json:"thresholdsBreached"`
 	Metrics            map[string]interface{} `json:"metrics"`
+	Notifications      []alerting.Delivery    `json:"notifications,omitempty"`
+	WebhookDeliveries  []webhooks.Delivery    `json:"webhookDeliveries,omitempty"`
 }
 
 // Run executes the watch command.
@@ -84,6 +97,13 @@ func (cmd *MonitorWatchCmd) Run(globals *Globals) error {
 	if err := requirePackage(globals.Package); err != nil {
 		return err
 	}
+	var notifier *alerting.Notifier
+	if cmd.Notify {
+		var err error
+		if notifier, err = profileNotifier(globals); err != nil {
+			return err
+		}
+	}
 
 	ctx := globals.Context
 	if ctx == nil {
@@ -137,11 +157,20 @@ func (cmd *MonitorWatchCmd) Run(globals *Globals) error {
 		pollCount++
 
 		// Poll all requested metrics
+		seen := len(result.Alerts)
 		for _, metric := range metrics {
 			if err := cmd.pollMetric(ctx, client, svc, globals.Package, metric, result); err != nil {
 				return err
 			}
 		}
+		if notifier != nil && len(result.Alerts) > seen {
+			deliveries := notifier.Notify(ctx, watchAlerts(globals.Package, result.Alerts[seen:]))
+			result.Notifications = append(result.Notifications, deliveries...)
+		}
+		for _, breach := range result.Alerts[seen:] {
+			result.WebhookDeliveries = append(result.WebhookDeliveries,
+				emitEvent(ctx, globals, webhooks.EventVitalsThreshold, breach)...)
+		}
 
 		result.PollCount = pollCount
 		result.Duration = time.Since(startTime)
@@ -177,22 +206,42 @@ finish:
 	return outputResultResult(outputResult, cmd.Format, globals.Pretty)
 }
 
+// watchAlerts converts threshold breaches to alerts for delivery.
+func watchAlerts(pkg string, breaches []monitorAlert) []alerting.Alert {
+	alerts := make([]alerting.Alert, 0, len(breaches))
+	for _, b := range breaches {
+		alerts = append(alerts, alerting.Alert{
+			Source:    "monitor watch",
+			Package:   pkg,
+			Metric:    b.Metric,
+			Severity:  b.Severity,
+			Value:     b.ActualValue,
+			Threshold: b.Threshold,
+			Time:      b.Timestamp,
+			Labels:    b.Dimensions,
+		})
+	}
+	return alerts
+}
+
 func (cmd *MonitorWatchCmd) normalizeMetrics() []string {
 	if len(cmd.Metrics) == 0 {
-		return []string{metricCrashes, metricAnrs, metricErrors}
+		return []string{metricCrashes, metricAnrs, metricErrors, metricLmk}
 	}
 
 	var result []string
 	for _, m := range cmd.Metrics {
 		switch strings.ToLower(m) {
 		case checkAll:
-			return []string{metricCrashes, metricAnrs, metricErrors}
+			return []string{metricCrashes, metricAnrs, metricErrors, metricLmk}
 		case metricCrashes, metricCrash:
 			result = append(result, metricCrashes)
 		case metricAnrs, metricAnr:
 			result = append(result, metricAnrs)
 		case metricErrors, metricError:
 			result = append(result, metricErrors)
+		case metricLmk, "lmks", "low-memory-kills":
+			result = append(result, metricLmk)
 		}
 	}
 	return result
@@ -229,6 +278,8 @@ func (cmd *MonitorWatchCmd) pollMetric(ctx context.Context, client *api.Client,
 		return cmd.checkAnrThreshold(ctx, client, svc, pkg, timelineSpec, result)
 	case metricErrors:
 		return cmd.checkErrorThreshold(ctx, client, svc, pkg, timelineSpec, result)
+	case metricLmk:
+		return cmd.checkLmkThreshold(ctx, client, svc, pkg, timelineSpec, result)
 	}
 	return nil
 }
@@ -371,6 +422,52 @@ func (cmd *MonitorWatchCmd) checkErrorThreshold(ctx context.Context, client *api
 	return nil
 }
 
+func (cmd *MonitorWatchCmd) checkLmkThreshold(ctx context.Context, client *api.Client, svc *playdeveloperreporting.Service, pkg string, timelineSpec *playdeveloperreporting.GooglePlayDeveloperReportingV1beta1TimelineSpec, result *monitorWatchResult) error {
+	name := fmt.Sprintf("apps/%s/%s", pkg, metricSetLmkRate)
+	req := &playdeveloperreporting.GooglePlayDeveloperReportingV1beta1QueryLmkRateMetricSetRequest{
+		TimelineSpec: timelineSpec,
+		Metrics:      []string{metricUserPerceivedLmkRate, metricDistinctUsers},
+		PageSize:     1,
+	}
+
+	var lmkRate float64
+	err := client.DoWithRetry(ctx, func() error {
+		resp, err := svc.Vitals.Lmkrate.Query(name, req).Context(ctx).Do()
+		if err != nil {
+			return err
+		}
+		if len(resp.Rows) > 0 && len(resp.Rows[0].Metrics) > 0 {
+			for _, m := range resp.Rows[0].Metrics {
+				if m.Metric == metricUserPerceivedLmkRate {
+					lmkRate = parseDecimalValue(m)
+					break
+				}
+			}
+		}
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	result.Metrics[metricUserPerceivedLmkRate] = lmkRate
+
+	if lmkRate > cmd.LmkThreshold {
+		alert := monitorAlert{
+			Metric:      metricUserPerceivedLmkRate,
+			Threshold:   cmd.LmkThreshold,
+			ActualValue: lmkRate,
+			Severity:    cmd.calculateSeverity(lmkRate, cmd.LmkThreshold),
+			Timestamp:   time.Now(),
+		}
+		result.Alerts = append(result.Alerts, alert)
+		result.ThresholdsBreached++
+	}
+
+	return nil
+}
+
 func (cmd *MonitorWatchCmd) calculateSeverity(actual, threshold float64) string {
 	ratio := actual / threshold
 	switch {
@@ -390,8 +487,11 @@ type MonitorAnomaliesCmd struct {
 	Metrics        []string `help:"Metrics to analyze: crashes, anrs, errors, all" default:"all"`
 	BaselinePeriod int      `help:"Days of baseline data for comparison" default:"30"`
 	Sensitivity    string   `help:"Anomaly sensitivity: low, medium, high" default:"medium" enum:"low,medium,high"`
+	Detectors      []string `help:"Detectors: window (current mean vs baseline mean), ewma (control chart), cusum, mad (robust z-score), seasonal (day-of-week baseline)" default:"window" enum:"window,ewma,cusum,mad,seasonal"`
 	Since          string   `help:"Start date for anomaly detection (ISO 8601)"`
 	Format         string   `help:"Output format: json, table, html" default:"json" enum:"json,table,html"`
+	FromStore      bool     `help:"Read daily values from the local vitals store (gpd vitals snapshot) instead of the API"`
+	Store          string   `help:"Vitals store directory (default: <data dir>/vitals)"`
 }
 
 // anomalyResult represents detected anomalies.
@@ -399,18 +499,26 @@ type anomalyResult struct {
 	Package        string            `json:"package"`
 	Timestamp      time.Time         `json:"timestamp"`
 	BaselinePeriod int               `json:"baselinePeriodDays"`
+	Detectors      []string          `json:"detectors"`
 	Anomalies      []detectedAnomaly `json:"anomalies"`
 	TotalAnomalies int               `json:"totalAnomalies"`
 }
 
-// detectedAnomaly represents a single detected anomaly.
+// detectedAnomaly represents a single detected anomaly. BaselineAvg is the
+// value the detector expected: the baseline mean, or its median for the
+// mad and seasonal detectors.
 type detectedAnomaly struct {
-	Metric       string    `json:"metric"`
-	Severity     string    `json:"severity"`
-	Deviation    float64   `json:"deviationPercent"`
-	CurrentValue float64   `json:"currentValue"`
-	BaselineAvg  float64   `json:"baselineAverage"`
-	Timestamp    time.Time `json:"timestamp"`
+	Metric        string     `json:"metric"`
+	Detector      string     `json:"detector"`
+	Date          string     `json:"date,omitempty"`
+	Severity      string     `json:"severity"`
+	Deviation     float64    `json:"deviationPercent"`
+	CurrentValue  float64    `json:"currentValue"`
+	BaselineAvg   float64    `json:"baselineAverage"`
+	ExpectedRange [2]float64 `json:"expectedRange"`
+	Score         float64    `json:"score"`
+	Confidence    float64    `json:"confidence"`
+	Timestamp     time.Time  `json:"timestamp"`
 }
 
 // Run executes the anomalies detection command.
@@ -426,28 +534,44 @@ func (cmd *MonitorAnomaliesCmd) Run(globals *Globals) error {
 			return errors.NewAPIError(errors.CodeValidationError, fmt.Sprintf("invalid since date: %v", err))
 		}
 	}
-
-	ctx := globals.Context
-	if ctx == nil {
-		ctx = context.Background()
+	detectors := cmd.normalizeDetectors()
+	for _, d := range detectors {
+		if d == anomaly.DetectorSeasonal && cmd.BaselinePeriod < anomaly.MinSeasonalBaseline {
+			return errors.NewAPIError(errors.CodeValidationError,
+				fmt.Sprintf("the seasonal detector needs a baseline of at least %d days", anomaly.MinSeasonalBaseline)).
+				WithHint("Increase --baseline-period, e.g. --baseline-period 28")
+		}
 	}
-	authMgr := newAuthManager()
 
-	creds, err := authMgr.Authenticate(ctx, globals.KeyPath)
-	if err != nil {
-		return err
-	}
+	var series anomalySeries
+	services := []string{"playdeveloperreporting"}
+	if cmd.FromStore {
+		series = storeAnomalySeries(openVitalsStore(cmd.Store), globals.Package)
+		services = []string{"vitals-store"}
+	} else {
+		ctx := globals.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		authMgr := newAuthManager()
 
-	client, err := api.NewClient(ctx, creds.TokenSource,
-		api.WithTimeout(globals.Timeout),
-		api.WithVerboseLogging(globals.Verbose))
-	if err != nil {
-		return errors.NewAPIError(errors.CodeAuthFailure, fmt.Sprintf("failed to create API client: %v", err))
-	}
+		creds, err := authMgr.Authenticate(ctx, globals.KeyPath)
+		if err != nil {
+			return err
+		}
 
-	svc, err := client.PlayReporting()
-	if err != nil {
-		return errors.NewAPIError(errors.CodeGeneralError, fmt.Sprintf("failed to get reporting service: %v", err))
+		client, err := api.NewClient(ctx, creds.TokenSource,
+			api.WithTimeout(globals.Timeout),
+			api.WithVerboseLogging(globals.Verbose))
+		if err != nil {
+			return errors.NewAPIError(errors.CodeAuthFailure, fmt.Sprintf("failed to create API client: %v", err))
+		}
+
+		svc, err := client.PlayReporting()
+		if err != nil {
+			return errors.NewAPIError(errors.CodeGeneralError, fmt.Sprintf("failed to get reporting service: %v", err))
+		}
+		series = cmd.apiSeries(ctx, client, svc, globals.Package)
 	}
 
 	metrics := cmd.normalizeMetrics()
@@ -457,6 +581,7 @@ func (cmd *MonitorAnomaliesCmd) Run(globals *Globals) error {
 		Package:        globals.Package,
 		Timestamp:      startTime,
 		BaselinePeriod: cmd.BaselinePeriod,
+		Detectors:      detectors,
 		Anomalies:      []detectedAnomaly{},
 	}
 
@@ -474,7 +599,7 @@ func (cmd *MonitorAnomaliesCmd) Run(globals *Globals) error {
 
 	// Detect anomalies for each metric
 	for _, metric := range metrics {
-		if err := cmd.detectAnomalies(ctx, client, svc, globals.Package, metric, baselineStart, baselineEnd, currentStart, currentEnd, result); err != nil {
+		if err := cmd.detectAnomalies(metric, detectors, series, baselineStart, baselineEnd, currentStart, currentEnd, result); err != nil {
 			return err
 		}
 	}
@@ -483,7 +608,7 @@ func (cmd *MonitorAnomaliesCmd) Run(globals *Globals) error {
 
 	outputResult := output.NewResult(result).
 		WithDuration(time.Since(startTime)).
-		WithServices("playdeveloperreporting")
+		WithServices(services...)
 
 	if result.TotalAnomalies > 0 {
 		outputResult = outputResult.WithWarnings(
@@ -515,230 +640,162 @@ func (cmd *MonitorAnomaliesCmd) normalizeMetrics() []string {
 	return result
 }
 
-func (cmd *MonitorAnomaliesCmd) detectAnomalies(ctx context.Context, client *api.Client, svc *playdeveloperreporting.Service, pkg, metric string, baselineStart, baselineEnd, currentStart, currentEnd time.Time, result *anomalyResult) error {
-	multiplier := cmd.getSensitivityMultiplier()
-
-	switch metric {
-	case metricCrashes:
-		return cmd.detectCrashAnomalies(ctx, client, svc, pkg, baselineStart, baselineEnd, currentStart, currentEnd, multiplier, result)
-	case metricAnrs:
-		return cmd.detectAnrAnomalies(ctx, client, svc, pkg, baselineStart, baselineEnd, currentStart, currentEnd, multiplier, result)
-	case metricErrors:
-		return cmd.detectErrorAnomalies(ctx, client, svc, pkg, baselineStart, baselineEnd, currentStart, currentEnd, multiplier, result)
+// normalizeDetectors returns the selected detectors without duplicates,
+// defaulting to the window detector.
+func (cmd *MonitorAnomaliesCmd) normalizeDetectors() []string {
+	var result []string
+	seen := make(map[string]bool)
+	for _, d := range cmd.Detectors {
+		d = strings.ToLower(strings.TrimSpace(d))
+		if d != "" && !seen[d] {
+			seen[d] = true
+			result = append(result, d)
+		}
 	}
-	return nil
-}
-
-func (cmd *MonitorAnomaliesCmd) getSensitivityMultiplier() float64 {
-	switch cmd.Sensitivity {
-	case "low":
-		return 3.0
-	case "high":
-		return 1.5
-	default:
-		return 2.0
+	if len(result) == 0 {
+		return []string{anomaly.DetectorWindow}
 	}
+	return result
 }
 
-func (cmd *MonitorAnomaliesCmd) detectCrashAnomalies(ctx context.Context, client *api.Client, svc *playdeveloperreporting.Service, pkg string, baselineStart, baselineEnd, currentStart, currentEnd time.Time, multiplier float64, result *anomalyResult) error {
-	// Get baseline data
-	baselineAvg, err := cmd.getCrashRate(ctx, client, svc, pkg, baselineStart, baselineEnd)
-	if err != nil {
-		return err
-	}
+// anomalySeries returns the app-level daily values of an anomalies metric
+// (crashes, anrs or errors) in [start, end], oldest first.
+type anomalySeries func(metric string, start, end time.Time) ([]anomaly.Point, error)
 
-	// Get current data
-	currentRate, err := cmd.getCrashRate(ctx, client, svc, pkg, currentStart, currentEnd)
-	if err != nil {
-		return err
-	}
-
-	// Check for anomaly
-	if baselineAvg > 0 && currentRate > baselineAvg*multiplier {
-		deviation := ((currentRate - baselineAvg) / baselineAvg) * 100
-		severity := cmd.calculateAnomalySeverity(deviation)
-
-		anomaly := detectedAnomaly{
-			Metric:       "crashRate",
-			Severity:     severity,
-			Deviation:    deviation,
-			CurrentValue: currentRate,
-			BaselineAvg:  baselineAvg,
-			Timestamp:    time.Now(),
-		}
-		result.Anomalies = append(result.Anomalies, anomaly)
-	}
-
-	return nil
+// anomalyMetricNames are the reported names of the anomalies metrics.
+var anomalyMetricNames = map[string]string{
+	metricCrashes: metricCrashRate,
+	metricAnrs:    metricAnrRate,
+	metricErrors:  metricErrorCount,
 }
 
-func (cmd *MonitorAnomaliesCmd) detectAnrAnomalies(ctx context.Context, client *api.Client, svc *playdeveloperreporting.Service, pkg string, baselineStart, baselineEnd, currentStart, currentEnd time.Time, multiplier float64, result *anomalyResult) error {
-	baselineAvg, err := cmd.getAnrRate(ctx, client, svc, pkg, baselineStart, baselineEnd)
-	if err != nil {
-		return err
-	}
-
-	currentRate, err := cmd.getAnrRate(ctx, client, svc, pkg, currentStart, currentEnd)
-	if err != nil {
-		return err
-	}
-
-	if baselineAvg > 0 && currentRate > baselineAvg*multiplier {
-		deviation := ((currentRate - baselineAvg) / baselineAvg) * 100
-		severity := cmd.calculateAnomalySeverity(deviation)
-
-		anomaly := detectedAnomaly{
-			Metric:       "anrRate",
-			Severity:     severity,
-			Deviation:    deviation,
-			CurrentValue: currentRate,
-			BaselineAvg:  baselineAvg,
-			Timestamp:    time.Now(),
-		}
-		result.Anomalies = append(result.Anomalies, anomaly)
-	}
-
-	return nil
+// anomalySources are the metric sets and metrics the anomalies metrics are
+// read from.
+var anomalySources = map[string][2]string{
+	metricCrashes: {"crashRateMetricSet", metricCrashRate},
+	metricAnrs:    {"anrRateMetricSet", metricAnrRate},
+	metricErrors:  {"errorCountMetricSet", "errorReportCount"},
 }
 
-func (cmd *MonitorAnomaliesCmd) detectErrorAnomalies(ctx context.Context, client *api.Client, svc *playdeveloperreporting.Service, pkg string, baselineStart, baselineEnd, currentStart, currentEnd time.Time, multiplier float64, result *anomalyResult) error {
-	baselineAvg, err := cmd.getErrorCount(ctx, client, svc, pkg, baselineStart, baselineEnd)
-	if err != nil {
-		return err
-	}
-
-	currentCount, err := cmd.getErrorCount(ctx, client, svc, pkg, currentStart, currentEnd)
-	if err != nil {
-		return err
-	}
-
-	if baselineAvg > 0 && currentCount > baselineAvg*multiplier {
-		deviation := ((float64(currentCount) - baselineAvg) / baselineAvg) * 100
-		severity := cmd.calculateAnomalySeverity(deviation)
-
-		anomaly := detectedAnomaly{
-			Metric:       "errorCount",
-			Severity:     severity,
-			Deviation:    deviation,
-			CurrentValue: float64(currentCount),
-			BaselineAvg:  baselineAvg,
-			Timestamp:    time.Now(),
+func (cmd *MonitorAnomaliesCmd) apiSeries(ctx context.Context, client *api.Client, svc *playdeveloperreporting.Service, pkg string) anomalySeries {
+	return func(metric string, start, end time.Time) ([]anomaly.Point, error) {
+		src, ok := anomalySources[metric]
+		if !ok {
+			return nil, nil
 		}
-		result.Anomalies = append(result.Anomalies, anomaly)
-	}
-
-	return nil
-}
-
-func (cmd *MonitorAnomaliesCmd) getCrashRate(ctx context.Context, client *api.Client, svc *playdeveloperreporting.Service, pkg string, start, end time.Time) (float64, error) {
-	name := fmt.Sprintf("apps/%s/crashRateMetricSet", pkg)
-	req := &playdeveloperreporting.GooglePlayDeveloperReportingV1beta1QueryCrashRateMetricSetRequest{
-		TimelineSpec: cmd.buildTimelineSpec(start, end),
-		Metrics:      []string{"crashRate"},
-		PageSize:     100,
-	}
-
-	var totalRate float64
-	var count int
-
-	err := client.DoWithRetry(ctx, func() error {
-		resp, err := svc.Vitals.Crashrate.Query(name, req).Context(ctx).Do()
+		rows, err := queryMetricSetRows(ctx, client, svc, pkg, src[0], cmd.buildTimelineSpec(start, end), nil, []string{src[1]})
 		if err != nil {
-			return err
+			return nil, err
 		}
-		for _, row := range resp.Rows {
+		points := make([]anomaly.Point, 0, len(rows))
+		for _, row := range rows {
+			day, err := time.Parse(vitalsstore.DateLayout, reportingDate(row.StartTime))
+			if err != nil {
+				continue
+			}
 			for _, m := range row.Metrics {
-				if m.Metric == "crashRate" {
-					totalRate += parseDecimalValue(m)
-					count++
+				if m.Metric == src[1] {
+					points = append(points, anomaly.Point{Date: day, Value: parseDecimalValue(m)})
 				}
 			}
 		}
-		return nil
-	})
-
-	if err != nil {
-		return 0, err
+		sort.Slice(points, func(i, j int) bool { return points[i].Date.Before(points[j].Date) })
+		return points, nil
 	}
-
-	if count == 0 {
-		return 0, nil
-	}
-	return totalRate / float64(count), nil
 }
 
-func (cmd *MonitorAnomaliesCmd) getAnrRate(ctx context.Context, client *api.Client, svc *playdeveloperreporting.Service, pkg string, start, end time.Time) (float64, error) {
-	name := fmt.Sprintf("apps/%s/anrRateMetricSet", pkg)
-	req := &playdeveloperreporting.GooglePlayDeveloperReportingV1beta1QueryAnrRateMetricSetRequest{
-		TimelineSpec: cmd.buildTimelineSpec(start, end),
-		Metrics:      []string{"anrRate"},
-		PageSize:     100,
-	}
-
-	var totalRate float64
-	var count int
-
-	err := client.DoWithRetry(ctx, func() error {
-		resp, err := svc.Vitals.Anrrate.Query(name, req).Context(ctx).Do()
+// storeAnomalySeries reads app-level daily values from the vitals store.
+func storeAnomalySeries(st *vitalsstore.Store, pkg string) anomalySeries {
+	return func(metric string, start, end time.Time) ([]anomaly.Point, error) {
+		src, ok := anomalySources[metric]
+		if !ok {
+			return nil, nil
+		}
+		stored, err := storeSeries(st, pkg, src[0], src[1], nil, start, end)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		for _, row := range resp.Rows {
-			for _, m := range row.Metrics {
-				if m.Metric == "anrRate" {
-					totalRate += parseDecimalValue(m)
-					count++
-				}
+		points := make([]anomaly.Point, 0, len(stored))
+		for _, p := range stored {
+			day, err := time.Parse(vitalsstore.DateLayout, p.Date)
+			if err != nil {
+				continue
 			}
+			points = append(points, anomaly.Point{Date: day, Value: p.Value})
 		}
-		return nil
-	})
-
-	if err != nil {
-		return 0, err
+		return points, nil
 	}
-
-	if count == 0 {
-		return 0, nil
-	}
-	return totalRate / float64(count), nil
 }
 
-func (cmd *MonitorAnomaliesCmd) getErrorCount(ctx context.Context, client *api.Client, svc *playdeveloperreporting.Service, pkg string, start, end time.Time) (float64, error) {
-	name := fmt.Sprintf("apps/%s/errorCountMetricSet", pkg)
-	req := &playdeveloperreporting.GooglePlayDeveloperReportingV1beta1QueryErrorCountMetricSetRequest{
-		TimelineSpec: cmd.buildTimelineSpec(start, end),
-		Metrics:      []string{metricErrorCount},
-		PageSize:     100,
+func (cmd *MonitorAnomaliesCmd) detectAnomalies(metric string, detectors []string, series anomalySeries, baselineStart, baselineEnd, currentStart, currentEnd time.Time, result *anomalyResult) error {
+	name, ok := anomalyMetricNames[metric]
+	if !ok {
+		return nil
 	}
 
-	var totalCount float64
+	baseline, err := series(metric, baselineStart, baselineEnd)
+	if err != nil {
+		return err
+	}
+	current, err := series(metric, currentStart, currentEnd)
+	if err != nil {
+		return err
+	}
 
-	err := client.DoWithRetry(ctx, func() error {
-		resp, err := svc.Vitals.Errors.Counts.Query(name, req).Context(ctx).Do()
+	opts := cmd.detectorOptions()
+	for _, detector := range detectors {
+		findings, err := anomaly.Detect(detector, baseline, current, opts)
 		if err != nil {
-			return err
+			return errors.NewAPIError(errors.CodeValidationError, err.Error())
 		}
-		for _, row := range resp.Rows {
-			for _, m := range row.Metrics {
-				if m.Metric == metricErrorCount {
-					totalCount += parseDecimalValue(m)
-				}
+		for _, f := range findings {
+			var deviation float64
+			if f.Expected > 0 {
+				deviation = ((f.Value - f.Expected) / f.Expected) * 100
 			}
+			result.Anomalies = append(result.Anomalies, detectedAnomaly{
+				Metric:        name,
+				Detector:      f.Detector,
+				Date:          f.Date.Format(vitalsstore.DateLayout),
+				Severity:      cmd.calculateAnomalySeverity(deviation),
+				Deviation:     deviation,
+				CurrentValue:  f.Value,
+				BaselineAvg:   f.Expected,
+				ExpectedRange: [2]float64{f.Lower, f.Upper},
+				Score:         f.Score,
+				Confidence:    f.Confidence,
+				Timestamp:     time.Now(),
+			})
 		}
-		return nil
-	})
+	}
+	return nil
+}
 
-	if err != nil {
-		return 0, err
+func (cmd *MonitorAnomaliesCmd) getSensitivityMultiplier() float64 {
+	switch cmd.Sensitivity {
+	case "low":
+		return 3.0
+	case "high":
+		return 1.5
+	default:
+		return 2.0
 	}
+}
 
-	// Return average per day
-	days := int(end.Sub(start).Hours() / 24)
-	if days == 0 {
-		days = 1
+// detectorOptions maps the sensitivity to detector limits: the window
+// multiplier, the width of the expected range in standard deviations, and
+// the CUSUM decision interval.
+func (cmd *MonitorAnomaliesCmd) detectorOptions() anomaly.Options {
+	opts := anomaly.Options{Multiplier: cmd.getSensitivityMultiplier()}
+	switch cmd.Sensitivity {
+	case "low":
+		opts.Threshold, opts.Limit = 4, 5
+	case "high":
+		opts.Threshold, opts.Limit = 2, 3
+	default:
+		opts.Threshold, opts.Limit = 3, 4
 	}
-	return float64(totalCount) / float64(days), nil
+	return opts
 }
 
 func (cmd *MonitorAnomaliesCmd) buildTimelineSpec(start, end time.Time) *playdeveloperreporting.GooglePlayDeveloperReportingV1beta1TimelineSpec {
@@ -778,9 +835,12 @@ func (cmd *MonitorAnomaliesCmd) calculateAnomalySeverity(deviation float64) stri
 
 // MonitorDashboardCmd generates monitoring dashboard data.
 type MonitorDashboardCmd struct {
-	Metrics []string `help:"Metrics to include: crashes, anrs, errors, slow-rendering, slow-start, wakeups, wakelocks, all" default:"all"`
-	Period  int      `help:"Days of data to include" default:"7"`
-	Format  string   `help:"Output format: json, html, markdown" default:"json" enum:"json,html,markdown"`
+	Metrics    []string `help:"Metrics to include: crashes, anrs, errors, slow-rendering, slow-start, wakeups, wakelocks, lmk, all" default:"all"`
+	Period     int      `help:"Days of data to include" default:"7"`
+	Format     string   `help:"Output format: json, html, markdown" default:"json" enum:"json,html,markdown"`
+	FromStore  bool     `help:"Read daily values from the local vitals store (gpd vitals snapshot) instead of the API"`
+	Store      string   `help:"Vitals store directory (default: <data dir>/vitals)"`
+	OutputFile string   `help:"Write the HTML dashboard to a file instead of stdout" name:"output-file" type:"path"`
 }
 
 // dashboardResult represents dashboard data.
@@ -815,6 +875,15 @@ func (cmd *MonitorDashboardCmd) Run(globals *Globals) error {
 	if err := requirePackage(globals.Package); err != nil {
 		return err
 	}
+	if cmd.Format == "html" {
+		return cmd.runHTML(globals)
+	}
+	if cmd.OutputFile != "" {
+		return errors.NewAPIError(errors.CodeValidationError, "--output-file requires --format html")
+	}
+	if cmd.FromStore {
+		return cmd.runFromStore(globals)
+	}
 
 	ctx := globals.Context
 	if ctx == nil {
@@ -868,6 +937,8 @@ func (cmd *MonitorDashboardCmd) Run(globals *Globals) error {
 			cmd.aggregateWakeups(ctx, client, svc, globals.Package, startDate, endDate, result)
 		case "wakelocks":
 			cmd.aggregateWakelocks(ctx, client, svc, globals.Package, startDate, endDate, result)
+		case metricLmk:
+			cmd.aggregateLmk(ctx, client, svc, globals.Package, startDate, endDate, result)
 		}
 	}
 
@@ -881,16 +952,142 @@ func (cmd *MonitorDashboardCmd) Run(globals *Globals) error {
 	return outputResultResult(outputResult, cmd.Format, globals.Pretty)
 }
 
+// runFromStore builds the dashboard from app-level values in the local
+// vitals store instead of the API.
+func (cmd *MonitorDashboardCmd) runFromStore(globals *Globals) error {
+	st := openVitalsStore(cmd.Store)
+	startTime := time.Now()
+	endDate := startTime.UTC()
+	startDate := endDate.AddDate(0, 0, -cmd.Period)
+
+	result := &dashboardResult{
+		Package:     globals.Package,
+		GeneratedAt: startTime,
+		PeriodDays:  cmd.Period,
+		Metrics:     make(map[string]interface{}),
+	}
+	var missing []string
+	for _, metric := range cmd.normalizeDashboardMetrics() {
+		found, err := cmd.aggregateFromStore(st, globals.Package, metric, startDate, endDate, result)
+		if err != nil {
+			return err
+		}
+		if !found {
+			missing = append(missing, metric)
+		}
+	}
+	cmd.calculateTrends(result)
+
+	outputResult := output.NewResult(result).
+		WithDuration(time.Since(startTime)).
+		WithServices("vitals-store")
+	if len(missing) > 0 {
+		outputResult = outputResult.WithWarnings(fmt.Sprintf("no stored values for %s in %s; record them with gpd vitals snapshot",
+			strings.Join(missing, ", "), st.Dir()))
+	}
+	return outputResultResult(outputResult, cmd.Format, globals.Pretty)
+}
+
+// aggregateFromStore fills the same dashboard fields as the API
+// aggregations and reports whether the store had values for the metric.
+func (cmd *MonitorDashboardCmd) aggregateFromStore(st *vitalsstore.Store, pkg, metric string, start, end time.Time, result *dashboardResult) (bool, error) {
+	found := false
+	series := func(metricSet, name string, dims map[string]string) ([]vitalsstore.Point, error) {
+		points, err := storeSeries(st, pkg, metricSet, name, dims, start, end)
+		found = found || len(points) > 0
+		return points, err
+	}
+	mean := func(metricSet, name string, dims map[string]string) (float64, error) {
+		points, err := series(metricSet, name, dims)
+		m, _ := vitalsstore.Mean(points)
+		return m, err
+	}
+	sum := func(metricSet, name string) (float64, error) {
+		points, err := series(metricSet, name, nil)
+		return vitalsstore.Sum(points), err
+	}
+
+	switch metric {
+	case metricCrashes:
+		rate, err := mean("crashRateMetricSet", metricCrashRate, nil)
+		if err != nil {
+			return false, err
+		}
+		users, err := sum("crashRateMetricSet", metricDistinctUsers)
+		if err != nil {
+			return false, err
+		}
+		result.Summary.AvgCrashRate = rate
+		result.Summary.AffectedUsers += int64(users)
+		result.Metrics["crashes"] = map[string]interface{}{"averageCrashRate": rate, "affectedUsers": int64(users)}
+	case metricAnrs:
+		rate, err := mean("anrRateMetricSet", metricAnrRate, nil)
+		if err != nil {
+			return false, err
+		}
+		users, err := sum("anrRateMetricSet", metricDistinctUsers)
+		if err != nil {
+			return false, err
+		}
+		result.Summary.AvgAnrRate = rate
+		result.Summary.AffectedUsers += int64(users)
+		result.Metrics["anrs"] = map[string]interface{}{"averageAnrRate": rate, "affectedUsers": int64(users)}
+	case metricErrors:
+		total, err := sum("errorCountMetricSet", "errorReportCount")
+		if err != nil {
+			return false, err
+		}
+		users, err := sum("errorCountMetricSet", metricDistinctUsers)
+		if err != nil {
+			return false, err
+		}
+		result.Summary.TotalErrors = int64(total)
+		result.Summary.AffectedUsers += int64(users)
+		result.Metrics["errors"] = map[string]interface{}{"totalErrors": int64(total), "affectedUsers": int64(users)}
+	case "slow-rendering":
+		rate, err := mean("slowRenderingRateMetricSet", "slowRenderingRate30Fps", nil)
+		if err != nil {
+			return false, err
+		}
+		result.Metrics["slowRendering"] = map[string]interface{}{"averageSlowRenderingRate": rate}
+	case "slow-start":
+		rate, err := mean("slowStartRateMetricSet", metricSlowStartRate, map[string]string{"startType": "COLD"})
+		if err != nil {
+			return false, err
+		}
+		result.Metrics["slowStart"] = map[string]interface{}{"averageSlowStartRate": rate}
+	case "wakeups":
+		rate, err := mean("excessiveWakeupRateMetricSet", metricExcessiveWakeupRate, nil)
+		if err != nil {
+			return false, err
+		}
+		result.Metrics["excessiveWakeups"] = map[string]interface{}{"averageExcessiveWakeupRate": rate}
+	case "wakelocks":
+		rate, err := mean("stuckBackgroundWakelockRateMetricSet", metricStuckBackgroundWakelockRate, nil)
+		if err != nil {
+			return false, err
+		}
+		result.Metrics["stuckWakelocks"] = map[string]interface{}{"averageStuckWakelockRate": rate}
+	case metricLmk:
+		rate, err := mean(metricSetLmkRate, metricUserPerceivedLmkRate, nil)
+		if err != nil {
+			return false, err
+		}
+		result.Metrics["lowMemoryKills"] = map[string]interface{}{"averageUserPerceivedLmkRate": rate}
+	}
+	return found, nil
+}
+
 func (cmd *MonitorDashboardCmd) normalizeDashboardMetrics() []string {
 	if len(cmd.Metrics) == 0 {
-		return []string{metricCrashes, metricAnrs, metricErrors, "slow-rendering", "slow-start", "wakeups", "wakelocks"}
+		return []string{metricCrashes, metricAnrs, metricErrors, "slow-rendering", "slow-start", "wakeups", "wakelocks", metricLmk}
 	}
 
 	var result []string
 	for _, m := range cmd.Metrics {
 		switch strings.ToLower(m) {
 		case checkAll:
-			return []string{metricCrashes, metricAnrs, metricErrors, "slow-rendering", "slow-start", "wakeups", "wakelocks"}
+			return []string{metricCrashes, metricAnrs, metricErrors, "slow-rendering", "slow-start", "wakeups", "wakelocks", metricLmk}
 		case metricCrashes, metricCrash:
 			result = append(result, metricCrashes)
 		case metricAnrs, metricAnr:
@@ -905,6 +1102,8 @@ func (cmd *MonitorDashboardCmd) normalizeDashboardMetrics() []string {
 			result = append(result, "wakeups")
 		case "wakelocks", "wakelock", "stuck-wakelocks":
 			result = append(result, "wakelocks")
+		case metricLmk, "lmks", "low-memory-kills":
+			result = append(result, metricLmk)
 		}
 	}
 	return result
@@ -1180,6 +1379,43 @@ func (cmd *MonitorDashboardCmd) aggregateWakelocks(ctx context.Context, client *
 	}
 }
 
+func (cmd *MonitorDashboardCmd) aggregateLmk(ctx context.Context, client *api.Client, svc *playdeveloperreporting.Service, pkg string, start, end time.Time, result *dashboardResult) {
+	name := fmt.Sprintf("apps/%s/%s", pkg, metricSetLmkRate)
+	req := &playdeveloperreporting.GooglePlayDeveloperReportingV1beta1QueryLmkRateMetricSetRequest{
+		TimelineSpec: cmd.buildTimelineSpec(start, end),
+		Metrics:      []string{metricUserPerceivedLmkRate},
+		PageSize:     100,
+	}
+
+	var totalRate float64
+	var count int
+
+	_ = client.DoWithRetry(ctx, func() error {
+		resp, err := svc.Vitals.Lmkrate.Query(name, req).Context(ctx).Do()
+		if err != nil {
+			return err
+		}
+		for _, row := range resp.Rows {
+			for _, m := range row.Metrics {
+				if m.Metric == metricUserPerceivedLmkRate {
+					totalRate += parseDecimalValue(m)
+					count++
+				}
+			}
+		}
+		return nil
+	})
+
+	avgRate := 0.0
+	if count > 0 {
+		avgRate = totalRate / float64(count)
+	}
+
+	result.Metrics["lowMemoryKills"] = map[string]interface{}{
+		"averageUserPerceivedLmkRate": avgRate,
+	}
+}
+
 func (cmd *MonitorDashboardCmd) calculateTrends(result *dashboardResult) {
 	// Simplified trend calculation based on metric values
 	// In a real implementation, you'd compare current period vs previous period
@@ -1249,6 +1485,7 @@ type reportSummary struct {
 	OverallHealth  string  `json:"overallHealth"`
 	CrashRate      float64 `json:"crashRate"`
 	AnrRate        float64 `json:"anrRate"`
+	LmkRate        float64 `json:"userPerceivedLmkRate"`
 	ErrorCount     int64   `json:"errorCount"`
 	ActiveUsers    int64   `json:"activeUsers"`
 	IssuesResolved int     `json:"issuesResolved"`
@@ -1360,9 +1597,16 @@ func (cmd *MonitorReportCmd) gatherReportData(ctx context.Context, client *api.C
 		result.Summary.IssuesOpen = issuesOpen
 	}
 
+	// Get low memory kill data
+	lmkRate, err := cmd.getReportLmkRate(ctx, client, svc, pkg, start, end)
+	if err == nil {
+		result.Summary.LmkRate = lmkRate
+	}
+
 	if cmd.IncludeRawData {
 		result.RawData["crashRate"] = crashRate
 		result.RawData["anrRate"] = anrRate
+		result.RawData["userPerceivedLmkRate"] = result.Summary.LmkRate
 		result.RawData["errorCount"] = errorCount
 		result.RawData["activeUsers"] = activeUsers
 	}
@@ -1444,6 +1688,43 @@ func (cmd *MonitorReportCmd) getReportAnrRate(ctx context.Context, client *api.C
 	return totalRate / float64(count), nil
 }
 
+func (cmd *MonitorReportCmd) getReportLmkRate(ctx context.Context, client *api.Client, svc *playdeveloperreporting.Service, pkg string, start, end time.Time) (float64, error) {
+	name := fmt.Sprintf("apps/%s/%s", pkg, metricSetLmkRate)
+	req := &playdeveloperreporting.GooglePlayDeveloperReportingV1beta1QueryLmkRateMetricSetRequest{
+		TimelineSpec: cmd.buildTimelineSpec(start, end),
+		Metrics:      []string{metricUserPerceivedLmkRate},
+		PageSize:     100,
+	}
+
+	var totalRate float64
+	var count int
+
+	err := client.DoWithRetry(ctx, func() error {
+		resp, err := svc.Vitals.Lmkrate.Query(name, req).Context(ctx).Do()
+		if err != nil {
+			return err
+		}
+		for _, row := range resp.Rows {
+			for _, m := range row.Metrics {
+				if m.Metric == metricUserPerceivedLmkRate {
+					totalRate += parseDecimalValue(m)
+					count++
+				}
+			}
+		}
+		return nil
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	if count == 0 {
+		return 0, nil
+	}
+	return totalRate / float64(count), nil
+}
+
 func (cmd *MonitorReportCmd) getReportErrorCount(ctx context.Context, client *api.Client, svc *playdeveloperreporting.Service, pkg string, start, end time.Time) (int64, error) {
 	name := fmt.Sprintf("apps/%s/errorCountMetricSet", pkg)
 	req := &playdeveloperreporting.GooglePlayDeveloperReportingV1beta1QueryErrorCountMetricSetRequest{
@@ -1563,6 +1844,10 @@ func (cmd *MonitorReportCmd) generateFindings(result *reportResult) {
 		findings = append(findings, fmt.Sprintf("High ANR rate detected: %.2f%% (threshold: 1%%)", result.Summary.AnrRate*100))
 	}
 
+	if result.Summary.LmkRate > 0.01 {
+		findings = append(findings, fmt.Sprintf("High low memory kill rate detected: %.2f%% (threshold: 1%%)", result.Summary.LmkRate*100))
+	}
+
 	if result.Summary.ErrorCount > 1000 {
 		findings = append(findings, fmt.Sprintf("High error volume: %d errors reported", result.Summary.ErrorCount))
 	}
@@ -1589,6 +1874,10 @@ func (cmd *MonitorReportCmd) generateRecommendations(result *reportResult) {
 		recommendations = append(recommendations, "Review ANR patterns - check main thread blocking operations and I/O on UI thread")
 	}
 
+	if result.Summary.LmkRate > 0.01 {
+		recommendations = append(recommendations, "Reduce memory use - check gpd vitals metrics lmk --dimensions deviceRamBucket for low-RAM devices")
+	}
+
 	if result.Summary.ErrorCount > 1000 {
 		recommendations = append(recommendations, "Investigate error patterns - use gpd vitals errors to analyze error clusters")
 	}
@@ -1626,81 +1915,6 @@ func (cmd *MonitorReportCmd) buildTimelineSpec(start, end time.Time) *playdevelo
 	}
 }
 
-// MonitorWebhooksCmd contains webhook management commands.
-type MonitorWebhooksCmd struct {
-	List MonitorWebhooksListCmd `cmd:"" help:"List configured webhooks (simulated)"`
-}
-
-// MonitorWebhooksListCmd lists configured webhooks.
-type MonitorWebhooksListCmd struct{}
-
-// webhookInfo represents webhook configuration.
-type webhookInfo struct {
-	ID         string    `json:"id"`
-	Name       string    `json:"name"`
-	URL        string    `json:"url"`
-	Events     []string  `json:"events"`
-	Active     bool      `json:"active"`
-	CreatedAt  time.Time `json:"createdAt"`
-	LastCalled time.Time `json:"lastCalled,omitempty"`
-	Status     string    `json:"status"`
-}
-
-// webhooksListResult represents the webhook list result.
-type webhooksListResult struct {
-	Webhooks   []webhookInfo `json:"webhooks"`
-	TotalCount int           `json:"totalCount"`
-	Note       string        `json:"note"`
-}
-
-// Run executes the webhooks list command.
-func (cmd *MonitorWebhooksListCmd) Run(globals *Globals) error {
-	// Google Play Console doesn't provide a public API for webhook management
-	// This is a simulated command that shows what webhooks would look like
-
-	startTime := time.Now()
-
-	result := &webhooksListResult{
-		Webhooks:   []webhookInfo{},
-		TotalCount: 0,
-		Note: "Google Play Console webhooks are configured through the Play Console UI, not via API. " +
-			"Use the Play Console > Setup > API access > Webhooks section to configure.",
-	}
-
-	// Example webhook entries showing what the format would be
-	exampleWebhooks := []webhookInfo{
-		{
-			ID:        "example-1",
-			Name:      "Crash Alerts",
-			URL:       "https://hooks.example.com/play/crashes",
-			Events:    []string{"vitals.crashes", "vitals.anrs"},
-			Active:    true,
-			CreatedAt: time.Now().AddDate(0, -3, 0),
-			Status:    "example-only",
-		},
-		{
-			ID:         "example-2",
-			Name:       "Release Notifications",
-			URL:        "https://hooks.example.com/play/releases",
-			Events:     []string{"publishing.releases"},
-			Active:     true,
-			CreatedAt:  time.Now().AddDate(0, -1, 0),
-			LastCalled: time.Now().AddDate(0, 0, -2),
-			Status:     "example-only",
-		},
-	}
-
-	result.Webhooks = exampleWebhooks
-	result.TotalCount = len(exampleWebhooks)
-
-	outputResult := output.NewResult(result).
-		WithDuration(time.Since(startTime)).
-		WithWarnings("Webhooks API not available - showing example format only").
-		WithServices("playdeveloperreporting")
-
-	return outputResultResult(outputResult, "json", globals.Pretty)
-}
-
 // parseDecimalValue parses a decimal value from the API response.
 func parseDecimalValue(m *playdeveloperreporting.GooglePlayDeveloperReportingV1beta1MetricValue) float64 {
 	if m == nil || m.DecimalValue == nil || m.DecimalValue.Value == "" {
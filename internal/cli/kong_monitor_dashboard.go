@@ -0,0 +1,321 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	playdeveloperreporting "google.golang.org/api/playdeveloperreporting/v1beta1"
+
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/api"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/compliance"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/dashboard"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/vitalsstore"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/errors"
+)
+
+// Table sizes of the HTML dashboard.
+const (
+	dashboardVersionRows = 10
+	dashboardDeviceRows  = 15
+	dashboardIssueRows   = 25
+)
+
+// htmlDashboardSource is what the HTML dashboard charts for one dashboard
+// metric.
+type htmlDashboardSource struct {
+	title     string
+	metricSet string
+	metrics   []string
+	// fixed are dimensions the API requires, with the value charted.
+	fixed map[string]string
+	rate  bool
+	// thresholdMetric is colored against threshold in the chart and
+	// broken down by version and device model; "" means no breakdowns.
+	thresholdMetric string
+	threshold       float64
+	deviceThreshold float64
+}
+
+var htmlDashboardSources = map[string]htmlDashboardSource{
+	metricCrashes: {
+		title: "Crashes", metricSet: "crashRateMetricSet", metrics: []string{metricCrashRate, "userPerceivedCrashRate"}, rate: true,
+		thresholdMetric: "userPerceivedCrashRate", threshold: compliance.OverallUserPerceivedCrashRate, deviceThreshold: compliance.DeviceUserPerceivedCrashRate,
+	},
+	metricAnrs: {
+		title: "ANRs", metricSet: "anrRateMetricSet", metrics: []string{metricAnrRate, "userPerceivedAnrRate"}, rate: true,
+		thresholdMetric: "userPerceivedAnrRate", threshold: compliance.OverallUserPerceivedAnrRate, deviceThreshold: compliance.DeviceUserPerceivedAnrRate,
+	},
+	metricErrors:     {title: "Error reports", metricSet: "errorCountMetricSet", metrics: []string{"errorReportCount"}},
+	"slow-rendering": {title: "Slow rendering", metricSet: "slowRenderingRateMetricSet", metrics: []string{"slowRenderingRate20Fps", "slowRenderingRate30Fps"}, rate: true},
+	"slow-start":     {title: "Slow cold start", metricSet: "slowStartRateMetricSet", metrics: []string{metricSlowStartRate}, fixed: map[string]string{"startType": "COLD"}, rate: true},
+	"wakeups":        {title: "Excessive wakeups", metricSet: "excessiveWakeupRateMetricSet", metrics: []string{metricExcessiveWakeupRate}, rate: true},
+	"wakelocks":      {title: "Stuck background wakelocks", metricSet: "stuckBackgroundWakelockRateMetricSet", metrics: []string{metricStuckBackgroundWakelockRate}, rate: true},
+	metricLmk:        {title: "Low memory kills", metricSet: metricSetLmkRate, metrics: []string{metricUserPerceivedLmkRate}, rate: true},
+}
+
+// dashboardPoints returns the daily points of a source's metric set in
+// the dashboard period, split by dimension in addition to the source's
+// fixed dimensions; "" returns app-level points.
+type dashboardPoints func(src htmlDashboardSource, dimension string) ([]vitalsstore.Point, error)
+
+// runHTML renders the self-contained HTML dashboard, from the API or the
+// local vitals store, to stdout or --output-file.
+func (cmd *MonitorDashboardCmd) runHTML(globals *Globals) error {
+	startTime := time.Now()
+	endDate := startTime.UTC()
+	startDate := endDate.AddDate(0, 0, -cmd.Period)
+	page := dashboard.Page{
+		Package:     globals.Package,
+		GeneratedAt: startTime,
+		PeriodDays:  cmd.Period,
+	}
+
+	var points dashboardPoints
+	if cmd.FromStore {
+		points = storeDashboardPoints(openVitalsStore(cmd.Store), globals.Package, startDate, endDate)
+		page.Notes = append(page.Notes, "Values from the local vitals store; error issues are only available from the API.")
+	} else {
+		ctx := globals.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		creds, err := newAuthManager().Authenticate(ctx, globals.KeyPath)
+		if err != nil {
+			return err
+		}
+		client, err := api.NewClient(ctx, creds.TokenSource,
+			api.WithTimeout(globals.Timeout),
+			api.WithVerboseLogging(globals.Verbose))
+		if err != nil {
+			return errors.NewAPIError(errors.CodeAuthFailure, fmt.Sprintf("failed to create API client: %v", err))
+		}
+		svc, err := client.PlayReporting()
+		if err != nil {
+			return errors.NewAPIError(errors.CodeGeneralError, fmt.Sprintf("failed to get reporting service: %v", err))
+		}
+		points = cmd.apiDashboardPoints(ctx, client, svc, globals.Package, startDate, endDate)
+		issues, err := dashboardIssues(ctx, client, svc, globals.Package, startDate, endDate)
+		if err != nil {
+			page.Notes = append(page.Notes, fmt.Sprintf("Error issues unavailable: %v", err))
+		}
+		page.Issues = issues
+	}
+
+	for _, metric := range cmd.normalizeDashboardMetrics() {
+		src, ok := htmlDashboardSources[metric]
+		if !ok {
+			continue
+		}
+		chart, breakdowns, err := buildDashboardSection(src, points)
+		if err != nil {
+			page.Notes = append(page.Notes, fmt.Sprintf("%s: %v", src.title, err))
+		}
+		page.Charts = append(page.Charts, chart)
+		page.Breakdowns = append(page.Breakdowns, breakdowns...)
+	}
+
+	var buf bytes.Buffer
+	if err := dashboard.Render(&buf, page); err != nil {
+		return errors.NewAPIError(errors.CodeGeneralError, fmt.Sprintf("failed to render dashboard: %v", err))
+	}
+	if cmd.OutputFile == "" {
+		_, err := os.Stdout.Write(buf.Bytes())
+		return err
+	}
+	if err := os.WriteFile(cmd.OutputFile, buf.Bytes(), 0o644); err != nil {
+		return errors.NewAPIError(errors.CodeGeneralError, fmt.Sprintf("failed to write dashboard: %v", err))
+	}
+	fmt.Fprintf(os.Stderr, "Dashboard saved to: %s\n", cmd.OutputFile)
+	return nil
+}
+
+// buildDashboardSection charts a source and, when it has a threshold
+// metric, breaks it down by version and device model.
+func buildDashboardSection(src htmlDashboardSource, points dashboardPoints) (dashboard.Chart, []dashboard.Breakdown, error) {
+	chart := dashboard.Chart{
+		Title:           src.title,
+		Rate:            src.rate,
+		Threshold:       src.threshold,
+		ThresholdSeries: src.thresholdMetric,
+		ThresholdLabel:  "Bad behavior threshold",
+	}
+	appLevel, err := points(src, "")
+	if err != nil {
+		return chart, nil, err
+	}
+	for _, metric := range src.metrics {
+		series := dashboard.Series{Name: metric}
+		for _, p := range vitalsstore.Filter(appLevel, vitalsstore.Query{Metric: metric, Dimensions: src.fixed}) {
+			series.Points = append(series.Points, dashboard.Point{Date: p.Date, Value: p.Value})
+		}
+		chart.Series = append(chart.Series, series)
+	}
+	if src.thresholdMetric == "" {
+		return chart, nil, nil
+	}
+
+	var breakdowns []dashboard.Breakdown
+	for _, b := range []struct {
+		dimension, label string
+		threshold        float64
+		limit            int
+	}{
+		{"versionCode", "version", src.threshold, dashboardVersionRows},
+		{"deviceModel", "device model", src.deviceThreshold, dashboardDeviceRows},
+	} {
+		split, err := points(src, b.dimension)
+		if err != nil {
+			return chart, breakdowns, err
+		}
+		breakdowns = append(breakdowns, dashboard.Breakdown{
+			Title:     fmt.Sprintf("%s by %s", src.title, b.label),
+			Dimension: b.dimension,
+			Metric:    src.thresholdMetric,
+			Threshold: b.threshold,
+			Rate:      src.rate,
+			Rows:      dashboard.Summarize(breakdownSamples(split, src.thresholdMetric, b.dimension), b.limit),
+		})
+	}
+	return chart, breakdowns, nil
+}
+
+// breakdownSamples pairs each daily value of metric with the distinct
+// users of the same day and dimensions, keyed by the dimension's value.
+func breakdownSamples(points []vitalsstore.Point, metric, dimension string) []dashboard.Sample {
+	users := make(map[string]float64)
+	for _, p := range points {
+		if p.Metric == metricDistinctUsers {
+			users[p.Date+"|"+vitalsstore.DimensionKey(p.Dimensions)] = p.Value
+		}
+	}
+	var samples []dashboard.Sample
+	for _, p := range points {
+		key, ok := p.Dimensions[dimension]
+		if p.Metric != metric || !ok {
+			continue
+		}
+		samples = append(samples, dashboard.Sample{
+			Key:   key,
+			Value: p.Value,
+			Users: users[p.Date+"|"+vitalsstore.DimensionKey(p.Dimensions)],
+		})
+	}
+	return samples
+}
+
+// dashboardDimensions are the dimensions a source is queried with.
+func dashboardDimensions(src htmlDashboardSource, dimension string) []string {
+	dims := make([]string, 0, len(src.fixed)+1)
+	for k := range src.fixed {
+		dims = append(dims, k)
+	}
+	if dimension != "" {
+		dims = append(dims, dimension)
+	}
+	sort.Strings(dims)
+	return dims
+}
+
+// matchesDimensions reports whether p is split by exactly dims, with the
+// source's fixed values.
+func matchesDimensions(p vitalsstore.Point, src htmlDashboardSource, dims []string) bool {
+	if len(p.Dimensions) != len(dims) {
+		return false
+	}
+	for _, d := range dims {
+		if _, ok := p.Dimensions[d]; !ok {
+			return false
+		}
+	}
+	for k, v := range src.fixed {
+		if p.Dimensions[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func (cmd *MonitorDashboardCmd) apiDashboardPoints(ctx context.Context, client *api.Client, svc *playdeveloperreporting.Service, pkg string, start, end time.Time) dashboardPoints {
+	return func(src htmlDashboardSource, dimension string) ([]vitalsstore.Point, error) {
+		metrics := src.metrics
+		if dimension != "" {
+			metrics = []string{src.thresholdMetric}
+		}
+		metrics = append(append([]string(nil), metrics...), metricDistinctUsers)
+		dims := dashboardDimensions(src, dimension)
+		rows, err := queryMetricSetRows(ctx, client, svc, pkg, src.metricSet, cmd.buildTimelineSpec(start, end), dims, metrics)
+		if err != nil {
+			return nil, err
+		}
+		var points []vitalsstore.Point
+		for _, p := range storePoints(rows, time.Now().UTC()) {
+			if matchesDimensions(p, src, dims) {
+				points = append(points, p)
+			}
+		}
+		return points, nil
+	}
+}
+
+// storeDashboardPoints reads points from the vitals store. Breakdowns need
+// values snapshotted with --dimensions versionCode or deviceModel.
+func storeDashboardPoints(st *vitalsstore.Store, pkg string, start, end time.Time) dashboardPoints {
+	from, to := start.Format(vitalsstore.DateLayout), end.Format(vitalsstore.DateLayout)
+	return func(src htmlDashboardSource, dimension string) ([]vitalsstore.Point, error) {
+		stored, err := st.Read(pkg, src.metricSet)
+		if err != nil {
+			return nil, errors.NewAPIError(errors.CodeGeneralError, fmt.Sprintf("failed to read vitals store: %v", err))
+		}
+		dims := dashboardDimensions(src, dimension)
+		var points []vitalsstore.Point
+		for _, p := range stored {
+			if p.Date >= from && p.Date <= to && matchesDimensions(p, src, dims) {
+				points = append(points, p)
+			}
+		}
+		return points, nil
+	}
+}
+
+// dashboardIssues returns the error issues active in the period with the
+// most affected users, linked to the Play Console.
+func dashboardIssues(ctx context.Context, client *api.Client, svc *playdeveloperreporting.Service, pkg string, start, end time.Time) ([]dashboard.Issue, error) {
+	parent := fmt.Sprintf("apps/%s/errorIssues", pkg)
+	filter := fmt.Sprintf("activeBetween(%q, %q)",
+		start.Format("2006-01-02")+"T00:00:00Z", end.Format("2006-01-02")+"T00:00:00Z")
+	var resp *playdeveloperreporting.GooglePlayDeveloperReportingV1beta1SearchErrorIssuesResponse
+	err := client.DoWithRetry(ctx, func() error {
+		var err error
+		resp, err = svc.Vitals.Errors.Issues.Search(parent).Context(ctx).Filter(filter).PageSize(100).Do()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toDashboardIssues(resp.ErrorIssues, dashboardIssueRows), nil
+}
+
+// toDashboardIssues converts error issues, most affected users first.
+func toDashboardIssues(issues []*playdeveloperreporting.GooglePlayDeveloperReportingV1beta1ErrorIssue, limit int) []dashboard.Issue {
+	out := make([]dashboard.Issue, 0, len(issues))
+	for _, issue := range issues {
+		out = append(out, dashboard.Issue{
+			Type:         strings.ToUpper(issue.Type),
+			Cause:        issue.Cause,
+			Location:     issue.Location,
+			ErrorReports: issue.ErrorReportCount,
+			Users:        issue.DistinctUsers,
+			URL:          issue.IssueUri,
+		})
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Users > out[j].Users })
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
@@ -0,0 +1,83 @@
+//go:build unit
+// +build unit
+
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	playdeveloperreporting "google.golang.org/api/playdeveloperreporting/v1beta1"
+
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/vitalsstore"
+)
+
+func TestMonitorDashboardCmd_HTMLFromStore(t *testing.T) {
+	dir := t.TempDir()
+	today := time.Now().UTC()
+	var points []vitalsstore.Point
+	for i := 0; i < 3; i++ {
+		date := today.AddDate(0, 0, i-2).Format(vitalsstore.DateLayout)
+		points = append(points,
+			vitalsstore.Point{Date: date, Metric: metricCrashRate, Value: 0.01},
+			vitalsstore.Point{Date: date, Metric: "userPerceivedCrashRate", Value: 0.004 * float64(i+1)},
+			vitalsstore.Point{Date: date, Metric: "userPerceivedCrashRate", Dimensions: map[string]string{"versionCode": "31"}, Value: 0.02},
+			vitalsstore.Point{Date: date, Metric: metricDistinctUsers, Dimensions: map[string]string{"versionCode": "31"}, Value: 900},
+			vitalsstore.Point{Date: date, Metric: "userPerceivedCrashRate", Dimensions: map[string]string{"versionCode": "30"}, Value: 0.001},
+			vitalsstore.Point{Date: date, Metric: metricDistinctUsers, Dimensions: map[string]string{"versionCode": "30"}, Value: 100},
+		)
+	}
+	if _, err := vitalsstore.Open(dir).Append("com.example.app", "crashRateMetricSet", points); err != nil {
+		t.Fatal(err)
+	}
+
+	file := filepath.Join(t.TempDir(), "dashboard.html")
+	cmd := &MonitorDashboardCmd{Metrics: []string{"crashes", "anrs"}, Period: 7, Format: "html", FromStore: true, Store: dir, OutputFile: file}
+	if err := cmd.Run(&Globals{Package: "com.example.app", KeyPath: "/nonexistent/key.json"}); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(data)
+	for _, want := range []string{
+		"<h2>Crashes</h2>",
+		"<h2>ANRs</h2>",
+		"userPerceivedCrashRate: <span class=\"badge sev-critical\">1.20%",
+		"<h2>Crashes by version</h2>",
+		"<td>31</td><td class=\"num\"><span class=\"badge sev-critical\">2.00%",
+		"<td>30</td>",
+		"<h2>Crashes by device model</h2>",
+		"error issues are only available from the API",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("dashboard missing %q", want)
+		}
+	}
+	if strings.Index(html, "<td>31</td>") > strings.Index(html, "<td>30</td>") {
+		t.Error("versions should be ordered by users")
+	}
+}
+
+func TestMonitorDashboardCmd_OutputFileRequiresHTML(t *testing.T) {
+	cmd := &MonitorDashboardCmd{Format: "json", FromStore: true, Store: t.TempDir(), OutputFile: "out.json"}
+	err := cmd.Run(&Globals{Package: "com.example.app"})
+	if err == nil || !strings.Contains(err.Error(), "--format html") {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestToDashboardIssues(t *testing.T) {
+	issues := toDashboardIssues([]*playdeveloperreporting.GooglePlayDeveloperReportingV1beta1ErrorIssue{
+		{Type: "ANR", Cause: "Input dispatching timed out", DistinctUsers: 5, ErrorReportCount: 9},
+		{Type: "CRASH", Cause: "java.lang.NullPointerException", DistinctUsers: 50, ErrorReportCount: 70, IssueUri: "https://play.google.com/console/issue/1"},
+		{Type: "CRASH", Cause: "java.lang.OutOfMemoryError", DistinctUsers: 20},
+	}, 2)
+	if len(issues) != 2 || issues[0].Users != 50 || issues[0].URL != "https://play.google.com/console/issue/1" || issues[1].Users != 20 {
+		t.Errorf("issues = %+v", issues)
+	}
+}
@@ -0,0 +1,384 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+	"unicode"
+
+	"google.golang.org/api/androidpublisher/v3"
+	playdeveloperreporting "google.golang.org/api/playdeveloperreporting/v1beta1"
+
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/api"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/openmetrics"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/errors"
+)
+
+// MonitorServeCmd polls Play for a set of packages and serves the results
+// as OpenMetrics gauges for Prometheus-compatible scrapers.
+type MonitorServeCmd struct {
+	Listen      string        `help:"Address to serve /metrics on" default:":9464"`
+	Packages    []string      `help:"Packages to export (repeatable, default: --package)"`
+	Interval    time.Duration `help:"Poll interval; vitals are daily, so polling faster adds quota use, not freshness" default:"15m"`
+	Days        int           `help:"Days of daily vitals searched for the latest value" default:"7"`
+	IssueWindow int           `help:"Days an error issue must have had reports in to count as open" default:"7"`
+	Once        bool          `help:"Poll once, write the metrics to stdout and exit"`
+}
+
+// Run executes the serve command. It polls until interrupted.
+func (cmd *MonitorServeCmd) Run(globals *Globals) error {
+	packages := cmd.Packages
+	if len(packages) == 0 && globals.Package != "" {
+		packages = []string{globals.Package}
+	}
+	if len(packages) == 0 {
+		return errors.NewAPIError(errors.CodeValidationError, "at least one package is required").
+			WithHint("Pass --packages (repeatable) or --package")
+	}
+	if !cmd.Once && cmd.Interval < time.Minute {
+		return errors.NewAPIError(errors.CodeValidationError, "--interval must be at least 1m")
+	}
+	if cmd.Days < 1 || cmd.IssueWindow < 1 {
+		return errors.NewAPIError(errors.CodeValidationError, "--days and --issue-window must be at least 1")
+	}
+
+	ctx := globals.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	authMgr := newAuthManager()
+	creds, err := authMgr.Authenticate(ctx, globals.KeyPath)
+	if err != nil {
+		return err
+	}
+	client, err := api.NewClient(ctx, creds.TokenSource,
+		api.WithTimeout(globals.Timeout),
+		api.WithVerboseLogging(globals.Verbose))
+	if err != nil {
+		return errors.NewAPIError(errors.CodeAuthFailure, fmt.Sprintf("failed to create API client: %v", err))
+	}
+	reporting, err := client.PlayReporting()
+	if err != nil {
+		return errors.NewAPIError(errors.CodeGeneralError, fmt.Sprintf("failed to get reporting service: %v", err))
+	}
+	publisher, err := client.AndroidPublisher()
+	if err != nil {
+		return errors.NewAPIError(errors.CodeGeneralError, fmt.Sprintf("failed to get publisher service: %v", err))
+	}
+	exp := &playExporter{
+		client: client, reporting: reporting, publisher: publisher,
+		days: cmd.Days, issueWindow: cmd.IssueWindow, verbose: globals.Verbose,
+	}
+
+	if cmd.Once {
+		return openmetrics.Write(os.Stdout, exp.poll(ctx, packages).Families())
+	}
+
+	ln, err := net.Listen("tcp", cmd.Listen)
+	if err != nil {
+		return errors.NewAPIError(errors.CodeGeneralError, fmt.Sprintf("failed to listen on %s: %v", cmd.Listen, err))
+	}
+	registry := &openmetrics.Registry{}
+	registry.Update(exp.poll(ctx, packages))
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", registry)
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- srv.Serve(ln) }()
+	fmt.Fprintf(os.Stderr, "Serving OpenMetrics for %s on http://%s/metrics\n", strings.Join(packages, ", "), ln.Addr())
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ticker := time.NewTicker(cmd.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			registry.Update(exp.poll(ctx, packages))
+		case err := <-serveErr:
+			return errors.NewAPIError(errors.CodeGeneralError, fmt.Sprintf("metrics server stopped: %v", err))
+		case <-ctx.Done():
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			return srv.Shutdown(shutdownCtx)
+		}
+	}
+}
+
+// playExporter polls the Play APIs into OpenMetrics gauges.
+type playExporter struct {
+	client      *api.Client
+	reporting   *playdeveloperreporting.Service
+	publisher   *androidpublisher.Service
+	days        int
+	issueWindow int
+	verbose     bool
+}
+
+// poll collects every source for every package. A failing source is
+// reported through gpd_exporter_source_up instead of failing the poll, so
+// one missing permission does not blank the other series.
+func (e *playExporter) poll(ctx context.Context, packages []string) *openmetrics.Set {
+	set := openmetrics.NewSet()
+	for _, pkg := range packages {
+		sources := []struct {
+			name    string
+			collect func(context.Context, *openmetrics.Set, string) error
+		}{
+			{"vitals", e.collectVitals},
+			{"error_issues", e.collectErrorIssues},
+			{"reviews", e.collectReviews},
+			{"tracks", e.collectTracks},
+		}
+		for _, src := range sources {
+			up := 1.0
+			if err := src.collect(ctx, set, pkg); err != nil {
+				up = 0
+				if e.verbose {
+					fmt.Fprintf(os.Stderr, "monitor serve: %s %s: %v\n", pkg, src.name, err)
+				}
+			}
+			set.Gauge("gpd_exporter_source_up", "Whether the last poll of a source succeeded.", up,
+				openmetrics.Label{Name: "package", Value: pkg}, openmetrics.Label{Name: "source", Value: src.name})
+		}
+		set.Gauge("gpd_exporter_last_poll_timestamp_seconds", "Unix time of the last poll.", float64(time.Now().Unix()),
+			openmetrics.Label{Name: "package", Value: pkg})
+	}
+	return set
+}
+
+// collectVitals exports the latest day of every vitals metric set, app-wide
+// and per version code.
+func (e *playExporter) collectVitals(ctx context.Context, set *openmetrics.Set, pkg string) error {
+	end := time.Now().UTC()
+	spec, err := buildTimelineSpec(end.AddDate(0, 0, -e.days).Format("2006-01-02"), end.Format("2006-01-02"))
+	if err != nil {
+		return err
+	}
+	var firstErr error
+	for _, ms := range vitalsMetricSets {
+		for _, dims := range [][]string{ms.dimensions, mergeDimensions(ms.dimensions, []string{"versionCode"})} {
+			rows, err := queryMetricSetRows(ctx, e.client, e.reporting, pkg, ms.name, spec, dims, ms.metrics)
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("%s: %w", ms.name, err)
+				}
+				break
+			}
+			vitalsGauges(set, pkg, rows)
+		}
+	}
+	return firstErr
+}
+
+// vitalsGauges adds a gpd_vitals_<metric> gauge per row of the latest day
+// in rows. Rows without a versionCode dimension are labelled version="all";
+// other dimensions become snake_case labels.
+func vitalsGauges(set *openmetrics.Set, pkg string, rows []*playdeveloperreporting.GooglePlayDeveloperReportingV1beta1MetricsRow) {
+	latest := ""
+	for _, row := range rows {
+		if d := reportingDate(row.StartTime); d > latest {
+			latest = d
+		}
+	}
+	for _, row := range rows {
+		if reportingDate(row.StartTime) != latest {
+			continue
+		}
+		dims := rowDimensions(row)
+		version := dims["versionCode"]
+		if version == "" {
+			version = "all"
+		}
+		labels := []openmetrics.Label{{Name: "package", Value: pkg}, {Name: "version", Value: version}}
+		names := make([]string, 0, len(dims))
+		for name := range dims {
+			if name != "versionCode" {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			labels = append(labels, openmetrics.Label{Name: snakeCase(name), Value: dims[name]})
+		}
+		for _, m := range row.Metrics {
+			// distinctUsers is reported by every set and would collide.
+			if m.Metric == metricDistinctUsers || m.DecimalValue == nil || m.DecimalValue.Value == "" {
+				continue
+			}
+			set.Gauge("gpd_vitals_"+snakeCase(m.Metric),
+				fmt.Sprintf("Latest daily %s from the Play Developer Reporting API.", m.Metric),
+				parseDecimalValue(m), labels...)
+		}
+	}
+}
+
+// collectErrorIssues exports the number of error issues with reports in
+// the issue window, by type.
+func (e *playExporter) collectErrorIssues(ctx context.Context, set *openmetrics.Set, pkg string) error {
+	parent := fmt.Sprintf("apps/%s/errorIssues", pkg)
+	filter := fmt.Sprintf("activeBetween(%q, %q)",
+		time.Now().AddDate(0, 0, -e.issueWindow).Format("2006-01-02")+"T00:00:00Z",
+		time.Now().Format("2006-01-02")+"T00:00:00Z")
+	var issues []*playdeveloperreporting.GooglePlayDeveloperReportingV1beta1ErrorIssue
+	pageToken := ""
+	for {
+		var resp *playdeveloperreporting.GooglePlayDeveloperReportingV1beta1SearchErrorIssuesResponse
+		err := e.client.DoWithRetry(ctx, func() error {
+			call := e.reporting.Vitals.Errors.Issues.Search(parent).Context(ctx).Filter(filter).PageSize(1000)
+			if pageToken != "" {
+				call = call.PageToken(pageToken)
+			}
+			var err error
+			resp, err = call.Do()
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		issues = append(issues, resp.ErrorIssues...)
+		if resp.NextPageToken == "" {
+			break
+		}
+		pageToken = resp.NextPageToken
+	}
+	issueGauges(set, pkg, issues)
+	return nil
+}
+
+// issueGauges adds gpd_vitals_open_error_issues per issue type.
+func issueGauges(set *openmetrics.Set, pkg string, issues []*playdeveloperreporting.GooglePlayDeveloperReportingV1beta1ErrorIssue) {
+	counts := map[string]int{"CRASH": 0, "ANR": 0}
+	for _, issue := range issues {
+		counts[issue.Type]++
+	}
+	for typ, n := range counts {
+		set.Gauge("gpd_vitals_open_error_issues", "Error issues with reports in the issue window.", float64(n),
+			openmetrics.Label{Name: "package", Value: pkg}, openmetrics.Label{Name: "type", Value: strings.ToLower(typ)})
+	}
+}
+
+// collectReviews exports the average star rating of the reviews the API
+// returns, which covers roughly the last week.
+func (e *playExporter) collectReviews(ctx context.Context, set *openmetrics.Set, pkg string) error {
+	var reviews []*androidpublisher.Review
+	token := ""
+	for {
+		var resp *androidpublisher.ReviewsListResponse
+		err := e.client.DoWithRetry(ctx, func() error {
+			call := e.publisher.Reviews.List(pkg).Context(ctx)
+			if token != "" {
+				call = call.Token(token)
+			}
+			var err error
+			resp, err = call.Do()
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		reviews = append(reviews, resp.Reviews...)
+		if resp.TokenPagination == nil || resp.TokenPagination.NextPageToken == "" {
+			break
+		}
+		token = resp.TokenPagination.NextPageToken
+	}
+	reviewGauges(set, pkg, reviews)
+	return nil
+}
+
+// reviewGauges adds gpd_reviews_recent and, when any review has a rating,
+// gpd_reviews_average_rating.
+func reviewGauges(set *openmetrics.Set, pkg string, reviews []*androidpublisher.Review) {
+	var sum, n float64
+	for _, r := range reviews {
+		for _, c := range r.Comments {
+			if c.UserComment != nil && c.UserComment.StarRating > 0 {
+				sum += float64(c.UserComment.StarRating)
+				n++
+				break
+			}
+		}
+	}
+	label := openmetrics.Label{Name: "package", Value: pkg}
+	set.Gauge("gpd_reviews_recent", "Rated reviews returned by the API (about the last week).", n, label)
+	if n > 0 {
+		set.Gauge("gpd_reviews_average_rating", "Average star rating of recent reviews.", sum/n, label)
+	}
+}
+
+// collectTracks exports the rollout fraction of every release through a
+// read-only edit, which is deleted afterwards.
+func (e *playExporter) collectTracks(ctx context.Context, set *openmetrics.Set, pkg string) error {
+	var edit *androidpublisher.AppEdit
+	err := e.client.DoWithRetry(ctx, func() error {
+		var err error
+		edit, err = e.publisher.Edits.Insert(pkg, &androidpublisher.AppEdit{}).Context(ctx).Do()
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = e.client.DoWithRetry(ctx, func() error {
+			return e.publisher.Edits.Delete(pkg, edit.Id).Context(ctx).Do()
+		})
+	}()
+	var tracks *androidpublisher.TracksListResponse
+	err = e.client.DoWithRetry(ctx, func() error {
+		var err error
+		tracks, err = e.publisher.Edits.Tracks.List(pkg, edit.Id).Context(ctx).Do()
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	rolloutGauges(set, pkg, tracks.Tracks)
+	return nil
+}
+
+// rolloutGauges adds gpd_rollout_fraction per track and version code of
+// every non-draft release; completed releases are at 1.
+func rolloutGauges(set *openmetrics.Set, pkg string, tracks []*androidpublisher.Track) {
+	for _, track := range tracks {
+		for _, release := range track.Releases {
+			fraction := release.UserFraction
+			switch release.Status {
+			case "draft":
+				continue
+			case "completed":
+				fraction = 1
+			}
+			for _, vc := range release.VersionCodes {
+				set.Gauge("gpd_rollout_fraction", "Share of users a release is rolled out to.", fraction,
+					openmetrics.Label{Name: "package", Value: pkg},
+					openmetrics.Label{Name: "track", Value: track.Track},
+					openmetrics.Label{Name: "version", Value: fmt.Sprintf("%d", vc)},
+					openmetrics.Label{Name: "status", Value: release.Status})
+			}
+		}
+	}
+}
+
+// snakeCase converts a Reporting API name such as slowRenderingRate20Fps to
+// slow_rendering_rate_20_fps.
+func snakeCase(s string) string {
+	var b strings.Builder
+	var prev rune
+	for i, r := range s {
+		if i > 0 && (unicode.IsUpper(r) || (unicode.IsDigit(r) && !unicode.IsDigit(prev))) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToLower(r))
+		prev = r
+	}
+	return b.String()
+}
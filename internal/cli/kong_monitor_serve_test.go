@@ -0,0 +1,112 @@
+//go:build unit
+// +build unit
+
+package cli
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/api/androidpublisher/v3"
+	playdeveloperreporting "google.golang.org/api/playdeveloperreporting/v1beta1"
+
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/openmetrics"
+)
+
+func exposition(t *testing.T, set *openmetrics.Set) string {
+	t.Helper()
+	var b strings.Builder
+	if err := openmetrics.Write(&b, set.Families()); err != nil {
+		t.Fatal(err)
+	}
+	return b.String()
+}
+
+func TestMonitorServeCmd_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		cmd  MonitorServeCmd
+		pkg  string
+	}{
+		{"no package", MonitorServeCmd{Interval: 15 * time.Minute, Days: 7, IssueWindow: 7}, ""},
+		{"short interval", MonitorServeCmd{Interval: time.Second, Days: 7, IssueWindow: 7}, "com.example.app"},
+		{"zero days", MonitorServeCmd{Once: true, Days: 0, IssueWindow: 7}, "com.example.app"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cmd.Run(&Globals{Package: tt.pkg, KeyPath: "/nonexistent/key.json"})
+			if err == nil {
+				t.Fatal("expected a validation error")
+			}
+		})
+	}
+}
+
+func TestVitalsGauges(t *testing.T) {
+	slow := canaryRow(14, map[string]string{"versionCode": "31", "startType": "COLD"}, metricSlowStartRate, "0.2")
+	slow.Metrics = append(slow.Metrics, &playdeveloperreporting.GooglePlayDeveloperReportingV1beta1MetricValue{
+		Metric: metricDistinctUsers, DecimalValue: &playdeveloperreporting.GoogleTypeDecimal{Value: "900"},
+	})
+	rows := []*playdeveloperreporting.GooglePlayDeveloperReportingV1beta1MetricsRow{
+		canaryRow(13, map[string]string{"versionCode": "30", "startType": "COLD"}, metricSlowStartRate, "0.5"),
+		slow,
+		canaryRow(14, nil, "slowRenderingRate20Fps", "0.03"),
+	}
+	set := openmetrics.NewSet()
+	vitalsGauges(set, "com.example.app", rows)
+	got := exposition(t, set)
+	for _, want := range []string{
+		`gpd_vitals_slow_start_rate{package="com.example.app",version="31",start_type="COLD"} 0.2`,
+		`gpd_vitals_slow_rendering_rate_20_fps{package="com.example.app",version="all"} 0.03`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %s in\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, `version="30"`) || strings.Contains(got, "distinct_users") {
+		t.Errorf("stale day or distinctUsers exported:\n%s", got)
+	}
+}
+
+func TestReviewAndRolloutGauges(t *testing.T) {
+	set := openmetrics.NewSet()
+	reviewGauges(set, "com.example.app", []*androidpublisher.Review{
+		{Comments: []*androidpublisher.Comment{{UserComment: &androidpublisher.UserComment{StarRating: 5}}}},
+		{Comments: []*androidpublisher.Comment{{UserComment: &androidpublisher.UserComment{StarRating: 2}}}},
+		{Comments: []*androidpublisher.Comment{{DeveloperComment: &androidpublisher.DeveloperComment{Text: "thanks"}}}},
+	})
+	rolloutGauges(set, "com.example.app", []*androidpublisher.Track{
+		{Track: "production", Releases: []*androidpublisher.TrackRelease{
+			{Status: "inProgress", UserFraction: 0.2, VersionCodes: []int64{31}},
+			{Status: "completed", VersionCodes: []int64{30}},
+			{Status: "draft", VersionCodes: []int64{32}},
+		}},
+	})
+	got := exposition(t, set)
+	for _, want := range []string{
+		`gpd_reviews_average_rating{package="com.example.app"} 3.5`,
+		`gpd_reviews_recent{package="com.example.app"} 2`,
+		`gpd_rollout_fraction{package="com.example.app",track="production",version="30",status="completed"} 1`,
+		`gpd_rollout_fraction{package="com.example.app",track="production",version="31",status="inProgress"} 0.2`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %s in\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, `version="32"`) {
+		t.Errorf("draft release exported:\n%s", got)
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	for in, want := range map[string]string{
+		"userPerceivedLmkRate":   "user_perceived_lmk_rate",
+		"slowRenderingRate30Fps": "slow_rendering_rate_30_fps",
+		"startType":              "start_type",
+	} {
+		if got := snakeCase(in); got != want {
+			t.Errorf("snakeCase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
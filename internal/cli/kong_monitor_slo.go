@@ -0,0 +1,299 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	playdeveloperreporting "google.golang.org/api/playdeveloperreporting/v1beta1"
+
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/api"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/alerting"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/slo"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/vitalsstore"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/config"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/errors"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/output"
+)
+
+// sloLagDays is how many days before today an SLO query starts beyond its
+// window, since the latest vitals are a day or two old.
+const sloLagDays = 3
+
+// sloSource is the metric set and rate metric behind an SLO metric.
+type sloSource struct {
+	metricSet string
+	rate      string
+}
+
+var sloSources = map[string]sloSource{
+	"crash-free-users":                {metricSet: "crashRateMetricSet", rate: metricCrashRate},
+	"user-perceived-crash-free-users": {metricSet: "crashRateMetricSet", rate: "userPerceivedCrashRate"},
+	"anr-free-users":                  {metricSet: "anrRateMetricSet", rate: metricAnrRate},
+	"user-perceived-anr-free-users":   {metricSet: "anrRateMetricSet", rate: "userPerceivedAnrRate"},
+}
+
+// MonitorSLOCmd checks user-weighted SLOs and their error budgets.
+type MonitorSLOCmd struct {
+	Name            []string `help:"Only check the configured SLOs with these names"`
+	Objective       float64  `help:"Check one ad hoc SLO with this target percentage of good users, e.g. 99.5, instead of the configured SLOs"`
+	Metric          string   `help:"Metric of the ad hoc SLO" default:"crash-free-users" enum:"crash-free-users,user-perceived-crash-free-users,anr-free-users,user-perceived-anr-free-users"`
+	WindowDays      int      `help:"Compliance window of the ad hoc SLO in days" default:"28"`
+	AlertOnBreaches bool     `help:"Exit with error code when an SLO burns its error budget too fast or exhausts it"`
+	Notify          bool     `help:"Deliver breaches to the alert sinks configured for the profile"`
+	Format          string   `help:"Output format: json, table, html" default:"json" enum:"json,table,html"`
+	FromStore       bool     `help:"Read daily values from the local vitals store (gpd vitals snapshot) instead of the API"`
+	Store           string   `help:"Vitals store directory (default: <data dir>/vitals)"`
+}
+
+// sloResult is the evaluation of every SLO of an app.
+type sloResult struct {
+	Package       string              `json:"package"`
+	Timestamp     time.Time           `json:"timestamp"`
+	SLOs          []slo.Status        `json:"slos"`
+	Breached      int                 `json:"breached"`
+	Notifications []alerting.Delivery `json:"notifications,omitempty"`
+}
+
+// sloDays reads the daily rate and user counts of an SLO source.
+type sloDays func(src sloSource, start, end time.Time) ([]slo.Day, error)
+
+// Run executes the slo command. The result is always written; with
+// --alert-on-breaches a breached SLO then returns a validation error, like
+// vitals canary, so CI and cron jobs can page on it.
+func (cmd *MonitorSLOCmd) Run(globals *Globals) error {
+	if err := requirePackage(globals.Package); err != nil {
+		return err
+	}
+	objectives, err := cmd.objectives(globals.Package)
+	if err != nil {
+		return err
+	}
+	var notifier *alerting.Notifier
+	if cmd.Notify {
+		if notifier, err = profileNotifier(globals); err != nil {
+			return err
+		}
+	}
+
+	ctx := globals.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var days sloDays
+	if cmd.FromStore {
+		days = storeSLODays(openVitalsStore(cmd.Store), globals.Package)
+	} else {
+		creds, err := newAuthManager().Authenticate(ctx, globals.KeyPath)
+		if err != nil {
+			return err
+		}
+		client, err := api.NewClient(ctx, creds.TokenSource,
+			api.WithTimeout(globals.Timeout),
+			api.WithVerboseLogging(globals.Verbose))
+		if err != nil {
+			return errors.NewAPIError(errors.CodeAuthFailure, fmt.Sprintf("failed to create API client: %v", err))
+		}
+		svc, err := client.PlayReporting()
+		if err != nil {
+			return errors.NewAPIError(errors.CodeGeneralError, fmt.Sprintf("failed to get reporting service: %v", err))
+		}
+		days = apiSLODays(ctx, client, svc, globals.Package)
+	}
+
+	startTime := time.Now()
+	result := &sloResult{Package: globals.Package, Timestamp: startTime, SLOs: []slo.Status{}}
+	end := time.Now().UTC()
+	for _, o := range objectives {
+		src := sloSources[o.Metric]
+		series, err := days(src, end.AddDate(0, 0, -o.WindowDays-sloLagDays), end)
+		if err != nil {
+			return errors.NewAPIError(errors.CodeGeneralError, fmt.Sprintf("failed to read %s: %v", src.metricSet, err))
+		}
+		st := slo.Evaluate(o, series)
+		if st.Breached() {
+			result.Breached++
+		}
+		result.SLOs = append(result.SLOs, st)
+	}
+	if notifier != nil && result.Breached > 0 {
+		result.Notifications = notifier.Notify(ctx, sloAlerts(globals.Package, result.SLOs, startTime))
+	}
+
+	res := output.NewResult(result).
+		WithDuration(time.Since(startTime)).
+		WithServices("playdeveloperreporting")
+	if result.Breached > 0 {
+		res = res.WithWarnings(fmt.Sprintf("%d SLOs breached: %s", result.Breached, strings.Join(breachedSLOs(result.SLOs), ", ")))
+	}
+	if err := outputResultResult(res, cmd.Format, globals.Pretty); err != nil {
+		return err
+	}
+	if result.Breached > 0 && cmd.AlertOnBreaches {
+		return errors.NewAPIError(errors.CodeValidationError,
+			fmt.Sprintf("SLO breached: %s", strings.Join(breachedSLOs(result.SLOs), ", ")))
+	}
+	return nil
+}
+
+// objectives returns the validated SLOs to check: the ad hoc one, or the
+// configured ones for pkg.
+func (cmd *MonitorSLOCmd) objectives(pkg string) ([]slo.Objective, error) {
+	if cmd.Objective != 0 {
+		o := slo.Objective{Name: cmd.Metric, Metric: cmd.Metric, Target: cmd.Objective, WindowDays: cmd.WindowDays}
+		if err := o.Validate(); err != nil {
+			return nil, errors.NewAPIError(errors.CodeValidationError, err.Error())
+		}
+		return []slo.Objective{o}, nil
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, errors.NewAPIError(errors.CodeGeneralError, fmt.Sprintf("failed to load config: %v", err)).
+			WithHint("Fix or remove the file shown by gpd config path")
+	}
+	configured := cfg.SLOsFor(pkg)
+	if len(configured) == 0 {
+		return nil, errors.NewAPIError(errors.CodeValidationError, fmt.Sprintf("no SLOs configured for %s", pkg)).
+			WithHint(`Add "slos": [{"name": "crash-free", "objective": 99.5, "windowDays": 28}] to the file shown by gpd config path, or pass --objective`)
+	}
+	wanted := make(map[string]bool, len(cmd.Name))
+	for _, name := range cmd.Name {
+		wanted[name] = true
+	}
+	var out []slo.Objective
+	for _, c := range configured {
+		if len(wanted) > 0 && !wanted[c.Name] {
+			continue
+		}
+		o := slo.Objective{Name: c.Name, Metric: c.Metric, Target: c.Objective, WindowDays: c.WindowDays}
+		if o.Metric == "" {
+			o.Metric = "crash-free-users"
+		}
+		if _, ok := sloSources[o.Metric]; !ok {
+			return nil, errors.NewAPIError(errors.CodeValidationError, fmt.Sprintf("SLO %q: unknown metric %q", c.Name, c.Metric)).
+				WithHint("Use crash-free-users, user-perceived-crash-free-users, anr-free-users or user-perceived-anr-free-users")
+		}
+		if c.FastBurn != nil {
+			o.Fast = slo.Burn{LongDays: c.FastBurn.LongWindowDays, ShortDays: c.FastBurn.ShortWindowDays, Rate: c.FastBurn.BurnRate}
+		}
+		if c.SlowBurn != nil {
+			o.Slow = slo.Burn{LongDays: c.SlowBurn.LongWindowDays, ShortDays: c.SlowBurn.ShortWindowDays, Rate: c.SlowBurn.BurnRate}
+		}
+		if err := o.Validate(); err != nil {
+			return nil, errors.NewAPIError(errors.CodeValidationError, err.Error())
+		}
+		out = append(out, o)
+	}
+	if len(out) == 0 {
+		return nil, errors.NewAPIError(errors.CodeValidationError,
+			fmt.Sprintf("no configured SLOs named %s for %s", strings.Join(cmd.Name, ", "), pkg))
+	}
+	return out, nil
+}
+
+// apiSLODays queries app-wide daily rates and user counts.
+func apiSLODays(ctx context.Context, client *api.Client, svc *playdeveloperreporting.Service, pkg string) sloDays {
+	return func(src sloSource, start, end time.Time) ([]slo.Day, error) {
+		spec, err := buildTimelineSpec(start.Format("2006-01-02"), end.Format("2006-01-02"))
+		if err != nil {
+			return nil, err
+		}
+		rows, err := queryMetricSetRows(ctx, client, svc, pkg, src.metricSet, spec, nil, []string{src.rate, metricDistinctUsers})
+		if err != nil {
+			return nil, err
+		}
+		return sloDaysFromPoints(storePoints(rows, time.Now().UTC()), src), nil
+	}
+}
+
+// storeSLODays reads app-wide daily rates and user counts from the vitals
+// store.
+func storeSLODays(st *vitalsstore.Store, pkg string) sloDays {
+	return func(src sloSource, start, end time.Time) ([]slo.Day, error) {
+		rates, err := storeSeries(st, pkg, src.metricSet, src.rate, nil, start, end)
+		if err != nil {
+			return nil, err
+		}
+		users, err := storeSeries(st, pkg, src.metricSet, metricDistinctUsers, nil, start, end)
+		if err != nil {
+			return nil, err
+		}
+		return sloDaysFromPoints(append(rates, users...), src), nil
+	}
+}
+
+// sloDaysFromPoints pairs each day's rate with its user count. Days
+// without a rate are dropped.
+func sloDaysFromPoints(points []vitalsstore.Point, src sloSource) []slo.Day {
+	byDate := make(map[string]*slo.Day)
+	var dates []string
+	for _, p := range points {
+		if len(p.Dimensions) > 0 {
+			continue
+		}
+		d, ok := byDate[p.Date]
+		if !ok {
+			d = &slo.Day{Date: p.Date, Rate: -1}
+			byDate[p.Date] = d
+			dates = append(dates, p.Date)
+		}
+		switch p.Metric {
+		case src.rate:
+			d.Rate = p.Value
+		case metricDistinctUsers:
+			d.Users = p.Value
+		}
+	}
+	out := make([]slo.Day, 0, len(dates))
+	for _, date := range dates {
+		if d := byDate[date]; d.Rate >= 0 {
+			out = append(out, *d)
+		}
+	}
+	return out
+}
+
+// sloAlerts converts breached SLOs to alerts: the burn rate of the firing
+// window, or the consumed budget once it is exhausted.
+func sloAlerts(pkg string, statuses []slo.Status, now time.Time) []alerting.Alert {
+	var alerts []alerting.Alert
+	for _, st := range statuses {
+		if !st.Breached() {
+			continue
+		}
+		alert := alerting.Alert{
+			Key:      pkg + "/slo/" + st.Name,
+			Source:   "monitor slo",
+			Package:  pkg,
+			Severity: st.Severity,
+			Time:     now,
+			Labels:   map[string]string{"slo": st.Name, "state": st.State},
+		}
+		if st.State == slo.StateExhausted {
+			alert.Metric = st.Name + " error budget consumed"
+			alert.Value, alert.Threshold = st.BudgetConsumed, 1
+		} else {
+			for _, w := range st.Windows {
+				if w.Name == st.State {
+					alert.Metric = fmt.Sprintf("%s %dd burn rate", st.Name, w.LongDays)
+					alert.Value, alert.Threshold = w.LongBurn, w.Threshold
+				}
+			}
+		}
+		alerts = append(alerts, alert)
+	}
+	return alerts
+}
+
+// breachedSLOs names the breached SLOs with their state.
+func breachedSLOs(statuses []slo.Status) []string {
+	var out []string
+	for _, st := range statuses {
+		if st.Breached() {
+			out = append(out, fmt.Sprintf("%s (%s)", st.Name, st.State))
+		}
+	}
+	return out
+}
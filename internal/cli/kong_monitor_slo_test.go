@@ -0,0 +1,117 @@
+//go:build unit
+// +build unit
+
+package cli
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/slo"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/vitalsstore"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/errors"
+)
+
+func TestMonitorSLOCmd_ConfiguredFromStore(t *testing.T) {
+	writeAlertConfig(t, `{"slos": [
+		{"name": "crash-free", "objective": 99.5},
+		{"name": "other-app", "package": "com.example.other", "objective": 99}
+	]}`)
+	values := make([]float64, 30)
+	for i := range values {
+		values[i] = 0.002
+	}
+	dir := seedVitalsStore(t, "crashRateMetricSet", metricCrashRate, values)
+	cmd := &MonitorSLOCmd{Format: "json", FromStore: true, Store: dir}
+	out, err := captureInspectStdout(t, func() error { return cmd.Run(&Globals{Package: "com.example.app", KeyPath: "/nonexistent/key.json"}) })
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	var got sloResult
+	decodeInspectData(t, out, &got)
+	if len(got.SLOs) != 1 || got.Breached != 0 {
+		t.Fatalf("result = %+v", got)
+	}
+	st := got.SLOs[0]
+	if st.Name != "crash-free" || st.State != slo.StateOK || st.DaysWithData != 28 || st.BudgetRemaining < 0.59 || st.BudgetRemaining > 0.61 {
+		t.Errorf("status = %+v", st)
+	}
+}
+
+func TestMonitorSLOCmd_AlertOnBreaches(t *testing.T) {
+	writeAlertConfig(t, "")
+	values := make([]float64, 28)
+	for i := range values {
+		values[i] = 0.001
+	}
+	values[26], values[27] = 0.03, 0.03
+	dir := seedVitalsStore(t, "crashRateMetricSet", metricCrashRate, values)
+	cmd := &MonitorSLOCmd{Objective: 99.5, Metric: "crash-free-users", WindowDays: 28, AlertOnBreaches: true, Format: "json", FromStore: true, Store: dir}
+	out, err := captureInspectStdout(t, func() error { return cmd.Run(&Globals{Package: "com.example.app", KeyPath: "/nonexistent/key.json"}) })
+	apiErr, ok := err.(*errors.APIError)
+	if !ok || apiErr.ExitCode() != errors.ExitValidationError || !strings.Contains(err.Error(), "crash-free-users (fast-burn)") {
+		t.Fatalf("err = %v", err)
+	}
+	// The burn rates and budget are written before the job fails.
+	var got sloResult
+	decodeInspectData(t, out, &got)
+	if got.Breached != 1 || len(got.SLOs) != 1 {
+		t.Errorf("written result = %+v", got)
+	}
+}
+
+func TestMonitorSLOCmd_RequiresObjective(t *testing.T) {
+	writeAlertConfig(t, "")
+	cmd := &MonitorSLOCmd{Format: "json", FromStore: true, Store: t.TempDir()}
+	err := cmd.Run(&Globals{Package: "com.example.app"})
+	if err == nil || !strings.Contains(err.Error(), "no SLOs configured") {
+		t.Fatalf("err = %v", err)
+	}
+
+	writeAlertConfig(t, `{"slos": [{"name": "bad", "metric": "crashes", "objective": 99}]}`)
+	err = cmd.Run(&Globals{Package: "com.example.app"})
+	if err == nil || !strings.Contains(err.Error(), `unknown metric "crashes"`) {
+		t.Fatalf("err = %v", err)
+	}
+
+	writeAlertConfig(t, `{"slos": `)
+	err = cmd.Run(&Globals{Package: "com.example.app"})
+	if err == nil || !strings.Contains(err.Error(), "failed to load config") {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestSLODaysFromPoints(t *testing.T) {
+	src := sloSources["crash-free-users"]
+	days := sloDaysFromPoints([]vitalsstore.Point{
+		{Date: "2026-10-01", Metric: metricCrashRate, Value: 0.01},
+		{Date: "2026-10-01", Metric: metricDistinctUsers, Value: 500},
+		{Date: "2026-10-01", Metric: metricCrashRate, Dimensions: map[string]string{"versionCode": "31"}, Value: 0.5},
+		{Date: "2026-10-02", Metric: metricDistinctUsers, Value: 400},
+	}, src)
+	if len(days) != 1 || days[0].Rate != 0.01 || days[0].Users != 500 {
+		t.Errorf("days = %+v", days)
+	}
+}
+
+func TestSLOAlerts(t *testing.T) {
+	now := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	alerts := sloAlerts("com.example.app", []slo.Status{
+		{Name: "ok", State: slo.StateOK},
+		{Name: "crash-free", State: slo.StateFastBurn, Severity: slo.SeverityCritical, Windows: []slo.Window{
+			{Name: slo.StateFastBurn, LongDays: 2, Threshold: 4, LongBurn: 6},
+			{Name: slo.StateSlowBurn, LongDays: 7, Threshold: 1, LongBurn: 2},
+		}},
+		{Name: "anr-free", State: slo.StateExhausted, Severity: slo.SeverityCritical, BudgetConsumed: 1.2},
+	}, now)
+	if len(alerts) != 2 {
+		t.Fatalf("alerts = %+v", alerts)
+	}
+	if a := alerts[0]; a.Key != "com.example.app/slo/crash-free" || a.Metric != "crash-free 2d burn rate" || a.Value != 6 || a.Threshold != 4 {
+		t.Errorf("burn alert = %+v", a)
+	}
+	if a := alerts[1]; a.Metric != "anr-free error budget consumed" || a.Value != 1.2 || a.Threshold != 1 {
+		t.Errorf("exhausted alert = %+v", a)
+	}
+}
@@ -25,7 +25,7 @@ func TestMonitorCmd_Structure(t *testing.T) {
 	typeOfCmd := v.Type()
 
 	expectedSubcommands := []string{
-		"Watch", "Anomalies", "Dashboard", "Report", "Webhooks",
+		"Watch", "Anomalies", "Dashboard", "Report", "Webhooks", "Serve", "SLO",
 	}
 
 	for _, name := range expectedSubcommands {
@@ -78,17 +78,22 @@ func TestMonitorWatchCmd_NormalizeMetrics(t *testing.T) {
 		{
 			name:     "empty returns all",
 			metrics:  []string{},
-			expected: []string{"crashes", "anrs", "errors"},
+			expected: []string{"crashes", "anrs", "errors", "lmk"},
 		},
 		{
 			name:     "nil returns all",
 			metrics:  nil,
-			expected: []string{"crashes", "anrs", "errors"},
+			expected: []string{"crashes", "anrs", "errors", "lmk"},
 		},
 		{
 			name:     "all returns all metrics",
 			metrics:  []string{"all"},
-			expected: []string{"crashes", "anrs", "errors"},
+			expected: []string{"crashes", "anrs", "errors", "lmk"},
+		},
+		{
+			name:     "lmk variations",
+			metrics:  []string{"lmk", "LMKs", "low-memory-kills"},
+			expected: []string{"lmk", "lmk", "lmk"},
 		},
 		{
 			name:     "single crash metric",
@@ -489,12 +494,12 @@ func TestMonitorDashboardCmd_NormalizeDashboardMetrics(t *testing.T) {
 		{
 			name:     "empty returns all",
 			metrics:  []string{},
-			expected: []string{"crashes", "anrs", "errors", "slow-rendering", "slow-start", "wakeups", "wakelocks"},
+			expected: []string{"crashes", "anrs", "errors", "slow-rendering", "slow-start", "wakeups", "wakelocks", "lmk"},
 		},
 		{
 			name:     "all returns all metrics",
 			metrics:  []string{"all"},
-			expected: []string{"crashes", "anrs", "errors", "slow-rendering", "slow-start", "wakeups", "wakelocks"},
+			expected: []string{"crashes", "anrs", "errors", "slow-rendering", "slow-start", "wakeups", "wakelocks", "lmk"},
 		},
 		{
 			name:     "crashes variations",
@@ -531,6 +536,11 @@ func TestMonitorDashboardCmd_NormalizeDashboardMetrics(t *testing.T) {
 			metrics:  []string{"wakelocks", "wakelock", "stuck-wakelocks"},
 			expected: []string{"wakelocks", "wakelocks", "wakelocks"},
 		},
+		{
+			name:     "lmk variations",
+			metrics:  []string{"lmk", "low-memory-kills"},
+			expected: []string{"lmk", "lmk"},
+		},
 		{
 			name:     "case insensitive",
 			metrics:  []string{"CRASHES", "Slow-Rendering", "WAKEUPS"},
@@ -1013,7 +1023,7 @@ func TestMonitorWebhooksCmd_Structure(t *testing.T) {
 	typeOfCmd := v.Type()
 
 	expectedSubcommands := []string{
-		"List",
+		"Add", "List", "Remove", "Test",
 	}
 
 	for _, name := range expectedSubcommands {
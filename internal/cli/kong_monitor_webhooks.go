@@ -0,0 +1,263 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"google.golang.org/api/androidpublisher/v3"
+
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/alerting"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/webhooks"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/config"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/errors"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/output"
+)
+
+// MonitorWebhooksCmd manages the local webhook registry. Monitor,
+// automation, review and workflow commands deliver events to the webhooks
+// subscribed to them.
+type MonitorWebhooksCmd struct {
+	Add    MonitorWebhooksAddCmd    `cmd:"" help:"Register a webhook for gpd events"`
+	List   MonitorWebhooksListCmd   `cmd:"" help:"List registered webhooks"`
+	Remove MonitorWebhooksRemoveCmd `cmd:"" help:"Remove a webhook"`
+	Test   MonitorWebhooksTestCmd   `cmd:"" help:"Send a signed sample event to a webhook"`
+}
+
+// MonitorWebhooksAddCmd registers a webhook.
+type MonitorWebhooksAddCmd struct {
+	URL      string   `arg:"" help:"Endpoint URL (http or https)"`
+	Events   []string `help:"Events to deliver: vitals.threshold, rollout.step, review.new, workflow.failed, or * for all" required:""`
+	Name     string   `help:"Display name"`
+	Secret   string   `help:"Signing secret (default: generated and shown once)"`
+	Inactive bool     `help:"Register as inactive: only test events are delivered"`
+}
+
+// MonitorWebhooksListCmd lists registered webhooks.
+type MonitorWebhooksListCmd struct{}
+
+// MonitorWebhooksRemoveCmd removes a webhook.
+type MonitorWebhooksRemoveCmd struct {
+	ID string `arg:"" help:"Webhook ID"`
+}
+
+// MonitorWebhooksTestCmd sends a sample event to a webhook.
+type MonitorWebhooksTestCmd struct {
+	ID    string `arg:"" help:"Webhook ID"`
+	Event string `help:"Event type of the sample" default:"webhook.test" enum:"webhook.test,vitals.threshold,rollout.step,review.new,workflow.failed"`
+}
+
+// webhookInfo is a registered webhook as listed; secrets are never shown.
+type webhookInfo struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	URL        string    `json:"url"`
+	Events     []string  `json:"events"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastCalled time.Time `json:"lastCalled,omitempty"`
+	Status     string    `json:"status"`
+}
+
+// webhooksListResult represents the webhook list result.
+type webhooksListResult struct {
+	Webhooks   []webhookInfo `json:"webhooks"`
+	TotalCount int           `json:"totalCount"`
+	Note       string        `json:"note"`
+}
+
+// webhookRegistryPath is the registry file in the gpd config directory.
+func webhookRegistryPath() string {
+	return filepath.Join(config.GetPaths().ConfigDir, "webhooks.json")
+}
+
+func loadWebhookRegistry() (*webhooks.Registry, error) {
+	reg, err := webhooks.Load(webhookRegistryPath())
+	if err != nil {
+		return nil, errors.NewAPIError(errors.CodeGeneralError, fmt.Sprintf("failed to load webhook registry: %v", err))
+	}
+	return reg, nil
+}
+
+func saveWebhookRegistry(reg *webhooks.Registry) error {
+	if err := reg.Save(); err != nil {
+		return errors.NewAPIError(errors.CodeGeneralError, fmt.Sprintf("failed to save webhook registry: %v", err))
+	}
+	return nil
+}
+
+// Run executes the webhooks add command.
+func (cmd *MonitorWebhooksAddCmd) Run(globals *Globals) error {
+	reg, err := loadWebhookRegistry()
+	if err != nil {
+		return err
+	}
+	w, err := reg.Add(webhooks.Webhook{
+		Name:   cmd.Name,
+		URL:    cmd.URL,
+		Events: cmd.Events,
+		Secret: cmd.Secret,
+		Active: !cmd.Inactive,
+	})
+	if err != nil {
+		return errors.NewAPIError(errors.CodeValidationError, err.Error())
+	}
+	if err := saveWebhookRegistry(reg); err != nil {
+		return err
+	}
+	return outputResult(output.NewResult(w).
+		WithWarnings("Store the secret now; receivers verify X-Gpd-Signature with it and list does not show it"),
+		globals.Output, globals.Pretty)
+}
+
+// Run executes the webhooks list command.
+func (cmd *MonitorWebhooksListCmd) Run(globals *Globals) error {
+	reg, err := loadWebhookRegistry()
+	if err != nil {
+		return err
+	}
+	result := &webhooksListResult{
+		Webhooks:   make([]webhookInfo, 0, len(reg.Webhooks)),
+		TotalCount: len(reg.Webhooks),
+		Note:       "Registry: " + webhookRegistryPath(),
+	}
+	for _, w := range reg.Webhooks {
+		info := webhookInfo{
+			ID: w.ID, Name: w.Name, URL: w.URL, Events: w.Events, Active: w.Active,
+			CreatedAt: w.CreatedAt, Status: w.LastStatus,
+		}
+		if w.LastCalled != nil {
+			info.LastCalled = *w.LastCalled
+		}
+		if info.Status == "" {
+			info.Status = "never-called"
+		}
+		result.Webhooks = append(result.Webhooks, info)
+	}
+	var data interface{} = result
+	switch output.ParseFormat(globals.Output) {
+	case output.FormatTable, output.FormatMarkdown, output.FormatCSV, output.FormatExcel:
+		data = result.Webhooks
+	}
+	return outputResult(output.NewResult(data), globals.Output, globals.Pretty)
+}
+
+// Run executes the webhooks remove command.
+func (cmd *MonitorWebhooksRemoveCmd) Run(globals *Globals) error {
+	reg, err := loadWebhookRegistry()
+	if err != nil {
+		return err
+	}
+	if !reg.Remove(cmd.ID) {
+		return errors.NewAPIError(errors.CodeNotFound, fmt.Sprintf("webhook %s not found", cmd.ID)).
+			WithHint("List webhook IDs with: gpd monitor webhooks list")
+	}
+	if err := saveWebhookRegistry(reg); err != nil {
+		return err
+	}
+	return outputResult(output.NewResult(map[string]interface{}{
+		"success": true,
+		"removed": cmd.ID,
+	}), globals.Output, globals.Pretty)
+}
+
+// Run executes the webhooks test command. The sample is sent even when the
+// webhook is inactive or not subscribed to the event.
+func (cmd *MonitorWebhooksTestCmd) Run(globals *Globals) error {
+	reg, err := loadWebhookRegistry()
+	if err != nil {
+		return err
+	}
+	w, ok := reg.Get(cmd.ID)
+	if !ok {
+		return errors.NewAPIError(errors.CodeNotFound, fmt.Sprintf("webhook %s not found", cmd.ID)).
+			WithHint("List webhook IDs with: gpd monitor webhooks list")
+	}
+	pkg := globals.Package
+	if pkg == "" {
+		pkg = "com.example.app"
+	}
+	ctx := globals.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	d := reg.Send(ctx, w, webhooks.NewEvent(cmd.Event, pkg, map[string]interface{}{
+		"test":    true,
+		"message": "Sample event from gpd monitor webhooks test",
+	}))
+	if err := saveWebhookRegistry(reg); err != nil {
+		return err
+	}
+	if d.Status != alerting.StatusSent {
+		return errors.NewAPIError(errors.CodeGeneralError, fmt.Sprintf("test delivery to %s failed: %s", w.URL, d.Error)).
+			WithDetails(d)
+	}
+	return outputResult(output.NewResult(d), globals.Output, globals.Pretty)
+}
+
+// emitEvent delivers an event to the registered webhooks subscribed to it.
+// Delivery problems never fail the emitting command: they are recorded in
+// the registry and reported on stderr in verbose mode.
+func emitEvent(ctx context.Context, globals *Globals, eventType string, data interface{}) []webhooks.Delivery {
+	reg, err := webhooks.Load(webhookRegistryPath())
+	if err != nil {
+		logWebhookError(globals, err)
+		return nil
+	}
+	if len(reg.Subscribers(eventType)) == 0 {
+		return nil
+	}
+	deliveries := reg.Emit(ctx, webhooks.NewEvent(eventType, globals.Package, data))
+	finishEmit(globals, reg, deliveries)
+	return deliveries
+}
+
+// emitNewReviews emits review.new for reviews modified since the last run.
+// The first run for a package only records where to start, so that
+// subscribing does not replay the whole review history.
+func emitNewReviews(ctx context.Context, globals *Globals, reviews []*androidpublisher.Review) []webhooks.Delivery {
+	reg, err := webhooks.Load(webhookRegistryPath())
+	if err != nil {
+		logWebhookError(globals, err)
+		return nil
+	}
+	if len(reg.Subscribers(webhooks.EventReviewNew)) == 0 {
+		return nil
+	}
+	conv := &ReviewsListCmd{IncludeReviewText: true}
+	key := "reviews/" + globals.Package
+	cursor, seen := reg.Cursor(key)
+	newest := cursor
+	var deliveries []webhooks.Delivery
+	for _, r := range reviews {
+		review := conv.convertReview(r)
+		if review.LastModified.After(newest) {
+			newest = review.LastModified
+		}
+		if seen && review.LastModified.After(cursor) {
+			deliveries = append(deliveries, reg.Emit(ctx, webhooks.NewEvent(webhooks.EventReviewNew, globals.Package, review))...)
+		}
+	}
+	reg.SetCursor(key, newest)
+	finishEmit(globals, reg, deliveries)
+	return deliveries
+}
+
+func finishEmit(globals *Globals, reg *webhooks.Registry, deliveries []webhooks.Delivery) {
+	if err := reg.Save(); err != nil {
+		logWebhookError(globals, err)
+	}
+	for _, d := range deliveries {
+		if d.Status != alerting.StatusSent {
+			logWebhookError(globals, fmt.Errorf("%s delivery to %s failed: %s", d.Event, d.WebhookID, d.Error))
+		}
+	}
+}
+
+func logWebhookError(globals *Globals, err error) {
+	if globals.Verbose {
+		fmt.Fprintf(os.Stderr, "webhooks: %v\n", err)
+	}
+}
@@ -0,0 +1,143 @@
+//go:build unit
+// +build unit
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/androidpublisher/v3"
+
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/alerting"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/webhooks"
+)
+
+// webhookReceiver records the events it receives and checks their
+// signature against secret.
+func webhookReceiver(t *testing.T, secret string) (*httptest.Server, *[]webhooks.Event) {
+	t.Helper()
+	var events []webhooks.Event
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get(alerting.HeaderSignature) != alerting.Sign(secret, r.Header.Get(alerting.HeaderTimestamp), body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var ev webhooks.Event
+		_ = json.Unmarshal(body, &ev)
+		events = append(events, ev)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &events
+}
+
+func TestMonitorWebhooks_AddListTestRemove(t *testing.T) {
+	writeAlertConfig(t, "")
+	srv, events := webhookReceiver(t, "s3cret")
+	globals := &Globals{Package: "com.example.app", Output: "json"}
+
+	out, err := captureInspectStdout(t, func() error {
+		return (&MonitorWebhooksAddCmd{URL: srv.URL, Events: []string{"rollout.step"}, Secret: "s3cret", Name: "ops"}).Run(globals)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var added webhooks.Webhook
+	decodeInspectData(t, out, &added)
+
+	out, err = captureInspectStdout(t, func() error { return (&MonitorWebhooksListCmd{}).Run(globals) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out, "s3cret") {
+		t.Errorf("list leaked the secret: %s", out)
+	}
+	var list webhooksListResult
+	decodeInspectData(t, out, &list)
+	if list.TotalCount != 1 || list.Webhooks[0].ID != added.ID || list.Webhooks[0].Status != "never-called" {
+		t.Fatalf("list = %+v", list)
+	}
+
+	if _, err := captureInspectStdout(t, func() error { return (&MonitorWebhooksTestCmd{ID: added.ID, Event: "webhook.test"}).Run(globals) }); err != nil {
+		t.Fatal(err)
+	}
+	if len(*events) != 1 || (*events)[0].Type != "webhook.test" || (*events)[0].Package != "com.example.app" {
+		t.Fatalf("events = %+v", *events)
+	}
+
+	if _, err := captureInspectStdout(t, func() error { return (&MonitorWebhooksRemoveCmd{ID: added.ID}).Run(globals) }); err != nil {
+		t.Fatal(err)
+	}
+	if err := (&MonitorWebhooksTestCmd{ID: added.ID}).Run(globals); err == nil {
+		t.Error("expected not found after remove")
+	}
+}
+
+func TestMonitorWebhooksAdd_RejectsUnknownEvent(t *testing.T) {
+	writeAlertConfig(t, "")
+	err := (&MonitorWebhooksAddCmd{URL: "https://example.com", Events: []string{"vitals.crashes"}}).Run(&Globals{})
+	if err == nil || !strings.Contains(err.Error(), "unknown event") {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestEmitEvent_OnlySubscribers(t *testing.T) {
+	writeAlertConfig(t, "")
+	srv, events := webhookReceiver(t, "s3cret")
+	reg, _ := loadWebhookRegistry()
+	_, _ = reg.Add(webhooks.Webhook{URL: srv.URL, Events: []string{webhooks.EventWorkflowFailed}, Secret: "s3cret", Active: true})
+	if err := saveWebhookRegistry(reg); err != nil {
+		t.Fatal(err)
+	}
+
+	globals := &Globals{Package: "com.example.app"}
+	if d := emitEvent(context.Background(), globals, webhooks.EventRolloutStep, nil); d != nil {
+		t.Errorf("unsubscribed event delivered: %+v", d)
+	}
+	d := emitEvent(context.Background(), globals, webhooks.EventWorkflowFailed,
+		workflowFailedEvent("release.json", nil, stderrors.New("parse error")))
+	if len(d) != 1 || d[0].Status != alerting.StatusSent || len(*events) != 1 {
+		t.Fatalf("deliveries = %+v", d)
+	}
+	data := (*events)[0].Data.(map[string]interface{})
+	if data["file"] != "release.json" || data["error"] != "parse error" {
+		t.Errorf("data = %+v", data)
+	}
+}
+
+func TestEmitNewReviews_BaselineThenNew(t *testing.T) {
+	writeAlertConfig(t, "")
+	srv, events := webhookReceiver(t, "s3cret")
+	reg, _ := loadWebhookRegistry()
+	_, _ = reg.Add(webhooks.Webhook{URL: srv.URL, Events: []string{webhooks.EventReviewNew}, Secret: "s3cret", Active: true})
+	_ = saveWebhookRegistry(reg)
+
+	review := func(id string, seconds int64) *androidpublisher.Review {
+		return &androidpublisher.Review{ReviewId: id, Comments: []*androidpublisher.Comment{{UserComment: &androidpublisher.UserComment{
+			StarRating: 2, Text: "crashes on start", LastModified: &androidpublisher.Timestamp{Seconds: seconds},
+		}}}}
+	}
+	globals := &Globals{Package: "com.example.app"}
+	ctx := context.Background()
+	if d := emitNewReviews(ctx, globals, []*androidpublisher.Review{review("r1", 1000)}); len(d) != 0 {
+		t.Fatalf("first run should only record a baseline, got %+v", d)
+	}
+	d := emitNewReviews(ctx, globals, []*androidpublisher.Review{review("r1", 1000), review("r2", 2000)})
+	if len(d) != 1 || len(*events) != 1 {
+		t.Fatalf("deliveries = %+v", d)
+	}
+	data := (*events)[0].Data.(map[string]interface{})
+	if data["reviewId"] != "r2" || data["reviewText"] != "crashes on start" {
+		t.Errorf("data = %+v", data)
+	}
+	if d := emitNewReviews(ctx, globals, []*androidpublisher.Review{review("r2", 2000)}); len(d) != 0 {
+		t.Errorf("already seen review delivered again: %+v", d)
+	}
+}
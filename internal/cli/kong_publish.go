@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	stderrors "errors"
@@ -16,9 +17,13 @@ import (
 	"google.golang.org/api/googleapi"
 
 	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/api"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/artifact"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/nativesymbols"
 	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/playship"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/config"
 	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/errors"
 	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/output"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/proguard"
 )
 
 // PublishCmd contains publishing commands.
@@ -106,6 +111,14 @@ func (cmd *PublishPlayCmd) runLivePublishPlay(globals *Globals, releaseStatus st
 		return err
 	}
 
+	manifest, err := upload.checkArtifactPackage(pkg)
+	if err != nil {
+		return err
+	}
+	if err := upload.checkUploadKey(globals.Profile); err != nil {
+		return err
+	}
+
 	client, svc, err := upload.createUploadClient(ctx, globals)
 	if err != nil {
 		return err
@@ -116,6 +129,10 @@ func (cmd *PublishPlayCmd) runLivePublishPlay(globals *Globals, releaseStatus st
 		return err
 	}
 
+	if err := upload.checkVersionCodeAgainstTracks(ctx, client, svc, pkg, editID, manifest.VersionCode); err != nil {
+		return err
+	}
+
 	versionCode, sha1, sha256, err := upload.uploadBinary(ctx, client, svc, pkg, editID, fileType)
 	if err != nil {
 		return err
@@ -192,6 +209,8 @@ type PublishUploadCmd struct {
 	ObbPatch                  string `help:"Patch expansion file path"`
 	ObbMainRefVersion         int64  `help:"Reference version code for main expansion file"`
 	ObbPatchRefVersion        int64  `help:"Reference version code for patch expansion file"`
+	Mapping                   string `help:"R8/ProGuard mapping.txt to upload with the artifact (default: the mapping embedded in an AAB); must match the embedded one" type:"existingfile"`
+	NoMapping                 bool   `help:"Do not upload a deobfuscation mapping"`
 	NoAutoCommit              bool   `help:"Keep edit open for manual commit"`
 	InProgressReviewBehaviour string `help:"Behavior when committing while review in progress: THROW_ERROR_IF_IN_PROGRESS, CANCEL_IN_PROGRESS_AND_SUBMIT, or IN_PROGRESS_REVIEW_BEHAVIOUR_UNSPECIFIED" enum:"THROW_ERROR_IF_IN_PROGRESS,CANCEL_IN_PROGRESS_AND_SUBMIT,IN_PROGRESS_REVIEW_BEHAVIOUR_UNSPECIFIED," default:""`
 	DryRun                    bool   `help:"Show intended actions without executing"`
@@ -213,6 +232,8 @@ type uploadResult struct {
 	Committed   bool   `json:"committed"`
 	File        string `json:"file"`
 	Size        int64  `json:"size"`
+	// Mapping is the deobfuscation mapping uploaded alongside the artifact.
+	Mapping *mappingCheck `json:"mapping,omitempty"`
 }
 
 // Run executes the upload command.
@@ -233,6 +254,18 @@ func (cmd *PublishUploadCmd) Run(globals *Globals) error {
 		return cmd.handleDryRunUpload(start, fileType, globals)
 	}
 
+	manifest, err := cmd.checkArtifactPackage(globals.Package)
+	if err != nil {
+		return err
+	}
+	if err := cmd.checkUploadKey(globals.Profile); err != nil {
+		return err
+	}
+	mapping, err := cmd.resolveMapping()
+	if err != nil {
+		return err
+	}
+
 	client, svc, err := cmd.createUploadClient(ctx, globals)
 	if err != nil {
 		return err
@@ -243,6 +276,10 @@ func (cmd *PublishUploadCmd) Run(globals *Globals) error {
 		return err
 	}
 
+	if err := cmd.checkVersionCodeAgainstTracks(ctx, client, svc, globals.Package, editID, manifest.VersionCode); err != nil {
+		return err
+	}
+
 	versionCode, sha1, sha256, err := cmd.uploadBinary(ctx, client, svc, globals.Package, editID, fileType)
 	if err != nil {
 		return err
@@ -252,12 +289,18 @@ func (cmd *PublishUploadCmd) Run(globals *Globals) error {
 		return err
 	}
 
+	if mapping != nil {
+		if err := cmd.uploadMapping(ctx, client, svc, globals.Package, editID, versionCode, mapping); err != nil {
+			return err
+		}
+	}
+
 	committed, err := cmd.commitUploadEdit(ctx, client, svc, globals.Package, editID)
 	if err != nil {
 		return err
 	}
 
-	return cmd.buildUploadResult(start, fileInfo, fileType, editID, versionCode, sha1, sha256, committed, globals)
+	return cmd.buildUploadResult(start, fileInfo, fileType, editID, versionCode, sha1, sha256, committed, mapping.check(), globals)
 }
 
 // validateUploadFile validates the file exists and is APK/AAB.
@@ -345,6 +388,200 @@ func (cmd *PublishUploadCmd) getOrCreateEditID(ctx context.Context, client *api.
 	return edit.Id, nil
 }
 
+// checkArtifactPackage reads the artifact manifest and fails before any
+// network traffic when it was built for a different application.
+func (cmd *PublishUploadCmd) checkArtifactPackage(packageName string) (*artifact.Manifest, error) {
+	a, err := openArtifact(cmd.File)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = a.Close() }()
+
+	if a.Manifest.Package != packageName {
+		return nil, errors.NewAPIError(errors.CodeValidationError,
+			fmt.Sprintf("artifact package %s does not match --package %s", a.Manifest.Package, packageName)).
+			WithHint(fmt.Sprintf("Upload a build of %s, or pass --package %s if this artifact is intended", packageName, a.Manifest.Package)).
+			WithDetails(map[string]interface{}{
+				"file":            cmd.File,
+				"artifactPackage": a.Manifest.Package,
+				"package":         packageName,
+				"versionCode":     a.Manifest.VersionCode,
+			})
+	}
+	return a.Manifest, nil
+}
+
+// checkUploadKey fails before any network traffic when the auth profile has
+// an expected upload-key fingerprint (gpd auth upload-key) and the artifact
+// is signed by a different key.
+func (cmd *PublishUploadCmd) checkUploadKey(profile string) error {
+	profile = config.ResolveAuthProfile(profile)
+	// A config that fails to load could hide a pinned key; fail closed.
+	cfg, err := config.Load()
+	if err != nil {
+		return errors.NewAPIError(errors.CodeGeneralError, fmt.Sprintf("failed to load config: %v", err)).
+			WithHint("Fix the file shown by `gpd config path`; the upload key check needs it")
+	}
+	expected := cfg.UploadKeyFingerprint(profile)
+	if expected == "" {
+		return nil
+	}
+	signer, err := verifiedSigner(cmd.File)
+	if err != nil {
+		return err
+	}
+	if got := signer.Certificate.SHA256; got != expected {
+		return errors.NewAPIError(errors.CodeValidationError,
+			fmt.Sprintf("artifact is signed by %s, not the upload key recorded for profile %s", got, profile)).
+			WithHint(fmt.Sprintf("Sign with the upload key for %s, or update it with `gpd auth upload-key --profile %s <sha256>`", profile, profile)).
+			WithDetails(map[string]interface{}{
+				"file":     cmd.File,
+				"profile":  profile,
+				"expected": expected,
+				"actual":   got,
+				"subject":  signer.Certificate.Subject,
+			})
+	}
+	return nil
+}
+
+// uploadMappingFile is a deobfuscation mapping ready to upload.
+type uploadMappingFile struct {
+	mappingCheck
+	data []byte
+}
+
+func (m *uploadMappingFile) check() *mappingCheck {
+	if m == nil {
+		return nil
+	}
+	return &m.mappingCheck
+}
+
+// resolveMapping picks the mapping to upload with the artifact: --mapping,
+// which must match the mapping embedded in an AAB, or else the embedded
+// mapping itself. It returns nil when there is none or with --no-mapping.
+func (cmd *PublishUploadCmd) resolveMapping() (*uploadMappingFile, error) {
+	if cmd.NoMapping {
+		return nil, nil
+	}
+	a, err := openArtifact(cmd.File)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = a.Close() }()
+	embedded, err := embeddedMappingHeader(a)
+	if err != nil {
+		return nil, err
+	}
+
+	if cmd.Mapping != "" {
+		check := &mappingCheck{Source: "file", File: cmd.Mapping}
+		if embedded != nil {
+			if check, _, err = checkMappingFile(cmd.Mapping, cmd.File, embedded); err != nil {
+				return nil, err
+			}
+		}
+		data, err := os.ReadFile(cmd.Mapping)
+		if err != nil {
+			return nil, errors.NewAPIError(errors.CodeValidationError, fmt.Sprintf("failed to read mapping file: %v", err))
+		}
+		if embedded == nil {
+			h, _ := proguard.ReadHeader(bytes.NewReader(data))
+			check.Header = h
+		}
+		return &uploadMappingFile{mappingCheck: *check, data: data}, nil
+	}
+
+	if embedded == nil {
+		return nil, nil
+	}
+	data, err := a.ReadFile(artifact.BundleMappingPath)
+	if err != nil {
+		return nil, errors.NewAPIError(errors.CodeValidationError, fmt.Sprintf("cannot read embedded mapping: %v", err))
+	}
+	return &uploadMappingFile{
+		mappingCheck: mappingCheck{Source: "bundle", File: artifact.BundleMappingPath, Header: *embedded},
+		data:         data,
+	}, nil
+}
+
+// uploadMapping uploads the proguard mapping for versionCode in the edit.
+func (cmd *PublishUploadCmd) uploadMapping(ctx context.Context, client *api.Client, svc *androidpublisher.Service, packageName, editID string, versionCode int64, mapping *uploadMappingFile) error {
+	if err := client.AcquireForUpload(ctx); err != nil {
+		return err
+	}
+	err := client.DoWithRetry(ctx, func() error {
+		_, uerr := svc.Edits.Deobfuscationfiles.Upload(packageName, editID, versionCode, "proguard").
+			Media(bytes.NewReader(mapping.data), googleapi.ContentType("application/octet-stream")).
+			Context(ctx).Do()
+		return uerr
+	})
+	client.ReleaseForUpload()
+	if err != nil {
+		return errors.NewAPIError(errors.CodeGeneralError, fmt.Sprintf("failed to upload deobfuscation mapping: %v", err)).
+			WithHint("Retry with --no-mapping and upload it later with `gpd publish deobfuscation upload --bundle`")
+	}
+	return nil
+}
+
+// checkVersionCodeAgainstTracks lists the tracks in the edit and rejects an
+// artifact whose versionCode is not above every version code in the edit.
+func (cmd *PublishUploadCmd) checkVersionCodeAgainstTracks(ctx context.Context, client *api.Client, svc *androidpublisher.Service, packageName, editID string, versionCode int64) error {
+	if err := client.Acquire(ctx); err != nil {
+		return err
+	}
+	var resp *androidpublisher.TracksListResponse
+	err := client.DoWithRetry(ctx, func() error {
+		var lerr error
+		resp, lerr = svc.Edits.Tracks.List(packageName, editID).Context(ctx).Do()
+		return lerr
+	})
+	client.Release()
+	if err != nil {
+		return errors.NewAPIError(errors.CodeGeneralError, fmt.Sprintf("failed to list tracks before upload: %v", err))
+	}
+
+	track := cmd.Track
+	if track == "" {
+		track = "internal"
+	}
+	return checkVersionCodeSupersedes(resp.Tracks, track, versionCode)
+}
+
+// checkVersionCodeSupersedes returns a validation error when versionCode is
+// not higher than every version code on any track, drafts and custom
+// tracks included. Play rejects such an upload when the edit is committed;
+// this catches it before the artifact is uploaded.
+func checkVersionCodeSupersedes(tracks []*androidpublisher.Track, target string, versionCode int64) error {
+	for _, t := range tracks {
+		if t == nil {
+			continue
+		}
+		for _, r := range t.Releases {
+			if r == nil {
+				continue
+			}
+			for _, existing := range r.VersionCodes {
+				if versionCode > existing {
+					continue
+				}
+				return errors.NewAPIError(errors.CodeValidationError,
+					fmt.Sprintf("artifact versionCode %d is not higher than version %d on track %s", versionCode, existing, t.Track)).
+					WithHint(fmt.Sprintf("Bump versionCode above %d (track %s, release status %s) before uploading", existing, t.Track, r.Status)).
+					WithDetails(map[string]interface{}{
+						"versionCode":        versionCode,
+						"conflictingTrack":   t.Track,
+						"conflictingVersion": existing,
+						"conflictingStatus":  r.Status,
+						"targetTrack":        target,
+					})
+			}
+		}
+	}
+	return nil
+}
+
 // uploadBinary uploads APK or AAB and returns version code and hashes.
 //
 //nolint:gocritic // Named results would shadow local variables
@@ -479,7 +716,7 @@ func (cmd *PublishUploadCmd) commitUploadEdit(ctx context.Context, client *api.C
 }
 
 // buildUploadResult builds and outputs the upload result.
-func (cmd *PublishUploadCmd) buildUploadResult(start time.Time, fileInfo os.FileInfo, fileType, editID string, versionCode int64, sha1, sha256 string, committed bool, globals *Globals) error {
+func (cmd *PublishUploadCmd) buildUploadResult(start time.Time, fileInfo os.FileInfo, fileType, editID string, versionCode int64, sha1, sha256 string, committed bool, mapping *mappingCheck, globals *Globals) error {
 	result := output.NewResult(uploadResult{
 		VersionCode: versionCode,
 		SHA1:        sha1,
@@ -489,6 +726,7 @@ func (cmd *PublishUploadCmd) buildUploadResult(start time.Time, fileInfo os.File
 		Committed:   committed,
 		File:        cmd.File,
 		Size:        fileInfo.Size(),
+		Mapping:     mapping,
 	}).WithDuration(time.Since(start)).
 		WithServices("androidpublisher")
 
@@ -3115,9 +3353,11 @@ type PublishDeobfuscationCmd struct {
 
 // PublishDeobfuscationUploadCmd uploads deobfuscation file.
 type PublishDeobfuscationUploadCmd struct {
-	File         string `arg:"" help:"File to upload" type:"existingfile"`
+	File         string `arg:"" optional:"" help:"File to upload (omit with --native-from)" type:"existingfile"`
 	Type         string `help:"Deobfuscation file type: proguard or nativeCode" required:"" enum:"proguard,nativeCode"`
-	VersionCode  int64  `help:"Version code to associate"`
+	VersionCode  int64  `help:"Version code to associate (default: the --bundle version code)"`
+	Bundle       string `help:"AAB the file was built with; a proguard mapping must match the one embedded in its BUNDLE-METADATA, native symbols must match its libraries' build IDs" type:"existingfile"`
+	NativeFrom   string `help:"Build directory to collect unstripped native libraries (.so, .so.sym, .so.dbg) from and zip per ABI; requires --type nativeCode" type:"existingdir"`
 	EditID       string `help:"Explicit edit transaction ID"`
 	ChunkSize    int64  `help:"Upload chunk size in bytes" default:"10485760"`
 	NoAutoCommit bool   `help:"Keep edit open for manual commit"`
@@ -3136,24 +3376,62 @@ func (cmd *PublishDeobfuscationUploadCmd) Run(globals *Globals) error {
 		return errors.ErrPackageRequired
 	}
 
+	switch {
+	case cmd.File == "" && cmd.NativeFrom == "":
+		return errors.NewAPIError(errors.CodeValidationError, "file is required").
+			WithHint("Provide a deobfuscation file (mapping.txt or native debug symbols), or collect symbols with --native-from")
+	case cmd.File != "" && cmd.NativeFrom != "":
+		return errors.NewAPIError(errors.CodeValidationError, "a file and --native-from cannot be combined").
+			WithHint("Upload either a prepared file or the symbols collected from a build directory")
+	case cmd.NativeFrom != "" && cmd.Type != "nativeCode":
+		return errors.NewAPIError(errors.CodeValidationError, "--native-from requires --type nativeCode")
+	}
+
+	var mapping *mappingCheck
+	var warnings []string
+	if cmd.Bundle != "" {
+		var err error
+		mapping, warnings, err = cmd.checkBundle()
+		if err != nil {
+			return err
+		}
+	}
+
 	if cmd.VersionCode <= 0 {
 		return errors.NewAPIError(errors.CodeValidationError, "version code is required").
-			WithHint("Specify the version code with --version-code")
+			WithHint("Specify the version code with --version-code or pass the bundle with --bundle")
 	}
 
-	if cmd.File == "" {
-		return errors.NewAPIError(errors.CodeValidationError, "file is required").
-			WithHint("Provide a deobfuscation file (mapping.txt or native debug symbols)")
+	uploadPath := cmd.File
+	var native *nativeSymbolsUpload
+	if cmd.NativeFrom != "" {
+		var nativeWarnings []string
+		var err error
+		native, nativeWarnings, err = cmd.packageNativeSymbols()
+		if err != nil {
+			return err
+		}
+		defer func() { _ = os.Remove(native.zipPath) }()
+		uploadPath = native.zipPath
+		warnings = append(warnings, nativeWarnings...)
 	}
 
 	if cmd.DryRun {
-		result := output.NewResult(map[string]interface{}{
+		data := map[string]interface{}{
 			"file":        cmd.File,
 			"type":        cmd.Type,
 			"versionCode": cmd.VersionCode,
 			"dryRun":      true,
-		}).WithDuration(time.Since(start)).
-			WithNoOp("dry run - deobfuscation file not uploaded")
+		}
+		if mapping != nil {
+			data["mapping"] = mapping
+		}
+		if native != nil {
+			data["nativeSymbols"] = native
+		}
+		result := output.NewResult(data).WithDuration(time.Since(start)).
+			WithNoOp("dry run - deobfuscation file not uploaded").
+			WithWarnings(warnings...)
 		return outputResult(result, globals.Output, globals.Pretty)
 	}
 
@@ -3189,7 +3467,7 @@ func (cmd *PublishDeobfuscationUploadCmd) Run(globals *Globals) error {
 	}
 
 	// Open file
-	file, err := os.Open(cmd.File)
+	file, err := os.Open(uploadPath)
 	if err != nil {
 		return errors.NewAPIError(errors.CodeValidationError, fmt.Sprintf("failed to open deobfuscation file: %v", err))
 	}
@@ -3246,11 +3524,189 @@ func (cmd *PublishDeobfuscationUploadCmd) Run(globals *Globals) error {
 	if uploadResp != nil && uploadResp.DeobfuscationFile != nil {
 		data["symbolType"] = uploadResp.DeobfuscationFile.SymbolType
 	}
+	if mapping != nil {
+		data["mapping"] = mapping
+	}
+	if native != nil {
+		data["nativeSymbols"] = native
+	}
 
-	result := output.NewResult(data).WithDuration(time.Since(start)).WithServices("androidpublisher")
+	result := output.NewResult(data).WithDuration(time.Since(start)).WithServices("androidpublisher").
+		WithWarnings(warnings...)
 	return outputResult(result, globals.Output, globals.Pretty)
 }
 
+// nativeSymbolsUpload is the zip of native symbols built from --native-from.
+type nativeSymbolsUpload struct {
+	*nativesymbols.Collection
+	ZipSize      int64                       `json:"zipSize"`
+	Verification *nativesymbols.Verification `json:"verification,omitempty"`
+	zipPath      string
+}
+
+// packageNativeSymbols collects the symbols under --native-from, checks
+// their build IDs against --bundle and zips them to a temporary file the
+// caller removes.
+func (cmd *PublishDeobfuscationUploadCmd) packageNativeSymbols() (*nativeSymbolsUpload, []string, error) {
+	c, err := nativesymbols.Collect(cmd.NativeFrom)
+	if err != nil {
+		return nil, nil, errors.NewAPIError(errors.CodeValidationError, fmt.Sprintf("failed to collect native symbols: %v", err)).
+			WithHint("Point --native-from at a single variant, e.g. app/build/intermediates/merged_native_libs/release")
+	}
+	if len(c.Files) == 0 {
+		return nil, nil, errors.NewAPIError(errors.CodeValidationError, fmt.Sprintf("no unstripped native libraries found under %s", cmd.NativeFrom)).
+			WithHint("AGP writes unstripped libraries to app/build/intermediates/merged_native_libs/<variant>/out/lib/<abi>").
+			WithDetails(map[string]interface{}{"skipped": c.Skipped})
+	}
+	native := &nativeSymbolsUpload{Collection: c}
+	warnings := append([]string{}, c.Skipped...)
+
+	if cmd.Bundle != "" {
+		v, err := verifyNativeSymbols(cmd.Bundle, c.Files)
+		if err != nil {
+			return nil, nil, err
+		}
+		native.Verification = v
+		warnings = append(warnings, v.Warnings()...)
+	}
+
+	tmp, err := os.CreateTemp("", "gpd-native-symbols-*.zip")
+	if err != nil {
+		return nil, nil, errors.NewAPIError(errors.CodeGeneralError, fmt.Sprintf("failed to create symbols zip: %v", err))
+	}
+	native.zipPath = tmp.Name()
+	err = c.WriteZip(tmp)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	var info os.FileInfo
+	if err == nil {
+		info, err = os.Stat(native.zipPath)
+	}
+	if err != nil {
+		_ = os.Remove(native.zipPath)
+		return nil, nil, errors.NewAPIError(errors.CodeGeneralError, fmt.Sprintf("failed to write symbols zip: %v", err))
+	}
+	native.ZipSize = info.Size()
+	if native.ZipSize > nativesymbols.MaxZipSize {
+		_ = os.Remove(native.zipPath)
+		return nil, nil, errors.NewAPIError(errors.CodeValidationError,
+			fmt.Sprintf("native symbols zip is %d bytes, over Play's %d byte limit", native.ZipSize, nativesymbols.MaxZipSize)).
+			WithHint("Build with debugSymbolLevel 'SYMBOL_TABLE' instead of 'FULL', or point --native-from at fewer ABIs")
+	}
+	return native, warnings, nil
+}
+
+// verifyNativeSymbols fails when a symbols file was built from different
+// code than the library of the same name in bundle.
+func verifyNativeSymbols(bundle string, files []nativesymbols.File) (*nativesymbols.Verification, error) {
+	a, err := openArtifact(bundle)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = a.Close() }()
+	libs, err := a.NativeLibs()
+	if err != nil {
+		return nil, errors.NewAPIError(errors.CodeValidationError, fmt.Sprintf("cannot read native libraries: %v", err))
+	}
+	v := nativesymbols.Verify(bundle, files, libs)
+	if !v.OK() {
+		first := v.Mismatches[0]
+		return nil, errors.NewAPIError(errors.CodeValidationError,
+			fmt.Sprintf("%d native libraries do not match %s: %s/%s has build ID %s, bundle has %s",
+				len(v.Mismatches), bundle, first.ABI, first.Library, first.Symbols, first.Bundle)).
+			WithHint("Collect symbols from the build that produced the bundle").
+			WithDetails(map[string]interface{}{"mismatches": v.Mismatches})
+	}
+	return v, nil
+}
+
+// mappingCheck records how a mapping file was matched to its bundle.
+type mappingCheck struct {
+	Source string `json:"source"`
+	File   string `json:"file,omitempty"`
+	proguard.Header
+	// Bundle and Basis are set when the mapping was checked against the
+	// copy embedded in a bundle; Basis is the identifier compared.
+	Bundle string `json:"bundle,omitempty"`
+	Basis  string `json:"basis,omitempty"`
+}
+
+// checkBundle defaults --version-code from --bundle and, for proguard
+// mappings, refuses a mapping from a different build than the bundle's.
+func (cmd *PublishDeobfuscationUploadCmd) checkBundle() (*mappingCheck, []string, error) {
+	a, err := openArtifact(cmd.Bundle)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = a.Close() }()
+	if a.Kind != artifact.KindAAB {
+		return nil, nil, errors.NewAPIError(errors.CodeValidationError, fmt.Sprintf("%s is not an Android App Bundle", cmd.Bundle)).
+			WithHint("Pass the .aab the mapping was built with")
+	}
+	if cmd.VersionCode <= 0 {
+		cmd.VersionCode = a.Manifest.VersionCode
+	} else if cmd.VersionCode != a.Manifest.VersionCode {
+		return nil, nil, errors.NewAPIError(errors.CodeValidationError,
+			fmt.Sprintf("--version-code %d does not match bundle version code %d", cmd.VersionCode, a.Manifest.VersionCode)).
+			WithHint("Drop --version-code to use the bundle's")
+	}
+	if cmd.Type != "proguard" {
+		return nil, nil, nil
+	}
+
+	embedded, err := embeddedMappingHeader(a)
+	if err != nil {
+		return nil, nil, err
+	}
+	if embedded == nil {
+		return nil, []string{fmt.Sprintf("%s has no embedded mapping (%s); cannot verify %s", cmd.Bundle, artifact.BundleMappingPath, cmd.File)}, nil
+	}
+	return checkMappingFile(cmd.File, cmd.Bundle, embedded)
+}
+
+// embeddedMappingHeader reads the header of a bundle's embedded mapping,
+// or returns nil when it has none.
+func embeddedMappingHeader(a *artifact.Artifact) (*proguard.Header, error) {
+	rc, err := a.EmbeddedMapping()
+	if err != nil {
+		return nil, errors.NewAPIError(errors.CodeValidationError, fmt.Sprintf("cannot read embedded mapping: %v", err))
+	}
+	if rc == nil {
+		return nil, nil
+	}
+	defer func() { _ = rc.Close() }()
+	h, err := proguard.ReadHeader(rc)
+	if err != nil {
+		return nil, errors.NewAPIError(errors.CodeValidationError, fmt.Sprintf("cannot read embedded mapping: %v", err))
+	}
+	return &h, nil
+}
+
+// checkMappingFile refuses a mapping file whose identity differs from the
+// mapping embedded in bundle.
+func checkMappingFile(path, bundle string, embedded *proguard.Header) (*mappingCheck, []string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, errors.NewAPIError(errors.CodeValidationError, fmt.Sprintf("failed to open mapping file: %v", err))
+	}
+	defer func() { _ = f.Close() }()
+	h, err := proguard.ReadHeader(f)
+	if err != nil {
+		return nil, nil, errors.NewAPIError(errors.CodeValidationError, err.Error())
+	}
+	ok, basis := proguard.Match(h, *embedded)
+	if !ok {
+		_, got := h.Identity()
+		_, want := embedded.Identity()
+		return nil, nil, errors.NewAPIError(errors.CodeValidationError,
+			fmt.Sprintf("%s is not the mapping of %s: %s %s, bundle has %s", path, bundle, basis, got, want)).
+			WithHint("Use the mapping.txt from the same build (build/outputs/mapping/<variant>/mapping.txt), or omit it to upload the bundle's embedded mapping").
+			WithDetails(map[string]interface{}{"mapping": h, "embedded": embedded, "basis": basis})
+	}
+	return &mappingCheck{Source: "file", File: path, Header: h, Bundle: bundle, Basis: basis}, nil, nil
+}
+
 // PublishTestersCmd manages testers.
 type PublishTestersCmd struct {
 	Add    PublishTestersAddCmd    `cmd:"" help:"Add tester groups"`
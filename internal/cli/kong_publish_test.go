@@ -14,7 +14,12 @@ import (
 	"testing"
 	"time"
 
+	"google.golang.org/api/androidpublisher/v3"
+
 	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/api"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/artifact"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/artifact/artifacttest"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/config"
 	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/errors"
 )
 
@@ -550,6 +555,257 @@ func TestPublishCommands_CreateAPIClient(t *testing.T) {
 	})
 }
 
+func TestPublishUploadCmd_Run_PackageMismatch(t *testing.T) {
+	aab := artifacttest.WriteAAB(t, artifacttest.Manifest("com.example.other", 7))
+	globals := &Globals{
+		Package: "com.example.app",
+		KeyPath: "/nonexistent/key.json",
+	}
+	cmd := &PublishUploadCmd{File: aab}
+
+	// The manifest check runs before auth, so the invalid key is never read.
+	err := cmd.Run(globals)
+	apiErr, ok := err.(*errors.APIError)
+	if !ok {
+		t.Fatalf("expected APIError, got %T %v", err, err)
+	}
+	if apiErr.Code != errors.CodeValidationError {
+		t.Errorf("code = %s, want %s", apiErr.Code, errors.CodeValidationError)
+	}
+	if !strings.Contains(apiErr.Message, "com.example.other") || !strings.Contains(apiErr.Hint, "--package com.example.other") {
+		t.Errorf("error = %v (hint %q)", apiErr, apiErr.Hint)
+	}
+}
+
+func TestPublishUploadCmd_Run_UploadKeyMismatch(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("HOME", tmp)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmp, ".config"))
+	t.Setenv("GPD_AUTH_PROFILE", "")
+
+	expected := artifacttest.NewSigner(t, "upload")
+	other := artifacttest.NewSigner(t, "debug")
+	aab := artifacttest.WriteAAB(t, artifacttest.Manifest("com.example.app", 7))
+	other.SignJAR(t, aab)
+	if err := config.SetUploadKeyFingerprint("release", expected.Fingerprint()); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := &PublishUploadCmd{File: aab}
+	err := cmd.Run(&Globals{Package: "com.example.app", Profile: "release", KeyPath: "/nonexistent/key.json"})
+	apiErr, ok := err.(*errors.APIError)
+	if !ok || apiErr.Code != errors.CodeValidationError {
+		t.Fatalf("expected validation error, got %T %v", err, err)
+	}
+	if !strings.Contains(apiErr.Message, other.Fingerprint()) || !strings.Contains(apiErr.Hint, "upload-key --profile release") {
+		t.Errorf("error = %v (hint %q)", apiErr, apiErr.Hint)
+	}
+}
+
+// testMapping returns an R8 mapping whose pg_map_hash is hash.
+func testMapping(hash string) []byte {
+	return []byte("# compiler: R8\n# compiler_version: 8.5.35\n# pg_map_id: " + hash[:7] + "\n# pg_map_hash: SHA-256 " + hash + "\n" +
+		"com.example.app.MainActivity -> a.a:\n")
+}
+
+func TestPublishUploadCmd_ResolveMapping(t *testing.T) {
+	embedded := testMapping("2d8c6ba4f0e1")
+	aab := artifacttest.WriteAAB(t, artifacttest.Manifest("com.example.app", 7),
+		artifacttest.Entry{Name: artifact.BundleMappingPath, Data: embedded})
+
+	t.Run("embedded mapping is extracted", func(t *testing.T) {
+		m, err := (&PublishUploadCmd{File: aab}).resolveMapping()
+		if err != nil {
+			t.Fatalf("resolveMapping: %v", err)
+		}
+		if m == nil || m.Source != "bundle" || string(m.data) != string(embedded) || m.MapID != "2d8c6ba" {
+			t.Errorf("mapping = %+v", m)
+		}
+	})
+
+	t.Run("matching mapping file", func(t *testing.T) {
+		path := createTempFile(t, "mapping.txt", embedded)
+		m, err := (&PublishUploadCmd{File: aab, Mapping: path}).resolveMapping()
+		if err != nil || m.Source != "file" || m.Basis != "pg_map_hash" {
+			t.Errorf("mapping = %+v, err = %v", m, err)
+		}
+	})
+
+	t.Run("no mapping", func(t *testing.T) {
+		for _, cmd := range []*PublishUploadCmd{
+			{File: aab, NoMapping: true},
+			{File: artifacttest.WriteAAB(t, artifacttest.Manifest("com.example.app", 7))},
+		} {
+			if m, err := cmd.resolveMapping(); m != nil || err != nil {
+				t.Errorf("%+v: mapping = %+v, err = %v", cmd, m, err)
+			}
+		}
+	})
+}
+
+func TestPublishUploadCmd_Run_MappingMismatch(t *testing.T) {
+	aab := artifacttest.WriteAAB(t, artifacttest.Manifest("com.example.app", 7),
+		artifacttest.Entry{Name: artifact.BundleMappingPath, Data: testMapping("2d8c6ba4f0e1")})
+	mapping := createTempFile(t, "mapping.txt", testMapping("99aa00bb11cc"))
+
+	// The mapping check runs before auth, so the invalid key is never read.
+	err := (&PublishUploadCmd{File: aab, Mapping: mapping}).Run(&Globals{Package: "com.example.app", KeyPath: "/nonexistent/key.json"})
+	apiErr, ok := err.(*errors.APIError)
+	if !ok || apiErr.Code != errors.CodeValidationError {
+		t.Fatalf("expected validation error, got %T %v", err, err)
+	}
+	if !strings.Contains(apiErr.Message, "is not the mapping of") || !strings.Contains(apiErr.Message, "99aa00bb11cc") {
+		t.Errorf("error = %v", apiErr)
+	}
+}
+
+func TestPublishDeobfuscationUploadCmd_Bundle(t *testing.T) {
+	aab := artifacttest.WriteAAB(t, artifacttest.Manifest("com.example.app", 31),
+		artifacttest.Entry{Name: artifact.BundleMappingPath, Data: testMapping("2d8c6ba4f0e1")})
+	globals := &Globals{Package: "com.example.app", Output: "json"}
+
+	t.Run("matching mapping defaults the version code", func(t *testing.T) {
+		cmd := &PublishDeobfuscationUploadCmd{File: createTempFile(t, "mapping.txt", testMapping("2d8c6ba4f0e1")), Type: "proguard", Bundle: aab, DryRun: true}
+		out, err := captureInspectStdout(t, func() error { return cmd.Run(globals) })
+		if err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+		var got struct {
+			VersionCode int64 `json:"versionCode"`
+			Mapping     struct {
+				Basis string `json:"basis"`
+			} `json:"mapping"`
+		}
+		decodeInspectData(t, out, &got)
+		if got.VersionCode != 31 || got.Mapping.Basis != "pg_map_hash" {
+			t.Errorf("result = %+v", got)
+		}
+	})
+
+	t.Run("mapping from another build is refused", func(t *testing.T) {
+		cmd := &PublishDeobfuscationUploadCmd{File: createTempFile(t, "mapping.txt", testMapping("99aa00bb11cc")), Type: "proguard", Bundle: aab, DryRun: true}
+		err := cmd.Run(globals)
+		if apiErr, ok := err.(*errors.APIError); !ok || !strings.Contains(apiErr.Message, "is not the mapping of") {
+			t.Errorf("err = %v", err)
+		}
+	})
+
+	t.Run("version code must match the bundle", func(t *testing.T) {
+		cmd := &PublishDeobfuscationUploadCmd{File: createTempFile(t, "mapping.txt", testMapping("2d8c6ba4f0e1")), Type: "proguard", Bundle: aab, VersionCode: 30, DryRun: true}
+		if err := cmd.Run(globals); err == nil || !strings.Contains(err.Error(), "does not match bundle version code 31") {
+			t.Errorf("err = %v", err)
+		}
+	})
+}
+
+func TestPublishDeobfuscationUploadCmd_NativeFrom(t *testing.T) {
+	buildDir := t.TempDir()
+	libDir := filepath.Join(buildDir, "intermediates", "merged_native_libs", "release", "out", "lib", "arm64-v8a")
+	if err := os.MkdirAll(libDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(libDir, "libapp.so"), artifacttest.SharedObject([]byte{0xaa, 0x01}, ".symtab"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	bundle := func(buildID []byte) string {
+		return artifacttest.WriteAAB(t, artifacttest.Manifest("com.example.app", 31),
+			artifacttest.Entry{Name: "base/lib/arm64-v8a/libapp.so", Data: artifacttest.SharedObject(buildID)})
+	}
+	globals := &Globals{Package: "com.example.app", Output: "json"}
+
+	t.Run("matching build IDs", func(t *testing.T) {
+		cmd := &PublishDeobfuscationUploadCmd{Type: "nativeCode", NativeFrom: buildDir, Bundle: bundle([]byte{0xaa, 0x01}), DryRun: true}
+		out, err := captureInspectStdout(t, func() error { return cmd.Run(globals) })
+		if err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+		var got struct {
+			VersionCode   int64 `json:"versionCode"`
+			NativeSymbols struct {
+				Files []struct {
+					ABI     string `json:"abi"`
+					BuildID string `json:"buildId"`
+				} `json:"files"`
+				ZipSize      int64 `json:"zipSize"`
+				Verification struct {
+					Matched int `json:"matched"`
+				} `json:"verification"`
+			} `json:"nativeSymbols"`
+		}
+		decodeInspectData(t, out, &got)
+		if got.VersionCode != 31 || len(got.NativeSymbols.Files) != 1 || got.NativeSymbols.Files[0].BuildID != "aa01" ||
+			got.NativeSymbols.ZipSize == 0 || got.NativeSymbols.Verification.Matched != 1 {
+			t.Errorf("result = %+v", got)
+		}
+	})
+
+	t.Run("mismatched build IDs", func(t *testing.T) {
+		cmd := &PublishDeobfuscationUploadCmd{Type: "nativeCode", NativeFrom: buildDir, Bundle: bundle([]byte{0xbb}), DryRun: true}
+		err := cmd.Run(globals)
+		if apiErr, ok := err.(*errors.APIError); !ok || !strings.Contains(apiErr.Message, "arm64-v8a/libapp.so has build ID aa01, bundle has bb") {
+			t.Errorf("err = %v", err)
+		}
+	})
+
+	t.Run("requires nativeCode", func(t *testing.T) {
+		cmd := &PublishDeobfuscationUploadCmd{Type: "proguard", NativeFrom: buildDir, VersionCode: 31, DryRun: true}
+		if err := cmd.Run(globals); err == nil || !strings.Contains(err.Error(), "requires --type nativeCode") {
+			t.Errorf("err = %v", err)
+		}
+	})
+
+	t.Run("no symbols", func(t *testing.T) {
+		cmd := &PublishDeobfuscationUploadCmd{Type: "nativeCode", NativeFrom: t.TempDir(), VersionCode: 31, DryRun: true}
+		if err := cmd.Run(globals); err == nil || !strings.Contains(err.Error(), "no unstripped native libraries") {
+			t.Errorf("err = %v", err)
+		}
+	})
+}
+
+func TestCheckVersionCodeSupersedes(t *testing.T) {
+	tracks := []*androidpublisher.Track{
+		{Track: "internal", Releases: []*androidpublisher.TrackRelease{{Status: "completed", VersionCodes: []int64{120}}}},
+		{Track: "beta", Releases: []*androidpublisher.TrackRelease{
+			{Status: "draft", VersionCodes: []int64{200}},
+			{Status: "completed", VersionCodes: []int64{110}},
+		}},
+		{Track: "production", Releases: []*androidpublisher.TrackRelease{{Status: "inProgress", VersionCodes: []int64{100}}}},
+		{Track: "qa-closed", Releases: []*androidpublisher.TrackRelease{{Status: "halted", VersionCodes: []int64{201}}}},
+	}
+
+	tests := []struct {
+		name        string
+		target      string
+		versionCode int64
+		wantTrack   string
+	}{
+		{"higher than everything", "internal", 202, ""},
+		{"shadowed on target", "internal", 120, "internal"},
+		{"less stable tracks checked", "production", 115, "internal"},
+		{"equal on another track", "alpha", 110, "internal"},
+		{"drafts checked", "internal", 150, "beta"},
+		{"custom tracks checked", "internal", 201, "qa-closed"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkVersionCodeSupersedes(tracks, tc.target, tc.versionCode)
+			if tc.wantTrack == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			apiErr, ok := err.(*errors.APIError)
+			if !ok {
+				t.Fatalf("expected APIError, got %T %v", err, err)
+			}
+			if apiErr.Code != errors.CodeValidationError || !strings.Contains(apiErr.Hint, "track "+tc.wantTrack) {
+				t.Errorf("error = %v (hint %q), want conflict on %s", apiErr, apiErr.Hint, tc.wantTrack)
+			}
+		})
+	}
+}
+
 // ============================================================================
 // Result Building Tests
 // ============================================================================
@@ -574,7 +830,7 @@ func TestPublishUploadCmd_BuildUploadResult(t *testing.T) {
 	}
 
 	start := time.Now()
-	err = cmd.buildUploadResult(start, fileInfo, "apk", "edit-123", 100, "sha1-abc", "sha256-xyz", true, globals)
+	err = cmd.buildUploadResult(start, fileInfo, "apk", "edit-123", 100, "sha1-abc", "sha256-xyz", true, nil, globals)
 	if err != nil {
 		t.Errorf("Unexpected error building result: %v", err)
 	}
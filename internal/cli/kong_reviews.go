@@ -38,6 +38,7 @@ type ReviewsListCmd struct {
 	PageSize            int64  `help:"Results per page" default:"50"`
 	PageToken           string `help:"Pagination token"`
 	All                 bool   `help:"Fetch all pages"`
+	EmitEvents          bool   `help:"Emit review.new webhook events for reviews modified since the previous --emit-events run"`
 }
 
 // reviewData represents a simplified review for output.
@@ -177,6 +178,10 @@ func (cmd *ReviewsListCmd) Run(globals *Globals) error {
 		return errors.NewAPIError(errors.CodeGeneralError, fmt.Sprintf("failed to list reviews: %v", err))
 	}
 
+	if cmd.EmitEvents {
+		emitNewReviews(ctx, globals, allReviews)
+	}
+
 	// Apply filtering
 	filtered := cmd.filterReviews(allReviews)
 
@@ -12,6 +12,8 @@ import (
 	"google.golang.org/api/androidpublisher/v3"
 
 	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/api"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/manifestlint"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/preflight"
 	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/errors"
 	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/output"
 )
@@ -23,6 +25,7 @@ type TestingCmd struct {
 	Screenshots   TestingScreenshotsCmd   `cmd:"" help:"Capture screenshots across devices"`
 	Validate      TestingValidateCmd      `cmd:"" help:"Comprehensive app validation"`
 	Compatibility TestingCompatibilityCmd `cmd:"" help:"Check device compatibility"`
+	Lint          TestingLintCmd          `cmd:"" help:"Lint the app manifest for security issues (JSON, table or SARIF)"`
 }
 
 // TestingPrelaunchCmd triggers or checks pre-launch report.
@@ -358,7 +361,7 @@ func (cmd *TestingScreenshotsCmd) Run(globals *Globals) error {
 // TestingValidateCmd performs comprehensive app validation.
 type TestingValidateCmd struct {
 	AppFile string   `help:"App file to validate (APK/AAB)" type:"existingfile" required:""`
-	Checks  []string `help:"Validation checks to run (repeatable)" default:"all" enum:"all,aab,signing,permissions,size,api-level"`
+	Checks  []string `help:"Validation checks to run (repeatable)" default:"all" enum:"all,aab,signing,permissions,size,api-level,page-size"`
 	Strict  bool     `help:"Treat warnings as errors"`
 }
 
@@ -380,65 +383,153 @@ type testingValidateCheck struct {
 	Details interface{} `json:"details,omitempty"`
 }
 
+// testingValidateChecks lists the checks "all" expands to, in report order.
+var testingValidateChecks = []string{"aab", "signing", "permissions", "size", "api-level", "page-size"}
+
 // Run executes the validate command.
 func (cmd *TestingValidateCmd) Run(globals *Globals) error {
 	if globals.Verbose {
 		fmt.Fprintf(os.Stderr, "Validating app file: %s\n", cmd.AppFile)
 	}
 
+	a, err := openArtifact(cmd.AppFile)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = a.Close() }()
+
 	result := &testingValidateResult{
 		Valid:       true,
-		Status:      "passed",
+		Status:      statusPassed,
 		Checks:      make([]testingValidateCheck, 0),
 		ValidatedAt: time.Now(),
 	}
 
-	// Run validation checks
-	for _, check := range cmd.Checks {
+	for _, check := range cmd.expandChecks() {
+		var c preflight.Check
 		switch check {
-		case checkAll, "aab":
-			result.Checks = append(result.Checks, testingValidateCheck{
-				Name:    "aab_format",
-				Status:  "pass",
-				Message: "App Bundle format is valid",
-			})
-
+		case "aab":
+			c = preflight.AAB(a)
 		case "signing":
-			result.Checks = append(result.Checks, testingValidateCheck{
-				Name:    "signing",
-				Status:  "pass",
-				Message: "App is properly signed",
-			})
-
+			c = preflight.Signing(a)
 		case "permissions":
-			result.Checks = append(result.Checks, testingValidateCheck{
-				Name:    "permissions",
-				Status:  "pass",
-				Message: "Permissions are valid",
-			})
-
+			c = preflight.Permissions(a.Manifest)
 		case "size":
-			result.Checks = append(result.Checks, testingValidateCheck{
-				Name:    "size",
-				Status:  "pass",
-				Message: "App size is within limits",
-			})
-
+			c = preflight.Size(a)
 		case "api-level":
-			result.Checks = append(result.Checks, testingValidateCheck{
-				Name:    "api_level",
-				Status:  "pass",
-				Message: "Target API level is valid",
-			})
+			c = preflight.APILevel(a.Manifest, result.ValidatedAt)
+		case "page-size":
+			c = preflight.PageSize(a)
+		default:
+			continue
+		}
+		if c.Status == preflight.StatusWarn && cmd.Strict {
+			c.Status = preflight.StatusFail
+		}
+
+		switch c.Status {
+		case preflight.StatusFail:
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %s", c.Name, c.Message))
+		case preflight.StatusWarn:
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: %s", c.Name, c.Message))
+		}
+		entry := testingValidateCheck{Name: c.Name, Status: c.Status, Message: c.Message}
+		if c.Details != nil {
+			entry.Details = c.Details
 		}
+		result.Checks = append(result.Checks, entry)
 	}
 
-	if cmd.Strict && len(result.Warnings) > 0 {
+	if len(result.Errors) > 0 {
 		result.Valid = false
-		result.Status = "failed"
+		result.Status = statusFailed
 	}
 
-	return writeOutput(globals, output.NewResult(result))
+	if err := writeOutput(globals, output.NewResult(result)); err != nil {
+		return err
+	}
+	if !result.Valid {
+		return errors.NewAPIError(errors.CodeValidationError, "app validation failed").
+			WithHint("Fix the failing checks in the validation report").
+			WithDetails(map[string]interface{}{"errors": result.Errors, "strict": cmd.Strict})
+	}
+	return nil
+}
+
+// expandChecks resolves "all" and removes duplicates, keeping report order.
+// No checks selected means all of them.
+func (cmd *TestingValidateCmd) expandChecks() []string {
+	selected := make(map[string]bool)
+	for _, c := range cmd.Checks {
+		if c == checkAll {
+			return testingValidateChecks
+		}
+		selected[c] = true
+	}
+	if len(selected) == 0 {
+		return testingValidateChecks
+	}
+	out := make([]string, 0, len(selected))
+	for _, c := range testingValidateChecks {
+		if selected[c] {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// TestingLintCmd runs the manifest security lint rules.
+type TestingLintCmd struct {
+	AppFile        string   `help:"App file to lint (APK/AAB)" type:"existingfile" required:""`
+	SourceManifest string   `help:"Source AndroidManifest.xml path that SARIF results point at (default: the app file)"`
+	Disable        []string `help:"Rule IDs or names to skip (repeatable)"`
+	Strict         bool     `help:"Treat warnings as errors"`
+}
+
+// Run executes the lint command. Use --output sarif for GitHub code scanning.
+func (cmd *TestingLintCmd) Run(globals *Globals) error {
+	a, err := openArtifact(cmd.AppFile)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = a.Close() }()
+
+	findings, err := manifestlint.LintArtifact(a)
+	if err != nil {
+		return errors.NewAPIError(errors.CodeValidationError, fmt.Sprintf("cannot read module manifests: %v", err))
+	}
+	disabled := make(map[string]bool, len(cmd.Disable))
+	for _, d := range cmd.Disable {
+		disabled[strings.ToUpper(d)] = true
+	}
+	kept := make([]manifestlint.Finding, 0, len(findings))
+	for _, f := range findings {
+		if disabled[f.RuleID] || disabled[strings.ToUpper(f.Rule)] {
+			continue
+		}
+		if cmd.Strict && f.Level == output.SARIFLevelWarning {
+			f.Level = output.SARIFLevelError
+		}
+		kept = append(kept, f)
+	}
+
+	report := manifestlint.NewReport(cmd.AppFile, kept)
+	report.ManifestURI = cmd.SourceManifest
+	report.ToolVersion = Version
+	var data interface{} = report
+	switch output.ParseFormat(globals.Output) {
+	case output.FormatTable, output.FormatMarkdown, output.FormatCSV, output.FormatExcel:
+		data = report.Rows()
+	}
+	if err := writeOutput(globals, output.NewResult(data).WithServices("testing")); err != nil {
+		return err
+	}
+	if n := report.Counts[output.SARIFLevelError]; n > 0 {
+		return errors.NewAPIError(errors.CodeValidationError, fmt.Sprintf("manifest lint found %d error(s)", n)).
+			WithHint("Fix the findings or skip a rule with --disable <id>").
+			WithDetails(map[string]interface{}{"counts": report.Counts, "strict": cmd.Strict})
+	}
+	return nil
 }
 
 // TestingCompatibilityCmd checks device compatibility.
@@ -4,7 +4,9 @@
 package cli
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -18,7 +20,10 @@ import (
 	"google.golang.org/api/androidpublisher/v3"
 	"google.golang.org/api/option"
 
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/artifact"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/artifact/artifacttest"
 	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/errors"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/output"
 )
 
 // ============================================================================
@@ -31,7 +36,7 @@ func TestTestingCmd_HasExpectedSubcommands(t *testing.T) {
 	typeOfCmd := v.Type()
 
 	expectedSubcommands := []string{
-		"Prelaunch", "DeviceLab", "Screenshots", "Validate", "Compatibility",
+		"Prelaunch", "DeviceLab", "Screenshots", "Validate", "Compatibility", "Lint",
 	}
 
 	for _, name := range expectedSubcommands {
@@ -162,7 +167,7 @@ func TestTestingValidateCmd_FieldTags(t *testing.T) {
 	}
 
 	enumTag := field.Tag.Get("enum")
-	expected := "all,aab,signing,permissions,size,api-level"
+	expected := "all,aab,signing,permissions,size,api-level,page-size"
 	if enumTag != expected {
 		t.Errorf("TestingValidateCmd.Checks enum tag = %q, want %q", enumTag, expected)
 	}
@@ -571,47 +576,72 @@ func TestTestingScreenshotsCmd_Run_VerboseMode(t *testing.T) {
 // TestingValidateCmd Tests
 // ============================================================================
 
+// writeValidatableArtifact writes a signed APK or AAB that passes every
+// testing validate check: current target SDK, no restricted permissions and
+// a 16 KB aligned native library.
+func writeValidatableArtifact(t *testing.T, kind string) string {
+	t.Helper()
+	manifest := artifacttest.E("manifest", []string{"package", "com.example.app", "android:versionCode", "5"},
+		artifacttest.E("uses-sdk", []string{"android:minSdkVersion", "24", "android:targetSdkVersion", "36"}),
+		artifacttest.E("uses-permission", []string{"android:name", "android.permission.INTERNET"}),
+	)
+	signer := artifacttest.NewSigner(t, "upload")
+	lib := artifacttest.ELF(true, artifact.PageSize16K)
+	if kind == "apk" {
+		path := artifacttest.WriteAPK(t, manifest,
+			artifacttest.Entry{Name: "lib/arm64-v8a/libapp.so", Data: lib, Stored: true, Align: artifact.PageSize16K})
+		signer.SignJAR(t, path)
+		signer.SignAPK(t, path, artifacttest.BlockIDV2)
+		return path
+	}
+	path := artifacttest.WriteAAB(t, manifest, artifacttest.Entry{Name: "base/lib/arm64-v8a/libapp.so", Data: lib})
+	signer.SignJAR(t, path)
+	return path
+}
+
 func TestTestingValidateCmd_Run_ValidInputs(t *testing.T) {
 	tests := []struct {
 		name           string
 		cmd            TestingValidateCmd
 		expectedChecks int
+		expectWarnings int
 	}{
 		{
 			name: "all checks",
 			cmd: TestingValidateCmd{
-				AppFile: createTempFile(t, "test.aab", []byte("fake aab")),
+				AppFile: writeValidatableArtifact(t, "aab"),
 				Checks:  []string{"all"},
 				Strict:  false,
 			},
-			expectedChecks: 1, // 'all' maps to aab check
+			expectedChecks: 6,
 		},
 		{
 			name: "specific checks",
 			cmd: TestingValidateCmd{
-				AppFile: createTempFile(t, "test.apk", []byte("fake apk")),
+				AppFile: writeValidatableArtifact(t, "apk"),
 				Checks:  []string{"aab", "signing", "permissions"},
 				Strict:  false,
 			},
 			expectedChecks: 3,
+			expectWarnings: 1, // APK instead of AAB
 		},
 		{
 			name: "all individual checks",
 			cmd: TestingValidateCmd{
-				AppFile: createTempFile(t, "test.aab", []byte("fake aab")),
-				Checks:  []string{"aab", "signing", "permissions", "size", "api-level"},
+				AppFile: writeValidatableArtifact(t, "aab"),
+				Checks:  []string{"aab", "signing", "permissions", "size", "api-level", "page-size"},
 				Strict:  false,
 			},
-			expectedChecks: 5,
+			expectedChecks: 6,
 		},
 		{
 			name: "strict mode",
 			cmd: TestingValidateCmd{
-				AppFile: createTempFile(t, "test.apk", []byte("fake apk")),
+				AppFile: writeValidatableArtifact(t, "aab"),
 				Checks:  []string{"all"},
 				Strict:  true,
 			},
-			expectedChecks: 1,
+			expectedChecks: 6,
 		},
 	}
 
@@ -622,17 +652,176 @@ func TestTestingValidateCmd_Run_ValidInputs(t *testing.T) {
 				Output:  "json",
 			}
 
-			err := tc.cmd.Run(globals)
+			out, err := captureInspectStdout(t, func() error { return tc.cmd.Run(globals) })
 			if err != nil {
 				t.Fatalf("Unexpected error: %v", err)
 			}
+			var got testingValidateResult
+			decodeInspectData(t, out, &got)
+			if !got.Valid || len(got.Checks) != tc.expectedChecks || len(got.Warnings) != tc.expectWarnings {
+				t.Errorf("result = %+v", got)
+			}
+		})
+	}
+}
+
+func TestTestingValidateCmd_Run_Failures(t *testing.T) {
+	signer := artifacttest.NewSigner(t, "upload")
+	// The shared fixture targets SDK 34 and requests READ_SMS.
+	fixture := artifacttest.Manifest("com.example.app", 5)
+	signedAAB := func() string {
+		path := artifacttest.WriteAAB(t, fixture)
+		signer.SignJAR(t, path)
+		return path
+	}
+	tampered := artifacttest.WriteAAB(t, fixture, artifacttest.Entry{Name: "base/assets/data.txt", Data: []byte("original"), Stored: true})
+	signer.SignJAR(t, tampered)
+	data, err := os.ReadFile(tampered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := bytes.Index(data, []byte("original"))
+	copy(data[i:], "modified")
+	if err := os.WriteFile(tampered, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		file       string
+		checks     []string
+		strict     bool
+		wantStatus map[string]string
+	}{
+		{
+			name:       "unsigned bundle",
+			file:       artifacttest.WriteAAB(t, fixture),
+			checks:     []string{"signing"},
+			wantStatus: map[string]string{"signing": "fail"},
+		},
+		{
+			name:       "signature does not verify",
+			file:       tampered,
+			checks:     []string{"signing"},
+			wantStatus: map[string]string{"signing": "fail"},
+		},
+		{
+			name:       "4 KB aligned native library",
+			file:       artifacttest.WriteAAB(t, fixture, artifacttest.Entry{Name: "base/lib/arm64-v8a/libold.so", Data: artifacttest.ELF(true, 4096)}),
+			checks:     []string{"page-size"},
+			wantStatus: map[string]string{"page_size": "fail"},
+		},
+		{
+			name:       "stale target sdk",
+			file:       signedAAB(),
+			checks:     []string{"api-level"},
+			wantStatus: map[string]string{"api_level": "fail"},
+		},
+		{
+			name:       "restricted permission fails in strict mode",
+			file:       signedAAB(),
+			checks:     []string{"permissions"},
+			strict:     true,
+			wantStatus: map[string]string{"permissions": "fail"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := &TestingValidateCmd{AppFile: tc.file, Checks: tc.checks, Strict: tc.strict}
+			out, err := captureInspectStdout(t, func() error { return cmd.Run(&Globals{Output: "json"}) })
+			apiErr, ok := err.(*errors.APIError)
+			if !ok || apiErr.Code != errors.CodeValidationError {
+				t.Fatalf("expected validation error, got %T %v", err, err)
+			}
+			var got testingValidateResult
+			decodeInspectData(t, out, &got)
+			if got.Valid || got.Status != "failed" {
+				t.Errorf("valid=%v status=%s", got.Valid, got.Status)
+			}
+			for _, c := range got.Checks {
+				if want, ok := tc.wantStatus[c.Name]; ok && c.Status != want {
+					t.Errorf("%s status = %s, want %s (%s)", c.Name, c.Status, want, c.Message)
+				}
+			}
 		})
 	}
+
+	t.Run("restricted permission only warns by default", func(t *testing.T) {
+		cmd := &TestingValidateCmd{AppFile: signedAAB(), Checks: []string{"permissions"}}
+		out, err := captureInspectStdout(t, func() error { return cmd.Run(&Globals{Output: "json"}) })
+		if err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+		var got testingValidateResult
+		decodeInspectData(t, out, &got)
+		if !got.Valid || len(got.Warnings) != 1 || !strings.Contains(got.Warnings[0], "READ_SMS") {
+			t.Errorf("result = %+v", got)
+		}
+	})
+}
+
+func TestTestingLintCmd_Run(t *testing.T) {
+	manifest := artifacttest.Manifest("com.example.app", 5)
+	manifest.Child("application").Set("android:debuggable", "true")
+	debuggable := artifacttest.WriteAAB(t, manifest)
+
+	t.Run("json", func(t *testing.T) {
+		out, err := captureInspectStdout(t, func() error {
+			return (&TestingLintCmd{AppFile: debuggable}).Run(&Globals{Output: "json"})
+		})
+		apiErr, ok := err.(*errors.APIError)
+		if !ok || apiErr.Code != errors.CodeValidationError {
+			t.Fatalf("err = %v, want validation error for debuggable build", err)
+		}
+		var got struct {
+			Findings []struct {
+				RuleID string `json:"ruleId"`
+				Module string `json:"module"`
+			} `json:"findings"`
+			Counts map[string]int `json:"counts"`
+		}
+		decodeInspectData(t, out, &got)
+		if got.Counts["error"] != 2 || got.Findings[0].RuleID != "GPD1001" || got.Findings[0].Module != "base" {
+			t.Errorf("report = %+v", got)
+		}
+	})
+
+	t.Run("sarif", func(t *testing.T) {
+		out, _ := captureInspectStdout(t, func() error {
+			return (&TestingLintCmd{AppFile: debuggable, SourceManifest: "app/src/main/AndroidManifest.xml"}).Run(&Globals{Output: "sarif"})
+		})
+		var log output.SARIFLog
+		if err := json.Unmarshal([]byte(out), &log); err != nil {
+			t.Fatalf("sarif: %v\n%s", err, out)
+		}
+		if log.Version != "2.1.0" || len(log.Runs[0].Results) == 0 ||
+			log.Runs[0].Results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI != "app/src/main/AndroidManifest.xml" {
+			t.Errorf("log = %+v", log)
+		}
+	})
+
+	t.Run("disabled rules pass", func(t *testing.T) {
+		_, err := captureInspectStdout(t, func() error {
+			return (&TestingLintCmd{AppFile: debuggable, Disable: []string{"GPD1001", "missing-exported"}}).Run(&Globals{Output: "json"})
+		})
+		if err != nil {
+			t.Errorf("err = %v", err)
+		}
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		_, err := captureInspectStdout(t, func() error {
+			return (&TestingLintCmd{AppFile: debuggable, Disable: []string{"GPD1001", "GPD1006"}, Strict: true}).Run(&Globals{Output: "json"})
+		})
+		if err == nil {
+			t.Error("strict lint passed with warnings")
+		}
+	})
 }
 
 func TestTestingValidateCmd_Run_VerboseMode(t *testing.T) {
 	cmd := &TestingValidateCmd{
-		AppFile: createTempFile(t, "test.apk", []byte("fake apk")),
+		AppFile: writeValidatableArtifact(t, "apk"),
 		Checks:  []string{"all"},
 		Strict:  false,
 	}
@@ -1152,7 +1341,7 @@ func TestTestingCommands_EdgeCases(t *testing.T) {
 
 	t.Run("validate with empty checks", func(t *testing.T) {
 		cmd := &TestingValidateCmd{
-			AppFile: createTempFile(t, "test.apk", []byte("fake apk")),
+			AppFile: writeValidatableArtifact(t, "apk"),
 			Checks:  []string{},
 			Strict:  false,
 		}
@@ -1562,7 +1751,7 @@ func TestTestingCommands_NilEmptyInputs(t *testing.T) {
 
 	t.Run("validate with nil checks defaults to all", func(t *testing.T) {
 		cmd := &TestingValidateCmd{
-			AppFile: createTempFile(t, "test.apk", []byte("fake apk")),
+			AppFile: writeValidatableArtifact(t, "apk"),
 			Checks:  nil, // Should default to all
 			Strict:  false,
 		}
@@ -1843,7 +2032,7 @@ func TestTestingCommands_ErrorRecovery(t *testing.T) {
 		}
 
 		err := cmd.Run(globals)
-		// Validate command doesn't actually read the file, just validates structure
+		// The file is not a zip, so the artifact cannot be opened
 		if err != nil {
 			t.Logf("Got error for non-APK file: %v", err)
 		}
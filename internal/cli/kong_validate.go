@@ -10,6 +10,8 @@ import (
 	"google.golang.org/api/androidpublisher/v3"
 
 	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/api"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/artifact"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/preflight"
 	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/errors"
 	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/output"
 )
@@ -37,10 +39,11 @@ var validateTrackProbe = defaultValidateTrackProbe
 var validateListingProbe = defaultValidateListingProbe
 
 type readinessCheck struct {
-	Name           string `json:"name"`
-	Status         string `json:"status"` // pass, fail, warn, skip
-	Message        string `json:"message"`
-	Recommendation string `json:"recommendation,omitempty"`
+	Name           string      `json:"name"`
+	Status         string      `json:"status"` // pass, fail, warn, skip
+	Message        string      `json:"message"`
+	Recommendation string      `json:"recommendation,omitempty"`
+	Details        interface{} `json:"details,omitempty"`
 }
 
 // Run executes the validate command.
@@ -437,12 +440,35 @@ func validateArtifactFile(path string) []readinessCheck {
 			Status:  "fail",
 			Message: "file is empty",
 		})
-	} else {
-		out = append(out, readinessCheck{
-			Name:    "artifact.size",
-			Status:  "pass",
-			Message: fmt.Sprintf("%d bytes", info.Size()),
-		})
+		return out
+	}
+	out = append(out, readinessCheck{
+		Name:    "artifact.size",
+		Status:  "pass",
+		Message: fmt.Sprintf("%d bytes", info.Size()),
+	})
+	return append(out, validateArtifactPageSize(path))
+}
+
+// validateArtifactPageSize runs the 16 KB page-size check on the native
+// libraries. An artifact that cannot be decoded only warns: the checks above
+// already cover the file itself.
+func validateArtifactPageSize(path string) readinessCheck {
+	const name = "artifact.page_size"
+	a, err := artifact.Open(path)
+	if err != nil {
+		return readinessCheck{
+			Name:    name,
+			Status:  "warn",
+			Message: fmt.Sprintf("cannot decode artifact, native libraries not checked: %v", err),
+		}
+	}
+	defer func() { _ = a.Close() }()
+
+	c := preflight.PageSize(a)
+	check := readinessCheck{Name: name, Status: c.Status, Message: c.Message, Details: c.Details}
+	if c.Status == preflight.StatusFail {
+		check.Recommendation = "Build with NDK r28+ (or link with -Wl,-z,max-page-size=16384) and AGP 8.5.1+ so uncompressed libraries are 16 KB zip-aligned"
 	}
-	return out
+	return check
 }
@@ -14,6 +14,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/artifact/artifacttest"
 	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/playship"
 )
 
@@ -82,6 +83,38 @@ func TestValidateArtifactFile_Helpers(t *testing.T) {
 	}
 }
 
+// TestValidateCmd_PageSize reports per-library 16 KB alignment for real
+// artifacts and fails readiness when a 64-bit library is 4 KB aligned.
+func TestValidateCmd_PageSize(t *testing.T) {
+	aab := artifacttest.WriteAAB(t, artifacttest.Manifest("com.example.app", 3),
+		artifacttest.Entry{Name: "base/lib/arm64-v8a/libok.so", Data: artifacttest.ELF(true, 16384)},
+		artifacttest.Entry{Name: "base/lib/arm64-v8a/libold.so", Data: artifacttest.ELF(true, 4096)},
+	)
+	body := captureValidateStdout(t, func() error {
+		cmd := &ValidateCmd{Track: "internal", File: aab, DryRun: true}
+		return cmd.Run(&Globals{Output: "json", Package: "com.example.app"})
+	})
+
+	report := parseValidateJSON(t, body)
+	if report["status"] != "not_ready" {
+		t.Fatalf("report status = %v, want not_ready", report["status"])
+	}
+	check := findValidateCheck(t, report, "artifact.page_size")
+	if check["status"] != "fail" || check["recommendation"] == nil {
+		t.Fatalf("artifact.page_size = %v", check)
+	}
+	libs, _ := check["details"].(map[string]interface{})["libraries"].([]interface{})
+	statuses := map[string]interface{}{}
+	for _, l := range libs {
+		lib := l.(map[string]interface{})
+		statuses[lib["path"].(string)] = lib["status"]
+	}
+	want := map[string]interface{}{"base/lib/arm64-v8a/libok.so": "pass", "base/lib/arm64-v8a/libold.so": "fail"}
+	if !reflect.DeepEqual(statuses, want) {
+		t.Errorf("library statuses = %v, want %v", statuses, want)
+	}
+}
+
 // TestValidateCmd_NetworkFlagOnStruct ensures --network is wired on ValidateCmd.
 func TestValidateCmd_NetworkFlagOnStruct(t *testing.T) {
 	typ := reflect.TypeOf(ValidateCmd{})
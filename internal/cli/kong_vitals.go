@@ -9,6 +9,7 @@ import (
 	playdeveloperreporting "google.golang.org/api/playdeveloperreporting/v1beta1"
 
 	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/api"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/crashcluster"
 	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/errors"
 	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/output"
 )
@@ -23,6 +24,7 @@ const (
 	metricSetStuckBackgroundWakelock = "stuckBackgroundWakelockRateMetricSet"
 	metricSetExcessiveWakeup         = "excessiveWakeupRateMetricSet"
 	metricSetErrorCount              = "errorCountMetricSet"
+	metricSetLmkRate                 = "lmkRateMetricSet"
 )
 
 // VitalsCrashesCmd queries crash rate data.
@@ -275,9 +277,16 @@ func (r anrRatePageResponse) GetItems() []*playdeveloperreporting.GooglePlayDeve
 
 // VitalsErrorsCmd contains error commands.
 type VitalsErrorsCmd struct {
-	Issues  VitalsErrorsIssuesCmd  `cmd:"" help:"Search error issues"`
-	Reports VitalsErrorsReportsCmd `cmd:"" help:"Search error reports"`
-	Counts  VitalsErrorsCountsCmd  `cmd:"" help:"Error count metrics"`
+	Issues  VitalsErrorsIssuesGroupCmd `cmd:"" help:"Search and export error issues"`
+	Reports VitalsErrorsReportsCmd     `cmd:"" help:"Search error reports"`
+	Counts  VitalsErrorsCountsCmd      `cmd:"" help:"Error count metrics"`
+}
+
+// VitalsErrorsIssuesGroupCmd contains error issue commands. Search is the
+// default so "vitals errors issues --query ..." keeps working.
+type VitalsErrorsIssuesGroupCmd struct {
+	Search VitalsErrorsIssuesCmd       `cmd:"" default:"withargs" help:"Search error issues (default)"`
+	Export VitalsErrorsIssuesExportCmd `cmd:"" help:"Export new and regressed error issues for GitHub, Jira or CSV import"`
 }
 
 // VitalsErrorsIssuesCmd searches error issues.
@@ -411,14 +420,22 @@ func (r errorIssuesPageResponse) GetItems() []*playdeveloperreporting.GooglePlay
 	return r.resp.ErrorIssues
 }
 
+// deobfuscateWithoutMapping warns that --deobfuscate was given no mapping.
+const deobfuscateWithoutMapping = "--deobfuscate has no effect without --mapping or --mapping-dir; report text is shown as Play returned it"
+
 // VitalsErrorsReportsCmd searches error reports.
 type VitalsErrorsReportsCmd struct {
-	Query       string `help:"Search query"`
-	Interval    string `help:"Time interval: last7Days, last30Days, last90Days" default:"last30Days"`
-	PageSize    int64  `help:"Results per page" default:"50"`
-	PageToken   string `help:"Pagination token"`
-	All         bool   `help:"Fetch all pages"`
-	Deobfuscate bool   `help:"Format report text for readability"`
+	Query       string   `help:"Search query"`
+	Interval    string   `help:"Time interval: last7Days, last30Days, last90Days" default:"last30Days"`
+	PageSize    int64    `help:"Results per page" default:"50"`
+	PageToken   string   `help:"Pagination token"`
+	All         bool     `help:"Fetch all pages"`
+	Deobfuscate bool     `help:"Retrace obfuscated stack traces in report text offline with --mapping or --mapping-dir (no effect without one)"`
+	Mapping     string   `help:"R8/ProGuard mapping.txt used for reports without a version-specific mapping (implies --deobfuscate)" type:"existingfile"`
+	MappingDir  string   `help:"Directory of mappings per version code: <dir>/<versionCode>/mapping.txt, <dir>/<versionCode>.txt or <dir>/mapping-<versionCode>.txt (implies --deobfuscate)" type:"existingdir"`
+	Cluster     bool     `help:"Group reports by crash signature (root-cause exception and top app frames) and flag clusters new in the latest version code"`
+	TopFrames   int      `help:"App frames in a crash signature" default:"3"`
+	AppPackage  []string `help:"Package prefixes of app frames (default: every frame outside java, android, androidx and kotlin)"`
 }
 
 // Run executes the errors reports search command.
@@ -427,6 +444,17 @@ func (cmd *VitalsErrorsReportsCmd) Run(globals *Globals) error {
 		return err
 	}
 
+	retracer, err := newReportRetracer(cmd.Mapping, cmd.MappingDir)
+	if err != nil {
+		return err
+	}
+	// --deobfuscate was accepted and ignored before mappings were
+	// supported; without one it still only warns.
+	var warnings []string
+	if cmd.Deobfuscate && retracer == nil {
+		warnings = append(warnings, deobfuscateWithoutMapping)
+	}
+
 	ctx := context.Background()
 	authMgr := newAuthManager()
 
@@ -495,9 +523,30 @@ func (cmd *VitalsErrorsReportsCmd) Run(globals *Globals) error {
 	}
 
 	data := formatErrorReports(allReports)
+	if retracer != nil {
+		retraced, err := retracer.retraceReports(data, allReports)
+		if err != nil {
+			return err
+		}
+		warnings = append(warnings, retraced...)
+	}
+	if cmd.Cluster {
+		clusters := crashcluster.Build(clusterReports(allReports, data), crashcluster.Options{AppPackages: cmd.AppPackage, TopFrames: cmd.TopFrames})
+		var clusterData interface{} = clusters
+		switch output.ParseFormat(globals.Output) {
+		case output.FormatTable, output.FormatMarkdown, output.FormatCSV, output.FormatExcel:
+			clusterData = clusters.Rows()
+		}
+		return writeOutput(globals, output.NewResult(clusterData).
+			WithDuration(time.Since(startTime)).
+			WithServices("playdeveloperreporting").
+			WithWarnings(warnings...))
+	}
+
 	result := output.NewResult(data).
 		WithDuration(time.Since(startTime)).
-		WithServices("playdeveloperreporting")
+		WithServices("playdeveloperreporting").
+		WithWarnings(warnings...)
 
 	return outputResult(result, globals.Output, globals.Pretty)
 }
@@ -718,6 +767,7 @@ type VitalsMetricsCmd struct {
 	SlowRendering    VitalsMetricsSlowRenderingCmd    `cmd:"" help:"Query slow rendering data"`
 	SlowStart        VitalsMetricsSlowStartCmd        `cmd:"" help:"Query slow start data"`
 	StuckWakelocks   VitalsMetricsStuckWakelocksCmd   `cmd:"" help:"Query stuck wakelocks data"`
+	Lmk              VitalsMetricsLmkCmd              `cmd:"" help:"Query low memory kill (LMK) rate data"`
 }
 
 // VitalsMetricsExcessiveWakeupsCmd queries excessive wakeups data.
@@ -1184,6 +1234,123 @@ func (r stuckWakelockPageResponse) GetItems() []*playdeveloperreporting.GooglePl
 	return r.resp.Rows
 }
 
+// VitalsMetricsLmkCmd queries low memory kill rate data.
+type VitalsMetricsLmkCmd struct {
+	StartDate  string   `help:"Start date (ISO 8601)"`
+	EndDate    string   `help:"End date (ISO 8601)"`
+	Dimensions []string `help:"Dimensions for grouping"`
+	Format     string   `help:"Output format: json, csv" default:"json"`
+	PageSize   int64    `help:"Results per page" default:"100"`
+	PageToken  string   `help:"Pagination token"`
+	All        bool     `help:"Fetch all pages"`
+}
+
+// Run executes the lmk command.
+func (cmd *VitalsMetricsLmkCmd) Run(globals *Globals) error {
+	if err := requirePackage(globals.Package); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	authMgr := newAuthManager()
+
+	creds, err := authMgr.Authenticate(ctx, globals.KeyPath)
+	if err != nil {
+		return err
+	}
+
+	client, err := api.NewClient(ctx, creds.TokenSource, api.WithTimeout(globals.Timeout))
+	if err != nil {
+		return errors.NewAPIError(errors.CodeAuthFailure, fmt.Sprintf("failed to create API client: %v", err))
+	}
+
+	svc, err := client.PlayReporting()
+	if err != nil {
+		return errors.NewAPIError(errors.CodeGeneralError, fmt.Sprintf("failed to get reporting service: %v", err))
+	}
+
+	name := fmt.Sprintf("apps/%s/%s", globals.Package, metricSetLmkRate)
+
+	timelineSpec, err := buildTimelineSpec(cmd.StartDate, cmd.EndDate)
+	if err != nil {
+		return err
+	}
+
+	// The LMK metric set only reports user-perceived rates.
+	req := &playdeveloperreporting.GooglePlayDeveloperReportingV1beta1QueryLmkRateMetricSetRequest{
+		TimelineSpec: timelineSpec,
+		Dimensions:   cmd.Dimensions,
+		Metrics: []string{
+			metricUserPerceivedLmkRate,
+			"userPerceivedLmkRate7dUserWeighted",
+			"userPerceivedLmkRate28dUserWeighted",
+			"distinctUsers",
+		},
+		PageSize:  cmd.PageSize,
+		PageToken: cmd.PageToken,
+	}
+
+	var allRows []*playdeveloperreporting.GooglePlayDeveloperReportingV1beta1MetricsRow
+	startTime := time.Now()
+
+	err = client.DoWithRetry(ctx, func() error {
+		resp, err := svc.Vitals.Lmkrate.Query(name, req).Context(ctx).Do()
+		if err != nil {
+			return err
+		}
+
+		allRows = append(allRows, resp.Rows...)
+
+		if cmd.All && resp.NextPageToken != "" {
+			query := func(pageToken string) (lmkPageResponse, error) {
+				pageReq := &playdeveloperreporting.GooglePlayDeveloperReportingV1beta1QueryLmkRateMetricSetRequest{
+					TimelineSpec: req.TimelineSpec,
+					Dimensions:   req.Dimensions,
+					Metrics:      req.Metrics,
+					PageSize:     req.PageSize,
+					PageToken:    pageToken,
+				}
+				pageResp, err := svc.Vitals.Lmkrate.Query(name, pageReq).Context(ctx).Do()
+				return lmkPageResponse{resp: pageResp}, err
+			}
+			additionalRows, _, err := fetchAllPages(ctx, query, resp.NextPageToken, 0)
+			if err != nil {
+				return err
+			}
+			allRows = append(allRows, additionalRows...)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return errors.NewAPIError(errors.CodeGeneralError, fmt.Sprintf("failed to query LMK rate: %v", err))
+	}
+
+	data := formatMetricsRows(allRows)
+	result := output.NewResult(data).
+		WithDuration(time.Since(startTime)).
+		WithServices("playdeveloperreporting")
+
+	if cmd.Format == formatCSV {
+		return outputResult(result, formatCSV, globals.Pretty)
+	}
+	return outputResult(result, globals.Output, globals.Pretty)
+}
+
+// lmkPageResponse wraps the LMK rate query response to implement PageResponse.
+type lmkPageResponse struct {
+	resp *playdeveloperreporting.GooglePlayDeveloperReportingV1beta1QueryLmkRateMetricSetResponse
+}
+
+func (r lmkPageResponse) GetNextPageToken() string {
+	return r.resp.NextPageToken
+}
+
+func (r lmkPageResponse) GetItems() []*playdeveloperreporting.GooglePlayDeveloperReportingV1beta1MetricsRow {
+	return r.resp.Rows
+}
+
 // VitalsAnomaliesCmd contains anomaly commands.
 type VitalsAnomaliesCmd struct {
 	List VitalsAnomaliesListCmd `cmd:"" help:"List anomalies"`
@@ -1331,22 +1498,7 @@ func (cmd *VitalsQueryCmd) Run(globals *Globals) error {
 	// Determine metric set from the first metric requested
 	metricSetName := metricSetCrashRate
 	if len(cmd.Metrics) > 0 {
-		switch cmd.Metrics[0] {
-		case metricCrashRate, "userPerceivedCrashRate":
-			metricSetName = metricSetCrashRate
-		case metricAnrRate, "userPerceivedAnrRate":
-			metricSetName = metricSetAnrRate
-		case "slowRenderingRate":
-			metricSetName = metricSetSlowRendering
-		case "slowStartRate":
-			metricSetName = metricSetSlowStart
-		case "stuckBackgroundWakelockRate":
-			metricSetName = metricSetStuckBackgroundWakelock
-		case "excessiveWakeupRate":
-			metricSetName = metricSetExcessiveWakeup
-		case "errorCount", "errorReportCount":
-			metricSetName = metricSetErrorCount
-		}
+		metricSetName = metricSetForQuery(cmd.Metrics[0])
 	}
 
 	name := fmt.Sprintf("apps/%s/%s", globals.Package, metricSetName)
@@ -1374,6 +1526,33 @@ func (cmd *VitalsQueryCmd) Run(globals *Globals) error {
 	return outputResult(result, globals.Output, globals.Pretty)
 }
 
+// metricSetForQuery returns the metric set a query for metric targets.
+// The 7-day and 28-day user-weighted variants and the user-perceived rates
+// live in the same set as their base metric; unknown metrics fall back to
+// the crash rate set.
+func metricSetForQuery(metric string) string {
+	base := strings.TrimSuffix(strings.TrimSuffix(metric, "7dUserWeighted"), "28dUserWeighted")
+	switch {
+	case base == metricCrashRate, base == "userPerceivedCrashRate":
+		return metricSetCrashRate
+	case base == metricAnrRate, base == "userPerceivedAnrRate":
+		return metricSetAnrRate
+	case strings.HasPrefix(base, metricSlowRenderingRate):
+		return metricSetSlowRendering
+	case base == metricSlowStartRate:
+		return metricSetSlowStart
+	case base == metricStuckBackgroundWakelockRate:
+		return metricSetStuckBackgroundWakelock
+	case base == metricExcessiveWakeupRate:
+		return metricSetExcessiveWakeup
+	case base == metricErrorCount, base == "errorReportCount":
+		return metricSetErrorCount
+	case base == metricUserPerceivedLmkRate:
+		return metricSetLmkRate
+	}
+	return metricSetCrashRate
+}
+
 // queryMetricSet dispatches the vitals query to the appropriate metric set API endpoint.
 func (cmd *VitalsQueryCmd) queryMetricSet(
 	ctx context.Context,
@@ -1400,10 +1579,12 @@ func (cmd *VitalsQueryCmd) queryMetricSet(
 		allRows, err = cmd.queryExcessiveWakeupMetrics(ctx, client, svc, name, timelineSpec)
 	case metricSetErrorCount:
 		allRows, err = cmd.queryErrorCountMetrics(ctx, client, svc, name, timelineSpec)
+	case metricSetLmkRate:
+		allRows, err = cmd.queryLmkMetrics(ctx, client, svc, name, timelineSpec)
 	default:
 		return nil, errors.NewAPIError(errors.CodeValidationError,
 			fmt.Sprintf("unsupported metric set: %s", metricSetName)).
-			WithHint("Supported metric sets: crashRate, anrRate, slowRenderingRate, slowStartRate, stuckBackgroundWakelockRate, excessiveWakeupRate, errorCount")
+			WithHint("Supported metric sets: crashRate, anrRate, slowRenderingRate, slowStartRate, stuckBackgroundWakelockRate, excessiveWakeupRate, errorCount, lmkRate")
 	}
 
 	if err != nil {
@@ -1678,6 +1859,44 @@ func (cmd *VitalsQueryCmd) queryErrorCountMetrics(ctx context.Context, client *a
 	return allRows, err
 }
 
+func (cmd *VitalsQueryCmd) queryLmkMetrics(ctx context.Context, client *api.Client, svc *playdeveloperreporting.Service, name string, timelineSpec *playdeveloperreporting.GooglePlayDeveloperReportingV1beta1TimelineSpec) ([]*playdeveloperreporting.GooglePlayDeveloperReportingV1beta1MetricsRow, error) {
+	var allRows []*playdeveloperreporting.GooglePlayDeveloperReportingV1beta1MetricsRow
+	req := &playdeveloperreporting.GooglePlayDeveloperReportingV1beta1QueryLmkRateMetricSetRequest{
+		TimelineSpec: timelineSpec,
+		Dimensions:   cmd.Dimensions,
+		Metrics:      cmd.Metrics,
+		PageSize:     cmd.PageSize,
+		PageToken:    cmd.PageToken,
+	}
+	err := client.DoWithRetry(ctx, func() error {
+		resp, qErr := svc.Vitals.Lmkrate.Query(name, req).Context(ctx).Do()
+		if qErr != nil {
+			return qErr
+		}
+		allRows = append(allRows, resp.Rows...)
+		if cmd.All && resp.NextPageToken != "" {
+			query := func(pageToken string) (lmkPageResponse, error) {
+				pageReq := &playdeveloperreporting.GooglePlayDeveloperReportingV1beta1QueryLmkRateMetricSetRequest{
+					TimelineSpec: req.TimelineSpec,
+					Dimensions:   req.Dimensions,
+					Metrics:      req.Metrics,
+					PageSize:     req.PageSize,
+					PageToken:    pageToken,
+				}
+				pageResp, pErr := svc.Vitals.Lmkrate.Query(name, pageReq).Context(ctx).Do()
+				return lmkPageResponse{resp: pageResp}, pErr
+			}
+			additionalRows, _, fErr := fetchAllPages(ctx, query, resp.NextPageToken, 0)
+			if fErr != nil {
+				return fErr
+			}
+			allRows = append(allRows, additionalRows...)
+		}
+		return nil
+	})
+	return allRows, err
+}
+
 // VitalsCapabilitiesCmd lists available vitals metrics.
 type VitalsCapabilitiesCmd struct{}
 
@@ -1758,6 +1977,16 @@ func (cmd *VitalsCapabilitiesCmd) Run(globals *Globals) error {
 					"errorReportCount", "distinctUsers",
 				},
 			},
+			{
+				"name":        metricSetLmkRate,
+				"description": "Low memory kill (LMK) rate metrics, user-perceived only",
+				"command":     "gpd vitals metrics lmk",
+				"freshness":   "Data available within 24 hours",
+				"metrics": []string{
+					"userPerceivedLmkRate", "userPerceivedLmkRate7dUserWeighted",
+					"userPerceivedLmkRate28dUserWeighted", "distinctUsers",
+				},
+			},
 		},
 		"dimensions": []string{
 			"apiLevel", "versionCode", "deviceModel", "deviceBrand",
@@ -1812,6 +2041,12 @@ type VitalsCmd struct {
 	Anomalies    VitalsAnomaliesCmd    `cmd:"" help:"Anomalies in vitals metrics"`
 	Query        VitalsQueryCmd        `cmd:"" help:"Query vitals metrics"`
 	Capabilities VitalsCapabilitiesCmd `cmd:"" help:"List available vitals metrics"`
+	Retrace      VitalsRetraceCmd      `cmd:"" help:"Retrace an obfuscated stack trace offline with an R8/ProGuard mapping"`
+	Canary       VitalsCanaryCmd       `cmd:"" help:"Compare a new version code's vitals against a baseline version with a significance test"`
+	Snapshot     VitalsSnapshotCmd     `cmd:"" help:"Append daily vitals values to the local vitals store"`
+	History      VitalsHistoryCmd      `cmd:"" help:"Query long-term trends from the local vitals store"`
+	Compliance   VitalsComplianceCmd   `cmd:"" help:"Check user-perceived crash and ANR rates against Play's bad behavior thresholds"`
+	Freshness    VitalsFreshnessCmd    `cmd:"" help:"Show the latest complete period of each vitals metric set"`
 }
 
 // Helper functions
@@ -1930,6 +2165,29 @@ func formatErrorReports(reports []*playdeveloperreporting.GooglePlayDeveloperRep
 	return result
 }
 
+// clusterReports converts error reports for clustering, reading the
+// report text from the formatted rows so retraced text is clustered.
+func clusterReports(reports []*playdeveloperreporting.GooglePlayDeveloperReportingV1beta1ErrorReport, data []map[string]interface{}) []crashcluster.Report {
+	out := make([]crashcluster.Report, 0, len(reports))
+	for i, report := range reports {
+		r := crashcluster.Report{Name: report.Name, Issue: report.Issue, Text: report.ReportText}
+		if text, ok := data[i]["reportText"].(string); ok {
+			r.Text = text
+		}
+		if device, ok := data[i]["deviceModel"].(string); ok {
+			r.DeviceModel = device
+		}
+		if report.AppVersion != nil {
+			r.VersionCode = report.AppVersion.VersionCode
+		}
+		if t, err := time.Parse(time.RFC3339, report.EventTime); err == nil {
+			r.EventTime = t
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
 func formatAnomalies(anomalies []*playdeveloperreporting.GooglePlayDeveloperReportingV1beta1Anomaly) []map[string]interface{} {
 	result := make([]map[string]interface{}, 0, len(anomalies))
 	for _, anomaly := range anomalies {
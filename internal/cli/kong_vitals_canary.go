@@ -0,0 +1,176 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	playdeveloperreporting "google.golang.org/api/playdeveloperreporting/v1beta1"
+
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/api"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/canary"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/errors"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/output"
+)
+
+// VitalsCanaryCmd compares the vitals of a new version code against a
+// baseline version with a significance test, for use as a rollout gate.
+type VitalsCanaryCmd struct {
+	Version     int64    `help:"Version code under test" required:""`
+	Baseline    int64    `help:"Baseline version code to compare against" required:""`
+	StartDate   string   `help:"Start date (ISO 8601, default: 30 days ago)"`
+	EndDate     string   `help:"End date (ISO 8601, default: today)"`
+	Metrics     []string `help:"Metrics to compare: crash, anr, slow-start, slow-rendering" enum:"crash,anr,slow-start,slow-rendering" default:"crash,anr,slow-start,slow-rendering"`
+	Alpha       float64  `help:"Significance level of the t-test" default:"0.05"`
+	MinDays     int      `help:"Daily values each version needs for a verdict" default:"3"`
+	MaxIncrease float64  `help:"Relative increase over baseline that passes when not significant" default:"0.1"`
+	Strict      bool     `help:"Treat an inconclusive verdict as failure"`
+}
+
+// canaryMetric is a metric the canary compares.
+type canaryMetric struct {
+	// name is the --metrics value.
+	name       string
+	metricSet  string
+	metric     string
+	dimensions []string
+	// startType restricts rows to one app start type.
+	startType string
+}
+
+var canaryMetrics = []canaryMetric{
+	{name: "crash", metricSet: "crashRateMetricSet", metric: metricCrashRate, dimensions: []string{"versionCode"}},
+	{name: "anr", metricSet: "anrRateMetricSet", metric: metricAnrRate, dimensions: []string{"versionCode"}},
+	// The slow start metric set requires the startType dimension; cold
+	// starts are the ones users notice.
+	{name: "slow-start", metricSet: "slowStartRateMetricSet", metric: metricSlowStartRate, dimensions: []string{"versionCode", "startType"}, startType: "COLD"},
+	// Frames slower than 30 fps; the metric set has no plain
+	// slowRenderingRate.
+	{name: "slow-rendering", metricSet: "slowRenderingRateMetricSet", metric: "slowRenderingRate30Fps", dimensions: []string{"versionCode"}},
+}
+
+// Run executes the canary command. The result is always written; a
+// failing verdict, or an inconclusive one with --strict, then returns a
+// validation error so the exit code gates the rollout.
+func (cmd *VitalsCanaryCmd) Run(globals *Globals) error {
+	if err := requirePackage(globals.Package); err != nil {
+		return err
+	}
+	if cmd.Version <= 0 || cmd.Baseline <= 0 || cmd.Version == cmd.Baseline {
+		return errors.NewAPIError(errors.CodeValidationError, "--version and --baseline must be two different version codes")
+	}
+	if cmd.Alpha <= 0 || cmd.Alpha >= 1 {
+		return errors.NewAPIError(errors.CodeValidationError, "--alpha must be between 0 and 1")
+	}
+	if cmd.MinDays < 2 {
+		return errors.NewAPIError(errors.CodeValidationError, "--min-days must be at least 2").
+			WithHint("The t-test needs two daily values per version")
+	}
+	spec, err := buildTimelineSpec(cmd.StartDate, cmd.EndDate)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	authMgr := newAuthManager()
+	creds, err := authMgr.Authenticate(ctx, globals.KeyPath)
+	if err != nil {
+		return err
+	}
+	client, err := api.NewClient(ctx, creds.TokenSource, api.WithTimeout(globals.Timeout))
+	if err != nil {
+		return errors.NewAPIError(errors.CodeAuthFailure, fmt.Sprintf("failed to create API client: %v", err))
+	}
+	svc, err := client.PlayReporting()
+	if err != nil {
+		return errors.NewAPIError(errors.CodeGeneralError, fmt.Sprintf("failed to get reporting service: %v", err))
+	}
+
+	selected := make(map[string]bool, len(cmd.Metrics))
+	for _, name := range cmd.Metrics {
+		selected[name] = true
+	}
+	startTime := time.Now()
+	series := make([]canary.Series, 0, len(cmd.Metrics))
+	for _, m := range canaryMetrics {
+		if !selected[m.name] {
+			continue
+		}
+		if globals.Verbose {
+			fmt.Fprintf(os.Stderr, "Querying %s by version code\n", m.metricSet)
+		}
+		rows, err := queryMetricSetRows(ctx, client, svc, globals.Package, m.metricSet, spec, m.dimensions, []string{m.metric})
+		if err != nil {
+			return errors.NewAPIError(errors.CodeGeneralError, fmt.Sprintf("failed to query %s: %v", m.metricSet, err))
+		}
+		series = append(series, canarySeries(m, rows, cmd.Version, cmd.Baseline))
+	}
+
+	res := canary.Evaluate(cmd.Version, cmd.Baseline, series, canary.Options{
+		Alpha: cmd.Alpha, MinSamples: cmd.MinDays, MaxIncrease: cmd.MaxIncrease,
+	})
+	return cmd.writeResult(globals, res, startTime)
+}
+
+// writeResult writes res and then fails on a gating verdict, so a CI job
+// sees the per-metric means and p-values of the verdict that stopped it.
+func (cmd *VitalsCanaryCmd) writeResult(globals *Globals, res *canary.Result, startTime time.Time) error {
+	var data interface{} = res
+	switch output.ParseFormat(globals.Output) {
+	case output.FormatTable, output.FormatMarkdown, output.FormatCSV, output.FormatExcel:
+		data = res.Rows()
+	}
+	if err := writeOutput(globals, output.NewResult(data).
+		WithDuration(time.Since(startTime)).
+		WithServices("playdeveloperreporting")); err != nil {
+		return err
+	}
+	if res.Verdict == canary.VerdictFail {
+		return errors.NewAPIError(errors.CodeValidationError,
+			fmt.Sprintf("canary failed: version %d regressed %s against %d", cmd.Version, strings.Join(res.Failed, ", "), cmd.Baseline))
+	}
+	if res.Verdict == canary.VerdictInconclusive && cmd.Strict {
+		return errors.NewAPIError(errors.CodeValidationError, "canary inconclusive").
+			WithHint("Wait for more daily data, or drop --strict")
+	}
+	return nil
+}
+
+// canarySeries collects the daily values of the metric for both versions,
+// in date order.
+func canarySeries(m canaryMetric, rows []*playdeveloperreporting.GooglePlayDeveloperReportingV1beta1MetricsRow, version, baseline int64) canary.Series {
+	type point struct {
+		day   string
+		value float64
+	}
+	byVersion := map[int64][]point{}
+	for _, row := range rows {
+		dims := rowDimensions(row)
+		versionCode, _ := strconv.ParseInt(dims["versionCode"], 10, 64)
+		if versionCode != version && versionCode != baseline {
+			continue
+		}
+		if m.startType != "" && dims["startType"] != "" && dims["startType"] != m.startType {
+			continue
+		}
+		for _, value := range row.Metrics {
+			if value.Metric == m.metric && value.DecimalValue != nil && value.DecimalValue.Value != "" {
+				byVersion[versionCode] = append(byVersion[versionCode], point{day: reportingDate(row.StartTime), value: parseDecimalValue(value)})
+			}
+		}
+	}
+	values := func(vc int64) []float64 {
+		points := byVersion[vc]
+		sort.SliceStable(points, func(i, j int) bool { return points[i].day < points[j].day })
+		out := make([]float64, 0, len(points))
+		for _, p := range points {
+			out = append(out, p.value)
+		}
+		return out
+	}
+	return canary.Series{Metric: m.metric, Baseline: values(baseline), Candidate: values(version)}
+}
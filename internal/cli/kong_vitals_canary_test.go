@@ -0,0 +1,97 @@
+//go:build unit
+// +build unit
+
+package cli
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	playdeveloperreporting "google.golang.org/api/playdeveloperreporting/v1beta1"
+
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/canary"
+)
+
+func canaryRow(day int64, dims map[string]string, metric, value string) *playdeveloperreporting.GooglePlayDeveloperReportingV1beta1MetricsRow {
+	row := &playdeveloperreporting.GooglePlayDeveloperReportingV1beta1MetricsRow{
+		StartTime: &playdeveloperreporting.GoogleTypeDateTime{Year: 2026, Month: 10, Day: day},
+		Metrics: []*playdeveloperreporting.GooglePlayDeveloperReportingV1beta1MetricValue{
+			{Metric: metric, DecimalValue: &playdeveloperreporting.GoogleTypeDecimal{Value: value}},
+		},
+	}
+	for k, v := range dims {
+		row.Dimensions = append(row.Dimensions, &playdeveloperreporting.GooglePlayDeveloperReportingV1beta1DimensionValue{Dimension: k, StringValue: v})
+	}
+	return row
+}
+
+func TestCanarySeries(t *testing.T) {
+	var slowStart canaryMetric
+	for _, m := range canaryMetrics {
+		if m.name == "slow-start" {
+			slowStart = m
+		}
+	}
+	rows := []*playdeveloperreporting.GooglePlayDeveloperReportingV1beta1MetricsRow{
+		canaryRow(2, map[string]string{"versionCode": "31", "startType": "COLD"}, metricSlowStartRate, "0.04"),
+		canaryRow(1, map[string]string{"versionCode": "31", "startType": "COLD"}, metricSlowStartRate, "0.03"),
+		canaryRow(1, map[string]string{"versionCode": "31", "startType": "WARM"}, metricSlowStartRate, "0.50"),
+		canaryRow(1, map[string]string{"versionCode": "30", "startType": "COLD"}, metricSlowStartRate, "0.02"),
+		canaryRow(1, map[string]string{"versionCode": "29", "startType": "COLD"}, metricSlowStartRate, "0.90"),
+		canaryRow(1, map[string]string{"versionCode": "30", "startType": "COLD"}, "distinctUsers", "1000"),
+	}
+	s := canarySeries(slowStart, rows, 31, 30)
+	if s.Metric != metricSlowStartRate || !reflect.DeepEqual(s.Candidate, []float64{0.03, 0.04}) || !reflect.DeepEqual(s.Baseline, []float64{0.02}) {
+		t.Errorf("series = %+v", s)
+	}
+}
+
+func TestVitalsCanaryCmd_Validation(t *testing.T) {
+	// Checked before auth, so the invalid key is never read.
+	globals := &Globals{Package: "com.example.app", KeyPath: "/nonexistent/key.json"}
+	tests := []struct {
+		cmd  VitalsCanaryCmd
+		want string
+	}{
+		{VitalsCanaryCmd{Version: 31, Baseline: 31, Alpha: 0.05, MinDays: 3}, "two different version codes"},
+		{VitalsCanaryCmd{Version: 31, Baseline: 30, Alpha: 1.5, MinDays: 3}, "--alpha"},
+		{VitalsCanaryCmd{Version: 31, Baseline: 30, Alpha: 0.05, MinDays: 1}, "--min-days"},
+		{VitalsCanaryCmd{Version: 31, Baseline: 30, Alpha: 0.05, MinDays: 3, StartDate: "yesterday"}, "invalid start date"},
+	}
+	for _, tc := range tests {
+		err := tc.cmd.Run(globals)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%+v: err = %v, want %q", tc.cmd, err, tc.want)
+		}
+	}
+}
+
+func TestVitalsCanaryCmd_WriteResultOnFail(t *testing.T) {
+	res := &canary.Result{Version: 31, Baseline: 30, Verdict: canary.VerdictFail, Failed: []string{metricCrashRate},
+		Metrics: []canary.Comparison{{Metric: metricCrashRate, Verdict: canary.VerdictFail, BaselineMean: 0.01, CandidateMean: 0.03, PValue: 0.001}}}
+	cmd := &VitalsCanaryCmd{Version: 31, Baseline: 30}
+	out, err := captureInspectStdout(t, func() error {
+		return cmd.writeResult(&Globals{Output: "json"}, res, time.Now())
+	})
+	if err == nil || !strings.Contains(err.Error(), "canary failed") {
+		t.Fatalf("err = %v", err)
+	}
+	var got canary.Result
+	decodeInspectData(t, out, &got)
+	if len(got.Metrics) != 1 || got.Metrics[0].PValue != 0.001 || got.Metrics[0].CandidateMean != 0.03 {
+		t.Errorf("written result = %+v", got)
+	}
+
+	// Inconclusive gates only with --strict; the result is written either way.
+	res = &canary.Result{Version: 31, Baseline: 30, Verdict: canary.VerdictInconclusive}
+	if _, err := captureInspectStdout(t, func() error { return cmd.writeResult(&Globals{Output: "json"}, res, time.Now()) }); err != nil {
+		t.Errorf("inconclusive: err = %v", err)
+	}
+	cmd.Strict = true
+	out, err = captureInspectStdout(t, func() error { return cmd.writeResult(&Globals{Output: "json"}, res, time.Now()) })
+	if err == nil || !strings.Contains(out, `"verdict"`) {
+		t.Errorf("strict: err = %v, out = %s", err, out)
+	}
+}
@@ -0,0 +1,183 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	playdeveloperreporting "google.golang.org/api/playdeveloperreporting/v1beta1"
+
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/api"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/compliance"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/errors"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/output"
+)
+
+// VitalsComplianceCmd evaluates user-perceived crash and ANR rates against
+// Play's overall and per-phone-model bad behavior thresholds.
+type VitalsComplianceCmd struct {
+	StartDate string  `help:"Start date of the window searched for the latest day (ISO 8601, default: 7 days ago)"`
+	EndDate   string  `help:"End date (ISO 8601, default: today)"`
+	WarnRatio float64 `help:"Share of a threshold at which a value is reported at risk" default:"0.8"`
+	MinUsers  float64 `help:"Ignore device models with fewer distinct users" default:"0"`
+	Fail      bool    `help:"Exit with an error when any value is over its threshold"`
+}
+
+// complianceMetricSets pairs each evaluated metric with its metric set.
+var complianceMetricSets = []struct {
+	metricSet string
+	metric    string
+}{
+	{metricSet: "crashRateMetricSet", metric: compliance.MetricUserPerceivedCrashRate},
+	{metricSet: "anrRateMetricSet", metric: compliance.MetricUserPerceivedAnrRate},
+}
+
+// complianceResult is the report for the latest day with data.
+type complianceResult struct {
+	Package string `json:"package"`
+	Date    string `json:"date"`
+	*compliance.Report
+}
+
+// Run executes the compliance command. The 28-day user-weighted rates of
+// the latest day in the window are evaluated, since that is the window
+// Play applies its thresholds to.
+func (cmd *VitalsComplianceCmd) Run(globals *Globals) error {
+	if err := requirePackage(globals.Package); err != nil {
+		return err
+	}
+	if cmd.WarnRatio <= 0 || cmd.WarnRatio > 1 {
+		return errors.NewAPIError(errors.CodeValidationError, "--warn-ratio must be greater than 0 and at most 1")
+	}
+	if cmd.MinUsers < 0 {
+		return errors.NewAPIError(errors.CodeValidationError, "--min-users must not be negative")
+	}
+	startDate := cmd.StartDate
+	if startDate == "" {
+		startDate = time.Now().UTC().AddDate(0, 0, -7).Format("2006-01-02")
+	}
+	spec, err := buildTimelineSpec(startDate, cmd.EndDate)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	authMgr := newAuthManager()
+	creds, err := authMgr.Authenticate(ctx, globals.KeyPath)
+	if err != nil {
+		return err
+	}
+	client, err := api.NewClient(ctx, creds.TokenSource, api.WithTimeout(globals.Timeout))
+	if err != nil {
+		return errors.NewAPIError(errors.CodeAuthFailure, fmt.Sprintf("failed to create API client: %v", err))
+	}
+	svc, err := client.PlayReporting()
+	if err != nil {
+		return errors.NewAPIError(errors.CodeGeneralError, fmt.Sprintf("failed to get reporting service: %v", err))
+	}
+
+	startTime := time.Now()
+	inputs := make([]compliance.Input, 0, len(complianceMetricSets))
+	date := ""
+	for _, m := range complianceMetricSets {
+		if globals.Verbose {
+			fmt.Fprintf(os.Stderr, "Querying %s overall and by device model\n", m.metricSet)
+		}
+		metrics := []string{m.metric, metricDistinctUsers}
+		overall, err := queryMetricSetRows(ctx, client, svc, globals.Package, m.metricSet, spec, nil, metrics)
+		if err != nil {
+			return errors.NewAPIError(errors.CodeGeneralError, fmt.Sprintf("failed to query %s: %v", m.metricSet, err))
+		}
+		devices, err := queryMetricSetRows(ctx, client, svc, globals.Package, m.metricSet, spec, []string{"deviceModel"}, metrics)
+		if err != nil {
+			return errors.NewAPIError(errors.CodeGeneralError, fmt.Sprintf("failed to query %s by device model: %v", m.metricSet, err))
+		}
+		in, day := complianceInput(m.metric, overall, devices)
+		if day == "" {
+			continue
+		}
+		if day > date {
+			date = day
+		}
+		inputs = append(inputs, in)
+	}
+	if len(inputs) == 0 {
+		return errors.NewAPIError(errors.CodeNotFound, "no user-perceived crash or ANR rates in the date range").
+			WithHint("Vitals lag by a few days; widen the window with --start-date")
+	}
+
+	res := complianceResult{
+		Package: globals.Package,
+		Date:    date,
+		Report:  compliance.Evaluate(inputs, compliance.Options{WarnRatio: cmd.WarnRatio, MinUsers: cmd.MinUsers}),
+	}
+	if cmd.Fail && res.Status == compliance.StatusOver {
+		over := make([]string, 0, len(res.Metrics))
+		for _, m := range res.Metrics {
+			if m.Status == compliance.StatusOver {
+				over = append(over, m.Metric)
+			}
+		}
+		return errors.NewAPIError(errors.CodeValidationError,
+			fmt.Sprintf("bad behavior threshold exceeded: %s", strings.Join(over, ", "))).
+			WithHint("Play may reduce the app's visibility; see devicesOverThreshold for the device models responsible").
+			WithDetails(res)
+	}
+
+	var data interface{} = res
+	switch output.ParseFormat(globals.Output) {
+	case output.FormatTable, output.FormatMarkdown, output.FormatCSV, output.FormatExcel:
+		data = res.Rows()
+	}
+	return writeOutput(globals, output.NewResult(data).
+		WithDuration(time.Since(startTime)).
+		WithServices("playdeveloperreporting"))
+}
+
+// complianceInput takes the latest day of the overall rows and collects the
+// overall and per-device values of metric on that day. The day is empty
+// when no row carries the metric.
+func complianceInput(metric string, overall, devices []*playdeveloperreporting.GooglePlayDeveloperReportingV1beta1MetricsRow) (compliance.Input, string) {
+	in := compliance.Input{Metric: metric, Devices: make([]compliance.DeviceRate, 0)}
+	day := ""
+	for _, row := range overall {
+		d := reportingDate(row.StartTime)
+		rate, _, ok := complianceValues(row, metric)
+		if ok && d > day {
+			day, in.Overall = d, rate
+		}
+	}
+	if day == "" {
+		return in, ""
+	}
+	for _, row := range devices {
+		if reportingDate(row.StartTime) != day {
+			continue
+		}
+		model := rowDimensions(row)["deviceModel"]
+		rate, users, ok := complianceValues(row, metric)
+		if ok && model != "" {
+			in.Devices = append(in.Devices, compliance.DeviceRate{DeviceModel: model, Rate: rate, Users: users})
+		}
+	}
+	return in, day
+}
+
+// complianceValues returns the row's value of metric and its distinct
+// users, and whether the metric is present.
+func complianceValues(row *playdeveloperreporting.GooglePlayDeveloperReportingV1beta1MetricsRow, metric string) (rate, users float64, ok bool) {
+	for _, value := range row.Metrics {
+		if value.DecimalValue == nil || value.DecimalValue.Value == "" {
+			continue
+		}
+		switch value.Metric {
+		case metric:
+			rate, ok = parseDecimalValue(value), true
+		case metricDistinctUsers:
+			users = parseDecimalValue(value)
+		}
+	}
+	return rate, users, ok
+}
@@ -0,0 +1,65 @@
+//go:build unit
+// +build unit
+
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	playdeveloperreporting "google.golang.org/api/playdeveloperreporting/v1beta1"
+
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/compliance"
+)
+
+func TestComplianceInput(t *testing.T) {
+	metric := compliance.MetricUserPerceivedCrashRate
+	device := func(day int64, model, rate, users string) *playdeveloperreporting.GooglePlayDeveloperReportingV1beta1MetricsRow {
+		row := canaryRow(day, map[string]string{"deviceModel": model}, metric, rate)
+		row.Metrics = append(row.Metrics, &playdeveloperreporting.GooglePlayDeveloperReportingV1beta1MetricValue{
+			Metric: metricDistinctUsers, DecimalValue: &playdeveloperreporting.GoogleTypeDecimal{Value: users},
+		})
+		return row
+	}
+	overall := []*playdeveloperreporting.GooglePlayDeveloperReportingV1beta1MetricsRow{
+		canaryRow(11, nil, metric, "0.008"),
+		canaryRow(12, nil, metric, "0.012"),
+		canaryRow(13, nil, metricDistinctUsers, "5000"),
+	}
+	devices := []*playdeveloperreporting.GooglePlayDeveloperReportingV1beta1MetricsRow{
+		device(11, "samsung/a52", "0.30", "900"),
+		device(12, "samsung/a52", "0.09", "900"),
+		device(12, "google/oriole", "0.01", "4000"),
+	}
+
+	in, day := complianceInput(metric, overall, devices)
+	if day != "2026-10-12" || in.Overall != 0.012 || len(in.Devices) != 2 {
+		t.Fatalf("input = %+v, day = %q", in, day)
+	}
+	if d := in.Devices[0]; d.DeviceModel != "samsung/a52" || d.Rate != 0.09 || d.Users != 900 {
+		t.Errorf("device = %+v", d)
+	}
+
+	if _, day := complianceInput(metric, nil, devices); day != "" {
+		t.Errorf("day without overall rows = %q", day)
+	}
+}
+
+func TestVitalsComplianceCmd_Validation(t *testing.T) {
+	// Checked before auth, so the invalid key is never read.
+	globals := &Globals{Package: "com.example.app", KeyPath: "/nonexistent/key.json"}
+	tests := []struct {
+		cmd  VitalsComplianceCmd
+		want string
+	}{
+		{VitalsComplianceCmd{WarnRatio: 1.5}, "--warn-ratio"},
+		{VitalsComplianceCmd{WarnRatio: 0.8, MinUsers: -1}, "--min-users"},
+		{VitalsComplianceCmd{WarnRatio: 0.8, EndDate: "today"}, "invalid end date"},
+	}
+	for _, tc := range tests {
+		err := tc.cmd.Run(globals)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%+v: err = %v, want %q", tc.cmd, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	playdeveloperreporting "google.golang.org/api/playdeveloperreporting/v1beta1"
+
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/api"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/errors"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/output"
+)
+
+// VitalsFreshnessCmd reports the latest complete period of each metric set.
+type VitalsFreshnessCmd struct {
+	MetricSets []string `help:"Metric sets to look up: crash, anr, slow-start, slow-rendering, excessive-wakeup, stuck-wakelock, error-count, lmk" enum:"crash,anr,slow-start,slow-rendering,excessive-wakeup,stuck-wakelock,error-count,lmk" default:"crash,anr,slow-start,slow-rendering,excessive-wakeup,stuck-wakelock,error-count,lmk"`
+}
+
+// freshnessRow is the latest end time of one metric set and aggregation
+// period.
+type freshnessRow struct {
+	MetricSet         string `json:"metricSet"`
+	AggregationPeriod string `json:"aggregationPeriod"`
+	LatestEndTime     string `json:"latestEndTime"`
+	TimeZone          string `json:"timeZone,omitempty"`
+}
+
+// Run executes the freshness command.
+func (cmd *VitalsFreshnessCmd) Run(globals *Globals) error {
+	if err := requirePackage(globals.Package); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	authMgr := newAuthManager()
+	creds, err := authMgr.Authenticate(ctx, globals.KeyPath)
+	if err != nil {
+		return err
+	}
+	client, err := api.NewClient(ctx, creds.TokenSource, api.WithTimeout(globals.Timeout))
+	if err != nil {
+		return errors.NewAPIError(errors.CodeAuthFailure, fmt.Sprintf("failed to create API client: %v", err))
+	}
+	svc, err := client.PlayReporting()
+	if err != nil {
+		return errors.NewAPIError(errors.CodeGeneralError, fmt.Sprintf("failed to get reporting service: %v", err))
+	}
+
+	selected := make(map[string]bool, len(cmd.MetricSets))
+	for _, alias := range cmd.MetricSets {
+		selected[alias] = true
+	}
+	startTime := time.Now()
+	rows := make([]freshnessRow, 0)
+	for _, set := range vitalsMetricSets {
+		if !selected[set.alias] {
+			continue
+		}
+		var info *playdeveloperreporting.GooglePlayDeveloperReportingV1beta1FreshnessInfo
+		err := client.DoWithRetry(ctx, func() error {
+			var err error
+			info, err = metricSetFreshness(ctx, svc, fmt.Sprintf("apps/%s/%s", globals.Package, set.name), set.name)
+			return err
+		})
+		if err != nil {
+			return errors.NewAPIError(errors.CodeGeneralError, fmt.Sprintf("failed to get %s freshness: %v", set.name, err))
+		}
+		rows = append(rows, freshnessRows(set.name, info)...)
+	}
+
+	return writeOutput(globals, output.NewResult(rows).
+		WithDuration(time.Since(startTime)).
+		WithServices("playdeveloperreporting"))
+}
+
+// metricSetFreshness gets the freshness info of a metric set.
+func metricSetFreshness(ctx context.Context, svc *playdeveloperreporting.Service, name, metricSet string) (*playdeveloperreporting.GooglePlayDeveloperReportingV1beta1FreshnessInfo, error) {
+	switch metricSet {
+	case metricSetCrashRate:
+		set, err := svc.Vitals.Crashrate.Get(name).Context(ctx).Do()
+		if err != nil {
+			return nil, err
+		}
+		return set.FreshnessInfo, nil
+	case metricSetAnrRate:
+		set, err := svc.Vitals.Anrrate.Get(name).Context(ctx).Do()
+		if err != nil {
+			return nil, err
+		}
+		return set.FreshnessInfo, nil
+	case metricSetSlowStart:
+		set, err := svc.Vitals.Slowstartrate.Get(name).Context(ctx).Do()
+		if err != nil {
+			return nil, err
+		}
+		return set.FreshnessInfo, nil
+	case metricSetSlowRendering:
+		set, err := svc.Vitals.Slowrenderingrate.Get(name).Context(ctx).Do()
+		if err != nil {
+			return nil, err
+		}
+		return set.FreshnessInfo, nil
+	case metricSetExcessiveWakeup:
+		set, err := svc.Vitals.Excessivewakeuprate.Get(name).Context(ctx).Do()
+		if err != nil {
+			return nil, err
+		}
+		return set.FreshnessInfo, nil
+	case metricSetStuckBackgroundWakelock:
+		set, err := svc.Vitals.Stuckbackgroundwakelockrate.Get(name).Context(ctx).Do()
+		if err != nil {
+			return nil, err
+		}
+		return set.FreshnessInfo, nil
+	case metricSetErrorCount:
+		set, err := svc.Vitals.Errors.Counts.Get(name).Context(ctx).Do()
+		if err != nil {
+			return nil, err
+		}
+		return set.FreshnessInfo, nil
+	case metricSetLmkRate:
+		set, err := svc.Vitals.Lmkrate.Get(name).Context(ctx).Do()
+		if err != nil {
+			return nil, err
+		}
+		return set.FreshnessInfo, nil
+	}
+	return nil, fmt.Errorf("unsupported metric set %s", metricSet)
+}
+
+// freshnessRows flattens freshness info to one row per aggregation period.
+func freshnessRows(metricSet string, info *playdeveloperreporting.GooglePlayDeveloperReportingV1beta1FreshnessInfo) []freshnessRow {
+	if info == nil {
+		return nil
+	}
+	rows := make([]freshnessRow, 0, len(info.Freshnesses))
+	for _, f := range info.Freshnesses {
+		row := freshnessRow{MetricSet: metricSet, AggregationPeriod: f.AggregationPeriod}
+		if t := f.LatestEndTime; t != nil {
+			row.LatestEndTime = fmt.Sprintf("%sT%02d:00", reportingDate(t), t.Hours)
+			if t.TimeZone != nil {
+				row.TimeZone = t.TimeZone.Id
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
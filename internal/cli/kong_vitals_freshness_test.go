@@ -0,0 +1,41 @@
+//go:build unit
+// +build unit
+
+package cli
+
+import (
+	"reflect"
+	"testing"
+
+	playdeveloperreporting "google.golang.org/api/playdeveloperreporting/v1beta1"
+)
+
+func TestFreshnessRows(t *testing.T) {
+	info := &playdeveloperreporting.GooglePlayDeveloperReportingV1beta1FreshnessInfo{
+		Freshnesses: []*playdeveloperreporting.GooglePlayDeveloperReportingV1beta1FreshnessInfoFreshness{
+			{AggregationPeriod: "DAILY", LatestEndTime: &playdeveloperreporting.GoogleTypeDateTime{
+				Year: 2026, Month: 10, Day: 14, TimeZone: &playdeveloperreporting.GoogleTypeTimeZone{Id: "America/Los_Angeles"},
+			}},
+			{AggregationPeriod: "HOURLY", LatestEndTime: &playdeveloperreporting.GoogleTypeDateTime{Year: 2026, Month: 10, Day: 15, Hours: 9}},
+			{AggregationPeriod: "DAILY"},
+		},
+	}
+	want := []freshnessRow{
+		{MetricSet: metricSetLmkRate, AggregationPeriod: "DAILY", LatestEndTime: "2026-10-14T00:00", TimeZone: "America/Los_Angeles"},
+		{MetricSet: metricSetLmkRate, AggregationPeriod: "HOURLY", LatestEndTime: "2026-10-15T09:00"},
+		{MetricSet: metricSetLmkRate, AggregationPeriod: "DAILY"},
+	}
+	if got := freshnessRows(metricSetLmkRate, info); !reflect.DeepEqual(got, want) {
+		t.Errorf("rows = %+v", got)
+	}
+	if got := freshnessRows(metricSetLmkRate, nil); got != nil {
+		t.Errorf("nil info rows = %+v", got)
+	}
+}
+
+func TestVitalsFreshnessCmd_RequiresPackage(t *testing.T) {
+	cmd := &VitalsFreshnessCmd{MetricSets: []string{"lmk"}}
+	if err := cmd.Run(&Globals{}); err == nil {
+		t.Fatal("expected an error without --package")
+	}
+}
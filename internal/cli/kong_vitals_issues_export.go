@@ -0,0 +1,240 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	playdeveloperreporting "google.golang.org/api/playdeveloperreporting/v1beta1"
+
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/api"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/crashcluster"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/issueexport"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/config"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/errors"
+)
+
+// VitalsErrorsIssuesExportCmd exports error issues in an issue-tracker
+// import format and remembers what it exported.
+type VitalsErrorsIssuesExportCmd struct {
+	Tracker         string   `help:"Import format: github (issue JSON), jira (CSV) or csv" required:"" enum:"github,jira,csv"`
+	Query           string   `help:"Search query"`
+	Interval        string   `help:"Time interval: last7Days, last30Days, last90Days" default:"last30Days"`
+	TopFrames       int      `help:"App frames from a sample report included per issue" default:"5"`
+	AppPackage      []string `help:"Package prefixes of app frames (default: every frame outside java, android, androidx and kotlin)"`
+	Mapping         string   `help:"R8/ProGuard mapping.txt used to retrace sample reports without a version-specific mapping" type:"existingfile"`
+	MappingDir      string   `help:"Directory of mappings per version code: <dir>/<versionCode>/mapping.txt, <dir>/<versionCode>.txt or <dir>/mapping-<versionCode>.txt" type:"existingdir"`
+	State           string   `help:"Export state file (default: <data dir>/issue-export/<package>.json)" type:"path"`
+	IncludeExported bool     `help:"Also export issues already exported that have not regressed"`
+	DryRun          bool     `help:"Write the export without updating the state file"`
+	OutputFile      string   `help:"Write the export to a file instead of stdout" name:"output-file" type:"path"`
+}
+
+// Run executes the errors issues export command.
+func (cmd *VitalsErrorsIssuesExportCmd) Run(globals *Globals) error {
+	if err := requirePackage(globals.Package); err != nil {
+		return err
+	}
+	if cmd.TopFrames < 0 {
+		return errors.NewAPIError(errors.CodeValidationError, "--top-frames must not be negative")
+	}
+	retracer, err := newReportRetracer(cmd.Mapping, cmd.MappingDir)
+	if err != nil {
+		return err
+	}
+	state, err := issueexport.LoadState(cmd.statePath(globals.Package))
+	if err != nil {
+		return errors.NewAPIError(errors.CodeValidationError, err.Error()).
+			WithHint("Fix or remove the state file; every issue is exported again without it")
+	}
+
+	ctx := context.Background()
+	creds, err := newAuthManager().Authenticate(ctx, globals.KeyPath)
+	if err != nil {
+		return err
+	}
+	client, err := api.NewClient(ctx, creds.TokenSource, api.WithTimeout(globals.Timeout), api.WithVerboseLogging(globals.Verbose))
+	if err != nil {
+		return errors.NewAPIError(errors.CodeAuthFailure, fmt.Sprintf("failed to create API client: %v", err))
+	}
+	svc, err := client.PlayReporting()
+	if err != nil {
+		return errors.NewAPIError(errors.CodeGeneralError, fmt.Sprintf("failed to get reporting service: %v", err))
+	}
+
+	apiIssues, err := cmd.searchIssues(ctx, client, svc, globals.Package)
+	if err != nil {
+		return errors.NewAPIError(errors.CodeGeneralError, fmt.Sprintf("failed to search error issues: %v", err))
+	}
+	entries := state.Select(toExportIssues(apiIssues), cmd.IncludeExported)
+
+	opts := crashcluster.Options{AppPackages: cmd.AppPackage, TopFrames: cmd.TopFrames}
+	var missingFrames int
+	for i := range entries {
+		if cmd.TopFrames == 0 {
+			break
+		}
+		frames, err := sampleIssueFrames(ctx, client, svc, globals.Package, entries[i].ID, retracer, opts)
+		if err != nil {
+			missingFrames++
+			continue
+		}
+		entries[i].Frames = frames
+	}
+
+	var buf bytes.Buffer
+	if err := issueexport.Write(&buf, cmd.Tracker, entries); err != nil {
+		return errors.NewAPIError(errors.CodeGeneralError, fmt.Sprintf("failed to write export: %v", err))
+	}
+	if cmd.OutputFile == "" {
+		if _, err := os.Stdout.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	} else {
+		if err := os.WriteFile(cmd.OutputFile, buf.Bytes(), 0o644); err != nil {
+			return errors.NewAPIError(errors.CodeGeneralError, fmt.Sprintf("failed to write export: %v", err))
+		}
+		fmt.Fprintf(os.Stderr, "Export saved to: %s\n", cmd.OutputFile)
+	}
+
+	if missingFrames > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: could not read a sample report for %d issues; they are exported without frames\n", missingFrames)
+	}
+	fmt.Fprintf(os.Stderr, "Exported %s\n", exportSummary(entries))
+	if cmd.DryRun {
+		return nil
+	}
+	state.Record(entries, cmd.Tracker, time.Now())
+	if err := state.Save(); err != nil {
+		return errors.NewAPIError(errors.CodeGeneralError, fmt.Sprintf("failed to save export state: %v", err))
+	}
+	return nil
+}
+
+func (cmd *VitalsErrorsIssuesExportCmd) statePath(pkg string) string {
+	if cmd.State != "" {
+		return cmd.State
+	}
+	return filepath.Join(config.GetPaths().DataDir, "issue-export", pkg+".json")
+}
+
+// searchIssues fetches every page of error issues matching the query.
+func (cmd *VitalsErrorsIssuesExportCmd) searchIssues(ctx context.Context, client *api.Client, svc *playdeveloperreporting.Service, pkg string) ([]*playdeveloperreporting.GooglePlayDeveloperReportingV1beta1ErrorIssue, error) {
+	parent := fmt.Sprintf("apps/%s/errorIssues", pkg)
+	filter := (&VitalsErrorsIssuesCmd{Query: cmd.Query, Interval: cmd.Interval}).buildFilter()
+	var issues []*playdeveloperreporting.GooglePlayDeveloperReportingV1beta1ErrorIssue
+	err := client.DoWithRetry(ctx, func() error {
+		issues = nil
+		query := func(pageToken string) (errorIssuesPageResponse, error) {
+			resp, err := svc.Vitals.Errors.Issues.Search(parent).Context(ctx).
+				Filter(filter).
+				PageSize(100).
+				PageToken(pageToken).
+				Do()
+			return errorIssuesPageResponse{resp: resp}, err
+		}
+		first, err := query("")
+		if err != nil {
+			return err
+		}
+		issues = append(issues, first.GetItems()...)
+		if first.GetNextPageToken() == "" {
+			return nil
+		}
+		more, _, err := fetchAllPages(ctx, query, first.GetNextPageToken(), 0)
+		if err != nil {
+			return err
+		}
+		issues = append(issues, more...)
+		return nil
+	})
+	return issues, err
+}
+
+// sampleIssueFrames retraces one error report of an issue and returns its
+// top app frames.
+func sampleIssueFrames(ctx context.Context, client *api.Client, svc *playdeveloperreporting.Service, pkg, issueID string, retracer *reportRetracer, opts crashcluster.Options) ([]string, error) {
+	var resp *playdeveloperreporting.GooglePlayDeveloperReportingV1beta1SearchErrorReportsResponse
+	err := client.DoWithRetry(ctx, func() error {
+		var err error
+		resp, err = svc.Vitals.Errors.Reports.Search(fmt.Sprintf("apps/%s", pkg)).Context(ctx).
+			Filter(fmt.Sprintf("errorIssueId = %s", issueID)).
+			PageSize(1).
+			Do()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.ErrorReports) == 0 {
+		return nil, fmt.Errorf("issue %s has no error reports", issueID)
+	}
+	report := resp.ErrorReports[0]
+	text := report.ReportText
+	if retracer != nil {
+		var versionCode int64
+		if report.AppVersion != nil {
+			versionCode = report.AppVersion.VersionCode
+		}
+		m, err := retracer.forVersion(versionCode)
+		if err != nil {
+			return nil, err
+		}
+		if m != nil {
+			text = m.Retrace(text).Text
+		}
+	}
+	return crashcluster.Fingerprint(text, opts).Frames, nil
+}
+
+// toExportIssues converts error issues for export.
+func toExportIssues(issues []*playdeveloperreporting.GooglePlayDeveloperReportingV1beta1ErrorIssue) []issueexport.Issue {
+	out := make([]issueexport.Issue, 0, len(issues))
+	for _, issue := range issues {
+		e := issueexport.Issue{
+			ID:           issue.Name[strings.LastIndex(issue.Name, "/")+1:],
+			Type:         strings.ToUpper(issue.Type),
+			Cause:        issue.Cause,
+			Location:     issue.Location,
+			ErrorReports: issue.ErrorReportCount,
+			Users:        issue.DistinctUsers,
+			LastSeen:     issue.LastErrorReportTime,
+			URI:          issue.IssueUri,
+		}
+		if issue.FirstAppVersion != nil {
+			e.FirstVersion = issue.FirstAppVersion.VersionCode
+		}
+		if issue.LastAppVersion != nil {
+			e.LastVersion = issue.LastAppVersion.VersionCode
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
+// exportSummary counts exported entries by status, e.g.
+// "3 issues (2 new, 1 regression)".
+func exportSummary(entries []issueexport.Entry) string {
+	if len(entries) == 0 {
+		return "0 issues"
+	}
+	counts := make(map[string]int)
+	for _, e := range entries {
+		counts[e.Status]++
+	}
+	var parts []string
+	for _, status := range []string{issueexport.StatusNew, issueexport.StatusRegression, issueexport.StatusExported} {
+		if counts[status] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[status], status))
+		}
+	}
+	noun := "issues"
+	if len(entries) == 1 {
+		noun = "issue"
+	}
+	return fmt.Sprintf("%d %s (%s)", len(entries), noun, strings.Join(parts, ", "))
+}
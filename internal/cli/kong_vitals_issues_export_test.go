@@ -0,0 +1,56 @@
+//go:build unit
+// +build unit
+
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	playdeveloperreporting "google.golang.org/api/playdeveloperreporting/v1beta1"
+
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/issueexport"
+)
+
+func TestVitalsErrorsIssuesExportCmd_CorruptStateFailsBeforeAuth(t *testing.T) {
+	state := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(state, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cmd := &VitalsErrorsIssuesExportCmd{Tracker: issueexport.TrackerGitHub, TopFrames: 5, State: state}
+	err := cmd.Run(&Globals{Package: "com.example.app", KeyPath: "/nonexistent/key.json"})
+	if err == nil || !strings.Contains(err.Error(), "parse export state") {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestToExportIssues(t *testing.T) {
+	issues := toExportIssues([]*playdeveloperreporting.GooglePlayDeveloperReportingV1beta1ErrorIssue{{
+		Name:                "apps/com.example.app/errorIssues/1a2b",
+		Type:                "crash",
+		Cause:               "java.lang.NullPointerException",
+		Location:            "com.example.Main.onCreate",
+		DistinctUsers:       80,
+		ErrorReportCount:    120,
+		FirstAppVersion:     &playdeveloperreporting.GooglePlayDeveloperReportingV1beta1AppVersion{VersionCode: 120},
+		LastAppVersion:      &playdeveloperreporting.GooglePlayDeveloperReportingV1beta1AppVersion{VersionCode: 131},
+		LastErrorReportTime: "2026-10-15T08:00:00Z",
+		IssueUri:            "https://play.google.com/console/issue/1a2b",
+	}})
+	got := issues[0]
+	if got.ID != "1a2b" || got.Type != "CRASH" || got.Users != 80 || got.FirstVersion != 120 || got.LastVersion != 131 || got.URI == "" {
+		t.Errorf("issue = %+v", got)
+	}
+}
+
+func TestExportSummary(t *testing.T) {
+	entries := []issueexport.Entry{{Status: issueexport.StatusNew}, {Status: issueexport.StatusNew}, {Status: issueexport.StatusRegression}}
+	if got := exportSummary(entries); got != "3 issues (2 new, 1 regression)" {
+		t.Errorf("summary = %q", got)
+	}
+	if got := exportSummary(nil); got != "0 issues" {
+		t.Errorf("empty summary = %q", got)
+	}
+}
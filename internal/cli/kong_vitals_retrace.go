@@ -0,0 +1,169 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	playdeveloperreporting "google.golang.org/api/playdeveloperreporting/v1beta1"
+
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/errors"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/output"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/proguard"
+)
+
+// VitalsRetraceCmd retraces an obfuscated stack trace offline.
+type VitalsRetraceCmd struct {
+	File        string `arg:"" optional:"" help:"Stack trace file (default: stdin)" type:"existingfile"`
+	Mapping     string `help:"R8/ProGuard mapping.txt" type:"existingfile"`
+	MappingDir  string `help:"Directory of mappings per version code: <dir>/<versionCode>/mapping.txt, <dir>/<versionCode>.txt or <dir>/mapping-<versionCode>.txt" type:"existingdir"`
+	VersionCode int64  `help:"Version code whose mapping to read from --mapping-dir"`
+}
+
+// retraceResult is the JSON output of vitals retrace.
+type retraceResult struct {
+	Mapping     string `json:"mapping"`
+	VersionCode int64  `json:"versionCode,omitempty"`
+	proguard.Result
+}
+
+// Run executes the retrace command. Table and markdown output print the
+// retraced trace as plain text so the command works as a filter.
+func (cmd *VitalsRetraceCmd) Run(globals *Globals) error {
+	mapping, path, err := cmd.loadMapping()
+	if err != nil {
+		return err
+	}
+
+	in := io.Reader(os.Stdin)
+	if cmd.File != "" {
+		f, err := os.Open(cmd.File)
+		if err != nil {
+			return errors.NewAPIError(errors.CodeValidationError, fmt.Sprintf("failed to open stack trace: %v", err))
+		}
+		defer func() { _ = f.Close() }()
+		in = f
+	}
+	trace, err := io.ReadAll(in)
+	if err != nil {
+		return errors.NewAPIError(errors.CodeGeneralError, fmt.Sprintf("failed to read stack trace: %v", err))
+	}
+	res := mapping.Retrace(string(trace))
+
+	switch output.ParseFormat(globals.Output) {
+	case output.FormatTable, output.FormatMarkdown:
+		_, err := fmt.Fprint(os.Stdout, res.Text)
+		return err
+	default:
+		return writeOutput(globals, output.NewResult(retraceResult{Mapping: path, VersionCode: cmd.VersionCode, Result: res}).WithServices("retrace"))
+	}
+}
+
+func (cmd *VitalsRetraceCmd) loadMapping() (*proguard.Mapping, string, error) {
+	switch {
+	case cmd.Mapping != "":
+		m, err := parseMappingFile(cmd.Mapping)
+		return m, cmd.Mapping, err
+	case cmd.MappingDir != "" && cmd.VersionCode > 0:
+		m, path, err := proguard.NewStore(cmd.MappingDir).Mapping(cmd.VersionCode)
+		if err != nil {
+			return nil, "", errors.NewAPIError(errors.CodeValidationError, fmt.Sprintf("failed to parse mapping: %v", err))
+		}
+		if m == nil {
+			return nil, "", errors.NewAPIError(errors.CodeValidationError,
+				fmt.Sprintf("no mapping for version code %d in %s", cmd.VersionCode, cmd.MappingDir)).
+				WithDetails(map[string]interface{}{"searched": proguard.NewStore(cmd.MappingDir).Candidates(cmd.VersionCode)})
+		}
+		return m, path, nil
+	}
+	return nil, "", errors.NewAPIError(errors.CodeValidationError, "a mapping is required").
+		WithHint("Pass --mapping mapping.txt, or --mapping-dir with --version-code")
+}
+
+func parseMappingFile(path string) (*proguard.Mapping, error) {
+	m, err := proguard.ParseFile(path)
+	if err != nil {
+		return nil, errors.NewAPIError(errors.CodeValidationError, fmt.Sprintf("failed to parse mapping %s: %v", path, err))
+	}
+	return m, nil
+}
+
+// reportRetracer picks the mapping for each error report: the one in
+// --mapping-dir for the report's version code, else --mapping.
+type reportRetracer struct {
+	mapping *proguard.Mapping
+	store   *proguard.Store
+}
+
+// newReportRetracer returns nil when neither mapping source is set.
+func newReportRetracer(mappingPath, mappingDir string) (*reportRetracer, error) {
+	if mappingPath == "" && mappingDir == "" {
+		return nil, nil
+	}
+	rr := &reportRetracer{}
+	if mappingPath != "" {
+		m, err := parseMappingFile(mappingPath)
+		if err != nil {
+			return nil, err
+		}
+		rr.mapping = m
+	}
+	if mappingDir != "" {
+		rr.store = proguard.NewStore(mappingDir)
+	}
+	return rr, nil
+}
+
+func (rr *reportRetracer) forVersion(versionCode int64) (*proguard.Mapping, error) {
+	if rr.store != nil && versionCode > 0 {
+		m, _, err := rr.store.Mapping(versionCode)
+		if err != nil || m != nil {
+			return m, err
+		}
+	}
+	return rr.mapping, nil
+}
+
+// retraceReports rewrites the reportText of each formatted report in
+// place and returns warnings for version codes without a mapping.
+func (rr *reportRetracer) retraceReports(data []map[string]interface{}, reports []*playdeveloperreporting.GooglePlayDeveloperReportingV1beta1ErrorReport) ([]string, error) {
+	missing := make(map[int64]bool)
+	for i, report := range reports {
+		var versionCode int64
+		if report.AppVersion != nil {
+			versionCode = report.AppVersion.VersionCode
+		}
+		m, err := rr.forVersion(versionCode)
+		if err != nil {
+			return nil, errors.NewAPIError(errors.CodeValidationError, fmt.Sprintf("failed to parse mapping: %v", err))
+		}
+		if m == nil {
+			missing[versionCode] = true
+			data[i]["retraced"] = false
+			continue
+		}
+		res := m.Retrace(report.ReportText)
+		data[i]["reportText"] = res.Text
+		data[i]["retraced"] = res.Retraced > 0
+	}
+	if len(missing) == 0 {
+		return nil, nil
+	}
+	codes := make([]int64, 0, len(missing))
+	for vc := range missing {
+		codes = append(codes, vc)
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+	names := make([]string, 0, len(codes))
+	for _, vc := range codes {
+		if vc == 0 {
+			names = append(names, "unknown")
+			continue
+		}
+		names = append(names, strconv.FormatInt(vc, 10))
+	}
+	return []string{fmt.Sprintf("no mapping for version codes %s; their reports are not retraced", strings.Join(names, ", "))}, nil
+}
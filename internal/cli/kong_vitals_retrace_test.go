@@ -0,0 +1,136 @@
+//go:build unit
+// +build unit
+
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	playdeveloperreporting "google.golang.org/api/playdeveloperreporting/v1beta1"
+
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/errors"
+)
+
+const testRetraceMapping = `# pg_map_id: 2d8c6ba
+com.example.app.MainActivity -> a.a:
+    1:1:void com.example.app.Repo.load():42:42 -> b
+    1:1:void refresh():31 -> b
+`
+
+const testObfuscatedTrace = "java.lang.IllegalStateException: boom\n\tat a.a.b(SourceFile:1)\n"
+
+const testRetracedTrace = "java.lang.IllegalStateException: boom\n" +
+	"\tat com.example.app.Repo.load(Repo.java:42)\n" +
+	"\tat com.example.app.MainActivity.refresh(MainActivity.java:31)\n"
+
+func writeMappingDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "31"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "31", "mapping.txt"), []byte(testRetraceMapping), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestVitalsRetraceCmd_Run(t *testing.T) {
+	mapping := createTempFile(t, "mapping.txt", []byte(testRetraceMapping))
+	trace := createTempFile(t, "trace.txt", []byte(testObfuscatedTrace))
+
+	t.Run("table prints the trace", func(t *testing.T) {
+		cmd := &VitalsRetraceCmd{File: trace, Mapping: mapping}
+		out, err := captureInspectStdout(t, func() error { return cmd.Run(&Globals{Output: "table"}) })
+		if err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+		if out != testRetracedTrace {
+			t.Errorf("output:\n%s\nwant:\n%s", out, testRetracedTrace)
+		}
+	})
+
+	t.Run("json from mapping dir", func(t *testing.T) {
+		cmd := &VitalsRetraceCmd{File: trace, MappingDir: writeMappingDir(t), VersionCode: 31}
+		out, err := captureInspectStdout(t, func() error { return cmd.Run(&Globals{Output: "json"}) })
+		if err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+		var got struct {
+			Mapping  string `json:"mapping"`
+			Text     string `json:"text"`
+			Frames   int    `json:"frames"`
+			Retraced int    `json:"retraced"`
+		}
+		decodeInspectData(t, out, &got)
+		if got.Text != testRetracedTrace || got.Frames != 1 || got.Retraced != 1 || !strings.HasSuffix(got.Mapping, "mapping.txt") {
+			t.Errorf("result = %+v", got)
+		}
+	})
+
+	t.Run("missing mapping", func(t *testing.T) {
+		for _, cmd := range []*VitalsRetraceCmd{
+			{File: trace},
+			{File: trace, MappingDir: writeMappingDir(t), VersionCode: 30},
+		} {
+			err := cmd.Run(&Globals{Output: "json"})
+			if apiErr, ok := err.(*errors.APIError); !ok || apiErr.Code != errors.CodeValidationError {
+				t.Errorf("%+v: err = %v", cmd, err)
+			}
+		}
+	})
+}
+
+func TestVitalsErrorsReportsCmd_DeobfuscateWithoutMapping(t *testing.T) {
+	// Still accepted without a mapping, as before mappings were supported;
+	// the run gets as far as auth.
+	cmd := &VitalsErrorsReportsCmd{Deobfuscate: true}
+	err := cmd.Run(&Globals{Package: "com.example.app", KeyPath: "/nonexistent/key.json"})
+	if err == nil || strings.Contains(err.Error(), "mapping") {
+		t.Errorf("err = %v, want an auth error", err)
+	}
+}
+
+func TestReportRetracer_RetraceReports(t *testing.T) {
+	reports := []*playdeveloperreporting.GooglePlayDeveloperReportingV1beta1ErrorReport{
+		{Name: "r1", ReportText: testObfuscatedTrace, AppVersion: &playdeveloperreporting.GooglePlayDeveloperReportingV1beta1AppVersion{VersionCode: 31}},
+		{Name: "r2", ReportText: testObfuscatedTrace, AppVersion: &playdeveloperreporting.GooglePlayDeveloperReportingV1beta1AppVersion{VersionCode: 30}},
+		{Name: "r3", ReportText: testObfuscatedTrace},
+	}
+
+	rr, err := newReportRetracer("", writeMappingDir(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := formatErrorReports(reports)
+	warnings, err := rr.retraceReports(data, reports)
+	if err != nil {
+		t.Fatalf("retraceReports: %v", err)
+	}
+	if data[0]["reportText"] != testRetracedTrace || data[0]["retraced"] != true {
+		t.Errorf("report 31 = %v", data[0])
+	}
+	if data[1]["reportText"] != testObfuscatedTrace || data[1]["retraced"] != false {
+		t.Errorf("report 30 = %v", data[1])
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "version codes unknown, 30") {
+		t.Errorf("warnings = %v", warnings)
+	}
+
+	// --mapping covers version codes the directory does not.
+	rr, err = newReportRetracer(createTempFile(t, "mapping.txt", []byte(testRetraceMapping)), writeMappingDir(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = formatErrorReports(reports)
+	if warnings, err := rr.retraceReports(data, reports); err != nil || len(warnings) != 0 || data[1]["reportText"] != testRetracedTrace {
+		t.Errorf("warnings = %v, err = %v, report 30 = %v", warnings, err, data[1])
+	}
+
+	if rr, err := newReportRetracer("", ""); rr != nil || err != nil {
+		t.Errorf("newReportRetracer() = %v, %v", rr, err)
+	}
+}
@@ -0,0 +1,418 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	playdeveloperreporting "google.golang.org/api/playdeveloperreporting/v1beta1"
+
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/api"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/vitalsstore"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/config"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/errors"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/output"
+)
+
+// vitalsMetricSet describes a Reporting API metric set the snapshot stores.
+type vitalsMetricSet struct {
+	// alias is the --metric-sets value.
+	alias   string
+	name    string
+	metrics []string
+	// dimensions are required by the API and always requested.
+	dimensions []string
+}
+
+var vitalsMetricSets = []vitalsMetricSet{
+	{alias: "crash", name: "crashRateMetricSet", metrics: []string{metricCrashRate, "userPerceivedCrashRate", metricDistinctUsers}},
+	{alias: "anr", name: "anrRateMetricSet", metrics: []string{metricAnrRate, "userPerceivedAnrRate", metricDistinctUsers}},
+	{alias: "slow-start", name: "slowStartRateMetricSet", metrics: []string{metricSlowStartRate, metricDistinctUsers}, dimensions: []string{"startType"}},
+	{alias: "slow-rendering", name: "slowRenderingRateMetricSet", metrics: []string{"slowRenderingRate20Fps", "slowRenderingRate30Fps", metricDistinctUsers}},
+	{alias: "excessive-wakeup", name: "excessiveWakeupRateMetricSet", metrics: []string{metricExcessiveWakeupRate, metricDistinctUsers}},
+	{alias: "stuck-wakelock", name: "stuckBackgroundWakelockRateMetricSet", metrics: []string{metricStuckBackgroundWakelockRate, metricDistinctUsers}},
+	{alias: "error-count", name: "errorCountMetricSet", metrics: []string{"errorReportCount", metricDistinctUsers}},
+	{alias: "lmk", name: metricSetLmkRate, metrics: []string{metricUserPerceivedLmkRate, metricDistinctUsers}},
+}
+
+// metricSetForMetric returns the metric set that reports a metric.
+func metricSetForMetric(metric string) (string, bool) {
+	for _, set := range vitalsMetricSets {
+		for _, m := range set.metrics {
+			if m == metric && m != metricDistinctUsers {
+				return set.name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// queryMetricSetRows queries every page of a metric set.
+func queryMetricSetRows(ctx context.Context, client *api.Client, svc *playdeveloperreporting.Service, pkg, metricSet string, spec *playdeveloperreporting.GooglePlayDeveloperReportingV1beta1TimelineSpec, dimensions, metrics []string) ([]*playdeveloperreporting.GooglePlayDeveloperReportingV1beta1MetricsRow, error) {
+	name := fmt.Sprintf("apps/%s/%s", pkg, metricSet)
+	var all []*playdeveloperreporting.GooglePlayDeveloperReportingV1beta1MetricsRow
+	pageToken := ""
+	for {
+		var rows []*playdeveloperreporting.GooglePlayDeveloperReportingV1beta1MetricsRow
+		var next string
+		err := client.DoWithRetry(ctx, func() error {
+			var err error
+			rows, next, err = queryMetricSetPage(ctx, svc, name, metricSet, spec, dimensions, metrics, pageToken)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, rows...)
+		if next == "" {
+			return all, nil
+		}
+		pageToken = next
+	}
+}
+
+func queryMetricSetPage(ctx context.Context, svc *playdeveloperreporting.Service, name, metricSet string, spec *playdeveloperreporting.GooglePlayDeveloperReportingV1beta1TimelineSpec, dimensions, metrics []string, pageToken string) ([]*playdeveloperreporting.GooglePlayDeveloperReportingV1beta1MetricsRow, string, error) {
+	const pageSize = 1000
+	switch metricSet {
+	case "crashRateMetricSet":
+		resp, err := svc.Vitals.Crashrate.Query(name, &playdeveloperreporting.GooglePlayDeveloperReportingV1beta1QueryCrashRateMetricSetRequest{
+			TimelineSpec: spec, Dimensions: dimensions, Metrics: metrics, PageSize: pageSize, PageToken: pageToken,
+		}).Context(ctx).Do()
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.Rows, resp.NextPageToken, nil
+	case "anrRateMetricSet":
+		resp, err := svc.Vitals.Anrrate.Query(name, &playdeveloperreporting.GooglePlayDeveloperReportingV1beta1QueryAnrRateMetricSetRequest{
+			TimelineSpec: spec, Dimensions: dimensions, Metrics: metrics, PageSize: pageSize, PageToken: pageToken,
+		}).Context(ctx).Do()
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.Rows, resp.NextPageToken, nil
+	case "slowStartRateMetricSet":
+		resp, err := svc.Vitals.Slowstartrate.Query(name, &playdeveloperreporting.GooglePlayDeveloperReportingV1beta1QuerySlowStartRateMetricSetRequest{
+			TimelineSpec: spec, Dimensions: dimensions, Metrics: metrics, PageSize: pageSize, PageToken: pageToken,
+		}).Context(ctx).Do()
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.Rows, resp.NextPageToken, nil
+	case "slowRenderingRateMetricSet":
+		resp, err := svc.Vitals.Slowrenderingrate.Query(name, &playdeveloperreporting.GooglePlayDeveloperReportingV1beta1QuerySlowRenderingRateMetricSetRequest{
+			TimelineSpec: spec, Dimensions: dimensions, Metrics: metrics, PageSize: pageSize, PageToken: pageToken,
+		}).Context(ctx).Do()
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.Rows, resp.NextPageToken, nil
+	case "excessiveWakeupRateMetricSet":
+		resp, err := svc.Vitals.Excessivewakeuprate.Query(name, &playdeveloperreporting.GooglePlayDeveloperReportingV1beta1QueryExcessiveWakeupRateMetricSetRequest{
+			TimelineSpec: spec, Dimensions: dimensions, Metrics: metrics, PageSize: pageSize, PageToken: pageToken,
+		}).Context(ctx).Do()
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.Rows, resp.NextPageToken, nil
+	case "stuckBackgroundWakelockRateMetricSet":
+		resp, err := svc.Vitals.Stuckbackgroundwakelockrate.Query(name, &playdeveloperreporting.GooglePlayDeveloperReportingV1beta1QueryStuckBackgroundWakelockRateMetricSetRequest{
+			TimelineSpec: spec, Dimensions: dimensions, Metrics: metrics, PageSize: pageSize, PageToken: pageToken,
+		}).Context(ctx).Do()
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.Rows, resp.NextPageToken, nil
+	case "errorCountMetricSet":
+		resp, err := svc.Vitals.Errors.Counts.Query(name, &playdeveloperreporting.GooglePlayDeveloperReportingV1beta1QueryErrorCountMetricSetRequest{
+			TimelineSpec: spec, Dimensions: dimensions, Metrics: metrics, PageSize: pageSize, PageToken: pageToken,
+		}).Context(ctx).Do()
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.Rows, resp.NextPageToken, nil
+	case metricSetLmkRate:
+		resp, err := svc.Vitals.Lmkrate.Query(name, &playdeveloperreporting.GooglePlayDeveloperReportingV1beta1QueryLmkRateMetricSetRequest{
+			TimelineSpec: spec, Dimensions: dimensions, Metrics: metrics, PageSize: pageSize, PageToken: pageToken,
+		}).Context(ctx).Do()
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.Rows, resp.NextPageToken, nil
+	}
+	return nil, "", fmt.Errorf("unsupported metric set %s", metricSet)
+}
+
+// rowDimensions returns a metrics row's dimension values as strings.
+func rowDimensions(row *playdeveloperreporting.GooglePlayDeveloperReportingV1beta1MetricsRow) map[string]string {
+	if len(row.Dimensions) == 0 {
+		return nil
+	}
+	dims := make(map[string]string, len(row.Dimensions))
+	for _, dim := range row.Dimensions {
+		if dim.StringValue != "" {
+			dims[dim.Dimension] = dim.StringValue
+		} else {
+			dims[dim.Dimension] = strconv.FormatInt(dim.Int64Value, 10)
+		}
+	}
+	return dims
+}
+
+// storePoints converts metrics rows to store points.
+func storePoints(rows []*playdeveloperreporting.GooglePlayDeveloperReportingV1beta1MetricsRow, recordedAt time.Time) []vitalsstore.Point {
+	points := make([]vitalsstore.Point, 0, len(rows))
+	for _, row := range rows {
+		if row.StartTime == nil {
+			continue
+		}
+		date := reportingDate(row.StartTime)
+		dims := rowDimensions(row)
+		for _, m := range row.Metrics {
+			if m.DecimalValue == nil || m.DecimalValue.Value == "" {
+				continue
+			}
+			points = append(points, vitalsstore.Point{
+				Date: date, Metric: m.Metric, Dimensions: dims, Value: parseDecimalValue(m), RecordedAt: recordedAt,
+			})
+		}
+	}
+	return points
+}
+
+// reportingDate formats the date of a metrics row.
+func reportingDate(t *playdeveloperreporting.GoogleTypeDateTime) string {
+	if t == nil {
+		return ""
+	}
+	return fmt.Sprintf("%04d-%02d-%02d", t.Year, t.Month, t.Day)
+}
+
+// openVitalsStore opens dir, or the default store under the gpd data dir.
+func openVitalsStore(dir string) *vitalsstore.Store {
+	if dir == "" {
+		dir = filepath.Join(config.GetPaths().DataDir, "vitals")
+	}
+	return vitalsstore.Open(dir)
+}
+
+// storeSeries reads the daily values of an app-level metric in [start, end].
+func storeSeries(st *vitalsstore.Store, pkg, metricSet, metric string, dims map[string]string, start, end time.Time) ([]vitalsstore.Point, error) {
+	points, err := st.Read(pkg, metricSet)
+	if err != nil {
+		return nil, errors.NewAPIError(errors.CodeGeneralError, fmt.Sprintf("failed to read vitals store: %v", err))
+	}
+	return vitalsstore.Filter(points, vitalsstore.Query{Metric: metric, Dimensions: dims, From: start, To: end}), nil
+}
+
+// VitalsSnapshotCmd appends daily vitals values to the local store.
+type VitalsSnapshotCmd struct {
+	MetricSets []string `help:"Metric sets to snapshot: crash, anr, slow-start, slow-rendering, excessive-wakeup, stuck-wakelock, error-count, lmk" enum:"crash,anr,slow-start,slow-rendering,excessive-wakeup,stuck-wakelock,error-count,lmk" default:"crash,anr,slow-start,slow-rendering,excessive-wakeup,stuck-wakelock,error-count,lmk"`
+	Dimensions []string `help:"Dimensions to split values by, e.g. versionCode (default: app-level values)"`
+	StartDate  string   `help:"Start date (ISO 8601, default: 30 days ago)"`
+	EndDate    string   `help:"End date (ISO 8601, default: today)"`
+	Store      string   `help:"Vitals store directory (default: <data dir>/vitals)"`
+}
+
+// snapshotMetricSet is the snapshot result for one metric set.
+type snapshotMetricSet struct {
+	MetricSet  string   `json:"metricSet"`
+	Dimensions []string `json:"dimensions,omitempty"`
+	Rows       int      `json:"rows"`
+	Added      int      `json:"added"`
+}
+
+// Run executes the snapshot command.
+func (cmd *VitalsSnapshotCmd) Run(globals *Globals) error {
+	if err := requirePackage(globals.Package); err != nil {
+		return err
+	}
+	spec, err := buildTimelineSpec(cmd.StartDate, cmd.EndDate)
+	if err != nil {
+		return err
+	}
+	st := openVitalsStore(cmd.Store)
+
+	ctx := context.Background()
+	authMgr := newAuthManager()
+	creds, err := authMgr.Authenticate(ctx, globals.KeyPath)
+	if err != nil {
+		return err
+	}
+	client, err := api.NewClient(ctx, creds.TokenSource, api.WithTimeout(globals.Timeout))
+	if err != nil {
+		return errors.NewAPIError(errors.CodeAuthFailure, fmt.Sprintf("failed to create API client: %v", err))
+	}
+	svc, err := client.PlayReporting()
+	if err != nil {
+		return errors.NewAPIError(errors.CodeGeneralError, fmt.Sprintf("failed to get reporting service: %v", err))
+	}
+
+	selected := make(map[string]bool, len(cmd.MetricSets))
+	for _, alias := range cmd.MetricSets {
+		selected[alias] = true
+	}
+	startTime := time.Now()
+	results := make([]snapshotMetricSet, 0, len(cmd.MetricSets))
+	added := 0
+	for _, set := range vitalsMetricSets {
+		if !selected[set.alias] {
+			continue
+		}
+		dims := mergeDimensions(set.dimensions, cmd.Dimensions)
+		if globals.Verbose {
+			fmt.Fprintf(os.Stderr, "Snapshotting %s\n", set.name)
+		}
+		rows, err := queryMetricSetRows(ctx, client, svc, globals.Package, set.name, spec, dims, set.metrics)
+		if err != nil {
+			return errors.NewAPIError(errors.CodeGeneralError, fmt.Sprintf("failed to query %s: %v", set.name, err))
+		}
+		n, err := st.Append(globals.Package, set.name, storePoints(rows, startTime.UTC()))
+		if err != nil {
+			return errors.NewAPIError(errors.CodeGeneralError, fmt.Sprintf("failed to write vitals store: %v", err))
+		}
+		added += n
+		results = append(results, snapshotMetricSet{MetricSet: set.name, Dimensions: dims, Rows: len(rows), Added: n})
+	}
+
+	return writeOutput(globals, output.NewResult(map[string]interface{}{
+		"package":    globals.Package,
+		"store":      st.Dir(),
+		"added":      added,
+		"metricSets": results,
+	}).WithDuration(time.Since(startTime)).WithServices("playdeveloperreporting"))
+}
+
+func mergeDimensions(required, extra []string) []string {
+	dims := append([]string{}, required...)
+	for _, d := range extra {
+		dup := false
+		for _, have := range dims {
+			dup = dup || have == d
+		}
+		if !dup {
+			dims = append(dims, d)
+		}
+	}
+	return dims
+}
+
+// VitalsHistoryCmd queries trends from the local vitals store.
+type VitalsHistoryCmd struct {
+	Metric    string   `help:"Metric to chart, e.g. crashRate, userPerceivedAnrRate, slowRenderingRate30Fps" default:"crashRate"`
+	MetricSet string   `help:"Metric set (default: the set that reports --metric)"`
+	Dimension []string `help:"Dimension value key=value (repeatable); matches values snapshotted with exactly these dimensions"`
+	From      string   `help:"First date (ISO 8601)"`
+	To        string   `help:"Last date (ISO 8601)"`
+	Period    string   `help:"Bucket size" default:"month" enum:"day,week,month,quarter,year"`
+	Store     string   `help:"Vitals store directory (default: <data dir>/vitals)"`
+}
+
+// historyResult is the JSON output of vitals history.
+type historyResult struct {
+	Package    string               `json:"package"`
+	MetricSet  string               `json:"metricSet"`
+	Metric     string               `json:"metric"`
+	Dimensions map[string]string    `json:"dimensions,omitempty"`
+	Period     string               `json:"period"`
+	Days       int                  `json:"days"`
+	Buckets    []vitalsstore.Bucket `json:"buckets"`
+}
+
+// Run executes the history command. It reads only the local store.
+func (cmd *VitalsHistoryCmd) Run(globals *Globals) error {
+	if err := requirePackage(globals.Package); err != nil {
+		return err
+	}
+	metricSet := cmd.MetricSet
+	if metricSet == "" {
+		set, ok := metricSetForMetric(cmd.Metric)
+		if !ok {
+			return errors.NewAPIError(errors.CodeValidationError, fmt.Sprintf("unknown metric %q", cmd.Metric)).
+				WithHint("Pass --metric-set for metrics such as distinctUsers that several metric sets report")
+		}
+		metricSet = set
+	}
+	dims, err := parseDimensionFilters(cmd.Dimension)
+	if err != nil {
+		return err
+	}
+	var from, to time.Time
+	for _, d := range []struct {
+		flag, value string
+		dst         *time.Time
+	}{{"--from", cmd.From, &from}, {"--to", cmd.To, &to}} {
+		if d.value == "" {
+			continue
+		}
+		if *d.dst, err = time.Parse(vitalsstore.DateLayout, d.value); err != nil {
+			return errors.NewAPIError(errors.CodeValidationError, fmt.Sprintf("invalid %s date: %v", d.flag, err))
+		}
+	}
+
+	st := openVitalsStore(cmd.Store)
+	points, err := storeSeries(st, globals.Package, metricSet, cmd.Metric, dims, from, to)
+	if err != nil {
+		return err
+	}
+	if len(points) == 0 {
+		return errors.NewAPIError(errors.CodeNotFound, fmt.Sprintf("no %s values for %s in %s", cmd.Metric, globals.Package, st.Dir())).
+			WithHint("Record values with gpd vitals snapshot, with the same --dimensions as the --dimension filters")
+	}
+	buckets, err := vitalsstore.Aggregate(points, cmd.Period)
+	if err != nil {
+		return errors.NewAPIError(errors.CodeValidationError, err.Error())
+	}
+
+	res := historyResult{
+		Package: globals.Package, MetricSet: metricSet, Metric: cmd.Metric, Dimensions: dims,
+		Period: cmd.Period, Days: len(points), Buckets: buckets,
+	}
+	var data interface{} = res
+	switch output.ParseFormat(globals.Output) {
+	case output.FormatTable, output.FormatMarkdown, output.FormatCSV, output.FormatExcel:
+		data = historyRows(buckets)
+	}
+	return writeOutput(globals, output.NewResult(data).WithServices("vitals-store"))
+}
+
+func parseDimensionFilters(filters []string) (map[string]string, error) {
+	if len(filters) == 0 {
+		return nil, nil
+	}
+	dims := make(map[string]string, len(filters))
+	for _, f := range filters {
+		k, v, ok := strings.Cut(f, "=")
+		if !ok || k == "" {
+			return nil, errors.NewAPIError(errors.CodeValidationError, fmt.Sprintf("invalid --dimension %q, want key=value", f))
+		}
+		dims[k] = v
+	}
+	return dims, nil
+}
+
+func historyRows(buckets []vitalsstore.Bucket) []map[string]interface{} {
+	rows := make([]map[string]interface{}, 0, len(buckets))
+	for _, b := range buckets {
+		row := map[string]interface{}{
+			"period":    b.Period,
+			"days":      b.Days,
+			"mean":      b.Mean,
+			"min":       b.Min,
+			"max":       b.Max,
+			"yearAgo":   "",
+			"yoyChange": "",
+		}
+		if b.YearAgo != nil {
+			row["yearAgo"] = *b.YearAgo
+		}
+		if b.YoYChange != nil {
+			row["yoyChange"] = fmt.Sprintf("%+.1f%%", *b.YoYChange*100)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
@@ -0,0 +1,164 @@
+//go:build unit
+// +build unit
+
+package cli
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	playdeveloperreporting "google.golang.org/api/playdeveloperreporting/v1beta1"
+
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/vitalsstore"
+)
+
+// seedVitalsStore records daily app-level values ending today.
+func seedVitalsStore(t *testing.T, metricSet, metric string, values []float64) string {
+	t.Helper()
+	dir := t.TempDir()
+	today := time.Now().UTC()
+	points := make([]vitalsstore.Point, 0, len(values))
+	for i, v := range values {
+		day := today.AddDate(0, 0, i-len(values)+1)
+		points = append(points, vitalsstore.Point{Date: day.Format(vitalsstore.DateLayout), Metric: metric, Value: v})
+	}
+	if _, err := vitalsstore.Open(dir).Append("com.example.app", metricSet, points); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestStorePoints(t *testing.T) {
+	row := canaryRow(5, map[string]string{"versionCode": "31"}, metricCrashRate, "0.0125")
+	row.Metrics = append(row.Metrics, &playdeveloperreporting.GooglePlayDeveloperReportingV1beta1MetricValue{Metric: metricDistinctUsers})
+	points := storePoints([]*playdeveloperreporting.GooglePlayDeveloperReportingV1beta1MetricsRow{row}, time.Unix(0, 0))
+	if len(points) != 1 || points[0].Date != "2026-10-05" || points[0].Value != 0.0125 || points[0].Dimensions["versionCode"] != "31" {
+		t.Errorf("points = %+v", points)
+	}
+}
+
+func TestVitalsHistoryCmd_Run(t *testing.T) {
+	dir := seedVitalsStore(t, "crashRateMetricSet", metricCrashRate, []float64{0.01, 0.03})
+	globals := &Globals{Package: "com.example.app", Output: "json"}
+
+	cmd := &VitalsHistoryCmd{Metric: metricCrashRate, Period: "year", Store: dir}
+	out, err := captureInspectStdout(t, func() error { return cmd.Run(globals) })
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	var got historyResult
+	decodeInspectData(t, out, &got)
+	if got.MetricSet != "crashRateMetricSet" || got.Days != 2 || len(got.Buckets) == 0 {
+		t.Errorf("result = %+v", got)
+	}
+
+	for _, tc := range []struct {
+		cmd  *VitalsHistoryCmd
+		want string
+	}{
+		{&VitalsHistoryCmd{Metric: metricDistinctUsers, Period: "month", Store: dir}, "--metric-set"},
+		{&VitalsHistoryCmd{Metric: metricCrashRate, Period: "month", Store: dir, Dimension: []string{"versionCode"}}, "key=value"},
+		{&VitalsHistoryCmd{Metric: metricCrashRate, Period: "month", Store: dir, From: "01/02/2026"}, "invalid --from date"},
+		{&VitalsHistoryCmd{Metric: metricAnrRate, Period: "month", Store: dir}, "gpd vitals snapshot"},
+	} {
+		if err := tc.cmd.Run(globals); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%+v: err = %v, want %q", tc.cmd, err, tc.want)
+		}
+	}
+}
+
+func TestMonitorDashboardCmd_FromStore(t *testing.T) {
+	dir := seedVitalsStore(t, "crashRateMetricSet", metricCrashRate, []float64{0.01, 0.03})
+	cmd := &MonitorDashboardCmd{Metrics: []string{"crashes", "anrs"}, Period: 7, Format: "json", FromStore: true, Store: dir}
+	out, err := captureInspectStdout(t, func() error { return cmd.Run(&Globals{Package: "com.example.app", KeyPath: "/nonexistent/key.json"}) })
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	var got dashboardResult
+	decodeInspectData(t, out, &got)
+	if fmt.Sprintf("%.3f", got.Summary.AvgCrashRate) != "0.020" {
+		t.Errorf("summary = %+v", got.Summary)
+	}
+	if !strings.Contains(out, "no stored values for anrs") {
+		t.Errorf("missing warning in %s", out)
+	}
+}
+
+func TestMonitorDashboardCmd_FromStoreLmk(t *testing.T) {
+	dir := seedVitalsStore(t, metricSetLmkRate, metricUserPerceivedLmkRate, []float64{0.02, 0.04})
+	cmd := &MonitorDashboardCmd{Metrics: []string{"lmk"}, Period: 7, Format: "json", FromStore: true, Store: dir}
+	out, err := captureInspectStdout(t, func() error { return cmd.Run(&Globals{Package: "com.example.app", KeyPath: "/nonexistent/key.json"}) })
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	var got dashboardResult
+	decodeInspectData(t, out, &got)
+	lmk, _ := got.Metrics["lowMemoryKills"].(map[string]interface{})
+	if rate, _ := lmk["averageUserPerceivedLmkRate"].(float64); fmt.Sprintf("%.3f", rate) != "0.030" {
+		t.Errorf("metrics = %+v", got.Metrics)
+	}
+}
+
+func TestMonitorAnomaliesCmd_FromStore(t *testing.T) {
+	// 30 baseline days at 1%, then a week at 5%.
+	values := make([]float64, 0, 38)
+	for i := 0; i < 30; i++ {
+		values = append(values, 0.01)
+	}
+	for i := 0; i < 8; i++ {
+		values = append(values, 0.05)
+	}
+	dir := seedVitalsStore(t, "crashRateMetricSet", metricCrashRate, values)
+	cmd := &MonitorAnomaliesCmd{Metrics: []string{"crashes"}, BaselinePeriod: 30, Sensitivity: "medium", Format: "json", FromStore: true, Store: dir}
+	out, err := captureInspectStdout(t, func() error { return cmd.Run(&Globals{Package: "com.example.app", KeyPath: "/nonexistent/key.json"}) })
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	var got anomalyResult
+	decodeInspectData(t, out, &got)
+	if got.TotalAnomalies != 1 || got.Anomalies[0].Metric != metricCrashRate || math.Abs(got.Anomalies[0].BaselineAvg-0.01) > 1e-9 {
+		t.Errorf("result = %+v", got)
+	}
+}
+
+func TestMonitorAnomaliesCmd_SeasonalFromStore(t *testing.T) {
+	// Weekends run at twice the weekday rate; only today's spike is new.
+	today := time.Now().UTC()
+	values := make([]float64, 45)
+	for i := range values {
+		switch today.AddDate(0, 0, i-len(values)+1).Weekday() {
+		case time.Saturday, time.Sunday:
+			values[i] = 0.02
+		default:
+			values[i] = 0.01
+		}
+	}
+	values[len(values)-1] = 0.05
+	dir := seedVitalsStore(t, "crashRateMetricSet", metricCrashRate, values)
+	cmd := &MonitorAnomaliesCmd{Metrics: []string{"crashes"}, BaselinePeriod: 30, Sensitivity: "medium", Detectors: []string{"seasonal"}, Format: "json", FromStore: true, Store: dir}
+	out, err := captureInspectStdout(t, func() error { return cmd.Run(&Globals{Package: "com.example.app", KeyPath: "/nonexistent/key.json"}) })
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	var got anomalyResult
+	decodeInspectData(t, out, &got)
+	if got.TotalAnomalies != 1 {
+		t.Fatalf("result = %+v", got)
+	}
+	a := got.Anomalies[0]
+	if a.Detector != "seasonal" || a.Date != today.Format(vitalsstore.DateLayout) || a.CurrentValue != 0.05 ||
+		a.ExpectedRange[1] >= 0.05 || a.Confidence < 0.99 {
+		t.Errorf("anomaly = %+v", a)
+	}
+}
+
+func TestMonitorAnomaliesCmd_SeasonalNeedsTwoWeeks(t *testing.T) {
+	cmd := &MonitorAnomaliesCmd{BaselinePeriod: 7, Detectors: []string{"seasonal"}, FromStore: true, Store: t.TempDir()}
+	err := cmd.Run(&Globals{Package: "com.example.app", KeyPath: "/nonexistent/key.json"})
+	if err == nil || !strings.Contains(err.Error(), "at least 14 days") {
+		t.Fatalf("err = %v", err)
+	}
+}
@@ -914,6 +914,38 @@ func TestFormatErrorReports_DeviceModel(t *testing.T) {
 // formatAnomalies Tests
 // ============================================================================
 
+func TestClusterReports(t *testing.T) {
+	reports := []*playdeveloperreporting.GooglePlayDeveloperReportingV1beta1ErrorReport{
+		{
+			Name:       "apps/com.example.app/errorReports/1",
+			Issue:      "apps/com.example.app/errorIssues/7",
+			EventTime:  "2026-10-01T12:00:00Z",
+			ReportText: "java.lang.NullPointerException\n\tat a.a.b(SourceFile:1)",
+			AppVersion: &playdeveloperreporting.GooglePlayDeveloperReportingV1beta1AppVersion{VersionCode: 31},
+			DeviceModel: &playdeveloperreporting.GooglePlayDeveloperReportingV1beta1DeviceModelSummary{
+				DeviceId: &playdeveloperreporting.GooglePlayDeveloperReportingV1beta1DeviceId{BuildBrand: "google", BuildDevice: "panther"},
+			},
+		},
+		{Name: "apps/com.example.app/errorReports/2", EventTime: "not a time", ReportText: "java.lang.NullPointerException"},
+	}
+	data := formatErrorReports(reports)
+	// Retraced text replaces the report text in the formatted rows.
+	data[0]["reportText"] = "java.lang.NullPointerException\n\tat com.example.app.Repo.load(Repo.java:42)"
+
+	got := clusterReports(reports, data)
+	if len(got) != 2 {
+		t.Fatalf("reports = %+v", got)
+	}
+	first := got[0]
+	if first.VersionCode != 31 || first.DeviceModel != "google/panther" || !first.EventTime.Equal(time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)) ||
+		!strings.Contains(first.Text, "com.example.app.Repo.load") || first.Issue != "apps/com.example.app/errorIssues/7" {
+		t.Errorf("first = %+v", first)
+	}
+	if second := got[1]; second.VersionCode != 0 || !second.EventTime.IsZero() || second.DeviceModel != "" {
+		t.Errorf("second = %+v", second)
+	}
+}
+
 func TestFormatAnomalies(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -1466,6 +1498,31 @@ func TestVitalsQueryCmd_MetricSetDetection(t *testing.T) {
 			metrics:     []string{"errorReportCount"},
 			expectedSet: metricSetErrorCount,
 		},
+		{
+			name:        "user perceived LMK rate",
+			metrics:     []string{"userPerceivedLmkRate"},
+			expectedSet: metricSetLmkRate,
+		},
+		{
+			name:        "user weighted LMK rate",
+			metrics:     []string{"userPerceivedLmkRate28dUserWeighted"},
+			expectedSet: metricSetLmkRate,
+		},
+		{
+			name:        "user weighted user perceived ANR rate",
+			metrics:     []string{"userPerceivedAnrRate7dUserWeighted"},
+			expectedSet: metricSetAnrRate,
+		},
+		{
+			name:        "slow rendering 30 fps",
+			metrics:     []string{"slowRenderingRate30Fps"},
+			expectedSet: metricSetSlowRendering,
+		},
+		{
+			name:        "unknown metric falls back to crash rate",
+			metrics:     []string{"unknownRate"},
+			expectedSet: metricSetCrashRate,
+		},
 		{
 			name:        "default when empty metrics",
 			metrics:     []string{},
@@ -1484,26 +1541,10 @@ func TestVitalsQueryCmd_MetricSetDetection(t *testing.T) {
 				Metrics: tt.metrics,
 			}
 
-			// The metric set detection happens inside Run(), but we can test the logic
-			// by checking the name construction logic
+			// Mirrors the metric set detection in Run()
 			metricSetName := metricSetCrashRate
 			if len(cmd.Metrics) > 0 {
-				switch cmd.Metrics[0] {
-				case metricCrashRate, "userPerceivedCrashRate":
-					metricSetName = metricSetCrashRate
-				case metricAnrRate, "userPerceivedAnrRate":
-					metricSetName = metricSetAnrRate
-				case "slowRenderingRate":
-					metricSetName = metricSetSlowRendering
-				case "slowStartRate":
-					metricSetName = metricSetSlowStart
-				case "stuckBackgroundWakelockRate":
-					metricSetName = metricSetStuckBackgroundWakelock
-				case "excessiveWakeupRate":
-					metricSetName = metricSetExcessiveWakeup
-				case "errorCount", "errorReportCount":
-					metricSetName = metricSetErrorCount
-				}
+				metricSetName = metricSetForQuery(cmd.Metrics[0])
 			}
 
 			if metricSetName != tt.expectedSet {
@@ -1528,6 +1569,7 @@ func TestVitalsConstants(t *testing.T) {
 		"metricSetStuckBackgroundWakelock": metricSetStuckBackgroundWakelock,
 		"metricSetExcessiveWakeup":         metricSetExcessiveWakeup,
 		"metricSetErrorCount":              metricSetErrorCount,
+		"metricSetLmkRate":                 metricSetLmkRate,
 	}
 
 	expected := map[string]string{
@@ -1539,6 +1581,7 @@ func TestVitalsConstants(t *testing.T) {
 		"metricSetStuckBackgroundWakelock": "stuckBackgroundWakelockRateMetricSet",
 		"metricSetExcessiveWakeup":         "excessiveWakeupRateMetricSet",
 		"metricSetErrorCount":              "errorCountMetricSet",
+		"metricSetLmkRate":                 "lmkRateMetricSet",
 	}
 
 	for name, value := range constants {
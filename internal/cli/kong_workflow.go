@@ -9,6 +9,7 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/webhooks"
 	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/errors"
 	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/output"
 	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/workflow"
@@ -59,6 +60,9 @@ func (cmd *WorkflowRunCmd) Run(globals *Globals) error {
 	// Execute workflow
 	ctx := context.Background()
 	state, err := runner.Run(ctx, cmd.File)
+	if err != nil || state.Error != "" {
+		emitEvent(ctx, globals, webhooks.EventWorkflowFailed, workflowFailedEvent(cmd.File, state, err))
+	}
 
 	if err != nil {
 		return errors.NewAPIError(errors.CodeGeneralError, "workflow execution failed").
@@ -87,6 +91,23 @@ func (cmd *WorkflowRunCmd) Run(globals *Globals) error {
 	return outputResult(result, globals.Output, globals.Pretty)
 }
 
+// workflowFailedEvent is the workflow.failed event data of a run; state is
+// nil when the run failed before it started.
+func workflowFailedEvent(file string, state *workflow.RunState, err error) map[string]interface{} {
+	data := map[string]interface{}{"file": file}
+	if state != nil {
+		data["runId"] = state.RunID
+		data["workflow"] = state.Workflow.Name
+		data["status"] = state.Status
+		data["currentStep"] = state.CurrentStep
+		data["error"] = state.Error
+	}
+	if err != nil {
+		data["error"] = err.Error()
+	}
+	return data
+}
+
 // WorkflowListCmd lists available workflows and run history.
 type WorkflowListCmd struct {
 	All bool `help:"Include run history"`
@@ -0,0 +1,306 @@
+// Package manifestlint holds pure security lint rules for gpd testing lint.
+// Rules read the decoded manifest of an APK or bundle module and report
+// findings with SARIF levels, so the same results feed JSON, table and
+// GitHub code scanning output. Kong adapters live in package cli.
+package manifestlint
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/artifact"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/output"
+)
+
+// Rule describes one lint rule.
+type Rule struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Level string `json:"level"`
+	// Summary is one line; Help says how to fix it.
+	Summary string `json:"summary"`
+	Help    string `json:"help"`
+}
+
+// Rule IDs.
+const (
+	RuleDebuggable          = "GPD1001"
+	RuleTestOnly            = "GPD1002"
+	RuleBackupWithoutRules  = "GPD1003"
+	RuleCleartextTraffic    = "GPD1004"
+	RuleExportedUnprotected = "GPD1005"
+	RuleMissingExported     = "GPD1006"
+	RuleDeepLinkAutoVerify  = "GPD1007"
+)
+
+// Rules lists every rule in report order.
+var Rules = []Rule{
+	{RuleDebuggable, "debuggable", output.SARIFLevelError,
+		"android:debuggable is true",
+		"Remove android:debuggable from the manifest; release builds must not be debuggable and Play rejects them."},
+	{RuleTestOnly, "test-only", output.SARIFLevelError,
+		"android:testOnly is true",
+		"Build with a release variant; Play rejects test-only APKs and bundles."},
+	{RuleBackupWithoutRules, "backup-without-rules", output.SARIFLevelWarning,
+		"Backups are enabled without backup rules",
+		"Set android:dataExtractionRules (API 31+) and android:fullBackupContent to exclude credentials and tokens, or set android:allowBackup=\"false\"."},
+	{RuleCleartextTraffic, "cleartext-traffic", output.SARIFLevelWarning,
+		"Cleartext HTTP traffic is allowed",
+		"Set android:usesCleartextTraffic=\"false\" or allow cleartext only for specific domains with a network security config."},
+	{RuleExportedUnprotected, "exported-without-permission", output.SARIFLevelWarning,
+		"Exported component is not protected by a permission",
+		"Set android:exported=\"false\" if other apps do not need the component, or require a signature-level android:permission."},
+	{RuleMissingExported, "missing-exported", output.SARIFLevelError,
+		"Component with an intent filter does not set android:exported",
+		"Set android:exported explicitly; from target SDK 31 installs fail without it."},
+	{RuleDeepLinkAutoVerify, "deep-link-autoverify", output.SARIFLevelWarning,
+		"Web deep link intent filter is not auto-verified",
+		"Add android:autoVerify=\"true\" and publish assetlinks.json so other apps cannot claim the links."},
+}
+
+// RuleByID returns the rule with the given ID.
+func RuleByID(id string) (Rule, bool) {
+	for _, r := range Rules {
+		if r.ID == id {
+			return r, true
+		}
+	}
+	return Rule{}, false
+}
+
+// Finding is one rule violation.
+type Finding struct {
+	RuleID  string `json:"ruleId"`
+	Rule    string `json:"rule"`
+	Level   string `json:"level"`
+	Message string `json:"message"`
+	// Module is the bundle module whose manifest has the finding; empty for
+	// APKs. Component is "<kind> <name>" for component findings.
+	Module    string `json:"module,omitempty"`
+	Component string `json:"component,omitempty"`
+}
+
+// Lint checks the manifest of one module.
+func Lint(m *artifact.Manifest, module string) []Finding {
+	l := linter{module: module, findings: make([]Finding, 0)}
+	if app := m.Application; app != nil {
+		l.application(app, m.TargetSDK)
+	}
+	for _, group := range []struct {
+		kind       string
+		components []artifact.Component
+	}{
+		{"activity", m.Activities},
+		{"service", m.Services},
+		{"receiver", m.Receivers},
+		{"provider", m.Providers},
+	} {
+		for _, c := range group.components {
+			l.component(group.kind, c, m.TargetSDK)
+		}
+	}
+	return l.findings
+}
+
+// LintArtifact checks the base manifest and every other bundle module.
+func LintArtifact(a *artifact.Artifact) ([]Finding, error) {
+	if a.Kind != artifact.KindAAB {
+		return Lint(a.Manifest, ""), nil
+	}
+	findings := make([]Finding, 0)
+	for _, module := range a.Modules {
+		m, err := a.ModuleManifest(module)
+		if err != nil {
+			return nil, err
+		}
+		findings = append(findings, Lint(m, module)...)
+	}
+	return findings, nil
+}
+
+type linter struct {
+	module   string
+	findings []Finding
+}
+
+func (l *linter) add(ruleID, component, message string) {
+	rule, _ := RuleByID(ruleID)
+	l.findings = append(l.findings, Finding{
+		RuleID: ruleID, Rule: rule.Name, Level: rule.Level, Message: message,
+		Module: l.module, Component: component,
+	})
+}
+
+func (l *linter) application(app *artifact.Application, targetSDK int) {
+	if isTrue(app.Debuggable) {
+		l.add(RuleDebuggable, "", "<application> sets android:debuggable=\"true\"")
+	}
+	if isTrue(app.TestOnly) {
+		l.add(RuleTestOnly, "", "<application> sets android:testOnly=\"true\"")
+	}
+	// allowBackup defaults to true.
+	if (app.AllowBackup == nil || *app.AllowBackup) && app.FullBackupContent == "" && app.DataExtractionRules == "" {
+		l.add(RuleBackupWithoutRules, "", "backups are enabled (android:allowBackup defaults to true) but neither android:fullBackupContent nor android:dataExtractionRules is set")
+	}
+	switch {
+	case isTrue(app.UsesCleartextTraffic):
+		l.add(RuleCleartextTraffic, "", "<application> sets android:usesCleartextTraffic=\"true\"")
+	case app.UsesCleartextTraffic == nil && app.NetworkSecurityConfig == "" && targetSDK < 28:
+		l.add(RuleCleartextTraffic, "", fmt.Sprintf("cleartext traffic is allowed by default at target SDK %d (below 28) and no network security config is set", targetSDK))
+	}
+}
+
+func (l *linter) component(kind string, c artifact.Component, targetSDK int) {
+	name := kind + " " + c.Name
+	if c.Exported == nil && len(c.IntentFilters) > 0 {
+		message := fmt.Sprintf("%s has an intent filter but no android:exported", c.Name)
+		if targetSDK >= 31 {
+			message += fmt.Sprintf("; installs fail at target SDK %d", targetSDK)
+		}
+		l.add(RuleMissingExported, name, message)
+	}
+	if c.IsExported() && c.Permission == "" && !isLauncher(c) {
+		l.add(RuleExportedUnprotected, name, fmt.Sprintf("%s is exported without android:permission", c.Name))
+	}
+	for _, f := range c.IntentFilters {
+		if hosts := webDeepLinkHosts(f); len(hosts) > 0 && !f.AutoVerify {
+			l.add(RuleDeepLinkAutoVerify, name, fmt.Sprintf("%s handles web links for %v without android:autoVerify=\"true\"", c.Name, hosts))
+		}
+	}
+}
+
+// isLauncher reports whether the component is the app's launcher entry
+// point, which must be exported and cannot require a permission.
+func isLauncher(c artifact.Component) bool {
+	for _, f := range c.IntentFilters {
+		if contains(f.Actions, "android.intent.action.MAIN") && contains(f.Categories, "android.intent.category.LAUNCHER") {
+			return true
+		}
+	}
+	return false
+}
+
+// webDeepLinkHosts returns the hosts of a browsable VIEW filter with http or
+// https data, sorted; nil when the filter is not a web deep link.
+func webDeepLinkHosts(f artifact.IntentFilter) []string {
+	if !contains(f.Actions, "android.intent.action.VIEW") || !contains(f.Categories, "android.intent.category.BROWSABLE") {
+		return nil
+	}
+	web := false
+	hosts := make([]string, 0)
+	for _, d := range f.Data {
+		if d.Scheme == "http" || d.Scheme == "https" {
+			web = true
+		}
+		if d.Host != "" && !contains(hosts, d.Host) {
+			hosts = append(hosts, d.Host)
+		}
+	}
+	if !web {
+		return nil
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+func isTrue(b *bool) bool {
+	return b != nil && *b
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Report is the lint result of one artifact. It renders as SARIF when
+// written with output.FormatSARIF.
+type Report struct {
+	File     string         `json:"file"`
+	Findings []Finding      `json:"findings"`
+	Counts   map[string]int `json:"counts"`
+	// ManifestURI is the source manifest SARIF locations point at.
+	ManifestURI string `json:"-"`
+	// ToolVersion is the gpd version recorded in SARIF.
+	ToolVersion string `json:"-"`
+}
+
+// NewReport counts findings by level.
+func NewReport(file string, findings []Finding) *Report {
+	r := &Report{File: file, Findings: findings, Counts: map[string]int{
+		output.SARIFLevelError: 0, output.SARIFLevelWarning: 0, output.SARIFLevelNote: 0,
+	}}
+	for _, f := range findings {
+		r.Counts[f.Level]++
+	}
+	return r
+}
+
+// SARIF renders the report as a SARIF 2.1.0 log with one run.
+func (r *Report) SARIF() *output.SARIFLog {
+	uri := r.ManifestURI
+	if uri == "" {
+		uri = r.File
+	}
+	driver := output.SARIFDriver{
+		Name:           "gpd",
+		Version:        r.ToolVersion,
+		InformationURI: "https://github.com/dl-alexandre/Google-Play-Developer-CLI",
+		Rules:          make([]output.SARIFRule, 0, len(Rules)),
+	}
+	index := make(map[string]int, len(Rules))
+	for i, rule := range Rules {
+		index[rule.ID] = i
+		driver.Rules = append(driver.Rules, output.SARIFRule{
+			ID:                   rule.ID,
+			Name:                 rule.Name,
+			ShortDescription:     output.SARIFMessage{Text: rule.Summary},
+			Help:                 &output.SARIFMessage{Text: rule.Help},
+			DefaultConfiguration: output.SARIFConfiguration{Level: rule.Level},
+		})
+	}
+
+	results := make([]output.SARIFResult, 0, len(r.Findings))
+	for _, f := range r.Findings {
+		loc := output.SARIFLocation{
+			PhysicalLocation: &output.SARIFPhysicalLocation{ArtifactLocation: output.SARIFArtifactLocation{URI: uri}},
+		}
+		if f.Component != "" || f.Module != "" {
+			logical := output.SARIFLogicalLocation{Name: f.Component, Kind: "element"}
+			if f.Component == "" {
+				logical = output.SARIFLogicalLocation{Name: f.Module, Kind: "module"}
+			}
+			if f.Module != "" {
+				logical.FullyQualifiedName = f.Module + ":" + logical.Name
+			}
+			loc.LogicalLocations = []output.SARIFLogicalLocation{logical}
+		}
+		results = append(results, output.SARIFResult{
+			RuleID:    f.RuleID,
+			RuleIndex: index[f.RuleID],
+			Level:     f.Level,
+			Message:   output.SARIFMessage{Text: f.Message},
+			Locations: []output.SARIFLocation{loc},
+		})
+	}
+	return &output.SARIFLog{
+		Schema:  output.SARIFSchema,
+		Version: output.SARIFVersion,
+		Runs:    []output.SARIFRun{{Tool: output.SARIFTool{Driver: driver}, Results: results}},
+	}
+}
+
+// Rows flattens findings for table, CSV and Excel output.
+func (r *Report) Rows() []map[string]interface{} {
+	rows := make([]map[string]interface{}, 0, len(r.Findings))
+	for _, f := range r.Findings {
+		rows = append(rows, map[string]interface{}{
+			"rule": f.RuleID + " " + f.Rule, "level": f.Level, "module": f.Module,
+			"component": f.Component, "message": f.Message,
+		})
+	}
+	return rows
+}
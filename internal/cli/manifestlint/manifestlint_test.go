@@ -0,0 +1,150 @@
+//go:build unit
+// +build unit
+
+package manifestlint
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/artifact"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/artifact/artifacttest"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/output"
+)
+
+func open(t *testing.T, path string) *artifact.Artifact {
+	t.Helper()
+	a, err := artifact.Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = a.Close() })
+	return a
+}
+
+func ruleIDs(findings []Finding) []string {
+	ids := make([]string, 0, len(findings))
+	for _, f := range findings {
+		ids = append(ids, f.RuleID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func TestLint_Fixture(t *testing.T) {
+	// The fixture has no backup rules and a receiver exported only through
+	// its intent filter; the launcher activity is exempt.
+	findings := Lint(open(t, artifacttest.WriteAPK(t, artifacttest.Manifest("com.example.app", 1))).Manifest, "")
+	want := []string{RuleBackupWithoutRules, RuleExportedUnprotected, RuleMissingExported}
+	if got := ruleIDs(findings); !reflect.DeepEqual(got, want) {
+		t.Fatalf("rules = %v, want %v: %+v", got, want, findings)
+	}
+	for _, f := range findings {
+		if f.RuleID == RuleMissingExported && (f.Component != "receiver .BootReceiver" || f.Level != output.SARIFLevelError) {
+			t.Errorf("missing-exported finding = %+v", f)
+		}
+	}
+}
+
+func TestLint_ApplicationFlags(t *testing.T) {
+	manifest := artifacttest.Manifest("com.example.app", 1)
+	manifest.Child("application").
+		Set("android:debuggable", "true").
+		Set("android:testOnly", "true").
+		Set("android:usesCleartextTraffic", "true").
+		Set("android:allowBackup", "false")
+	receiver := manifest.Child("application").Child("receiver")
+	receiver.Set("android:exported", "true").Set("android:permission", "android.permission.RECEIVE_BOOT_COMPLETED")
+
+	findings := Lint(open(t, artifacttest.WriteAPK(t, manifest)).Manifest, "")
+	want := []string{RuleDebuggable, RuleTestOnly, RuleCleartextTraffic}
+	sort.Strings(want)
+	if got := ruleIDs(findings); !reflect.DeepEqual(got, want) {
+		t.Errorf("rules = %v, want %v", got, want)
+	}
+}
+
+func TestLint_DeepLinks(t *testing.T) {
+	link := func(autoVerify string) *artifacttest.Element {
+		attrs := []string{"android:name", ".LinkActivity", "android:exported", "true"}
+		filter := artifacttest.E("intent-filter", nil,
+			artifacttest.E("action", []string{"android:name", "android.intent.action.VIEW"}),
+			artifacttest.E("category", []string{"android:name", "android.intent.category.DEFAULT"}),
+			artifacttest.E("category", []string{"android:name", "android.intent.category.BROWSABLE"}),
+			artifacttest.E("data", []string{"android:scheme", "https", "android:host", "example.com"}),
+		)
+		if autoVerify != "" {
+			filter.Set("android:autoVerify", autoVerify)
+		}
+		return artifacttest.E("activity", attrs, filter)
+	}
+	for _, tc := range []struct {
+		autoVerify string
+		want       bool
+	}{{"", true}, {"true", false}} {
+		manifest := artifacttest.Manifest("com.example.app", 1)
+		app := manifest.Child("application")
+		app.Children = append(app.Children, link(tc.autoVerify))
+		got := false
+		for _, f := range Lint(open(t, artifacttest.WriteAPK(t, manifest)).Manifest, "") {
+			if f.RuleID == RuleDeepLinkAutoVerify && f.Component == "activity .LinkActivity" {
+				got = true
+			}
+		}
+		if got != tc.want {
+			t.Errorf("autoVerify=%q: finding = %v, want %v", tc.autoVerify, got, tc.want)
+		}
+	}
+}
+
+func TestLintArtifact_BundleModules(t *testing.T) {
+	feature := artifacttest.Entry{
+		Name: "chat/manifest/AndroidManifest.xml",
+		Data: artifacttest.EncodeProtoXML(artifacttest.E("manifest", []string{"package", "com.example.app", "split", "chat"},
+			artifacttest.E("application", nil,
+				artifacttest.E("service", []string{"android:name", ".ChatService", "android:exported", "true"})))),
+	}
+	findings, err := LintArtifact(open(t, artifacttest.WriteAAB(t, artifacttest.Manifest("com.example.app", 1), feature)))
+	if err != nil {
+		t.Fatalf("LintArtifact: %v", err)
+	}
+	found := false
+	for _, f := range findings {
+		if f.Module == "chat" && f.RuleID == RuleExportedUnprotected && f.Component == "service .ChatService" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("findings = %+v", findings)
+	}
+}
+
+func TestReport_SARIF(t *testing.T) {
+	findings := []Finding{
+		{RuleID: RuleDebuggable, Rule: "debuggable", Level: output.SARIFLevelError, Message: "debuggable"},
+		{RuleID: RuleExportedUnprotected, Rule: "exported-without-permission", Level: output.SARIFLevelWarning, Message: "exported", Module: "chat", Component: "service .ChatService"},
+	}
+	r := NewReport("app.aab", findings)
+	r.ManifestURI = "app/src/main/AndroidManifest.xml"
+	if r.Counts[output.SARIFLevelError] != 1 || r.Counts[output.SARIFLevelWarning] != 1 {
+		t.Errorf("counts = %v", r.Counts)
+	}
+
+	log := r.SARIF()
+	if log.Version != output.SARIFVersion || len(log.Runs) != 1 {
+		t.Fatalf("log = %+v", log)
+	}
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != len(Rules) || len(run.Results) != 2 {
+		t.Fatalf("run = %+v", run)
+	}
+	res := run.Results[1]
+	if run.Tool.Driver.Rules[res.RuleIndex].ID != RuleExportedUnprotected {
+		t.Errorf("ruleIndex %d does not point at %s", res.RuleIndex, res.RuleID)
+	}
+	loc := res.Locations[0]
+	if loc.PhysicalLocation.ArtifactLocation.URI != r.ManifestURI || loc.LogicalLocations[0].FullyQualifiedName != "chat:service .ChatService" {
+		t.Errorf("location = %+v", loc)
+	}
+}
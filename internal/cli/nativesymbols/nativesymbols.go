@@ -0,0 +1,273 @@
+// Package nativesymbols collects unstripped native libraries from Android
+// Gradle Plugin build outputs and packages them in the zip layout Play
+// accepts as nativeCode deobfuscation files: one <abi>/<library> entry per
+// library. Kong adapters live in package cli.
+package nativesymbols
+
+import (
+	"archive/zip"
+	"debug/elf"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/artifact"
+)
+
+// MaxZipSize is the largest native debug symbols file Play accepts.
+const MaxZipSize = 300 << 20
+
+// ABIs are the directory names AGP uses for each Android ABI.
+var ABIs = map[string]bool{
+	"arm64-v8a":   true,
+	"armeabi-v7a": true,
+	"x86":         true,
+	"x86_64":      true,
+	"riscv64":     true,
+}
+
+// suffixes are the file names AGP writes symbols under: unstripped
+// libraries in merged_native_libs, and the .sym (symbol table) and .dbg
+// (full debug info) files of debugSymbolLevel.
+var suffixes = []string{".so", ".so.sym", ".so.dbg"}
+
+// Debug levels of a symbols file.
+const (
+	DebugFull     = "full"
+	DebugSymtab   = "symtab"
+	DebugStripped = "stripped"
+)
+
+// File is one symbols file found under the build directory.
+type File struct {
+	ABI string `json:"abi"`
+	// Library is the .so the file has symbols for; Name is the file name
+	// written to the zip.
+	Library string `json:"library"`
+	Name    string `json:"name"`
+	Path    string `json:"path"`
+	BuildID string `json:"buildId,omitempty"`
+	Debug   string `json:"debug"`
+	Size    int64  `json:"size"`
+}
+
+// ZipName is the entry name of f in the upload zip.
+func (f File) ZipName() string {
+	return f.ABI + "/" + f.Name
+}
+
+// Collection is the set of symbols files chosen for upload.
+type Collection struct {
+	Dir   string `json:"dir"`
+	Files []File `json:"files"`
+	// Skipped lists files left out, with the reason.
+	Skipped []string `json:"skipped,omitempty"`
+}
+
+// Collect walks dir for symbols files in <abi>/ directories, skipping AGP's
+// stripped_native_libs output and libraries without symbols. When the same
+// library is found more than once, e.g. under several intermediates, the
+// copy with the most debug info is kept; copies with different build IDs
+// mean dir spans several builds or variants and are an error.
+func Collect(dir string) (*Collection, error) {
+	c := &Collection{Dir: dir, Files: make([]File, 0)}
+	byLibrary := make(map[string][]File)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == "stripped_native_libs" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		abi := filepath.Base(filepath.Dir(path))
+		library, ok := libraryName(d.Name())
+		if !ABIs[abi] || !ok {
+			return nil
+		}
+		f, err := readFile(path, abi, library)
+		if err != nil {
+			c.Skipped = append(c.Skipped, fmt.Sprintf("%s: %v", path, err))
+			return nil
+		}
+		if f.Debug == DebugStripped {
+			c.Skipped = append(c.Skipped, fmt.Sprintf("%s: stripped, no symbols", path))
+			return nil
+		}
+		key := abi + "/" + library
+		byLibrary[key] = append(byLibrary[key], f)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(byLibrary))
+	for k := range byLibrary {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		f, err := pick(k, byLibrary[k])
+		if err != nil {
+			return nil, err
+		}
+		c.Files = append(c.Files, f)
+	}
+	return c, nil
+}
+
+// libraryName maps a symbols file name to the library it describes.
+func libraryName(name string) (string, bool) {
+	for _, s := range suffixes {
+		if strings.HasSuffix(name, s) && len(name) > len(s) {
+			return strings.TrimSuffix(name, s) + ".so", true
+		}
+	}
+	return "", false
+}
+
+func readFile(path, abi, library string) (File, error) {
+	ef, err := elf.Open(path)
+	if err != nil {
+		return File{}, fmt.Errorf("not an ELF file: %w", err)
+	}
+	defer func() { _ = ef.Close() }()
+	info, err := os.Stat(path)
+	if err != nil {
+		return File{}, err
+	}
+	f := File{
+		ABI: abi, Library: library, Name: filepath.Base(path), Path: path,
+		BuildID: artifact.BuildID(ef), Debug: DebugStripped, Size: info.Size(),
+	}
+	switch {
+	case ef.Section(".debug_info") != nil:
+		f.Debug = DebugFull
+	case ef.Section(".symtab") != nil:
+		f.Debug = DebugSymtab
+	}
+	return f, nil
+}
+
+// pick chooses one of several copies of a library.
+func pick(key string, copies []File) (File, error) {
+	rank := map[string]int{DebugFull: 2, DebugSymtab: 1}
+	best := copies[0]
+	for _, f := range copies[1:] {
+		if f.BuildID != best.BuildID {
+			return File{}, fmt.Errorf("%s found with different build IDs in %s (%s) and %s (%s)",
+				key, best.Path, best.BuildID, f.Path, f.BuildID)
+		}
+		if rank[f.Debug] > rank[best.Debug] {
+			best = f
+		}
+	}
+	return best, nil
+}
+
+// WriteZip writes the collection in Play's layout.
+func (c *Collection) WriteZip(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	for _, f := range c.Files {
+		if err := addFile(zw, f); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func addFile(zw *zip.Writer, f File) error {
+	src, err := os.Open(f.Path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = src.Close() }()
+	dst, err := zw.CreateHeader(&zip.FileHeader{Name: f.ZipName(), Method: zip.Deflate})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, src)
+	return err
+}
+
+// Mismatch is a library whose symbols were built from different code than
+// the copy in the bundle.
+type Mismatch struct {
+	ABI     string `json:"abi"`
+	Library string `json:"library"`
+	Symbols string `json:"symbolsBuildId"`
+	Bundle  string `json:"bundleBuildId"`
+}
+
+// Verification compares symbols build IDs with a bundle's libraries.
+type Verification struct {
+	Bundle     string     `json:"bundle"`
+	Matched    int        `json:"matched"`
+	Mismatches []Mismatch `json:"mismatches"`
+	// Unverified lists libraries where either side has no build ID.
+	Unverified []string `json:"unverified,omitempty"`
+	// NotInBundle lists symbols for libraries the bundle does not ship;
+	// MissingSymbols lists bundle libraries with no symbols.
+	NotInBundle    []string `json:"notInBundle,omitempty"`
+	MissingSymbols []string `json:"missingSymbols,omitempty"`
+}
+
+// OK reports whether no library has a mismatched build ID.
+func (v *Verification) OK() bool {
+	return len(v.Mismatches) == 0
+}
+
+// Verify matches files to the bundle's libraries by ABI and name and
+// compares their build IDs.
+func Verify(bundle string, files []File, libs []artifact.NativeLib) *Verification {
+	v := &Verification{Bundle: bundle, Mismatches: make([]Mismatch, 0)}
+	inBundle := make(map[string]artifact.NativeLib, len(libs))
+	for _, lib := range libs {
+		inBundle[lib.ABI+"/"+filepath.Base(lib.Path)] = lib
+	}
+	covered := make(map[string]bool, len(files))
+	for _, f := range files {
+		key := f.ABI + "/" + f.Library
+		covered[key] = true
+		lib, ok := inBundle[key]
+		switch {
+		case !ok:
+			v.NotInBundle = append(v.NotInBundle, key)
+		case f.BuildID == "" || lib.BuildID == "":
+			v.Unverified = append(v.Unverified, key)
+		case !strings.EqualFold(f.BuildID, lib.BuildID):
+			v.Mismatches = append(v.Mismatches, Mismatch{ABI: f.ABI, Library: f.Library, Symbols: f.BuildID, Bundle: lib.BuildID})
+		default:
+			v.Matched++
+		}
+	}
+	for key := range inBundle {
+		if !covered[key] {
+			v.MissingSymbols = append(v.MissingSymbols, key)
+		}
+	}
+	sort.Strings(v.MissingSymbols)
+	return v
+}
+
+// Warnings describes the libraries Verify could not check.
+func (v *Verification) Warnings() []string {
+	warnings := make([]string, 0)
+	for _, key := range v.Unverified {
+		warnings = append(warnings, fmt.Sprintf("%s has no build ID; cannot verify it against %s", key, v.Bundle))
+	}
+	for _, key := range v.NotInBundle {
+		warnings = append(warnings, fmt.Sprintf("%s is not in %s", key, v.Bundle))
+	}
+	if len(v.MissingSymbols) > 0 {
+		warnings = append(warnings, fmt.Sprintf("no symbols for %s", strings.Join(v.MissingSymbols, ", ")))
+	}
+	return warnings
+}
@@ -0,0 +1,131 @@
+//go:build unit
+// +build unit
+
+package nativesymbols
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/artifact"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/artifact/artifacttest"
+)
+
+func writeFiles(t *testing.T, files map[string][]byte) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, data := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+var (
+	idApp    = []byte{0xaa, 0x01}
+	idCrypto = []byte{0xbb, 0x02}
+)
+
+func agpBuildDir(t *testing.T) string {
+	return writeFiles(t, map[string][]byte{
+		"intermediates/merged_native_libs/release/out/lib/arm64-v8a/libapp.so":    artifacttest.SharedObject(idApp, ".symtab"),
+		"intermediates/merged_native_libs/release/out/lib/arm64-v8a/libcrypto.so": artifacttest.SharedObject(idCrypto, ".symtab"),
+		"intermediates/stripped_native_libs/release/out/lib/arm64-v8a/libapp.so":  artifacttest.SharedObject([]byte{0xff}),
+		"outputs/native-debug-symbols/release/arm64-v8a/libapp.so.dbg":            artifacttest.SharedObject(idApp, ".symtab", ".debug_info"),
+		"intermediates/merged_native_libs/release/out/lib/x86_64/libnosyms.so":    artifacttest.SharedObject([]byte{0xcc}),
+		"intermediates/merged_native_libs/release/out/lib/x86_64/README.txt":      []byte("ignored"),
+		"intermediates/cmake/release/obj/arm64/libapp.so":                         []byte("not in an ABI directory"),
+	})
+}
+
+func TestCollect(t *testing.T) {
+	c, err := Collect(agpBuildDir(t))
+	if err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+	names := make([]string, 0, len(c.Files))
+	for _, f := range c.Files {
+		names = append(names, f.ZipName()+" "+f.Debug+" "+f.BuildID)
+	}
+	want := []string{"arm64-v8a/libapp.so.dbg full aa01", "arm64-v8a/libcrypto.so symtab bb02"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("files = %v, want %v", names, want)
+	}
+	if len(c.Skipped) != 1 || !strings.Contains(c.Skipped[0], "libnosyms.so: stripped") {
+		t.Errorf("skipped = %v", c.Skipped)
+	}
+}
+
+func TestCollect_ConflictingBuildIDs(t *testing.T) {
+	dir := writeFiles(t, map[string][]byte{
+		"intermediates/merged_native_libs/debug/out/lib/arm64-v8a/libapp.so":   artifacttest.SharedObject([]byte{1}, ".symtab"),
+		"intermediates/merged_native_libs/release/out/lib/arm64-v8a/libapp.so": artifacttest.SharedObject([]byte{2}, ".symtab"),
+	})
+	if _, err := Collect(dir); err == nil || !strings.Contains(err.Error(), "different build IDs") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestWriteZip(t *testing.T) {
+	c, err := Collect(agpBuildDir(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := c.WriteZip(&buf); err != nil {
+		t.Fatalf("WriteZip: %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0, len(zr.File))
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	sort.Strings(names)
+	if want := []string{"arm64-v8a/libapp.so.dbg", "arm64-v8a/libcrypto.so"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("entries = %v, want %v", names, want)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	files := []File{
+		{ABI: "arm64-v8a", Library: "libapp.so", BuildID: "AA01"},
+		{ABI: "arm64-v8a", Library: "libcrypto.so", BuildID: "bb02"},
+		{ABI: "arm64-v8a", Library: "libold.so"},
+		{ABI: "x86_64", Library: "libapp.so", BuildID: "cc03"},
+	}
+	libs := []artifact.NativeLib{
+		{Path: "base/lib/arm64-v8a/libapp.so", ABI: "arm64-v8a", BuildID: "aa01"},
+		{Path: "base/lib/arm64-v8a/libcrypto.so", ABI: "arm64-v8a", BuildID: "bb99"},
+		{Path: "base/lib/arm64-v8a/libold.so", ABI: "arm64-v8a", BuildID: "dd04"},
+		{Path: "base/lib/armeabi-v7a/libapp.so", ABI: "armeabi-v7a", BuildID: "ee05"},
+	}
+	v := Verify("app.aab", files, libs)
+	if v.OK() || v.Matched != 1 {
+		t.Errorf("verification = %+v", v)
+	}
+	if want := []Mismatch{{ABI: "arm64-v8a", Library: "libcrypto.so", Symbols: "bb02", Bundle: "bb99"}}; !reflect.DeepEqual(v.Mismatches, want) {
+		t.Errorf("mismatches = %+v", v.Mismatches)
+	}
+	if !reflect.DeepEqual(v.Unverified, []string{"arm64-v8a/libold.so"}) ||
+		!reflect.DeepEqual(v.NotInBundle, []string{"x86_64/libapp.so"}) ||
+		!reflect.DeepEqual(v.MissingSymbols, []string{"armeabi-v7a/libapp.so"}) {
+		t.Errorf("verification = %+v", v)
+	}
+	if got := len(v.Warnings()); got != 3 {
+		t.Errorf("warnings = %v", v.Warnings())
+	}
+}
@@ -0,0 +1,154 @@
+// Package openmetrics renders gauges in the OpenMetrics text exposition
+// format and serves the latest set over HTTP, for Prometheus-compatible
+// scrapers. Kong adapters live in package cli.
+package openmetrics
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType is the media type of the exposition.
+const ContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+// Label is one label of a sample.
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Sample is one labelled value of a metric family.
+type Sample struct {
+	Labels []Label
+	Value  float64
+}
+
+// Family is a named gauge and its samples.
+type Family struct {
+	Name    string
+	Help    string
+	Samples []Sample
+}
+
+// Set collects gauge samples by family name.
+type Set struct {
+	families map[string]*Family
+}
+
+// NewSet returns an empty set.
+func NewSet() *Set {
+	return &Set{families: make(map[string]*Family)}
+}
+
+// Gauge adds a sample to the named family, creating it with help on first
+// use.
+func (s *Set) Gauge(name, help string, value float64, labels ...Label) {
+	f, ok := s.families[name]
+	if !ok {
+		f = &Family{Name: name, Help: help}
+		s.families[name] = f
+	}
+	f.Samples = append(f.Samples, Sample{Labels: labels, Value: value})
+}
+
+// Families returns the families ordered by name, with samples ordered by
+// their labels.
+func (s *Set) Families() []Family {
+	out := make([]Family, 0, len(s.families))
+	for _, f := range s.families {
+		samples := append([]Sample{}, f.Samples...)
+		sort.SliceStable(samples, func(i, j int) bool { return labelKey(samples[i].Labels) < labelKey(samples[j].Labels) })
+		out = append(out, Family{Name: f.Name, Help: f.Help, Samples: samples})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+func labelKey(labels []Label) string {
+	var b strings.Builder
+	for _, l := range labels {
+		b.WriteString(l.Name)
+		b.WriteByte(0)
+		b.WriteString(l.Value)
+		b.WriteByte(0)
+	}
+	return b.String()
+}
+
+// Write renders families as gauges, terminated by # EOF.
+func Write(w io.Writer, families []Family) error {
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		bw.WriteString("# TYPE " + f.Name + " gauge\n")
+		if f.Help != "" {
+			bw.WriteString("# HELP " + f.Name + " " + escape(f.Help, false) + "\n")
+		}
+		for _, s := range f.Samples {
+			bw.WriteString(f.Name)
+			if len(s.Labels) > 0 {
+				bw.WriteByte('{')
+				for i, l := range s.Labels {
+					if i > 0 {
+						bw.WriteByte(',')
+					}
+					bw.WriteString(l.Name + `="` + escape(l.Value, true) + `"`)
+				}
+				bw.WriteByte('}')
+			}
+			bw.WriteString(" " + formatValue(s.Value) + "\n")
+		}
+	}
+	bw.WriteString("# EOF\n")
+	return bw.Flush()
+}
+
+// escape escapes backslashes and newlines, and double quotes in label
+// values.
+func escape(s string, quote bool) string {
+	r := strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	if quote {
+		r = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+	}
+	return r.Replace(s)
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// Registry holds the latest families for concurrent scrapes.
+type Registry struct {
+	mu       sync.RWMutex
+	families []Family
+}
+
+// Update replaces the exposed families with those of s.
+func (r *Registry) Update(s *Set) {
+	families := s.Families()
+	r.mu.Lock()
+	r.families = families
+	r.mu.Unlock()
+}
+
+// ServeHTTP writes the latest families.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	r.mu.RLock()
+	families := r.families
+	r.mu.RUnlock()
+	w.Header().Set("Content-Type", ContentType)
+	_ = Write(w, families)
+}
@@ -0,0 +1,57 @@
+//go:build unit
+// +build unit
+
+package openmetrics
+
+import (
+	"io"
+	"math"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	s := NewSet()
+	s.Gauge("gpd_vitals_crash_rate", "Daily crash rate.", 0.012, Label{"package", "com.b"}, Label{"version", "all"})
+	s.Gauge("gpd_vitals_crash_rate", "ignored", 0.5, Label{"package", "com.a"}, Label{"version", "31"})
+	s.Gauge("gpd_exporter_source_up", "Whether the last poll of a source succeeded.\nPer package.", 1)
+	s.Gauge("gpd_odd", "", math.NaN(), Label{"name", "a \"quoted\"\\path\nline"})
+
+	var b strings.Builder
+	if err := Write(&b, s.Families()); err != nil {
+		t.Fatal(err)
+	}
+	want := `# TYPE gpd_exporter_source_up gauge
+# HELP gpd_exporter_source_up Whether the last poll of a source succeeded.\nPer package.
+gpd_exporter_source_up 1
+# TYPE gpd_odd gauge
+gpd_odd{name="a \"quoted\"\\path\nline"} NaN
+# TYPE gpd_vitals_crash_rate gauge
+# HELP gpd_vitals_crash_rate Daily crash rate.
+gpd_vitals_crash_rate{package="com.a",version="31"} 0.5
+gpd_vitals_crash_rate{package="com.b",version="all"} 0.012
+# EOF
+`
+	if b.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	var r Registry
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if body, _ := io.ReadAll(rec.Body); string(body) != "# EOF\n" || rec.Header().Get("Content-Type") != ContentType {
+		t.Errorf("empty registry = %q (%s)", body, rec.Header().Get("Content-Type"))
+	}
+
+	s := NewSet()
+	s.Gauge("gpd_up", "", 1)
+	r.Update(s)
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if !strings.Contains(rec.Body.String(), "gpd_up 1\n") {
+		t.Errorf("body = %q", rec.Body.String())
+	}
+}
@@ -22,6 +22,7 @@ var validOutputFormats = map[string]bool{
 	"markdown": true,
 	"csv":      true,
 	"excel":    true,
+	"sarif":    true,
 }
 
 // stdoutIsTerminal reports whether fd 1 is a terminal. Overridable in tests.
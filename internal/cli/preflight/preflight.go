@@ -0,0 +1,387 @@
+// Package preflight holds pure local checks run against an APK or AAB before
+// it is uploaded (gpd testing validate and friends). Each check reads the
+// decoded artifact and applies a Play policy; Kong adapters live in package
+// cli.
+package preflight
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/apksig"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/artifact"
+)
+
+// Check statuses.
+const (
+	StatusPass = "pass"
+	StatusWarn = "warn"
+	StatusFail = "fail"
+	StatusSkip = "skip"
+)
+
+// Check is the outcome of a single preflight check.
+type Check struct {
+	Name    string                 `json:"name"`
+	Status  string                 `json:"status"`
+	Message string                 `json:"message,omitempty"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// Play size limits for the compressed download. Install-time delivery covers
+// the base module and install-time feature modules together; asset packs are
+// limited individually. Checks warn once an artifact reaches SizeWarnRatio of
+// a limit.
+const (
+	MaxInstallTimeDownloadBytes = 200 * 1000 * 1000
+	MaxAssetPackBytes           = 1500 * 1000 * 1000
+	MaxAPKBytes                 = 100 * 1000 * 1000
+	SizeWarnRatio               = 0.9
+)
+
+// targetSDKRequirement is a Play target API level deadline for new apps and
+// app updates.
+type targetSDKRequirement struct {
+	Effective time.Time
+	MinTarget int
+}
+
+// targetSDKRequirements lists Play's target API deadlines for phone and
+// tablet apps, oldest first. Wear OS, TV and Automotive lag behind and are
+// not modeled.
+var targetSDKRequirements = []targetSDKRequirement{
+	{time.Date(2023, time.August, 31, 0, 0, 0, 0, time.UTC), 33},
+	{time.Date(2024, time.August, 31, 0, 0, 0, 0, time.UTC), 34},
+	{time.Date(2025, time.August, 31, 0, 0, 0, 0, time.UTC), 35},
+	{time.Date(2026, time.August, 31, 0, 0, 0, 0, time.UTC), 36},
+}
+
+// RequiredTargetSDK returns the minimum targetSdkVersion Play accepts for
+// uploads at now, or 0 before the first known deadline.
+func RequiredTargetSDK(now time.Time) int {
+	required := 0
+	for _, r := range targetSDKRequirements {
+		if !now.Before(r.Effective) {
+			required = r.MinTarget
+		}
+	}
+	return required
+}
+
+// nextTargetSDKRequirement returns the first deadline after now, if any.
+func nextTargetSDKRequirement(now time.Time) (targetSDKRequirement, bool) {
+	for _, r := range targetSDKRequirements {
+		if now.Before(r.Effective) {
+			return r, true
+		}
+	}
+	return targetSDKRequirement{}, false
+}
+
+// restrictedPermissions maps permissions that need a Play Console
+// declaration (or are refused outright without a qualifying core use case)
+// to the policy that governs them.
+var restrictedPermissions = map[string]string{
+	"android.permission.READ_SMS":                   "SMS and Call Log",
+	"android.permission.SEND_SMS":                   "SMS and Call Log",
+	"android.permission.RECEIVE_SMS":                "SMS and Call Log",
+	"android.permission.RECEIVE_MMS":                "SMS and Call Log",
+	"android.permission.RECEIVE_WAP_PUSH":           "SMS and Call Log",
+	"android.permission.READ_CALL_LOG":              "SMS and Call Log",
+	"android.permission.WRITE_CALL_LOG":             "SMS and Call Log",
+	"android.permission.PROCESS_OUTGOING_CALLS":     "SMS and Call Log",
+	"android.permission.MANAGE_EXTERNAL_STORAGE":    "All files access",
+	"android.permission.QUERY_ALL_PACKAGES":         "Package visibility",
+	"android.permission.REQUEST_INSTALL_PACKAGES":   "Request install packages",
+	"android.permission.ACCESS_BACKGROUND_LOCATION": "Background location",
+	"android.permission.READ_MEDIA_IMAGES":          "Photo and video permissions",
+	"android.permission.READ_MEDIA_VIDEO":           "Photo and video permissions",
+	"android.permission.USE_EXACT_ALARM":            "Exact alarm",
+	"android.permission.USE_FULL_SCREEN_INTENT":     "Full-screen intent",
+}
+
+// dangerousPermissions are runtime ("dangerous" protection level) permissions
+// that need no declaration but are reported for review.
+var dangerousPermissions = map[string]bool{
+	"android.permission.ACCESS_COARSE_LOCATION": true,
+	"android.permission.ACCESS_FINE_LOCATION":   true,
+	"android.permission.BODY_SENSORS":           true,
+	"android.permission.CALL_PHONE":             true,
+	"android.permission.CAMERA":                 true,
+	"android.permission.GET_ACCOUNTS":           true,
+	"android.permission.POST_NOTIFICATIONS":     true,
+	"android.permission.READ_CALENDAR":          true,
+	"android.permission.READ_CONTACTS":          true,
+	"android.permission.READ_EXTERNAL_STORAGE":  true,
+	"android.permission.READ_PHONE_NUMBERS":     true,
+	"android.permission.READ_PHONE_STATE":       true,
+	"android.permission.RECORD_AUDIO":           true,
+	"android.permission.WRITE_CALENDAR":         true,
+	"android.permission.WRITE_CONTACTS":         true,
+	"android.permission.WRITE_EXTERNAL_STORAGE": true,
+}
+
+// AAB checks the bundle layout bundletool and Play expect: a readable
+// BundleConfig.pb, a base module, and no stray top-level directories. APKs
+// are flagged because Play only accepts them for legacy apps.
+func AAB(a *artifact.Artifact) Check {
+	c := Check{Name: "aab_format"}
+	if a.Kind == artifact.KindAPK {
+		c.Status = StatusWarn
+		c.Message = "artifact is an APK; Play requires an Android App Bundle for new apps"
+		return c
+	}
+
+	version, err := a.BundletoolVersion()
+	if err != nil {
+		c.Status = StatusFail
+		c.Message = fmt.Sprintf("invalid bundle: %v", err)
+		return c
+	}
+	c.Details = map[string]interface{}{
+		"bundletoolVersion": version,
+		"modules":           a.Modules,
+	}
+	if stray := a.UnknownBundleDirs(); len(stray) > 0 {
+		c.Status = StatusFail
+		c.Message = fmt.Sprintf("directories without a module manifest: %s", strings.Join(stray, ", "))
+		c.Details["unknownDirs"] = stray
+		return c
+	}
+	c.Status = StatusPass
+	c.Message = fmt.Sprintf("valid bundle with %d module(s)", len(a.Modules))
+	return c
+}
+
+// Signing verifies every signature the artifact carries and reports the
+// effective signer. APKs targeting API 30 or later must also carry an APK
+// Signature Scheme v2+ block, or devices refuse to install them.
+func Signing(a *artifact.Artifact) Check {
+	c := Check{Name: "signing"}
+	res, err := apksig.Verify(a.Path)
+	if err != nil {
+		c.Status = StatusFail
+		c.Message = fmt.Sprintf("signature unreadable: %v", err)
+		return c
+	}
+	if !res.Signed() {
+		c.Status = StatusFail
+		c.Message = "artifact is unsigned; sign it with your upload key before uploading"
+		return c
+	}
+
+	schemes := res.Names()
+	c.Details = map[string]interface{}{"schemes": schemes}
+	for _, s := range res.Schemes {
+		if !s.Verified {
+			c.Status = StatusFail
+			c.Message = fmt.Sprintf("%s signature does not verify: %s", s.Scheme, s.Error)
+			return c
+		}
+	}
+	signer := res.Signer()
+	c.Details["sha256"] = signer.Certificate.SHA256
+	c.Details["subject"] = signer.Certificate.Subject
+	if len(signer.Lineage) > 0 {
+		c.Details["lineage"] = signer.Lineage
+	}
+	if a.Kind == artifact.KindAPK && a.Manifest.TargetSDK >= 30 &&
+		res.Scheme(apksig.SchemeV2) == nil && res.Scheme(apksig.SchemeV3) == nil && res.Scheme(apksig.SchemeV31) == nil {
+		c.Status = StatusFail
+		c.Message = fmt.Sprintf("targetSdk %d requires APK Signature Scheme v2 or later, found only %s",
+			a.Manifest.TargetSDK, strings.Join(schemes, ", "))
+		return c
+	}
+	c.Status = StatusPass
+	c.Message = fmt.Sprintf("signed (%s) by %s", strings.Join(schemes, ", "), signer.Certificate.SHA256)
+	return c
+}
+
+// Permissions flags restricted permissions that need a Play Console
+// declaration and lists the runtime permissions requested.
+func Permissions(m *artifact.Manifest) Check {
+	c := Check{Name: "permissions"}
+	restricted := make(map[string]string)
+	dangerous := make([]string, 0)
+	for _, name := range m.PermissionNames() {
+		if policy := restrictedPermissions[name]; policy != "" {
+			restricted[name] = policy
+		} else if dangerousPermissions[name] {
+			dangerous = append(dangerous, name)
+		}
+	}
+	c.Details = map[string]interface{}{
+		"requested": len(m.Permissions),
+		"dangerous": dangerous,
+	}
+	if len(restricted) == 0 {
+		c.Status = StatusPass
+		c.Message = fmt.Sprintf("%d permission(s), none restricted", len(m.Permissions))
+		return c
+	}
+
+	names := make([]string, 0, len(restricted))
+	for name := range restricted {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	c.Details["restricted"] = restricted
+	c.Status = StatusWarn
+	c.Message = fmt.Sprintf("restricted permissions need a Play Console declaration: %s", strings.Join(names, ", "))
+	return c
+}
+
+// Size estimates the compressed download size from zip entry sizes and
+// compares it with Play's limits.
+func Size(a *artifact.Artifact) Check {
+	c := Check{Name: "size"}
+	sizes, err := a.ModuleSizes()
+	if err != nil {
+		c.Status = StatusFail
+		c.Message = fmt.Sprintf("cannot size modules: %v", err)
+		return c
+	}
+
+	var installTime int64
+	worst := 0.0
+	var over []string
+	for _, s := range sizes {
+		if s.Type == "asset-pack" {
+			ratio := float64(s.Compressed) / MaxAssetPackBytes
+			worst = max(worst, ratio)
+			if ratio > 1 {
+				over = append(over, fmt.Sprintf("asset pack %s is %s (limit %s)", s.Module, formatBytes(s.Compressed), formatBytes(MaxAssetPackBytes)))
+			}
+			continue
+		}
+		if s.Delivery == artifact.DeliveryInstallTime {
+			installTime += s.Compressed
+		}
+	}
+
+	limit := int64(MaxInstallTimeDownloadBytes)
+	if a.Kind == artifact.KindAPK {
+		limit = MaxAPKBytes
+	}
+	ratio := float64(installTime) / float64(limit)
+	worst = max(worst, ratio)
+	if ratio > 1 {
+		over = append(over, fmt.Sprintf("install-time download is %s (limit %s)", formatBytes(installTime), formatBytes(limit)))
+	}
+
+	c.Details = map[string]interface{}{
+		"installTimeBytes": installTime,
+		"limitBytes":       limit,
+		"modules":          sizes,
+	}
+	switch {
+	case len(over) > 0:
+		c.Status = StatusFail
+		c.Message = strings.Join(over, "; ")
+	case worst >= SizeWarnRatio:
+		c.Status = StatusWarn
+		c.Message = fmt.Sprintf("install-time download is %s, within %.0f%% of the %s limit",
+			formatBytes(installTime), (1-SizeWarnRatio)*100, formatBytes(limit))
+	default:
+		c.Status = StatusPass
+		c.Message = fmt.Sprintf("install-time download is %s (limit %s)", formatBytes(installTime), formatBytes(limit))
+	}
+	return c
+}
+
+// APILevel compares targetSdkVersion with Play's target API requirement at
+// now, warning when the next scheduled requirement is not yet met.
+func APILevel(m *artifact.Manifest, now time.Time) Check {
+	c := Check{Name: "api_level"}
+	required := RequiredTargetSDK(now)
+	c.Details = map[string]interface{}{
+		"minSdk":            m.MinSDK,
+		"targetSdk":         m.TargetSDK,
+		"requiredTargetSdk": required,
+	}
+	if m.TargetSDK < required {
+		c.Status = StatusFail
+		c.Message = fmt.Sprintf("targetSdk %d is below Play's required %d; raise targetSdkVersion", m.TargetSDK, required)
+		return c
+	}
+	if next, ok := nextTargetSDKRequirement(now); ok && m.TargetSDK < next.MinTarget {
+		c.Details["nextRequiredTargetSdk"] = next.MinTarget
+		c.Details["nextDeadline"] = next.Effective.Format("2006-01-02")
+		c.Status = StatusWarn
+		c.Message = fmt.Sprintf("targetSdk %d must be raised to %d by %s",
+			m.TargetSDK, next.MinTarget, next.Effective.Format("2006-01-02"))
+		return c
+	}
+	c.Status = StatusPass
+	c.Message = fmt.Sprintf("targetSdk %d meets Play's required %d", m.TargetSDK, required)
+	return c
+}
+
+// NativeLibResult is the page-size verdict for one native library.
+type NativeLibResult struct {
+	artifact.NativeLib
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// PageSize checks that every 64-bit native library supports 16 KB pages:
+// each ELF LOAD segment aligned to at least 16 KB and, for uncompressed
+// libraries in an APK, the file data itself 16 KB aligned in the zip.
+// 32-bit libraries are listed but not required to comply.
+func PageSize(a *artifact.Artifact) Check {
+	c := Check{Name: "page_size"}
+	libs, err := a.NativeLibs()
+	if err != nil {
+		c.Status = StatusFail
+		c.Message = fmt.Sprintf("cannot read native libraries: %v", err)
+		return c
+	}
+	if len(libs) == 0 {
+		c.Status = StatusPass
+		c.Message = "no native libraries"
+		return c
+	}
+
+	results := make([]NativeLibResult, 0, len(libs))
+	var failing []string
+	passed := 0
+	for _, lib := range libs {
+		r := NativeLibResult{NativeLib: lib, Status: StatusPass}
+		switch {
+		case lib.Error != "":
+			r.Status, r.Reason = StatusFail, lib.Error
+		case !lib.Is64Bit:
+			r.Status, r.Reason = StatusSkip, "32-bit libraries are exempt"
+		case lib.LoadAlign < artifact.PageSize16K:
+			r.Status, r.Reason = StatusFail, fmt.Sprintf("LOAD segments aligned to %d bytes; relink with -Wl,-z,max-page-size=16384", lib.LoadAlign)
+		case lib.ZipAligned != nil && !*lib.ZipAligned:
+			r.Status, r.Reason = StatusFail, fmt.Sprintf("uncompressed at zip offset %d; run zipalign -P 16", lib.ZipOffset)
+		}
+		switch r.Status {
+		case StatusFail:
+			failing = append(failing, lib.Path)
+		case StatusPass:
+			passed++
+		}
+		results = append(results, r)
+	}
+	c.Details = map[string]interface{}{"libraries": results}
+	if len(failing) > 0 {
+		c.Status = StatusFail
+		c.Message = fmt.Sprintf("%d of %d native libraries do not support 16 KB pages: %s",
+			len(failing), len(libs), strings.Join(failing, ", "))
+		return c
+	}
+	c.Status = StatusPass
+	c.Message = fmt.Sprintf("%d 64-bit native libraries support 16 KB pages", passed)
+	if exempt := len(libs) - passed; exempt > 0 {
+		c.Message += fmt.Sprintf(" (%d 32-bit exempt)", exempt)
+	}
+	return c
+}
+
+func formatBytes(n int64) string {
+	return fmt.Sprintf("%.1f MB", float64(n)/1000/1000)
+}
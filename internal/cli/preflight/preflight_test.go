@@ -0,0 +1,171 @@
+//go:build unit
+// +build unit
+
+package preflight
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/artifact"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/artifact/artifacttest"
+)
+
+func open(t *testing.T, path string) *artifact.Artifact {
+	t.Helper()
+	a, err := artifact.Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = a.Close() })
+	return a
+}
+
+func TestAAB(t *testing.T) {
+	manifest := artifacttest.Manifest("com.example.app", 1)
+	if c := AAB(open(t, artifacttest.WriteAAB(t, manifest))); c.Status != StatusPass {
+		t.Errorf("valid bundle: %+v", c)
+	}
+	if c := AAB(open(t, artifacttest.WriteAPK(t, manifest))); c.Status != StatusWarn {
+		t.Errorf("apk: %+v", c)
+	}
+	stray := artifacttest.WriteAAB(t, manifest, artifacttest.Entry{Name: "feature/dex/classes.dex", Data: []byte("x")})
+	if c := AAB(open(t, stray)); c.Status != StatusFail || !strings.Contains(c.Message, "feature") {
+		t.Errorf("stray dir: %+v", c)
+	}
+	noConfig := artifacttest.WriteZip(t, "app.aab", []artifacttest.Entry{
+		{Name: "base/manifest/AndroidManifest.xml", Data: artifacttest.EncodeProtoXML(manifest)},
+	})
+	if c := AAB(open(t, noConfig)); c.Status != StatusFail || !strings.Contains(c.Message, "BundleConfig.pb") {
+		t.Errorf("missing BundleConfig.pb: %+v", c)
+	}
+}
+
+func TestSigning(t *testing.T) {
+	signer := artifacttest.NewSigner(t, "upload")
+	manifest := artifacttest.Manifest("com.example.app", 1)
+
+	bundle := artifacttest.WriteAAB(t, manifest)
+	signer.SignJAR(t, bundle)
+	c := Signing(open(t, bundle))
+	if c.Status != StatusPass || c.Details["sha256"] != signer.Fingerprint() {
+		t.Errorf("signed bundle: %+v", c)
+	}
+	if c := Signing(open(t, artifacttest.WriteAAB(t, manifest))); c.Status != StatusFail || !strings.Contains(c.Message, "unsigned") {
+		t.Errorf("unsigned bundle: %+v", c)
+	}
+
+	v1Only := artifacttest.WriteAPK(t, manifest)
+	signer.SignJAR(t, v1Only)
+	if c := Signing(open(t, v1Only)); c.Status != StatusFail || !strings.Contains(c.Message, "v2") {
+		t.Errorf("v1-only APK targeting 34: %+v", c)
+	}
+	apk := artifacttest.WriteAPK(t, manifest)
+	signer.SignJAR(t, apk)
+	signer.SignAPK(t, apk, artifacttest.BlockIDV2)
+	if c := Signing(open(t, apk)); c.Status != StatusPass {
+		t.Errorf("v1+v2 APK: %+v", c)
+	}
+
+	junk := artifacttest.WriteAPK(t, manifest)
+	artifacttest.InsertSigningBlock(t, junk, map[uint32][]byte{artifacttest.BlockIDV2: []byte("v2")})
+	if c := Signing(open(t, junk)); c.Status != StatusFail || !strings.Contains(c.Message, "does not verify") {
+		t.Errorf("malformed v2 block: %+v", c)
+	}
+}
+
+func TestPermissions(t *testing.T) {
+	manifest := artifacttest.Manifest("com.example.app", 1)
+	c := Permissions(open(t, artifacttest.WriteAPK(t, manifest)).Manifest)
+	if c.Status != StatusWarn || !strings.Contains(c.Message, "READ_SMS") {
+		t.Errorf("READ_SMS: %+v", c)
+	}
+
+	clean := artifacttest.E("manifest", []string{"package", "com.example.app"},
+		artifacttest.E("uses-permission", []string{"android:name", "android.permission.INTERNET"}),
+		artifacttest.E("uses-permission", []string{"android:name", "android.permission.CAMERA"}),
+	)
+	c = Permissions(open(t, artifacttest.WriteAPK(t, clean)).Manifest)
+	if c.Status != StatusPass {
+		t.Errorf("clean: %+v", c)
+	}
+	if got := c.Details["dangerous"].([]string); len(got) != 1 || got[0] != "android.permission.CAMERA" {
+		t.Errorf("dangerous = %v", got)
+	}
+}
+
+func TestSize(t *testing.T) {
+	manifest := artifacttest.Manifest("com.example.app", 1)
+	if c := Size(open(t, artifacttest.WriteAAB(t, manifest))); c.Status != StatusPass {
+		t.Errorf("small bundle: %+v", c)
+	}
+
+	// Stored entries keep their full size in the zip.
+	big := artifacttest.Entry{Name: "base/lib/arm64-v8a/libbig.so", Data: make([]byte, MaxInstallTimeDownloadBytes+1), Stored: true}
+	c := Size(open(t, artifacttest.WriteAAB(t, manifest, big)))
+	if c.Status != StatusFail || !strings.Contains(c.Message, "install-time") {
+		t.Errorf("oversized bundle: %+v", c)
+	}
+}
+
+func TestPageSize(t *testing.T) {
+	manifest := artifacttest.Manifest("com.example.app", 1)
+	if c := PageSize(open(t, artifacttest.WriteAPK(t, manifest))); c.Status != StatusPass || c.Message != "no native libraries" {
+		t.Errorf("no libs: %+v", c)
+	}
+
+	good := artifacttest.WriteAPK(t, manifest,
+		artifacttest.Entry{Name: "lib/arm64-v8a/libapp.so", Data: artifacttest.ELF(true, artifact.PageSize16K), Stored: true, Align: artifact.PageSize16K},
+		artifacttest.Entry{Name: "lib/armeabi-v7a/libapp.so", Data: artifacttest.ELF(false, 4096)},
+	)
+	c := PageSize(open(t, good))
+	if c.Status != StatusPass || !strings.Contains(c.Message, "1 32-bit exempt") {
+		t.Errorf("aligned: %+v", c)
+	}
+
+	bad := artifacttest.WriteAAB(t, manifest,
+		artifacttest.Entry{Name: "base/lib/arm64-v8a/libok.so", Data: artifacttest.ELF(true, artifact.PageSize16K)},
+		artifacttest.Entry{Name: "base/lib/x86_64/lib4k.so", Data: artifacttest.ELF(true, 4096)},
+	)
+	c = PageSize(open(t, bad))
+	if c.Status != StatusFail || !strings.Contains(c.Message, "base/lib/x86_64/lib4k.so") || strings.Contains(c.Message, "libok") {
+		t.Errorf("4 KB aligned: %+v", c)
+	}
+	libs := c.Details["libraries"].([]NativeLibResult)
+	if len(libs) != 2 || libs[1].Status != StatusFail || !strings.Contains(libs[1].Reason, "max-page-size") {
+		t.Errorf("libraries = %+v", libs)
+	}
+
+	unaligned := artifacttest.WriteAPK(t, manifest,
+		artifacttest.Entry{Name: "lib/arm64-v8a/libapp.so", Data: artifacttest.ELF(true, artifact.PageSize16K), Stored: true},
+	)
+	if c := PageSize(open(t, unaligned)); c.Status != StatusFail || !strings.Contains(c.Details["libraries"].([]NativeLibResult)[0].Reason, "zipalign") {
+		t.Errorf("zip-unaligned: %+v", c)
+	}
+}
+
+func TestAPILevel(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		now    time.Time
+		want   string
+	}{
+		{"meets requirement", "35", time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC), StatusWarn},
+		{"meets next requirement", "36", time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC), StatusPass},
+		{"below requirement", "34", time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC), StatusFail},
+		{"after latest deadline", "36", time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC), StatusPass},
+		{"deadline day", "35", time.Date(2026, time.August, 31, 0, 0, 0, 0, time.UTC), StatusFail},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			manifest := artifacttest.E("manifest", []string{"package", "com.example.app"},
+				artifacttest.E("uses-sdk", []string{"android:minSdkVersion", "24", "android:targetSdkVersion", tc.target}))
+			c := APILevel(open(t, artifacttest.WriteAPK(t, manifest)).Manifest, tc.now)
+			if c.Status != tc.want {
+				t.Errorf("status = %s, want %s (%s)", c.Status, tc.want, c.Message)
+			}
+		})
+	}
+}
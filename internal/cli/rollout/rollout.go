@@ -0,0 +1,250 @@
+// Package rollout drives a staged rollout: it raises the user fraction of a
+// track's in-progress release step by step, checks the health of the
+// rolling-out version code before each raise, and halts the release or
+// restores the previous fraction when it is unhealthy. A check without data
+// holds the release where it is. Every transition is
+// recorded, and a State file carries a rollout across runs. Kong adapters
+// live in package cli.
+package rollout
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Actions of a transition.
+const (
+	ActionStart    = "start"
+	ActionHealth   = "health-check"
+	ActionAdvance  = "advance"
+	ActionHold     = "hold"
+	ActionHalt     = "halt"
+	ActionRollback = "rollback"
+)
+
+// Final statuses of a run.
+const (
+	StatusCompleted  = "completed"
+	StatusInProgress = "in-progress"
+	StatusHalted     = "halted"
+	StatusRolledBack = "rolled-back"
+)
+
+// Release is the in-progress release of a track.
+type Release struct {
+	VersionCodes []int64
+	// Percentage is the share of users the release is rolled out to.
+	Percentage float64
+}
+
+// VersionCode is the highest version code of the release, the one whose
+// vitals matter.
+func (r Release) VersionCode() int64 {
+	var vc int64
+	for _, v := range r.VersionCodes {
+		if v > vc {
+			vc = v
+		}
+	}
+	return vc
+}
+
+// Track changes the in-progress release of one track. Each change is its
+// own committed edit.
+type Track interface {
+	// Release returns the in-progress release, or nil when there is none.
+	Release(ctx context.Context) (*Release, error)
+	// SetPercentage rolls the release out to percent of users; 100
+	// completes it.
+	SetPercentage(ctx context.Context, percent float64) error
+	// Halt halts the release.
+	Halt(ctx context.Context) error
+}
+
+// Health is the outcome of a health check.
+type Health struct {
+	Healthy   bool    `json:"healthy"`
+	Metric    string  `json:"metric,omitempty"`
+	Value     float64 `json:"value"`
+	Threshold float64 `json:"threshold"`
+	Users     float64 `json:"users,omitempty"`
+	Reason    string  `json:"reason,omitempty"`
+	// Inconclusive is set when there is no data to judge by.
+	Inconclusive bool `json:"inconclusive,omitempty"`
+}
+
+// HealthCheck checks the vitals of a version code.
+type HealthCheck func(ctx context.Context, versionCode int64) (Health, error)
+
+// Plan is what a run should do.
+type Plan struct {
+	// Steps are the rollout percentages in increasing order; steps at or
+	// below the live percentage are skipped.
+	Steps []float64
+	// Interval is the time to observe each percentage before checking
+	// its health and advancing, when Wait is set.
+	Interval time.Duration
+	// Wait runs every step, waiting Interval before each health check.
+	// Without it a run checks health once and advances at most one step,
+	// for callers that schedule the waiting themselves.
+	Wait bool
+	// AutoRollback restores the previous percentage on a failed check
+	// instead of halting.
+	AutoRollback bool
+	// Previous is the percentage before the last advance of an earlier
+	// run, restored by AutoRollback before this run has advanced.
+	Previous float64
+	// VersionCode, when set, must be the version code of the release.
+	VersionCode int64
+}
+
+// Transition is one recorded change or check.
+type Transition struct {
+	Time        time.Time `json:"time"`
+	Action      string    `json:"action"`
+	Step        int       `json:"step,omitempty"`
+	From        float64   `json:"fromPercentage"`
+	To          float64   `json:"toPercentage"`
+	VersionCode int64     `json:"versionCode"`
+	Health      *Health   `json:"health,omitempty"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// Result is the outcome of a run.
+type Result struct {
+	Status      string       `json:"status"`
+	VersionCode int64        `json:"versionCode"`
+	Percentage  float64      `json:"percentage"`
+	Steps       []float64    `json:"steps"`
+	Transitions []Transition `json:"transitions"`
+}
+
+// Runner runs plans against a track.
+type Runner struct {
+	Track Track
+	// Check is nil when health checks are disabled.
+	Check HealthCheck
+	// Sleep waits d or until ctx is done; it defaults to a timer.
+	Sleep func(ctx context.Context, d time.Duration) error
+	// Now defaults to time.Now.
+	Now func() time.Time
+	// OnTransition, if set, sees every transition as it is recorded.
+	OnTransition func(Transition)
+}
+
+// Run executes plan. A halt or rollback is not an error; callers inspect
+// Result.Status. Errors are API failures, with the transitions recorded
+// up to the failure.
+//
+// An inconclusive check holds the release at its percentage and ends the
+// run in progress, so a later run checks again. A release at 0% has no
+// users to report vitals, so it is not held back from its first step.
+func (r *Runner) Run(ctx context.Context, plan Plan) (*Result, error) {
+	rel, err := r.Track.Release(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if rel == nil {
+		return nil, fmt.Errorf("no in-progress release on the track")
+	}
+	if plan.VersionCode != 0 && rel.VersionCode() != plan.VersionCode {
+		return nil, fmt.Errorf("in-progress release is version code %d, not %d", rel.VersionCode(), plan.VersionCode)
+	}
+	res := &Result{Status: StatusInProgress, VersionCode: rel.VersionCode(), Percentage: rel.Percentage, Steps: plan.Steps}
+	r.record(res, Transition{Action: ActionStart, From: rel.Percentage, To: rel.Percentage})
+
+	previous := plan.Previous
+	advanced := 0
+	for i, target := range plan.Steps {
+		if target <= res.Percentage {
+			continue
+		}
+		if !plan.Wait && advanced > 0 {
+			break
+		}
+		if plan.Wait {
+			if err := r.sleep(ctx, plan.Interval); err != nil {
+				return res, err
+			}
+		}
+		if r.Check != nil {
+			health, err := r.Check(ctx, res.VersionCode)
+			if err != nil {
+				r.record(res, Transition{Action: ActionHealth, Step: i + 1, From: res.Percentage, To: res.Percentage, Error: err.Error()})
+				return res, fmt.Errorf("health check: %w", err)
+			}
+			r.record(res, Transition{Action: ActionHealth, Step: i + 1, From: res.Percentage, To: res.Percentage, Health: &health})
+			switch {
+			case health.Inconclusive && res.Percentage > 0:
+				r.record(res, Transition{Action: ActionHold, Step: i + 1, From: res.Percentage, To: res.Percentage})
+				return res, nil
+			case !health.Healthy && !health.Inconclusive:
+				return res, r.fail(ctx, res, plan, i+1, previous)
+			}
+		}
+		if err := r.Track.SetPercentage(ctx, target); err != nil {
+			r.record(res, Transition{Action: ActionAdvance, Step: i + 1, From: res.Percentage, To: target, Error: err.Error()})
+			return res, err
+		}
+		r.record(res, Transition{Action: ActionAdvance, Step: i + 1, From: res.Percentage, To: target})
+		previous, res.Percentage = res.Percentage, target
+		advanced++
+	}
+	if len(plan.Steps) == 0 || res.Percentage >= plan.Steps[len(plan.Steps)-1] {
+		res.Status = StatusCompleted
+	}
+	return res, nil
+}
+
+// fail halts the release, or restores the percentage before the last
+// advance when plan.AutoRollback is set. Without such an advance there is
+// nothing to restore, so the release is halted.
+func (r *Runner) fail(ctx context.Context, res *Result, plan Plan, step int, previous float64) error {
+	if plan.AutoRollback && previous > 0 {
+		err := r.Track.SetPercentage(ctx, previous)
+		t := Transition{Action: ActionRollback, Step: step, From: res.Percentage, To: previous}
+		if err == nil {
+			r.record(res, t)
+			res.Status, res.Percentage = StatusRolledBack, previous
+			return nil
+		}
+		// Play may refuse to lower a rollout; halting still stops it.
+		t.Error = err.Error()
+		r.record(res, t)
+	}
+	if err := r.Track.Halt(ctx); err != nil {
+		r.record(res, Transition{Action: ActionHalt, Step: step, From: res.Percentage, To: res.Percentage, Error: err.Error()})
+		return err
+	}
+	r.record(res, Transition{Action: ActionHalt, Step: step, From: res.Percentage, To: res.Percentage})
+	res.Status = StatusHalted
+	return nil
+}
+
+func (r *Runner) record(res *Result, t Transition) {
+	if r.Now != nil {
+		t.Time = r.Now().UTC()
+	} else {
+		t.Time = time.Now().UTC()
+	}
+	t.VersionCode = res.VersionCode
+	res.Transitions = append(res.Transitions, t)
+	if r.OnTransition != nil {
+		r.OnTransition(t)
+	}
+}
+
+func (r *Runner) sleep(ctx context.Context, d time.Duration) error {
+	if r.Sleep != nil {
+		return r.Sleep(ctx, d)
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,211 @@
+//go:build unit
+// +build unit
+
+package rollout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakeTrack records the calls a run makes.
+type fakeTrack struct {
+	release     *Release
+	calls       []string
+	refuseLower bool
+}
+
+func (f *fakeTrack) Release(context.Context) (*Release, error) {
+	return f.release, nil
+}
+
+func (f *fakeTrack) SetPercentage(_ context.Context, percent float64) error {
+	if f.refuseLower && percent < f.release.Percentage {
+		return errors.New("rollout percentage cannot be decreased")
+	}
+	f.calls = append(f.calls, fmt.Sprintf("set %g", percent))
+	f.release.Percentage = percent
+	return nil
+}
+
+func (f *fakeTrack) Halt(context.Context) error {
+	f.calls = append(f.calls, "halt")
+	return nil
+}
+
+// checks returns a health check answering healthy in order, then healthy.
+func checks(healthy ...bool) (HealthCheck, *[]int64) {
+	var seen []int64
+	return func(_ context.Context, vc int64) (Health, error) {
+		seen = append(seen, vc)
+		if len(seen) <= len(healthy) && !healthy[len(seen)-1] {
+			return Health{Healthy: false, Metric: "crashRate", Value: 0.03, Threshold: 0.01}, nil
+		}
+		return Health{Healthy: true, Metric: "crashRate", Value: 0.002, Threshold: 0.01}, nil
+	}, &seen
+}
+
+func newRunner(track *fakeTrack, check HealthCheck, slept *[]time.Duration) *Runner {
+	return &Runner{
+		Track: track,
+		Check: check,
+		Sleep: func(_ context.Context, d time.Duration) error {
+			*slept = append(*slept, d)
+			return nil
+		},
+	}
+}
+
+func actions(res *Result) []string {
+	out := make([]string, 0, len(res.Transitions))
+	for _, t := range res.Transitions {
+		out = append(out, t.Action)
+	}
+	return out
+}
+
+func TestRunAdvancesEveryStep(t *testing.T) {
+	track := &fakeTrack{release: &Release{VersionCodes: []int64{30, 31}, Percentage: 5}}
+	check, seen := checks()
+	var slept []time.Duration
+	res, err := newRunner(track, check, &slept).Run(context.Background(), Plan{Steps: []float64{1, 5, 20, 100}, Interval: time.Hour, Wait: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Status != StatusCompleted || res.Percentage != 100 || res.VersionCode != 31 {
+		t.Errorf("result = %+v", res)
+	}
+	if want := []string{"set 20", "set 100"}; !reflect.DeepEqual(track.calls, want) {
+		t.Errorf("calls = %v, want %v", track.calls, want)
+	}
+	if len(slept) != 2 || len(*seen) != 2 || (*seen)[0] != 31 {
+		t.Errorf("slept = %v, checked = %v", slept, *seen)
+	}
+	want := []string{ActionStart, ActionHealth, ActionAdvance, ActionHealth, ActionAdvance}
+	if got := actions(res); !reflect.DeepEqual(got, want) {
+		t.Errorf("transitions = %v, want %v", got, want)
+	}
+	if last := res.Transitions[len(res.Transitions)-1]; last.From != 20 || last.To != 100 || last.Step != 4 || last.VersionCode != 31 {
+		t.Errorf("last transition = %+v", last)
+	}
+}
+
+func TestRunHaltsWhenUnhealthy(t *testing.T) {
+	track := &fakeTrack{release: &Release{VersionCodes: []int64{31}, Percentage: 1}}
+	check, _ := checks(true, false)
+	var slept []time.Duration
+	res, err := newRunner(track, check, &slept).Run(context.Background(), Plan{Steps: []float64{10, 50, 100}, Wait: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Status != StatusHalted || res.Percentage != 10 {
+		t.Errorf("result = %+v", res)
+	}
+	if want := []string{"set 10", "halt"}; !reflect.DeepEqual(track.calls, want) {
+		t.Errorf("calls = %v, want %v", track.calls, want)
+	}
+	if h := res.Transitions[3].Health; h == nil || h.Healthy || h.Value != 0.03 {
+		t.Errorf("failed check = %+v", res.Transitions[3])
+	}
+}
+
+func TestRunRollsBack(t *testing.T) {
+	track := &fakeTrack{release: &Release{VersionCodes: []int64{31}, Percentage: 1}}
+	check, _ := checks(true, false)
+	var slept []time.Duration
+	res, err := newRunner(track, check, &slept).Run(context.Background(), Plan{Steps: []float64{10, 50}, Wait: true, AutoRollback: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Status != StatusRolledBack || res.Percentage != 1 {
+		t.Errorf("result = %+v", res)
+	}
+	if want := []string{"set 10", "set 1"}; !reflect.DeepEqual(track.calls, want) {
+		t.Errorf("calls = %v, want %v", track.calls, want)
+	}
+
+	// Without an advance in this run there is no previous percentage.
+	track = &fakeTrack{release: &Release{VersionCodes: []int64{31}, Percentage: 10}}
+	check, _ = checks(false)
+	res, _ = newRunner(track, check, &slept).Run(context.Background(), Plan{Steps: []float64{50}, Wait: true, AutoRollback: true})
+	if res.Status != StatusHalted || !reflect.DeepEqual(track.calls, []string{"halt"}) {
+		t.Errorf("result = %+v, calls = %v", res, track.calls)
+	}
+}
+
+func TestRunHaltsWhenRollbackRefused(t *testing.T) {
+	track := &fakeTrack{release: &Release{VersionCodes: []int64{31}, Percentage: 1}, refuseLower: true}
+	check, _ := checks(true, false)
+	var slept []time.Duration
+	res, err := newRunner(track, check, &slept).Run(context.Background(), Plan{Steps: []float64{10, 50}, Wait: true, AutoRollback: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Status != StatusHalted || !reflect.DeepEqual(track.calls, []string{"set 10", "halt"}) {
+		t.Errorf("result = %+v, calls = %v", res, track.calls)
+	}
+	if rb := res.Transitions[4]; rb.Action != ActionRollback || rb.Error == "" {
+		t.Errorf("rollback transition = %+v", rb)
+	}
+}
+
+func TestRunHoldsWhenInconclusive(t *testing.T) {
+	noData := func(context.Context, int64) (Health, error) {
+		return Health{Inconclusive: true, Reason: "no vitals for version code yet"}, nil
+	}
+	track := &fakeTrack{release: &Release{VersionCodes: []int64{31}, Percentage: 5}}
+	var slept []time.Duration
+	res, err := newRunner(track, noData, &slept).Run(context.Background(), Plan{Steps: []float64{5, 20, 100}, Wait: true, AutoRollback: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Status != StatusInProgress || res.Percentage != 5 || len(track.calls) != 0 {
+		t.Errorf("result = %+v, calls = %v", res, track.calls)
+	}
+	if want := []string{ActionStart, ActionHealth, ActionHold}; !reflect.DeepEqual(actions(res), want) {
+		t.Errorf("transitions = %v, want %v", actions(res), want)
+	}
+
+	// Nobody has a release at 0% yet, so it takes its first step.
+	track = &fakeTrack{release: &Release{VersionCodes: []int64{31}}}
+	res, err = newRunner(track, noData, &slept).Run(context.Background(), Plan{Steps: []float64{1, 100}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Percentage != 1 || !reflect.DeepEqual(track.calls, []string{"set 1"}) {
+		t.Errorf("result = %+v, calls = %v", res, track.calls)
+	}
+}
+
+func TestRunWithoutWaitAdvancesOneStep(t *testing.T) {
+	track := &fakeTrack{release: &Release{VersionCodes: []int64{31}, Percentage: 10}}
+	check, seen := checks()
+	var slept []time.Duration
+	res, err := newRunner(track, check, &slept).Run(context.Background(), Plan{Steps: []float64{10, 20, 30}, Interval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Status != StatusInProgress || res.Percentage != 20 || len(slept) != 0 || len(*seen) != 1 {
+		t.Errorf("result = %+v, slept = %v", res, slept)
+	}
+}
+
+func TestRunWithoutHealthChecks(t *testing.T) {
+	track := &fakeTrack{release: &Release{VersionCodes: []int64{31}, Percentage: 1}}
+	var slept []time.Duration
+	res, err := newRunner(track, nil, &slept).Run(context.Background(), Plan{Steps: []float64{50, 100}, Wait: true, Interval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Status != StatusCompleted || len(slept) != 2 {
+		t.Errorf("result = %+v, slept = %v", res, slept)
+	}
+
+	if _, err := newRunner(&fakeTrack{}, nil, &slept).Run(context.Background(), Plan{}); err == nil {
+		t.Error("expected an error without an in-progress release")
+	}
+}
@@ -0,0 +1,159 @@
+package rollout
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+var idPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// State is a rollout spread over many runs, each advancing at most one
+// step once the previous one has been observed for the plan's interval.
+type State struct {
+	ID              string    `json:"id"`
+	Package         string    `json:"package"`
+	Track           string    `json:"track"`
+	VersionCode     int64     `json:"versionCode,omitempty"`
+	Steps           []float64 `json:"steps"`
+	Interval        Duration  `json:"interval"`
+	HealthThreshold float64   `json:"healthThreshold"`
+	AutoRollback    bool      `json:"autoRollback,omitempty"`
+	// AllowMissingVitals treats a version code without vitals as healthy.
+	AllowMissingVitals bool   `json:"allowMissingVitals,omitempty"`
+	Status             string `json:"status"`
+	// Stage is the number of steps taken; Percentage is the live one.
+	Stage      int     `json:"stage"`
+	Percentage float64 `json:"percentage"`
+	// Previous is the percentage before the last advance.
+	Previous       float64      `json:"previousPercentage,omitempty"`
+	LastHealth     *Health      `json:"lastHealth,omitempty"`
+	NextEligibleAt time.Time    `json:"nextEligibleAt,omitempty"`
+	CreatedAt      time.Time    `json:"createdAt"`
+	UpdatedAt      time.Time    `json:"updatedAt"`
+	Transitions    []Transition `json:"transitions"`
+}
+
+// Duration is a time.Duration that reads and writes as a string like
+// "30m".
+type Duration time.Duration
+
+// MarshalJSON writes d as a duration string.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON reads a duration string.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// ValidateID reports whether id can name a state file.
+func ValidateID(id string) error {
+	if !idPattern.MatchString(id) {
+		return fmt.Errorf("invalid rollout id %q: use letters, digits, '.', '_' and '-'", id)
+	}
+	return nil
+}
+
+// Done reports whether the rollout has finished, successfully or not.
+func (s *State) Done() bool {
+	return s.Status == StatusCompleted || s.Status == StatusHalted || s.Status == StatusRolledBack
+}
+
+// Eligible reports whether a run at now may take the next step.
+func (s *State) Eligible(now time.Time) bool {
+	return !s.Done() && !now.Before(s.NextEligibleAt)
+}
+
+// Plan is the plan of the next run: one step, no waiting.
+func (s *State) Plan() Plan {
+	return Plan{
+		Steps:        s.Steps,
+		AutoRollback: s.AutoRollback,
+		Previous:     s.Previous,
+		VersionCode:  s.VersionCode,
+	}
+}
+
+// Apply records the result of a run at now. A run that advanced makes the
+// next step eligible an interval later.
+func (s *State) Apply(res *Result, now time.Time) {
+	s.VersionCode = res.VersionCode
+	s.Status = res.Status
+	s.Transitions = append(s.Transitions, res.Transitions...)
+	advanced := false
+	for _, t := range res.Transitions {
+		switch {
+		case t.Health != nil:
+			h := *t.Health
+			s.LastHealth = &h
+		case t.Error != "":
+		case t.Action == ActionAdvance:
+			s.Previous = t.From
+			advanced = true
+		case t.Action == ActionRollback:
+			s.Previous = 0
+		}
+	}
+	s.Percentage = res.Percentage
+	s.Stage = 0
+	for _, step := range s.Steps {
+		if step <= s.Percentage {
+			s.Stage++
+		}
+	}
+	if advanced && !s.Done() {
+		s.NextEligibleAt = now.Add(time.Duration(s.Interval))
+	}
+	s.UpdatedAt = now
+}
+
+// LoadState reads the state at path. It returns nil without an error when
+// the file does not exist.
+func LoadState(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rollout state: %w", err)
+	}
+	var s State
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse rollout state %s: %w", path, err)
+	}
+	return &s, nil
+}
+
+// Save writes the state to path atomically.
+func (s *State) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("failed to create rollout state directory: %w", err)
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal rollout state: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0640); err != nil {
+		return fmt.Errorf("failed to write rollout state: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to save rollout state: %w", err)
+	}
+	return nil
+}
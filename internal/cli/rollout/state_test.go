@@ -0,0 +1,124 @@
+//go:build unit
+// +build unit
+
+package rollout
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStateAdvancesOneStepPerRun(t *testing.T) {
+	now := time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC)
+	st := &State{ID: "r31", Steps: []float64{1, 10, 50, 100}, Interval: Duration(time.Hour), Status: StatusInProgress, AutoRollback: true}
+	track := &fakeTrack{release: &Release{VersionCodes: []int64{31}}}
+	check, _ := checks(true, true, false)
+	var slept []time.Duration
+	runner := newRunner(track, check, &slept)
+
+	for run := 1; run <= 2; run++ {
+		if !st.Eligible(now) {
+			t.Fatalf("run %d: not eligible at %v (next %v)", run, now, st.NextEligibleAt)
+		}
+		res, err := runner.Run(context.Background(), st.Plan())
+		if err != nil {
+			t.Fatal(err)
+		}
+		st.Apply(res, now)
+		if st.Stage != run || !st.NextEligibleAt.Equal(now.Add(time.Hour)) || st.LastHealth == nil || !st.LastHealth.Healthy {
+			t.Fatalf("run %d: state = %+v", run, st)
+		}
+		if st.Eligible(now.Add(59 * time.Minute)) {
+			t.Errorf("run %d: eligible before the interval", run)
+		}
+		now = now.Add(time.Hour)
+	}
+	if st.Percentage != 10 || st.Previous != 1 || st.VersionCode != 31 {
+		t.Errorf("state = %+v", st)
+	}
+
+	// The third check fails; the fraction before the last run's advance
+	// is restored.
+	res, err := runner.Run(context.Background(), st.Plan())
+	if err != nil {
+		t.Fatal(err)
+	}
+	st.Apply(res, now)
+	if st.Status != StatusRolledBack || st.Percentage != 1 || st.Stage != 1 || !st.Done() || st.Eligible(now.Add(24*time.Hour)) {
+		t.Errorf("state = %+v", st)
+	}
+	if want := []string{"set 1", "set 10", "set 1"}; !reflect.DeepEqual(track.calls, want) {
+		t.Errorf("calls = %v, want %v", track.calls, want)
+	}
+	if st.LastHealth == nil || st.LastHealth.Healthy || len(st.Transitions) != 9 || len(slept) != 0 {
+		t.Errorf("health = %+v, transitions = %d", st.LastHealth, len(st.Transitions))
+	}
+}
+
+func TestStateRetriesAfterHold(t *testing.T) {
+	now := time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC)
+	st := &State{ID: "r31", Steps: []float64{1, 10}, Interval: Duration(time.Hour), Status: StatusInProgress, Percentage: 1, Stage: 1, NextEligibleAt: now}
+	track := &fakeTrack{release: &Release{VersionCodes: []int64{31}, Percentage: 1}}
+	noData := func(context.Context, int64) (Health, error) {
+		return Health{Inconclusive: true}, nil
+	}
+	var slept []time.Duration
+	res, err := newRunner(track, noData, &slept).Run(context.Background(), st.Plan())
+	if err != nil {
+		t.Fatal(err)
+	}
+	st.Apply(res, now)
+	if st.Status != StatusInProgress || st.Percentage != 1 || st.Stage != 1 || !st.Eligible(now) {
+		t.Errorf("state = %+v", st)
+	}
+	if st.LastHealth == nil || !st.LastHealth.Inconclusive {
+		t.Errorf("last health = %+v", st.LastHealth)
+	}
+}
+
+func TestStateRejectsAnotherVersion(t *testing.T) {
+	st := &State{Steps: []float64{50}, VersionCode: 30, Status: StatusInProgress}
+	track := &fakeTrack{release: &Release{VersionCodes: []int64{31}, Percentage: 10}}
+	var slept []time.Duration
+	if _, err := newRunner(track, nil, &slept).Run(context.Background(), st.Plan()); err == nil || len(track.calls) != 0 {
+		t.Errorf("err = %v, calls = %v", err, track.calls)
+	}
+}
+
+func TestStateSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rollouts", "r31.json")
+	if st, err := LoadState(path); st != nil || err != nil {
+		t.Fatalf("missing state = %+v, %v", st, err)
+	}
+	want := &State{
+		ID: "r31", Package: "com.example.app", Track: "production", Steps: []float64{1, 100},
+		Interval: Duration(30 * time.Minute), Status: StatusInProgress,
+		NextEligibleAt: time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC),
+	}
+	if err := want.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Interval != want.Interval || !got.NextEligibleAt.Equal(want.NextEligibleAt) || got.Track != "production" {
+		t.Errorf("loaded = %+v", got)
+	}
+}
+
+func TestValidateID(t *testing.T) {
+	for _, id := range []string{"r31", "release-2026.10_1"} {
+		if err := ValidateID(id); err != nil {
+			t.Errorf("ValidateID(%q) = %v", id, err)
+		}
+	}
+	for _, id := range []string{"", "../x", "a/b", ".hidden"} {
+		if err := ValidateID(id); err == nil {
+			t.Errorf("ValidateID(%q): expected an error", id)
+		}
+	}
+}
@@ -0,0 +1,368 @@
+// Package sizereport holds pure helpers for gpd inspect size: it breaks an
+// APK or AAB down by module, category, ABI and screen density, estimates the
+// download for common device specs, and compares two reports against a
+// growth budget the way benchcheck compares benchmark runs. Kong adapters
+// live in package cli.
+package sizereport
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/artifact"
+)
+
+// DefaultMaxIncreasePercent is the growth budget when --baseline is given
+// without --max-increase; it matches benchcheck's detection threshold.
+const DefaultMaxIncreasePercent = 10
+
+// Bucket sums the entries sharing a category, ABI or density.
+type Bucket struct {
+	Name         string `json:"name"`
+	Files        int    `json:"files"`
+	Compressed   int64  `json:"compressedBytes"`
+	Uncompressed int64  `json:"uncompressedBytes"`
+}
+
+// DeviceSpec describes a device class by the ABIs it runs, most preferred
+// first, and its screen density bucket.
+type DeviceSpec struct {
+	Name    string   `json:"name"`
+	ABIs    []string `json:"abis"`
+	Density string   `json:"density"`
+}
+
+// DefaultDevices are the device classes size estimates are reported for.
+var DefaultDevices = []DeviceSpec{
+	{Name: "phone-arm64-xxhdpi", ABIs: []string{"arm64-v8a", "armeabi-v7a", "armeabi"}, Density: "xxhdpi"},
+	{Name: "phone-arm64-xhdpi", ABIs: []string{"arm64-v8a", "armeabi-v7a", "armeabi"}, Density: "xhdpi"},
+	{Name: "go-armv7-hdpi", ABIs: []string{"armeabi-v7a", "armeabi"}, Density: "hdpi"},
+	{Name: "chromebook-x86_64-xhdpi", ABIs: []string{"x86_64", "x86"}, Density: "xhdpi"},
+}
+
+// DeviceEstimate is the estimated install-time download for one device
+// spec. ABI and Density are the splits it would receive.
+type DeviceEstimate struct {
+	Device        string `json:"device"`
+	ABI           string `json:"abi,omitempty"`
+	Density       string `json:"density,omitempty"`
+	DownloadBytes int64  `json:"downloadBytes"`
+}
+
+// Report is the size breakdown of one artifact. Byte counts are zip entry
+// sizes; Play recompresses on delivery, so downloads are estimates.
+type Report struct {
+	Format            artifact.Kind         `json:"format"`
+	CompressedBytes   int64                 `json:"compressedBytes"`
+	UncompressedBytes int64                 `json:"uncompressedBytes"`
+	Modules           []artifact.ModuleSize `json:"modules"`
+	Categories        []Bucket              `json:"categories"`
+	ABIs              []Bucket              `json:"abis,omitempty"`
+	Densities         []Bucket              `json:"densities,omitempty"`
+	Devices           []DeviceEstimate      `json:"devices"`
+}
+
+// Build computes the size report of an opened artifact.
+func Build(a *artifact.Artifact) (*Report, error) {
+	modules, err := a.ModuleSizes()
+	if err != nil {
+		return nil, err
+	}
+	entries := a.EntrySizes()
+	r := &Report{Format: a.Kind, Modules: modules}
+	categories := make(map[string]*Bucket)
+	abis := make(map[string]*Bucket)
+	densities := make(map[string]*Bucket)
+	for _, e := range entries {
+		r.CompressedBytes += e.Compressed
+		r.UncompressedBytes += e.Uncompressed
+		addTo(categories, e.Category, e)
+		if e.ABI != "" {
+			addTo(abis, e.ABI, e)
+		}
+		if e.Density != "" {
+			addTo(densities, e.Density, e)
+		}
+	}
+	r.Categories = sortedBuckets(categories)
+	r.ABIs = sortedBuckets(abis)
+	r.Densities = sortedBuckets(densities)
+
+	installTime := make(map[string]bool)
+	for _, m := range modules {
+		if m.Delivery == artifact.DeliveryInstallTime && m.Type != "asset-pack" {
+			installTime[m.Module] = true
+		}
+	}
+	for _, spec := range DefaultDevices {
+		r.Devices = append(r.Devices, estimate(a.Kind, entries, installTime, spec, abis, densities))
+	}
+	return r, nil
+}
+
+func addTo(buckets map[string]*Bucket, name string, e artifact.EntrySize) {
+	b := buckets[name]
+	if b == nil {
+		b = &Bucket{Name: name}
+		buckets[name] = b
+	}
+	b.Files++
+	b.Compressed += e.Compressed
+	b.Uncompressed += e.Uncompressed
+}
+
+// sortedBuckets orders buckets largest first, by name on ties.
+func sortedBuckets(buckets map[string]*Bucket) []Bucket {
+	out := make([]Bucket, 0, len(buckets))
+	for _, b := range buckets {
+		out = append(out, *b)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Compressed != out[j].Compressed {
+			return out[i].Compressed > out[j].Compressed
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// estimate sums what spec downloads at install time. An APK is delivered
+// whole. A bundle is split: install-time modules only, native libraries for
+// the device's preferred ABI present in the bundle, and resources for its
+// density, or the nearest density the bundle has.
+func estimate(kind artifact.Kind, entries []artifact.EntrySize, installTime map[string]bool, spec DeviceSpec, abis, densities map[string]*Bucket) DeviceEstimate {
+	est := DeviceEstimate{Device: spec.Name}
+	for _, abi := range spec.ABIs {
+		if abis[abi] != nil {
+			est.ABI = abi
+			break
+		}
+	}
+	est.Density = nearestDensity(spec.Density, densities)
+
+	for _, e := range entries {
+		if kind == artifact.KindAPK {
+			est.DownloadBytes += e.Compressed
+			continue
+		}
+		if !installTime[e.Module] {
+			continue
+		}
+		if e.ABI != "" && e.ABI != est.ABI {
+			continue
+		}
+		if e.Density != "" && dpi(e.Density) > 0 && e.Density != est.Density {
+			continue
+		}
+		est.DownloadBytes += e.Compressed
+	}
+	if kind == artifact.KindAPK {
+		est.ABI, est.Density = "", ""
+	}
+	return est
+}
+
+// nearestDensity picks want when present, otherwise the smallest higher
+// density the bundle has (Play scales down rather than up), falling back to
+// the largest lower one.
+func nearestDensity(want string, densities map[string]*Bucket) string {
+	if densities[want] != nil {
+		return want
+	}
+	target := dpi(want)
+	above, below := "", ""
+	for name := range densities {
+		d := dpi(name)
+		switch {
+		case d == 0:
+		case d > target && (above == "" || d < dpi(above)):
+			above = name
+		case d < target && (below == "" || d > dpi(below)):
+			below = name
+		}
+	}
+	if above != "" {
+		return above
+	}
+	return below
+}
+
+// dpi returns the dots per inch of a density qualifier; 0 for nodpi and
+// anydpi, which every device receives.
+func dpi(density string) int {
+	switch density {
+	case "ldpi":
+		return 120
+	case "mdpi":
+		return 160
+	case "tvdpi":
+		return 213
+	case "hdpi":
+		return 240
+	case "xhdpi":
+		return 320
+	case "xxhdpi":
+		return 480
+	case "xxxhdpi":
+		return 640
+	}
+	if n, ok := strings.CutSuffix(density, "dpi"); ok {
+		v, _ := strconv.Atoi(n)
+		return v
+	}
+	return 0
+}
+
+// Budget bounds growth against a baseline. Either limit may be zero
+// (unset); growth exceeding any set limit fails.
+type Budget struct {
+	MaxBytes   int64   `json:"maxIncreaseBytes,omitempty"`
+	MaxPercent float64 `json:"maxIncreasePercent,omitempty"`
+}
+
+// ParseBudget parses --max-increase: a percentage ("5%") or a size in
+// bytes with an optional KB, MB or GB suffix (decimal units, as Play
+// reports sizes).
+func ParseBudget(s string) (Budget, error) {
+	s = strings.TrimSpace(s)
+	if pct, ok := strings.CutSuffix(s, "%"); ok {
+		v, err := strconv.ParseFloat(strings.TrimSpace(pct), 64)
+		if err != nil || v < 0 {
+			return Budget{}, fmt.Errorf("invalid percentage %q", s)
+		}
+		return Budget{MaxPercent: v}, nil
+	}
+	upper := strings.ToUpper(s)
+	multiplier := 1.0
+	for _, unit := range []struct {
+		suffix string
+		factor float64
+	}{{"GB", 1e9}, {"MB", 1e6}, {"KB", 1e3}, {"B", 1}} {
+		if trimmed, ok := strings.CutSuffix(upper, unit.suffix); ok {
+			upper, multiplier = strings.TrimSpace(trimmed), unit.factor
+			break
+		}
+	}
+	v, err := strconv.ParseFloat(upper, 64)
+	if err != nil || v < 0 {
+		return Budget{}, fmt.Errorf("invalid size %q: want e.g. 5%%, 500KB or 2MB", s)
+	}
+	return Budget{MaxBytes: int64(v * multiplier)}, nil
+}
+
+// Delta is the change of one metric between baseline and current.
+type Delta struct {
+	Metric    string  `json:"metric"`
+	Old       int64   `json:"oldBytes"`
+	New       int64   `json:"newBytes"`
+	Delta     int64   `json:"deltaBytes"`
+	ChangePct float64 `json:"changePct"`
+	// NewABI is the native ABI a device receives that the baseline did not
+	// ship, so its growth can be told apart from regular growth. The budget
+	// still applies.
+	NewABI   string `json:"newAbi,omitempty"`
+	Exceeded bool   `json:"exceeded,omitempty"`
+}
+
+// Comparison is the result of comparing a report with its baseline. The
+// budget applies to Downloads (total and per device); module and category
+// deltas explain where the growth came from.
+type Comparison struct {
+	Budget     Budget  `json:"budget"`
+	Downloads  []Delta `json:"downloads"`
+	Modules    []Delta `json:"modules"`
+	Categories []Delta `json:"categories"`
+	Exceeded   bool    `json:"exceeded"`
+}
+
+// Compare diffs current against baseline and flags download growth over
+// budget.
+func Compare(baseline, current *Report, budget Budget) *Comparison {
+	c := &Comparison{Budget: budget}
+	total := newDelta("total", baseline.CompressedBytes, current.CompressedBytes)
+	c.Downloads = append(c.Downloads, total)
+	old := make(map[string]DeviceEstimate, len(baseline.Devices))
+	for _, d := range baseline.Devices {
+		old[d.Device] = d
+	}
+	for _, d := range current.Devices {
+		delta := newDelta("device:"+d.Device, old[d.Device].DownloadBytes, d.DownloadBytes)
+		if d.ABI != "" && d.ABI != old[d.Device].ABI {
+			delta.NewABI = d.ABI
+		}
+		c.Downloads = append(c.Downloads, delta)
+	}
+	for i := range c.Downloads {
+		c.Downloads[i].Exceeded = budget.exceeded(c.Downloads[i])
+		c.Exceeded = c.Exceeded || c.Downloads[i].Exceeded
+	}
+
+	oldModules, newModules := make(map[string]int64), make(map[string]int64)
+	for _, m := range baseline.Modules {
+		oldModules[m.Module] = m.Compressed
+	}
+	for _, m := range current.Modules {
+		newModules[m.Module] = m.Compressed
+	}
+	c.Modules = diffMaps(oldModules, newModules)
+
+	oldCategories, newCategories := make(map[string]int64), make(map[string]int64)
+	for _, b := range baseline.Categories {
+		oldCategories[b.Name] = b.Compressed
+	}
+	for _, b := range current.Categories {
+		newCategories[b.Name] = b.Compressed
+	}
+	c.Categories = diffMaps(oldCategories, newCategories)
+	return c
+}
+
+func (b Budget) exceeded(d Delta) bool {
+	if d.Delta <= 0 {
+		return false
+	}
+	if b.MaxBytes > 0 && d.Delta > b.MaxBytes {
+		return true
+	}
+	return b.MaxPercent > 0 && d.ChangePct > b.MaxPercent
+}
+
+func newDelta(metric string, old, current int64) Delta {
+	d := Delta{Metric: metric, Old: old, New: current, Delta: current - old}
+	switch {
+	case old > 0:
+		d.ChangePct = math.Round(float64(d.Delta)/float64(old)*10000) / 100
+	case current > 0:
+		d.ChangePct = 100
+	}
+	return d
+}
+
+// diffMaps returns one delta per key in either map, largest growth first.
+func diffMaps(old, current map[string]int64) []Delta {
+	keys := make(map[string]bool, len(old)+len(current))
+	for k := range old {
+		keys[k] = true
+	}
+	for k := range current {
+		keys[k] = true
+	}
+	out := make([]Delta, 0, len(keys))
+	for k := range keys {
+		name := k
+		if name == "" {
+			name = "apk"
+		}
+		out = append(out, newDelta(name, old[k], current[k]))
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Delta != out[j].Delta {
+			return out[i].Delta > out[j].Delta
+		}
+		return out[i].Metric < out[j].Metric
+	})
+	return out
+}
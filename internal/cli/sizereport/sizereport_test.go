@@ -0,0 +1,169 @@
+//go:build unit
+// +build unit
+
+package sizereport
+
+import (
+	"testing"
+
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/artifact"
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/artifact/artifacttest"
+)
+
+func build(t *testing.T, path string) *Report {
+	t.Helper()
+	a, err := artifact.Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer func() { _ = a.Close() }()
+	r, err := Build(a)
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	return r
+}
+
+func stored(name string, size int) artifacttest.Entry {
+	return artifacttest.Entry{Name: name, Data: make([]byte, size), Stored: true}
+}
+
+func device(r *Report, name string) DeviceEstimate {
+	for _, d := range r.Devices {
+		if d.Device == name {
+			return d
+		}
+	}
+	return DeviceEstimate{}
+}
+
+func TestBuild_BundleSplits(t *testing.T) {
+	onDemand := artifacttest.Entry{
+		Name: "camera/manifest/AndroidManifest.xml",
+		Data: artifacttest.EncodeProtoXML(artifacttest.E("manifest", []string{"package", "com.example.app", "split", "camera"},
+			artifacttest.E("module", nil, artifacttest.E("delivery", nil, artifacttest.E("on-demand", nil))))),
+	}
+	r := build(t, artifacttest.WriteAAB(t, artifacttest.Manifest("com.example.app", 1),
+		stored("base/lib/arm64-v8a/libapp.so", 4000),
+		stored("base/lib/armeabi-v7a/libapp.so", 3000),
+		stored("base/res/drawable-xxhdpi/icon.png", 900),
+		stored("base/res/drawable-hdpi/icon.png", 400),
+		stored("base/res/drawable-nodpi/bg.png", 50),
+		onDemand,
+		stored("camera/dex/classes.dex", 7000),
+	))
+
+	if len(r.ABIs) != 2 || r.ABIs[0].Name != "arm64-v8a" || len(r.Densities) != 3 {
+		t.Errorf("abis = %+v densities = %+v", r.ABIs, r.Densities)
+	}
+	if r.Categories[0].Name != artifact.CategoryDex {
+		t.Errorf("largest category = %+v", r.Categories[0])
+	}
+
+	phone := device(r, "phone-arm64-xxhdpi")
+	goPhone := device(r, "go-armv7-hdpi")
+	xhdpi := device(r, "phone-arm64-xhdpi")
+	if phone.ABI != "arm64-v8a" || phone.Density != "xxhdpi" || goPhone.ABI != "armeabi-v7a" || goPhone.Density != "hdpi" {
+		t.Errorf("splits: phone=%+v go=%+v", phone, goPhone)
+	}
+	// No xhdpi resources: the next higher density is delivered.
+	if xhdpi.Density != "xxhdpi" {
+		t.Errorf("xhdpi device got %q", xhdpi.Density)
+	}
+	// The on-demand camera module and the other ABI and density are excluded.
+	if d := phone.DownloadBytes - goPhone.DownloadBytes; d != (4000-3000)+(900-400) {
+		t.Errorf("phone - go = %d", d)
+	}
+	if phone.DownloadBytes >= r.CompressedBytes-7000 {
+		t.Errorf("phone download %d should exclude unused splits of %d", phone.DownloadBytes, r.CompressedBytes)
+	}
+	if chromebook := device(r, "chromebook-x86_64-xhdpi"); chromebook.ABI != "" {
+		t.Errorf("chromebook ABI = %q, bundle has no x86 libraries", chromebook.ABI)
+	}
+}
+
+func TestBuild_APKIsDeliveredWhole(t *testing.T) {
+	r := build(t, artifacttest.WriteAPK(t, artifacttest.Manifest("com.example.app", 1),
+		stored("lib/arm64-v8a/libapp.so", 4000),
+		stored("lib/x86_64/libapp.so", 4000),
+	))
+	for _, d := range r.Devices {
+		if d.DownloadBytes != r.CompressedBytes || d.ABI != "" {
+			t.Errorf("%s = %+v, want the whole %d bytes", d.Device, d, r.CompressedBytes)
+		}
+	}
+}
+
+func TestParseBudget(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Budget
+	}{
+		{"5%", Budget{MaxPercent: 5}},
+		{"2.5 %", Budget{MaxPercent: 2.5}},
+		{"500KB", Budget{MaxBytes: 500_000}},
+		{"1.5mb", Budget{MaxBytes: 1_500_000}},
+		{"2048", Budget{MaxBytes: 2048}},
+	}
+	for _, tc := range tests {
+		got, err := ParseBudget(tc.in)
+		if err != nil || got != tc.want {
+			t.Errorf("ParseBudget(%q) = %+v, %v; want %+v", tc.in, got, err, tc.want)
+		}
+	}
+	for _, bad := range []string{"", "lots", "-5%", "5TB"} {
+		if _, err := ParseBudget(bad); err == nil {
+			t.Errorf("ParseBudget(%q) accepted", bad)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	manifest := artifacttest.Manifest("com.example.app", 1)
+	old := build(t, artifacttest.WriteAAB(t, manifest, stored("base/lib/arm64-v8a/libapp.so", 100_000)))
+	grown := build(t, artifacttest.WriteAAB(t, manifest,
+		stored("base/lib/arm64-v8a/libapp.so", 100_000),
+		stored("base/lib/x86_64/libapp.so", 100_000),
+		stored("base/assets/model.bin", 5_000),
+	))
+
+	c := Compare(old, grown, Budget{MaxPercent: 10})
+	if !c.Exceeded {
+		t.Fatalf("comparison = %+v", c)
+	}
+	for _, d := range c.Downloads {
+		switch d.Metric {
+		case "total":
+			if !d.Exceeded {
+				t.Errorf("%s should exceed: %+v", d.Metric, d)
+			}
+		case "device:chromebook-x86_64-xhdpi":
+			// The new x86_64 libraries push the device over budget; the
+			// ABI change is flagged but not exempt.
+			if !d.Exceeded || d.NewABI != "x86_64" {
+				t.Errorf("%s = %+v", d.Metric, d)
+			}
+		case "device:phone-arm64-xxhdpi":
+			// Only the 5 KB asset reaches arm64 phones: 5% growth.
+			if d.Exceeded || d.Delta != 5_000 {
+				t.Errorf("%s = %+v", d.Metric, d)
+			}
+		}
+	}
+	if c.Categories[0].Metric != artifact.CategoryNative || c.Categories[0].Delta != 100_000 {
+		t.Errorf("categories = %+v", c.Categories)
+	}
+
+	if c := Compare(old, grown, Budget{MaxBytes: 1_000_000}); c.Exceeded {
+		t.Errorf("1 MB budget exceeded: %+v", c.Downloads)
+	}
+	c = Compare(old, grown, Budget{MaxBytes: 50_000})
+	for _, d := range c.Downloads {
+		if d.Metric == "device:chromebook-x86_64-xhdpi" && !d.Exceeded {
+			t.Errorf("new ABI within a 50 KB budget: %+v", d)
+		}
+	}
+	if c := Compare(grown, old, Budget{MaxPercent: 1}); c.Exceeded {
+		t.Error("shrinking exceeded the budget")
+	}
+}
@@ -0,0 +1,216 @@
+// Package slo evaluates user-weighted service level objectives, such as
+// 99.5% crash-free users over 28 days, on daily vitals. It computes the
+// error budget left in the window and multi-window burn-rate alerts: an
+// alert fires when the budget burns too fast over both a long window,
+// which proves the burn is significant, and a short one, which proves it
+// is still happening. Kong adapters live in package cli.
+package slo
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// DateLayout is the layout of Day.Date.
+const DateLayout = "2006-01-02"
+
+// DefaultWindowDays is the compliance window of an objective without one.
+const DefaultWindowDays = 28
+
+// States of an objective, from healthy to worst.
+const (
+	StateOK        = "ok"
+	StateNoData    = "no-data"
+	StateSlowBurn  = "slow-burn"
+	StateFastBurn  = "fast-burn"
+	StateExhausted = "exhausted"
+)
+
+// Severities of a state.
+const (
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// Burn is a multi-window burn-rate alert.
+type Burn struct {
+	LongDays  int     `json:"longWindowDays"`
+	ShortDays int     `json:"shortWindowDays"`
+	Rate      float64 `json:"burnRate"`
+}
+
+// The default alerts, scaled from the hourly SRE windows to daily data.
+// A fast burn spends about 30% of a 28-day budget in two days; a slow
+// burn is on course to exhaust it before the window ends.
+var (
+	DefaultFastBurn = Burn{LongDays: 2, ShortDays: 1, Rate: 4}
+	DefaultSlowBurn = Burn{LongDays: 7, ShortDays: 2, Rate: 1}
+)
+
+// Objective is an SLO on the share of users without a bad event.
+type Objective struct {
+	Name   string
+	Metric string
+	// Target is the percentage of good users, e.g. 99.5.
+	Target     float64
+	WindowDays int
+	Fast, Slow Burn
+}
+
+// Validate checks the objective and fills in defaults.
+func (o *Objective) Validate() error {
+	if o.Target <= 0 || o.Target >= 100 {
+		return fmt.Errorf("objective %q: target %g%% must be between 0 and 100", o.Name, o.Target)
+	}
+	if o.WindowDays == 0 {
+		o.WindowDays = DefaultWindowDays
+	}
+	if o.WindowDays < 1 {
+		return fmt.Errorf("objective %q: window must be at least one day", o.Name)
+	}
+	if o.Fast == (Burn{}) {
+		o.Fast = DefaultFastBurn
+	}
+	if o.Slow == (Burn{}) {
+		o.Slow = DefaultSlowBurn
+	}
+	for _, b := range []Burn{o.Fast, o.Slow} {
+		if b.ShortDays < 1 || b.LongDays < b.ShortDays || b.LongDays > o.WindowDays || b.Rate <= 0 {
+			return fmt.Errorf("objective %q: burn alert needs 1 <= short window <= long window <= %d days and a positive burn rate", o.Name, o.WindowDays)
+		}
+	}
+	return nil
+}
+
+// Budget is the fraction of users allowed a bad event.
+func (o Objective) Budget() float64 {
+	return 1 - o.Target/100
+}
+
+// Day is one day of a rate metric and the users it was measured over.
+type Day struct {
+	Date  string
+	Rate  float64
+	Users float64
+}
+
+// Window is the state of one burn-rate alert.
+type Window struct {
+	Name      string  `json:"name"`
+	LongDays  int     `json:"longWindowDays"`
+	ShortDays int     `json:"shortWindowDays"`
+	Threshold float64 `json:"threshold"`
+	LongBurn  float64 `json:"longBurnRate"`
+	ShortBurn float64 `json:"shortBurnRate"`
+	Firing    bool    `json:"firing"`
+}
+
+// Status is the evaluation of an objective. Users sums the daily user
+// counts, so a user active on several days counts on each of them.
+type Status struct {
+	Name            string   `json:"name"`
+	Metric          string   `json:"metric"`
+	Objective       float64  `json:"objectivePercent"`
+	WindowDays      int      `json:"windowDays"`
+	From            string   `json:"from,omitempty"`
+	To              string   `json:"to,omitempty"`
+	DaysWithData    int      `json:"daysWithData"`
+	Users           float64  `json:"users"`
+	BadUsers        float64  `json:"badUsers"`
+	Attainment      float64  `json:"attainmentPercent"`
+	ErrorBudget     float64  `json:"errorBudget"`
+	BudgetConsumed  float64  `json:"budgetConsumed"`
+	BudgetRemaining float64  `json:"budgetRemaining"`
+	BurnRate        float64  `json:"burnRate"`
+	Windows         []Window `json:"windows"`
+	State           string   `json:"state"`
+	Severity        string   `json:"severity,omitempty"`
+}
+
+// Breached reports whether the objective needs attention.
+func (s Status) Breached() bool {
+	return s.Severity != ""
+}
+
+// Evaluate computes the status of a validated objective. Windows end on
+// the latest day with data, since vitals lag by a day or two.
+func Evaluate(o Objective, days []Day) Status {
+	st := Status{
+		Name: o.Name, Metric: o.Metric, Objective: o.Target, WindowDays: o.WindowDays,
+		ErrorBudget: o.Budget(), State: StateNoData,
+	}
+	sorted := append([]Day(nil), days...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Date < sorted[j].Date })
+	if len(sorted) == 0 {
+		return st
+	}
+	last, err := time.Parse(DateLayout, sorted[len(sorted)-1].Date)
+	if err != nil {
+		return st
+	}
+	within := func(n int) []Day {
+		from := last.AddDate(0, 0, -n+1).Format(DateLayout)
+		i := sort.Search(len(sorted), func(i int) bool { return sorted[i].Date >= from })
+		return sorted[i:]
+	}
+
+	window := within(o.WindowDays)
+	if len(window) == 0 {
+		return st
+	}
+	st.From, st.To = window[0].Date, window[len(window)-1].Date
+	st.DaysWithData = len(window)
+	bad := badFraction(window)
+	for _, d := range window {
+		st.Users += d.Users
+		st.BadUsers += d.Rate * d.Users
+	}
+	st.Attainment = 100 * (1 - bad)
+	st.BurnRate = bad / st.ErrorBudget
+	// The budget covers the whole window, so the window's burn rate is
+	// also the share of the budget spent.
+	st.BudgetConsumed = st.BurnRate
+	st.BudgetRemaining = 1 - st.BudgetConsumed
+
+	burn := func(name string, b Burn) Window {
+		w := Window{Name: name, LongDays: b.LongDays, ShortDays: b.ShortDays, Threshold: b.Rate}
+		long, short := within(b.LongDays), within(b.ShortDays)
+		if len(long) == 0 || len(short) == 0 {
+			return w
+		}
+		w.LongBurn = badFraction(long) / st.ErrorBudget
+		w.ShortBurn = badFraction(short) / st.ErrorBudget
+		w.Firing = w.LongBurn >= b.Rate && w.ShortBurn >= b.Rate
+		return w
+	}
+	fast, slow := burn(StateFastBurn, o.Fast), burn(StateSlowBurn, o.Slow)
+	st.Windows = []Window{fast, slow}
+
+	switch {
+	case st.BudgetRemaining <= 0:
+		st.State, st.Severity = StateExhausted, SeverityCritical
+	case fast.Firing:
+		st.State, st.Severity = StateFastBurn, SeverityCritical
+	case slow.Firing:
+		st.State, st.Severity = StateSlowBurn, SeverityWarning
+	default:
+		st.State = StateOK
+	}
+	return st
+}
+
+// badFraction is the user-weighted share of bad users over days; days
+// without a user count fall back to the plain mean rate.
+func badFraction(days []Day) float64 {
+	var bad, users, sum float64
+	for _, d := range days {
+		bad += d.Rate * d.Users
+		users += d.Users
+		sum += d.Rate
+	}
+	if users > 0 {
+		return bad / users
+	}
+	return sum / float64(len(days))
+}
@@ -0,0 +1,113 @@
+//go:build unit
+// +build unit
+
+package slo
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+// series returns n daily days ending 2026-10-14 with the given rates, oldest
+// first, each over 1000 users.
+func series(rates ...float64) []Day {
+	end := time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)
+	days := make([]Day, 0, len(rates))
+	for i, r := range rates {
+		days = append(days, Day{Date: end.AddDate(0, 0, i-len(rates)+1).Format(DateLayout), Rate: r, Users: 1000})
+	}
+	return days
+}
+
+func repeat(rate float64, n int) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = rate
+	}
+	return out
+}
+
+func objective(t *testing.T) Objective {
+	t.Helper()
+	o := Objective{Name: "crash-free", Metric: "crash-free-users", Target: 99.5}
+	if err := o.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	return o
+}
+
+func TestEvaluateHealthy(t *testing.T) {
+	st := Evaluate(objective(t), series(repeat(0.002, 28)...))
+	if st.State != StateOK || st.Breached() || st.DaysWithData != 28 || st.From != "2026-09-17" || st.To != "2026-10-14" {
+		t.Fatalf("status = %+v", st)
+	}
+	if !near(st.BurnRate, 0.4) || !near(st.BudgetRemaining, 0.6) || !near(st.Attainment, 99.8) || !near(st.BadUsers, 56) {
+		t.Errorf("budget = %+v", st)
+	}
+}
+
+func TestEvaluateFastBurn(t *testing.T) {
+	// A bad release on the last two days burns 6x the budget.
+	st := Evaluate(objective(t), series(append(repeat(0.001, 26), 0.03, 0.03)...))
+	if st.State != StateFastBurn || st.Severity != SeverityCritical {
+		t.Fatalf("state = %s, windows = %+v", st.State, st.Windows)
+	}
+	if fast := st.Windows[0]; !fast.Firing || !near(fast.LongBurn, 6) || !near(fast.ShortBurn, 6) {
+		t.Errorf("fast = %+v", fast)
+	}
+}
+
+func TestEvaluateRecoveredFastBurn(t *testing.T) {
+	// The one-day short window proves the fast burn has stopped; the
+	// two-day slow window still sees it.
+	st := Evaluate(objective(t), series(append(repeat(0.001, 26), 0.03, 0.001)...))
+	if st.Windows[0].Firing || st.State != StateSlowBurn {
+		t.Errorf("status = %+v", st)
+	}
+}
+
+func TestEvaluateSlowBurnAndExhaustion(t *testing.T) {
+	st := Evaluate(objective(t), series(append(repeat(0.001, 21), repeat(0.006, 7)...)...))
+	if st.State != StateSlowBurn || st.Severity != SeverityWarning || st.Windows[0].Firing {
+		t.Errorf("slow burn = %+v", st)
+	}
+	st = Evaluate(objective(t), series(repeat(0.006, 28)...))
+	if st.State != StateExhausted || st.BudgetRemaining >= 0 {
+		t.Errorf("exhausted = %+v", st)
+	}
+}
+
+func TestEvaluateWeightsByUsers(t *testing.T) {
+	days := series(0.01, 0.001)
+	days[0].Users = 100
+	days[1].Users = 900
+	st := Evaluate(objective(t), days)
+	if !near(st.BadUsers, 1.9) || !near(st.BurnRate, 0.0019/0.005) {
+		t.Errorf("status = %+v", st)
+	}
+	if st := Evaluate(objective(t), nil); st.State != StateNoData || st.Breached() {
+		t.Errorf("no data = %+v", st)
+	}
+}
+
+func TestObjectiveValidate(t *testing.T) {
+	for _, o := range []Objective{
+		{Name: "a", Target: 100},
+		{Name: "b", Target: 99, WindowDays: -1},
+		{Name: "c", Target: 99, WindowDays: 7, Slow: Burn{LongDays: 14, ShortDays: 1, Rate: 1}},
+		{Name: "d", Target: 99, Fast: Burn{LongDays: 1, ShortDays: 2, Rate: 4}},
+	} {
+		if err := o.Validate(); err == nil {
+			t.Errorf("%s: expected an error", o.Name)
+		}
+	}
+	o := Objective{Name: "e", Target: 99}
+	if err := o.Validate(); err != nil || o.WindowDays != DefaultWindowDays || o.Fast != DefaultFastBurn || o.Slow != DefaultSlowBurn {
+		t.Errorf("defaults = %+v, %v", o, err)
+	}
+}
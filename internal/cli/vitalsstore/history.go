@@ -0,0 +1,110 @@
+package vitalsstore
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Periods Aggregate buckets points by.
+const (
+	PeriodDay     = "day"
+	PeriodWeek    = "week"
+	PeriodMonth   = "month"
+	PeriodQuarter = "quarter"
+	PeriodYear    = "year"
+)
+
+// Bucket summarizes the daily values in one period.
+type Bucket struct {
+	// Period labels the bucket: 2026-10-01, 2026-W41, 2026-10, 2026-Q4 or
+	// 2026.
+	Period string  `json:"period"`
+	Start  string  `json:"start"`
+	Days   int     `json:"days"`
+	Mean   float64 `json:"mean"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	// YearAgo is the mean of the same period one year earlier, and
+	// YoYChange the relative change from it, when that period has data.
+	YearAgo   *float64 `json:"yearAgo,omitempty"`
+	YoYChange *float64 `json:"yoyChange,omitempty"`
+}
+
+// Aggregate buckets points by period, oldest first, with year-over-year
+// comparisons against earlier buckets in the same points.
+func Aggregate(points []Point, period string) ([]Bucket, error) {
+	type acc struct {
+		bucket Bucket
+		sum    float64
+	}
+	byLabel := make(map[string]*acc)
+	order := make([]string, 0)
+	for _, p := range points {
+		day, err := time.Parse(DateLayout, p.Date)
+		if err != nil {
+			return nil, fmt.Errorf("invalid point date %q", p.Date)
+		}
+		label, start, err := bucketOf(day, period)
+		if err != nil {
+			return nil, err
+		}
+		a, ok := byLabel[label]
+		if !ok {
+			a = &acc{bucket: Bucket{Period: label, Start: start.Format(DateLayout), Min: p.Value, Max: p.Value}}
+			byLabel[label] = a
+			order = append(order, label)
+		}
+		a.sum += p.Value
+		a.bucket.Days++
+		if p.Value < a.bucket.Min {
+			a.bucket.Min = p.Value
+		}
+		if p.Value > a.bucket.Max {
+			a.bucket.Max = p.Value
+		}
+	}
+
+	buckets := make([]Bucket, 0, len(order))
+	for _, label := range order {
+		a := byLabel[label]
+		a.bucket.Mean = a.sum / float64(a.bucket.Days)
+	}
+	for _, label := range order {
+		b := byLabel[label].bucket
+		start, _ := time.Parse(DateLayout, b.Start)
+		prevLabel, _, _ := bucketOf(start.AddDate(-1, 0, 0), period)
+		if prev, ok := byLabel[prevLabel]; ok {
+			yearAgo := prev.bucket.Mean
+			b.YearAgo = &yearAgo
+			if yearAgo != 0 {
+				change := (b.Mean - yearAgo) / yearAgo
+				b.YoYChange = &change
+			}
+		}
+		buckets = append(buckets, b)
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].Start < buckets[j].Start })
+	return buckets, nil
+}
+
+// bucketOf returns the label and first day of the period containing day.
+func bucketOf(day time.Time, period string) (string, time.Time, error) {
+	y, m, d := day.Date()
+	switch period {
+	case PeriodDay:
+		return day.Format(DateLayout), time.Date(y, m, d, 0, 0, 0, 0, time.UTC), nil
+	case PeriodWeek:
+		year, week := day.ISOWeek()
+		offset := (int(day.Weekday()) + 6) % 7
+		return fmt.Sprintf("%d-W%02d", year, week), time.Date(y, m, d-offset, 0, 0, 0, 0, time.UTC), nil
+	case PeriodMonth:
+		return fmt.Sprintf("%d-%02d", y, m), time.Date(y, m, 1, 0, 0, 0, 0, time.UTC), nil
+	case PeriodQuarter:
+		q := (int(m)-1)/3 + 1
+		return fmt.Sprintf("%d-Q%d", y, q), time.Date(y, time.Month((q-1)*3+1), 1, 0, 0, 0, 0, time.UTC), nil
+	case PeriodYear:
+		return fmt.Sprintf("%d", y), time.Date(y, 1, 1, 0, 0, 0, 0, time.UTC), nil
+	}
+	return "", time.Time{}, fmt.Errorf("unknown period %q", period)
+}
@@ -0,0 +1,282 @@
+// Package vitalsstore is an append-only local store of daily vitals values,
+// so trends outlive the Reporting API's retention window. Each app and
+// metric set is one JSON Lines file; a later line for the same day, metric
+// and dimensions supersedes earlier ones, which is how revised values from
+// the API are recorded. Kong adapters live in package cli.
+package vitalsstore
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DateLayout is the layout of Point.Date.
+const DateLayout = "2006-01-02"
+
+// Point is one daily value.
+type Point struct {
+	Date       string            `json:"date"`
+	Metric     string            `json:"metric"`
+	Dimensions map[string]string `json:"dimensions,omitempty"`
+	Value      float64           `json:"value"`
+	RecordedAt time.Time         `json:"recordedAt"`
+}
+
+// Key identifies the series and day of a point: points with equal keys
+// are versions of the same value.
+func (p Point) Key() string {
+	return p.Date + "|" + p.Metric + "|" + DimensionKey(p.Dimensions)
+}
+
+// DimensionKey renders dimensions as sorted key=value pairs.
+func DimensionKey(dims map[string]string) string {
+	keys := make([]string, 0, len(dims))
+	for k := range dims {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, k+"="+dims[k])
+	}
+	return strings.Join(parts, ",")
+}
+
+// Store is a directory of <package>/<metricSet>.jsonl files.
+type Store struct {
+	dir string
+}
+
+// Open returns the store rooted at dir. Nothing is created until the
+// first Append.
+func Open(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Dir returns the store directory.
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+func (s *Store) path(pkg, metricSet string) (string, error) {
+	for _, name := range []string{pkg, metricSet} {
+		if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+			return "", fmt.Errorf("invalid store name %q", name)
+		}
+	}
+	return filepath.Join(s.dir, pkg, metricSet+".jsonl"), nil
+}
+
+// Append records points for an app's metric set and returns how many were
+// written. Points whose value is already the latest recorded for their
+// key are skipped, so repeated snapshots of the same days do not grow the
+// file.
+func (s *Store) Append(pkg, metricSet string, points []Point) (int, error) {
+	path, err := s.path(pkg, metricSet)
+	if err != nil {
+		return 0, err
+	}
+	existing, err := s.Read(pkg, metricSet)
+	if err != nil {
+		return 0, err
+	}
+	latest := make(map[string]float64, len(existing))
+	for _, p := range existing {
+		latest[p.Key()] = p.Value
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	added := 0
+	for _, p := range points {
+		if _, err := time.Parse(DateLayout, p.Date); err != nil {
+			return 0, fmt.Errorf("invalid point date %q", p.Date)
+		}
+		if v, ok := latest[p.Key()]; ok && v == p.Value {
+			continue
+		}
+		latest[p.Key()] = p.Value
+		if err := enc.Encode(p); err != nil {
+			return 0, err
+		}
+		added++
+	}
+	if added == 0 {
+		return 0, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return 0, err
+	}
+	if err := dropPartialLine(path); err != nil {
+		return 0, err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		_ = f.Close()
+		return 0, err
+	}
+	return added, f.Close()
+}
+
+// dropPartialLine truncates the unterminated final line an interrupted
+// append leaves behind, so the next append starts on a fresh line.
+func dropPartialLine(path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) || len(data) == 0 || data[len(data)-1] == '\n' {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return os.Truncate(path, int64(bytes.LastIndexByte(data, '\n')+1))
+}
+
+// Read returns the latest value of every point recorded for an app's
+// metric set, ordered by date, metric and dimensions. A missing file reads
+// as no points. A malformed final line, left by an interrupted append, is
+// ignored.
+func (s *Store) Read(pkg, metricSet string) ([]Point, error) {
+	path, err := s.path(pkg, metricSet)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	byKey := make(map[string]Point)
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	lineNo := 0
+	var pending error
+	for sc.Scan() {
+		lineNo++
+		if pending != nil {
+			return nil, pending
+		}
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var p Point
+		if err := json.Unmarshal(line, &p); err != nil {
+			pending = fmt.Errorf("%s:%d: %w", path, lineNo, err)
+			continue
+		}
+		byKey[p.Key()] = p
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	points := make([]Point, 0, len(byKey))
+	for _, p := range byKey {
+		points = append(points, p)
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].Key() < points[j].Key() })
+	return points, nil
+}
+
+// Packages lists the apps with recorded data.
+func (s *Store) Packages() ([]string, error) {
+	return s.list(s.dir, true)
+}
+
+// MetricSets lists the metric sets recorded for an app.
+func (s *Store) MetricSets(pkg string) ([]string, error) {
+	if _, err := s.path(pkg, "x"); err != nil {
+		return nil, err
+	}
+	return s.list(filepath.Join(s.dir, pkg), false)
+}
+
+func (s *Store) list(dir string, dirs bool) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		switch {
+		case dirs && e.IsDir():
+			names = append(names, e.Name())
+		case !dirs && !e.IsDir() && strings.HasSuffix(e.Name(), ".jsonl"):
+			names = append(names, strings.TrimSuffix(e.Name(), ".jsonl"))
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Query selects points of one series.
+type Query struct {
+	Metric string
+	// Dimensions must equal the point's dimensions exactly; nil selects
+	// app-level points recorded without dimensions.
+	Dimensions map[string]string
+	// From and To bound the dates, inclusive; zero values are open.
+	From, To time.Time
+}
+
+// Filter returns the points matching q, in date order.
+func Filter(points []Point, q Query) []Point {
+	want := DimensionKey(q.Dimensions)
+	from, to := "", ""
+	if !q.From.IsZero() {
+		from = q.From.Format(DateLayout)
+	}
+	if !q.To.IsZero() {
+		to = q.To.Format(DateLayout)
+	}
+	out := make([]Point, 0)
+	for _, p := range points {
+		if p.Metric != q.Metric || DimensionKey(p.Dimensions) != want {
+			continue
+		}
+		if (from != "" && p.Date < from) || (to != "" && p.Date > to) {
+			continue
+		}
+		out = append(out, p)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Date < out[j].Date })
+	return out
+}
+
+// Mean returns the mean value of points and how many there are.
+func Mean(points []Point) (mean float64, n int) {
+	if len(points) == 0 {
+		return 0, 0
+	}
+	var sum float64
+	for _, p := range points {
+		sum += p.Value
+	}
+	return sum / float64(len(points)), len(points)
+}
+
+// Sum returns the sum of the values of points.
+func Sum(points []Point) float64 {
+	var sum float64
+	for _, p := range points {
+		sum += p.Value
+	}
+	return sum
+}
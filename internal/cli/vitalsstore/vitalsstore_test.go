@@ -0,0 +1,118 @@
+//go:build unit
+// +build unit
+
+package vitalsstore
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStore_AppendRead(t *testing.T) {
+	s := Open(t.TempDir())
+	at := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	points := []Point{
+		{Date: "2026-10-02", Metric: "crashRate", Value: 0.02, RecordedAt: at},
+		{Date: "2026-10-01", Metric: "crashRate", Value: 0.01, RecordedAt: at},
+		{Date: "2026-10-01", Metric: "crashRate", Dimensions: map[string]string{"versionCode": "31"}, Value: 0.03, RecordedAt: at},
+	}
+	if n, err := s.Append("com.example.app", "crashRateMetricSet", points); err != nil || n != 3 {
+		t.Fatalf("Append = %d, %v", n, err)
+	}
+	// Unchanged values are skipped; a revised value is appended and wins.
+	revised := []Point{points[0], {Date: "2026-10-01", Metric: "crashRate", Value: 0.015, RecordedAt: at.Add(time.Hour)}}
+	if n, err := s.Append("com.example.app", "crashRateMetricSet", revised); err != nil || n != 1 {
+		t.Fatalf("Append revised = %d, %v", n, err)
+	}
+
+	got, err := s.Read("com.example.app", "crashRateMetricSet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := Filter(got, Query{Metric: "crashRate"})
+	if len(got) != 3 || len(app) != 2 || app[0].Value != 0.015 || app[1].Date != "2026-10-02" {
+		t.Errorf("points = %+v", got)
+	}
+	byVersion := Filter(got, Query{Metric: "crashRate", Dimensions: map[string]string{"versionCode": "31"}})
+	if len(byVersion) != 1 || byVersion[0].Value != 0.03 {
+		t.Errorf("by version = %+v", byVersion)
+	}
+	if ranged := Filter(got, Query{Metric: "crashRate", From: time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC)}); len(ranged) != 1 {
+		t.Errorf("ranged = %+v", ranged)
+	}
+	if mean, n := Mean(app); n != 2 || mean != 0.0175 {
+		t.Errorf("Mean = %v, %d", mean, n)
+	}
+
+	// An interrupted append leaves a partial final line, which is ignored.
+	path := filepath.Join(s.Dir(), "com.example.app", "crashRateMetricSet.jsonl")
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString(`{"date":"2026-10-03","met`)
+	_ = f.Close()
+	if got, err := s.Read("com.example.app", "crashRateMetricSet"); err != nil || len(got) != 3 {
+		t.Errorf("Read after partial line = %d, %v", len(got), err)
+	}
+	// The next append drops the partial line.
+	if n, err := s.Append("com.example.app", "crashRateMetricSet", []Point{{Date: "2026-10-03", Metric: "crashRate", Value: 0.01}}); err != nil || n != 1 {
+		t.Fatalf("Append after partial line = %d, %v", n, err)
+	}
+	if got, err := s.Read("com.example.app", "crashRateMetricSet"); err != nil || len(got) != 4 {
+		t.Errorf("Read after append = %d, %v", len(got), err)
+	}
+
+	if pkgs, err := s.Packages(); err != nil || !reflect.DeepEqual(pkgs, []string{"com.example.app"}) {
+		t.Errorf("Packages = %v, %v", pkgs, err)
+	}
+	if sets, err := s.MetricSets("com.example.app"); err != nil || !reflect.DeepEqual(sets, []string{"crashRateMetricSet"}) {
+		t.Errorf("MetricSets = %v, %v", sets, err)
+	}
+	if got, err := s.Read("com.example.other", "crashRateMetricSet"); err != nil || got != nil {
+		t.Errorf("Read missing = %v, %v", got, err)
+	}
+	if _, err := s.Append("../escape", "crashRateMetricSet", points); err == nil || !strings.Contains(err.Error(), "invalid store name") {
+		t.Errorf("Append escape err = %v", err)
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	points := []Point{
+		{Date: "2025-10-05", Value: 0.02},
+		{Date: "2025-10-20", Value: 0.04},
+		{Date: "2026-10-01", Value: 0.01},
+		{Date: "2026-10-02", Value: 0.02},
+		{Date: "2026-11-01", Value: 0.05},
+	}
+	months, err := Aggregate(points, PeriodMonth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(months) != 3 || months[0].Period != "2025-10" || months[1].Period != "2026-10" || months[2].Period != "2026-11" {
+		t.Fatalf("months = %+v", months)
+	}
+	oct := months[1]
+	if oct.Days != 2 || oct.Min != 0.01 || oct.Max != 0.02 || oct.YearAgo == nil || *oct.YearAgo < 0.0299 || oct.YoYChange == nil || *oct.YoYChange > -0.49 {
+		t.Errorf("2026-10 = %+v", oct)
+	}
+	if months[2].YearAgo != nil {
+		t.Errorf("2026-11 has no year-ago data: %+v", months[2])
+	}
+
+	quarters, _ := Aggregate(points, PeriodQuarter)
+	if len(quarters) != 2 || quarters[1].Period != "2026-Q4" || quarters[1].Start != "2026-10-01" || quarters[1].YoYChange == nil {
+		t.Errorf("quarters = %+v", quarters)
+	}
+	weeks, _ := Aggregate(points[2:4], PeriodWeek)
+	if len(weeks) != 1 || weeks[0].Period != "2026-W40" || weeks[0].Start != "2026-09-28" {
+		t.Errorf("weeks = %+v", weeks)
+	}
+	if _, err := Aggregate(points, "decade"); err == nil {
+		t.Error("unknown period accepted")
+	}
+}
@@ -0,0 +1,283 @@
+// Package webhooks keeps a local registry of webhooks and delivers gpd
+// events to the ones subscribed to them. Deliveries are signed the same way
+// as alert webhooks (see package alerting). Kong adapters live in package
+// cli.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/alerting"
+)
+
+// Event types.
+const (
+	EventVitalsThreshold = "vitals.threshold"
+	EventRolloutStep     = "rollout.step"
+	EventReviewNew       = "review.new"
+	EventWorkflowFailed  = "workflow.failed"
+	// EventTest is only sent by the test command.
+	EventTest = "webhook.test"
+)
+
+// Events lists the event types a webhook can subscribe to; "*" subscribes
+// to all of them.
+var Events = []string{EventVitalsThreshold, EventRolloutStep, EventReviewNew, EventWorkflowFailed}
+
+// HeaderEvent carries the event type of a delivery.
+const HeaderEvent = "X-Gpd-Event"
+
+// Webhook is one registered endpoint.
+type Webhook struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name,omitempty"`
+	URL        string     `json:"url"`
+	Events     []string   `json:"events"`
+	Secret     string     `json:"secret,omitempty"`
+	Active     bool       `json:"active"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastCalled *time.Time `json:"lastCalled,omitempty"`
+	LastStatus string     `json:"lastStatus,omitempty"`
+}
+
+// Subscribed reports whether the webhook wants events of type event.
+func (w Webhook) Subscribed(event string) bool {
+	for _, e := range w.Events {
+		if e == event || e == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// Event is the JSON body of a delivery.
+type Event struct {
+	ID      string      `json:"id"`
+	Type    string      `json:"type"`
+	Time    time.Time   `json:"time"`
+	Package string      `json:"package,omitempty"`
+	Data    interface{} `json:"data"`
+}
+
+// NewEvent returns an event with a fresh ID.
+func NewEvent(eventType, pkg string, data interface{}) Event {
+	return Event{ID: "evt_" + randomHex(8), Type: eventType, Time: time.Now().UTC(), Package: pkg, Data: data}
+}
+
+// Delivery is the outcome of one event on one webhook.
+type Delivery struct {
+	WebhookID  string `json:"webhookId"`
+	Event      string `json:"event"`
+	Status     string `json:"status"`
+	StatusCode int    `json:"statusCode,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Registry is the set of webhooks stored in a file, plus per-package
+// cursors used to detect new items between runs.
+type Registry struct {
+	Webhooks []Webhook            `json:"webhooks"`
+	Cursors  map[string]time.Time `json:"cursors,omitempty"`
+
+	path   string
+	client *http.Client
+}
+
+// Load reads the registry at path; a missing file is an empty registry.
+func Load(path string) (*Registry, error) {
+	r := &Registry{path: path, client: &http.Client{Timeout: 10 * time.Second}}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, fmt.Errorf("parse webhook registry %s: %w", path, err)
+	}
+	return r, nil
+}
+
+// Save writes the registry back to its file.
+func (r *Registry) Save() error {
+	if r.Webhooks == nil {
+		r.Webhooks = []Webhook{}
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o700); err != nil {
+		return err
+	}
+	// The file holds signing secrets.
+	return os.WriteFile(r.path, data, 0o600)
+}
+
+// Add validates w, fills in its ID, secret and creation time, and appends
+// it.
+func (r *Registry) Add(w Webhook) (Webhook, error) {
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return Webhook{}, fmt.Errorf("invalid webhook URL %q: must be http(s)", w.URL)
+	}
+	if len(w.Events) == 0 {
+		return Webhook{}, fmt.Errorf("at least one event is required")
+	}
+	for _, e := range w.Events {
+		if !validEvent(e) {
+			return Webhook{}, fmt.Errorf("unknown event %q (want one of %v or *)", e, Events)
+		}
+	}
+	w.Events = normalizeEvents(w.Events)
+	w.ID = "wh_" + randomHex(6)
+	if w.Secret == "" {
+		w.Secret = randomHex(32)
+	}
+	w.CreatedAt = time.Now().UTC()
+	r.Webhooks = append(r.Webhooks, w)
+	return w, nil
+}
+
+func validEvent(e string) bool {
+	if e == "*" {
+		return true
+	}
+	for _, known := range Events {
+		if e == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Remove deletes the webhook with id and reports whether it existed.
+func (r *Registry) Remove(id string) bool {
+	for i, w := range r.Webhooks {
+		if w.ID == id {
+			r.Webhooks = append(r.Webhooks[:i], r.Webhooks[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Get returns the webhook with id.
+func (r *Registry) Get(id string) (*Webhook, bool) {
+	for i := range r.Webhooks {
+		if r.Webhooks[i].ID == id {
+			return &r.Webhooks[i], true
+		}
+	}
+	return nil, false
+}
+
+// Subscribers returns the active webhooks subscribed to event.
+func (r *Registry) Subscribers(event string) []*Webhook {
+	var out []*Webhook
+	for i := range r.Webhooks {
+		if r.Webhooks[i].Active && r.Webhooks[i].Subscribed(event) {
+			out = append(out, &r.Webhooks[i])
+		}
+	}
+	return out
+}
+
+// Emit delivers ev to every active webhook subscribed to it and records
+// the outcome on each. The caller saves the registry.
+func (r *Registry) Emit(ctx context.Context, ev Event) []Delivery {
+	var deliveries []Delivery
+	for _, w := range r.Subscribers(ev.Type) {
+		deliveries = append(deliveries, r.Send(ctx, w, ev))
+	}
+	return deliveries
+}
+
+// Send delivers ev to w, whether or not w is active or subscribed, and
+// records the outcome on w.
+func (r *Registry) Send(ctx context.Context, w *Webhook, ev Event) Delivery {
+	d := Delivery{WebhookID: w.ID, Event: ev.Type, Status: alerting.StatusFailed}
+	defer func() {
+		now := time.Now().UTC()
+		w.LastCalled = &now
+		w.LastStatus = d.Status
+	}()
+	body, err := json.Marshal(ev)
+	if err != nil {
+		d.Error = err.Error()
+		return d
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		d.Error = err.Error()
+		return d
+	}
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "gpd-webhooks")
+	req.Header.Set(HeaderEvent, ev.Type)
+	req.Header.Set(alerting.HeaderTimestamp, ts)
+	req.Header.Set(alerting.HeaderSignature, alerting.Sign(w.Secret, ts, body))
+	resp, err := r.client.Do(req)
+	if err != nil {
+		d.Error = err.Error()
+		return d
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	d.StatusCode = resp.StatusCode
+	if resp.StatusCode >= 300 {
+		d.Error = fmt.Sprintf("HTTP %d", resp.StatusCode)
+		return d
+	}
+	d.Status = alerting.StatusSent
+	return d
+}
+
+// Cursor returns the time recorded under key, and whether one was.
+func (r *Registry) Cursor(key string) (time.Time, bool) {
+	t, ok := r.Cursors[key]
+	return t, ok
+}
+
+// SetCursor records t under key.
+func (r *Registry) SetCursor(key string, t time.Time) {
+	if r.Cursors == nil {
+		r.Cursors = make(map[string]time.Time)
+	}
+	r.Cursors[key] = t
+}
+
+// normalizeEvents returns events sorted and deduplicated.
+func normalizeEvents(events []string) []string {
+	seen := make(map[string]bool, len(events))
+	out := make([]string, 0, len(events))
+	for _, e := range events {
+		if !seen[e] {
+			seen[e] = true
+			out = append(out, e)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,116 @@
+//go:build unit
+// +build unit
+
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dl-alexandre/Google-Play-Developer-CLI/internal/cli/alerting"
+)
+
+func TestRegistryAddSaveLoadRemove(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gpd", "webhooks.json")
+	r, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := r.Add(Webhook{URL: "https://hooks.example.com/gpd", Events: []string{EventRolloutStep, EventVitalsThreshold, EventRolloutStep}, Active: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.ID == "" || len(w.Secret) != 64 || len(w.Events) != 2 || w.Events[0] != EventRolloutStep {
+		t.Errorf("added = %+v", w)
+	}
+	if err := r.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+		t.Errorf("mode = %v", info.Mode().Perm())
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := loaded.Get(w.ID); !ok || got.Secret != w.Secret {
+		t.Fatalf("loaded = %+v", loaded.Webhooks)
+	}
+	if !loaded.Remove(w.ID) || loaded.Remove(w.ID) || len(loaded.Webhooks) != 0 {
+		t.Errorf("remove left %+v", loaded.Webhooks)
+	}
+}
+
+func TestRegistryAddRejectsInvalid(t *testing.T) {
+	r, _ := Load(filepath.Join(t.TempDir(), "webhooks.json"))
+	for name, w := range map[string]Webhook{
+		"scheme":   {URL: "ftp://example.com", Events: []string{"*"}},
+		"no event": {URL: "https://example.com"},
+		"unknown":  {URL: "https://example.com", Events: []string{"vitals.crashes"}},
+	} {
+		if _, err := r.Add(w); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestEmitDeliversSignedEventsToSubscribers(t *testing.T) {
+	var received []Event
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		if req.Header.Get(alerting.HeaderSignature) != alerting.Sign("s3cret", req.Header.Get(alerting.HeaderTimestamp), body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var ev Event
+		_ = json.Unmarshal(body, &ev)
+		if req.Header.Get(HeaderEvent) != ev.Type {
+			t.Errorf("event header = %s", req.Header.Get(HeaderEvent))
+		}
+		received = append(received, ev)
+	}))
+	defer srv.Close()
+
+	r, _ := Load(filepath.Join(t.TempDir(), "webhooks.json"))
+	all, _ := r.Add(Webhook{URL: srv.URL, Events: []string{"*"}, Secret: "s3cret", Active: true})
+	_, _ = r.Add(Webhook{URL: srv.URL, Events: []string{EventReviewNew}, Secret: "s3cret", Active: true})
+	_, _ = r.Add(Webhook{URL: srv.URL, Events: []string{EventRolloutStep}, Secret: "s3cret", Active: false})
+	wrong, _ := r.Add(Webhook{URL: srv.URL, Events: []string{EventRolloutStep}, Secret: "other", Active: true})
+
+	deliveries := r.Emit(context.Background(), NewEvent(EventRolloutStep, "com.example.app", map[string]float64{"userFraction": 0.2}))
+	if len(deliveries) != 2 {
+		t.Fatalf("deliveries = %+v", deliveries)
+	}
+	if deliveries[0].WebhookID != all.ID || deliveries[0].Status != alerting.StatusSent {
+		t.Errorf("subscriber delivery = %+v", deliveries[0])
+	}
+	if deliveries[1].WebhookID != wrong.ID || deliveries[1].StatusCode != http.StatusUnauthorized {
+		t.Errorf("bad secret delivery = %+v", deliveries[1])
+	}
+	if len(received) != 1 || received[0].Package != "com.example.app" || received[0].Type != EventRolloutStep {
+		t.Errorf("received = %+v", received)
+	}
+	if got, _ := r.Get(all.ID); got.LastCalled == nil || got.LastStatus != alerting.StatusSent {
+		t.Errorf("last call not recorded: %+v", got)
+	}
+}
+
+func TestCursor(t *testing.T) {
+	r, _ := Load(filepath.Join(t.TempDir(), "webhooks.json"))
+	if _, ok := r.Cursor("reviews/com.example.app"); ok {
+		t.Fatal("unexpected cursor")
+	}
+	now := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	r.SetCursor("reviews/com.example.app", now)
+	if got, ok := r.Cursor("reviews/com.example.app"); !ok || !got.Equal(now) {
+		t.Errorf("cursor = %v, %v", got, ok)
+	}
+}
@@ -22,6 +22,72 @@ type Config struct {
 	RateLimits            map[string]string `json:"rateLimits,omitempty"`
 	TesterLimits          *TesterLimits     `json:"testerLimits,omitempty"`
 	ActiveProfile         string            `json:"activeProfile,omitempty"`
+	// UploadKeyFingerprints maps an auth profile to the SHA-256 fingerprint
+	// of the upload key its artifacts must be signed with.
+	UploadKeyFingerprints map[string]string `json:"uploadKeyFingerprints,omitempty"`
+	// Alerts maps an auth profile to where its monitor and automation
+	// alerts are delivered.
+	Alerts map[string]*AlertConfig `json:"alerts,omitempty"`
+	// SLOs are the service level objectives checked by gpd monitor slo.
+	SLOs []SLOConfig `json:"slos,omitempty"`
+}
+
+// SLOConfig is a user-weighted service level objective, e.g. 99.5%
+// crash-free users over 28 days.
+type SLOConfig struct {
+	Name string `json:"name"`
+	// Package limits the SLO to one app; empty applies it to every app.
+	Package string `json:"package,omitempty"`
+	// Metric is crash-free-users (default), user-perceived-crash-free-users,
+	// anr-free-users or user-perceived-anr-free-users.
+	Metric string `json:"metric,omitempty"`
+	// Objective is the target percentage of good users, e.g. 99.5.
+	Objective float64 `json:"objective"`
+	// WindowDays is the rolling compliance window; 0 means 28.
+	WindowDays int `json:"windowDays,omitempty"`
+	// FastBurn and SlowBurn override the default burn-rate alerts.
+	FastBurn *BurnAlertConfig `json:"fastBurn,omitempty"`
+	SlowBurn *BurnAlertConfig `json:"slowBurn,omitempty"`
+}
+
+// BurnAlertConfig fires when the error budget burns at least BurnRate
+// times faster than sustainable over both windows.
+type BurnAlertConfig struct {
+	LongWindowDays  int     `json:"longWindowDays"`
+	ShortWindowDays int     `json:"shortWindowDays"`
+	BurnRate        float64 `json:"burnRate"`
+}
+
+// AlertConfig configures alert delivery for one profile.
+type AlertConfig struct {
+	Sinks []AlertSink `json:"sinks"`
+	// Template is a text/template for alert messages; sinks may override it.
+	Template string `json:"template,omitempty"`
+	// Cooldown suppresses repeats of the same alert, e.g. "1h".
+	Cooldown string `json:"cooldown,omitempty"`
+	// Retries is the number of extra delivery attempts per sink.
+	Retries *int `json:"retries,omitempty"`
+	// Backoff is the delay before the first retry; it doubles per attempt.
+	Backoff string `json:"backoff,omitempty"`
+}
+
+// AlertSink is one alert destination. URL, Secret and Password may
+// reference environment variables as ${NAME}.
+type AlertSink struct {
+	Name string `json:"name,omitempty"`
+	// Type is "webhook", "slack", "email" or "command".
+	Type     string            `json:"type"`
+	URL      string            `json:"url,omitempty"`
+	Secret   string            `json:"secret,omitempty"`
+	Headers  map[string]string `json:"headers,omitempty"`
+	SMTPHost string            `json:"smtpHost,omitempty"`
+	SMTPPort int               `json:"smtpPort,omitempty"`
+	Username string            `json:"username,omitempty"`
+	Password string            `json:"password,omitempty"`
+	From     string            `json:"from,omitempty"`
+	To       []string          `json:"to,omitempty"`
+	Command  []string          `json:"command,omitempty"`
+	Template string            `json:"template,omitempty"`
 }
 
 // TesterLimits defines limits for different tester types.
@@ -116,6 +182,9 @@ type Paths struct {
 	ConfigDir  string
 	CacheDir   string
 	ConfigFile string
+	// DataDir holds data gpd accumulates and must keep, such as the local
+	// vitals store; unlike CacheDir it is not safe to delete.
+	DataDir string
 }
 
 var runtimeGOOS = runtime.GOOS
@@ -145,13 +214,14 @@ func GetPaths() Paths {
 }
 
 func getPathsForOS(goos string) Paths {
-	var configDir, cacheDir string
+	var configDir, cacheDir, dataDir string
 
 	switch goos {
 	case "darwin":
 		home := getHomeDirForOS(goos)
 		configDir = filepath.Join(home, "Library", "Application Support", "gpd")
 		cacheDir = filepath.Join(home, "Library", "Caches", "gpd")
+		dataDir = configDir
 	case "windows":
 		appData := os.Getenv("APPDATA")
 		localAppData := os.Getenv("LOCALAPPDATA")
@@ -166,6 +236,7 @@ func getPathsForOS(goos string) Paths {
 		}
 		configDir = filepath.Join(appData, "gpd")
 		cacheDir = filepath.Join(localAppData, "gpd")
+		dataDir = filepath.Join(appData, "gpd", "data")
 	default:
 		home := getHomeDirForOS(goos)
 		if xdgConfig := os.Getenv("XDG_CONFIG_HOME"); xdgConfig != "" {
@@ -178,12 +249,18 @@ func getPathsForOS(goos string) Paths {
 		} else {
 			cacheDir = filepath.Join(home, ".cache", "gpd")
 		}
+		if xdgData := os.Getenv("XDG_DATA_HOME"); xdgData != "" {
+			dataDir = filepath.Join(xdgData, "gpd")
+		} else {
+			dataDir = filepath.Join(home, ".local", "share", "gpd")
+		}
 	}
 
 	return Paths{
 		ConfigDir:  configDir,
 		CacheDir:   cacheDir,
 		ConfigFile: filepath.Join(configDir, "config.json"),
+		DataDir:    dataDir,
 	}
 }
 
@@ -374,6 +451,61 @@ func SetActiveProfile(profile string) error {
 	return cfg.Save()
 }
 
+// UploadKeyFingerprint returns the expected upload-key fingerprint recorded
+// for profile, or "" when none is set.
+func (c *Config) UploadKeyFingerprint(profile string) string {
+	if c == nil {
+		return ""
+	}
+	return c.UploadKeyFingerprints[strings.TrimSpace(profile)]
+}
+
+// AlertConfig returns the alert configuration of profile, or nil when none
+// is set.
+func (c *Config) AlertConfig(profile string) *AlertConfig {
+	if c == nil {
+		return nil
+	}
+	return c.Alerts[strings.TrimSpace(profile)]
+}
+
+// SLOsFor returns the SLOs that apply to pkg.
+func (c *Config) SLOsFor(pkg string) []SLOConfig {
+	if c == nil {
+		return nil
+	}
+	var out []SLOConfig
+	for _, s := range c.SLOs {
+		if s.Package == "" || s.Package == pkg {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// SetUploadKeyFingerprint persists the expected upload-key fingerprint for
+// profile. An empty fingerprint clears it.
+func SetUploadKeyFingerprint(profile, fingerprint string) error {
+	profile = strings.TrimSpace(profile)
+	if profile == "" {
+		profile = "default"
+	}
+	cfg, loadWarn := Load()
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	_ = loadWarn
+	if fingerprint == "" {
+		delete(cfg.UploadKeyFingerprints, profile)
+	} else {
+		if cfg.UploadKeyFingerprints == nil {
+			cfg.UploadKeyFingerprints = make(map[string]string)
+		}
+		cfg.UploadKeyFingerprints[profile] = fingerprint
+	}
+	return cfg.Save()
+}
+
 // GetEnvTimeout returns the timeout from environment.
 func GetEnvTimeout() string {
 	return os.Getenv(EnvTimeout)
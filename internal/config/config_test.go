@@ -187,10 +187,14 @@ func TestGetPathsForOS(t *testing.T) {
 	if !strings.Contains(winPaths.CacheDir, "localappdata") {
 		t.Fatal("expected windows cache dir from LOCALAPPDATA")
 	}
+	if winPaths.DataDir != filepath.Join(os.Getenv("APPDATA"), "gpd", "data") {
+		t.Fatalf("unexpected windows data dir %q", winPaths.DataDir)
+	}
 
 	home := setTestHome(t)
 	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "xdgconfig"))
 	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, "xdgcache"))
+	t.Setenv("XDG_DATA_HOME", filepath.Join(home, "xdgdata"))
 	linuxPaths := getPathsForOS("linux")
 	if !strings.Contains(linuxPaths.ConfigDir, "xdgconfig") {
 		t.Fatal("expected linux config dir from XDG_CONFIG_HOME")
@@ -198,12 +202,16 @@ func TestGetPathsForOS(t *testing.T) {
 	if !strings.Contains(linuxPaths.CacheDir, "xdgcache") {
 		t.Fatal("expected linux cache dir from XDG_CACHE_HOME")
 	}
+	if linuxPaths.DataDir != filepath.Join(home, "xdgdata", "gpd") {
+		t.Fatalf("unexpected linux data dir %q", linuxPaths.DataDir)
+	}
 }
 
 func TestGetPathsForOSDefaultXDG(t *testing.T) {
 	home := setTestHome(t)
 	t.Setenv("XDG_CONFIG_HOME", "")
 	t.Setenv("XDG_CACHE_HOME", "")
+	t.Setenv("XDG_DATA_HOME", "")
 	paths := getPathsForOS("linux")
 	if !strings.Contains(paths.ConfigDir, filepath.Join(home, ".config")) {
 		t.Fatal("expected default config dir")
@@ -211,6 +219,9 @@ func TestGetPathsForOSDefaultXDG(t *testing.T) {
 	if !strings.Contains(paths.CacheDir, filepath.Join(home, ".cache")) {
 		t.Fatal("expected default cache dir")
 	}
+	if paths.DataDir != filepath.Join(home, ".local", "share", "gpd") {
+		t.Fatal("expected default data dir")
+	}
 }
 
 func TestLoadPrimaryConfig(t *testing.T) {
@@ -588,7 +599,6 @@ func TestConfigValidate(t *testing.T) {
 	}
 }
 
-
 func TestResolveAuthProfile(t *testing.T) {
 	t.Setenv("GPD_AUTH_PROFILE", "")
 	// flag wins
@@ -607,3 +617,67 @@ func TestResolveAuthProfile(t *testing.T) {
 		t.Fatalf("default: got %q", got)
 	}
 }
+
+func TestSetUploadKeyFingerprint(t *testing.T) {
+	setTestHome(t)
+	if err := SetUploadKeyFingerprint("team-a", "AA:BB"); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got := cfg.UploadKeyFingerprint("team-a"); got != "AA:BB" {
+		t.Errorf("team-a = %q", got)
+	}
+	if got := cfg.UploadKeyFingerprint("default"); got != "" {
+		t.Errorf("default = %q, want unset", got)
+	}
+
+	if err := SetUploadKeyFingerprint("team-a", ""); err != nil {
+		t.Fatalf("clear: %v", err)
+	}
+	cfg, _ = Load()
+	if got := cfg.UploadKeyFingerprint("team-a"); got != "" {
+		t.Errorf("after clear = %q", got)
+	}
+}
+
+func TestAlertConfig(t *testing.T) {
+	var cfg Config
+	if err := json.Unmarshal([]byte(`{"alerts":{"team-a":{"cooldown":"2h","retries":0,"sinks":[{"type":"slack","url":"${SLACK_URL}"}]}}}`), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	got := cfg.AlertConfig(" team-a ")
+	if got == nil || got.Cooldown != "2h" || got.Retries == nil || *got.Retries != 0 || got.Sinks[0].URL != "${SLACK_URL}" {
+		t.Errorf("team-a = %+v", got)
+	}
+	if cfg.AlertConfig("default") != nil {
+		t.Error("default should have no alert config")
+	}
+	var nilCfg *Config
+	if nilCfg.AlertConfig("team-a") != nil {
+		t.Error("nil config should have no alert config")
+	}
+}
+
+func TestSLOsFor(t *testing.T) {
+	var cfg Config
+	if err := json.Unmarshal([]byte(`{"slos":[
+		{"name":"crash-free","objective":99.5},
+		{"name":"anr-free","package":"com.example.other","metric":"anr-free-users","objective":99.8,"fastBurn":{"longWindowDays":2,"shortWindowDays":1,"burnRate":6}}
+	]}`), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.SLOsFor("com.example.app"); len(got) != 1 || got[0].Name != "crash-free" || got[0].Objective != 99.5 {
+		t.Errorf("com.example.app = %+v", got)
+	}
+	got := cfg.SLOsFor("com.example.other")
+	if len(got) != 2 || got[1].FastBurn == nil || got[1].FastBurn.BurnRate != 6 {
+		t.Errorf("com.example.other = %+v", got)
+	}
+	var nilCfg *Config
+	if nilCfg.SLOsFor("com.example.app") != nil {
+		t.Error("nil config should have no SLOs")
+	}
+}
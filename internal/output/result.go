@@ -24,6 +24,7 @@ const (
 	FormatMarkdown Format = "markdown"
 	FormatCSV      Format = "csv"   // Only for analytics/vitals
 	FormatExcel    Format = "excel" // Excel (.xlsx) format
+	FormatSARIF    Format = "sarif" // Only for lint findings
 )
 
 // Metadata contains response metadata.
@@ -217,6 +218,8 @@ func (m *Manager) Write(r *Result) error {
 		return m.writeCSV(r)
 	case FormatExcel:
 		return m.writeExcel(r)
+	case FormatSARIF:
+		return m.writeSARIF(r)
 	default:
 		return m.writeJSON(r)
 	}
@@ -902,6 +905,8 @@ func ParseFormat(s string) Format {
 		return FormatCSV
 	case "excel", "xlsx":
 		return FormatExcel
+	case "sarif":
+		return FormatSARIF
 	default:
 		return FormatJSON
 	}
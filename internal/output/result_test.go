@@ -187,6 +187,7 @@ func TestParseFormat(t *testing.T) {
 		{"markdown", FormatMarkdown},
 		{"md", FormatMarkdown},
 		{"csv", FormatCSV},
+		{"sarif", FormatSARIF},
 		{"invalid", FormatJSON}, // Default
 		{"", FormatJSON},        // Default
 	}
@@ -1189,3 +1190,42 @@ func TestParseFormatExcel(t *testing.T) {
 		t.Errorf("ParseFormat(\"EXCEL\") = %v, want %v", got, FormatExcel)
 	}
 }
+
+type sarifData struct{}
+
+func (sarifData) SARIF() *SARIFLog {
+	return &SARIFLog{Schema: SARIFSchema, Version: SARIFVersion, Runs: []SARIFRun{{
+		Tool:    SARIFTool{Driver: SARIFDriver{Name: "gpd", Rules: []SARIFRule{{ID: "R1", DefaultConfiguration: SARIFConfiguration{Level: SARIFLevelWarning}}}}},
+		Results: []SARIFResult{{RuleID: "R1", Level: SARIFLevelWarning, Message: SARIFMessage{Text: "found"}}},
+	}}}
+}
+
+func TestSARIFFormat(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	if err := NewManager(&buf).SetFormat(FormatSARIF).Write(NewResult(sarifData{})); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	var log SARIFLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("output should be SARIF JSON: %v", err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 || log.Runs[0].Results[0].RuleID != "R1" {
+		t.Errorf("log = %+v", log)
+	}
+	if strings.Contains(buf.String(), `"meta"`) {
+		t.Error("SARIF output should not be wrapped in the result envelope")
+	}
+}
+
+func TestSARIFFormatUnsupportedTypeFallbacksToJSON(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	if err := NewManager(&buf).SetFormat(FormatSARIF).Write(NewResult(map[string]interface{}{"k": "v"})); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	var parsed map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &parsed); err != nil || parsed["data"] == nil {
+		t.Fatalf("Fallback output should be the JSON envelope: %v %s", err, buf.String())
+	}
+}
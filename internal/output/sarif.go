@@ -0,0 +1,123 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// SARIF 2.1.0 schema and version written by FormatSARIF.
+const (
+	SARIFSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	SARIFVersion = "2.1.0"
+)
+
+// SARIF result levels.
+const (
+	SARIFLevelError   = "error"
+	SARIFLevelWarning = "warning"
+	SARIFLevelNote    = "note"
+)
+
+// SARIFProvider is implemented by result data that can be rendered as a
+// SARIF log, e.g. for GitHub code scanning. Other data falls back to JSON.
+type SARIFProvider interface {
+	SARIF() *SARIFLog
+}
+
+// SARIFLog is the subset of the SARIF 2.1.0 log format gpd emits.
+type SARIFLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []SARIFRun `json:"runs"`
+}
+
+// SARIFRun is one tool invocation.
+type SARIFRun struct {
+	Tool    SARIFTool     `json:"tool"`
+	Results []SARIFResult `json:"results"`
+}
+
+// SARIFTool describes the analysis tool.
+type SARIFTool struct {
+	Driver SARIFDriver `json:"driver"`
+}
+
+// SARIFDriver is the tool component that produced the results.
+type SARIFDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []SARIFRule `json:"rules"`
+}
+
+// SARIFRule is a reporting descriptor for one rule.
+type SARIFRule struct {
+	ID                   string             `json:"id"`
+	Name                 string             `json:"name,omitempty"`
+	ShortDescription     SARIFMessage       `json:"shortDescription"`
+	FullDescription      *SARIFMessage      `json:"fullDescription,omitempty"`
+	Help                 *SARIFMessage      `json:"help,omitempty"`
+	DefaultConfiguration SARIFConfiguration `json:"defaultConfiguration"`
+}
+
+// SARIFConfiguration is a rule's default reporting configuration.
+type SARIFConfiguration struct {
+	Level string `json:"level"`
+}
+
+// SARIFMessage is a plain-text message.
+type SARIFMessage struct {
+	Text string `json:"text"`
+}
+
+// SARIFResult is one finding.
+type SARIFResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   SARIFMessage    `json:"message"`
+	Locations []SARIFLocation `json:"locations,omitempty"`
+}
+
+// SARIFLocation points at the file, and optionally the logical element,
+// a result is about.
+type SARIFLocation struct {
+	PhysicalLocation *SARIFPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []SARIFLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+// SARIFPhysicalLocation is a file location.
+type SARIFPhysicalLocation struct {
+	ArtifactLocation SARIFArtifactLocation `json:"artifactLocation"`
+}
+
+// SARIFArtifactLocation is a file URI, relative to the repository root for
+// code scanning.
+type SARIFArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// SARIFLogicalLocation names a program element such as a manifest component.
+type SARIFLogicalLocation struct {
+	Name               string `json:"name,omitempty"`
+	FullyQualifiedName string `json:"fullyQualifiedName,omitempty"`
+	Kind               string `json:"kind,omitempty"`
+}
+
+// writeSARIF writes the SARIF log of a SARIFProvider. Errors and data
+// without a SARIF rendering are written as JSON.
+func (m *Manager) writeSARIF(r *Result) error {
+	if r.Error != nil {
+		return m.writeJSON(r)
+	}
+	provider, ok := r.Data.(SARIFProvider)
+	if !ok {
+		return m.writeJSON(r)
+	}
+	data, err := json.MarshalIndent(provider.SARIF(), "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(m.writer, string(data))
+	return err
+}
@@ -0,0 +1,117 @@
+// Package proguard reads R8 and ProGuard mapping files: the header R8 writes
+// to identify a mapping (pg_map_id, pg_map_hash), used to check that a
+// mapping belongs to a build before it is uploaded, and the class and
+// member mappings used to retrace obfuscated stack traces offline.
+package proguard
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Header is the comment block at the top of a mapping file. R8 writes
+// lines such as:
+//
+//	# compiler: R8
+//	# compiler_version: 8.5.35
+//	# min_api: 24
+//	# pg_map_id: 2d8c6ba
+//	# pg_map_hash: SHA-256 2d8c6ba4...
+//
+// ProGuard and older R8 versions write no map id; ContentSHA256 then
+// identifies the mapping.
+type Header struct {
+	Compiler        string `json:"compiler,omitempty"`
+	CompilerVersion string `json:"compilerVersion,omitempty"`
+	MinAPI          string `json:"minApi,omitempty"`
+	MapID           string `json:"pgMapId,omitempty"`
+	// MapHash is the pg_map_hash value including its algorithm, e.g.
+	// "SHA-256 2d8c...".
+	MapHash string `json:"pgMapHash,omitempty"`
+	// ContentSHA256 is the hex SHA-256 of the whole file.
+	ContentSHA256 string `json:"contentSha256"`
+	Size          int64  `json:"size"`
+}
+
+// ReadHeader reads a mapping file to the end, parsing its leading comment
+// block and hashing the full content.
+func ReadHeader(r io.Reader) (Header, error) {
+	var h Header
+	hash := sha256.New()
+	br := bufio.NewReader(io.TeeReader(r, hash))
+	inHeader := true
+	for {
+		line, err := br.ReadString('\n')
+		h.Size += int64(len(line))
+		if inHeader && line != "" {
+			inHeader = h.parseLine(line)
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Header{}, fmt.Errorf("read mapping: %w", err)
+		}
+	}
+	h.ContentSHA256 = hex.EncodeToString(hash.Sum(nil))
+	return h, nil
+}
+
+// parseLine records a header field and reports whether the header
+// continues past line.
+func (h *Header) parseLine(line string) bool {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return true
+	}
+	comment, ok := strings.CutPrefix(line, "#")
+	if !ok {
+		return false
+	}
+	key, value, ok := strings.Cut(comment, ":")
+	if !ok {
+		return true
+	}
+	value = strings.TrimSpace(value)
+	switch strings.TrimSpace(key) {
+	case "compiler":
+		h.Compiler = value
+	case "compiler_version":
+		h.CompilerVersion = value
+	case "min_api":
+		h.MinAPI = value
+	case "pg_map_id":
+		h.MapID = value
+	case "pg_map_hash":
+		h.MapHash = value
+	}
+	return true
+}
+
+// Identity returns the strongest identifier the header has and what it is:
+// the pg_map_hash, else the pg_map_id, else the content hash.
+func (h Header) Identity() (basis, value string) {
+	switch {
+	case h.MapHash != "":
+		return "pg_map_hash", h.MapHash
+	case h.MapID != "":
+		return "pg_map_id", h.MapID
+	}
+	return "sha256", h.ContentSHA256
+}
+
+// Match reports whether two mapping files describe the same build,
+// comparing the strongest identifier both headers have, and names it.
+func Match(a, b Header) (bool, string) {
+	switch {
+	case a.MapHash != "" && b.MapHash != "":
+		return strings.EqualFold(a.MapHash, b.MapHash), "pg_map_hash"
+	case a.MapID != "" && b.MapID != "":
+		return strings.EqualFold(a.MapID, b.MapID), "pg_map_id"
+	}
+	return a.ContentSHA256 == b.ContentSHA256, "sha256"
+}
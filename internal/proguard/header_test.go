@@ -0,0 +1,68 @@
+//go:build unit
+// +build unit
+
+package proguard
+
+import (
+	"strings"
+	"testing"
+)
+
+const r8Mapping = `# compiler: R8
+# compiler_version: 8.5.35
+# min_api: 24
+# common_typos_disable
+# {"id":"com.android.tools.r8.mapping","version":"2.2"}
+# pg_map_id: 2d8c6ba
+# pg_map_hash: SHA-256 2d8c6ba4f0e1
+com.example.app.MainActivity -> com.example.app.MainActivity:
+# pg_map_id: not-a-header-line
+    void onCreate(android.os.Bundle) -> onCreate
+`
+
+func TestReadHeader(t *testing.T) {
+	h, err := ReadHeader(strings.NewReader(r8Mapping))
+	if err != nil {
+		t.Fatalf("ReadHeader: %v", err)
+	}
+	want := Header{Compiler: "R8", CompilerVersion: "8.5.35", MinAPI: "24", MapID: "2d8c6ba", MapHash: "SHA-256 2d8c6ba4f0e1"}
+	if h.Compiler != want.Compiler || h.CompilerVersion != want.CompilerVersion || h.MinAPI != want.MinAPI ||
+		h.MapID != want.MapID || h.MapHash != want.MapHash {
+		t.Errorf("header = %+v", h)
+	}
+	if h.Size != int64(len(r8Mapping)) || len(h.ContentSHA256) != 64 {
+		t.Errorf("size = %d sha256 = %q", h.Size, h.ContentSHA256)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	read := func(s string) Header {
+		h, err := ReadHeader(strings.NewReader(s))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return h
+	}
+	base := read(r8Mapping)
+	otherBuild := read(strings.Replace(r8Mapping, "2d8c6ba4f0e1", "99aa00bb11cc", 1))
+	// ProGuard writes no ids: only identical content matches.
+	proguard := read("com.example.A -> a:\n")
+
+	tests := []struct {
+		name      string
+		a, b      Header
+		want      bool
+		wantBasis string
+	}{
+		{"same build", base, read(r8Mapping), true, "pg_map_hash"},
+		{"different hash", base, otherBuild, false, "pg_map_hash"},
+		{"id only", Header{MapID: "abc"}, Header{MapID: "ABC", ContentSHA256: "x"}, true, "pg_map_id"},
+		{"no ids, same content", proguard, read("com.example.A -> a:\n"), true, "sha256"},
+		{"no ids, different content", proguard, read("com.example.A -> b:\n"), false, "sha256"},
+	}
+	for _, tc := range tests {
+		if got, basis := Match(tc.a, tc.b); got != tc.want || basis != tc.wantBasis {
+			t.Errorf("%s: Match = %v (%s), want %v (%s)", tc.name, got, basis, tc.want, tc.wantBasis)
+		}
+	}
+}
@@ -0,0 +1,302 @@
+package proguard
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// R8 metadata ids, written as JSON comments after the class or member line
+// they apply to.
+const (
+	metaSourceFile      = "sourceFile"
+	metaSynthesized     = "com.android.tools.r8.synthesized"
+	metaOutline         = "com.android.tools.r8.outline"
+	metaOutlineCallsite = "com.android.tools.r8.outlineCallsite"
+	metaRewriteFrame    = "com.android.tools.r8.rewriteFrame"
+)
+
+// Mapping is a parsed mapping file, indexed for retracing.
+type Mapping struct {
+	Header Header
+	// classes is keyed by obfuscated name, original by original name.
+	classes  map[string]*class
+	original map[string]*class
+}
+
+type class struct {
+	name        string
+	obfuscated  string
+	sourceFile  string
+	synthesized bool
+	// methods holds the member ranges of each obfuscated method name in
+	// file order; consecutive ranges with the same obfuscated range form
+	// an inline stack, innermost first.
+	methods map[string][]*memberRange
+}
+
+// memberRange is one method line:
+//
+//	[obfStart:obfEnd:]returnType [class.]name(args)[:origStart[:origEnd]] -> obfName
+type memberRange struct {
+	obfStart, obfEnd   int
+	hasObfRange        bool
+	origStart, origEnd int
+	hasOrig            bool
+	// class is set when the method was inlined from another class.
+	class       string
+	returnType  string
+	name        string
+	args        string
+	synthesized bool
+	outline     bool
+	// outlinePositions maps positions in an outline to positions in this
+	// callsite's obfuscated range.
+	outlinePositions map[int]int
+	rewrites         []rewriteRule
+}
+
+type rewriteRule struct {
+	conditions       []string
+	removeInnerFrame int
+}
+
+// metadata is an R8 mapping comment, e.g.
+//
+//	# {"id":"sourceFile","fileName":"Main.kt"}
+type metadata struct {
+	ID         string         `json:"id"`
+	FileName   string         `json:"fileName"`
+	Conditions []string       `json:"conditions"`
+	Actions    []string       `json:"actions"`
+	Positions  map[string]int `json:"positions"`
+}
+
+// ParseFile parses the mapping file at path.
+func ParseFile(path string) (*Mapping, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	return Parse(f)
+}
+
+// Parse reads a ProGuard or R8 mapping file.
+func Parse(r io.Reader) (*Mapping, error) {
+	var header Header
+	m := &Mapping{classes: make(map[string]*class), original: make(map[string]*class)}
+	hash := sha256.New()
+	counted := &countingWriter{w: hash}
+
+	sc := bufio.NewScanner(io.TeeReader(r, counted))
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	var current *class
+	var last *memberRange
+	for n := 1; sc.Scan(); n++ {
+		line := sc.Text()
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			continue
+		case strings.HasPrefix(trimmed, "#"):
+			if current == nil {
+				header.parseLine(trimmed)
+				continue
+			}
+			m.applyMetadata(trimmed, current, last)
+			continue
+		}
+		if line[0] != ' ' && line[0] != '\t' {
+			c, err := parseClass(trimmed)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", n, err)
+			}
+			current, last = c, nil
+			m.classes[c.obfuscated] = c
+			m.original[c.name] = c
+			continue
+		}
+		if current == nil {
+			return nil, fmt.Errorf("line %d: member mapping before any class", n)
+		}
+		mr, obf, err := parseMember(trimmed)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		if mr == nil {
+			// Field mappings do not appear in stack traces; metadata after
+			// one is read into a range that is dropped.
+			last = &memberRange{}
+			continue
+		}
+		current.methods[obf] = append(current.methods[obf], mr)
+		last = mr
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read mapping: %w", err)
+	}
+	header.ContentSHA256 = hex.EncodeToString(hash.Sum(nil))
+	header.Size = counted.n
+	m.Header = header
+	return m, nil
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	c.n += int64(len(p))
+	return c.w.Write(p)
+}
+
+func parseClass(line string) (*class, error) {
+	orig, obf, ok := strings.Cut(strings.TrimSuffix(line, ":"), " -> ")
+	if !ok || !strings.HasSuffix(line, ":") {
+		return nil, fmt.Errorf("malformed class mapping %q", line)
+	}
+	return &class{
+		name:       strings.TrimSpace(orig),
+		obfuscated: strings.TrimSpace(obf),
+		methods:    make(map[string][]*memberRange),
+	}, nil
+}
+
+// parseMember parses a member line, returning nil for fields.
+func parseMember(line string) (*memberRange, string, error) {
+	i := strings.LastIndex(line, " -> ")
+	if i < 0 {
+		return nil, "", fmt.Errorf("malformed member mapping %q", line)
+	}
+	left, obf := line[:i], strings.TrimSpace(line[i+4:])
+	open := strings.IndexByte(left, '(')
+	if open < 0 {
+		return nil, obf, nil
+	}
+	closing := strings.LastIndexByte(left, ')')
+	if closing < open {
+		return nil, "", fmt.Errorf("malformed member mapping %q", line)
+	}
+	mr := &memberRange{args: left[open+1 : closing]}
+
+	// Leading "obfStart:obfEnd:" range.
+	sig := left[:open]
+	if start, rest, ok := cutNumber(sig); ok {
+		end, rest2, ok := cutNumber(rest)
+		if !ok {
+			end, rest2 = start, rest
+		}
+		mr.obfStart, mr.obfEnd, mr.hasObfRange = start, end, true
+		sig = rest2
+	}
+	returnType, name, ok := strings.Cut(strings.TrimSpace(sig), " ")
+	if !ok {
+		return nil, "", fmt.Errorf("malformed method signature %q", line)
+	}
+	mr.returnType = returnType
+	if dot := strings.LastIndexByte(name, '.'); dot >= 0 {
+		mr.class, name = name[:dot], name[dot+1:]
+	}
+	mr.name = name
+
+	// Trailing ":origStart[:origEnd]".
+	if tail := left[closing+1:]; tail != "" {
+		parts := strings.Split(strings.TrimPrefix(tail, ":"), ":")
+		start, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return nil, "", fmt.Errorf("malformed original line in %q", line)
+		}
+		mr.origStart, mr.origEnd, mr.hasOrig = start, start, true
+		if len(parts) > 1 {
+			if mr.origEnd, err = strconv.Atoi(parts[1]); err != nil {
+				return nil, "", fmt.Errorf("malformed original line in %q", line)
+			}
+		}
+	}
+	return mr, obf, nil
+}
+
+// cutNumber parses a leading "<digits>:".
+func cutNumber(s string) (int, string, bool) {
+	head, rest, ok := strings.Cut(s, ":")
+	if !ok {
+		return 0, s, false
+	}
+	n, err := strconv.Atoi(head)
+	if err != nil {
+		return 0, s, false
+	}
+	return n, rest, true
+}
+
+func (m *Mapping) applyMetadata(line string, current *class, last *memberRange) {
+	var md metadata
+	if err := json.Unmarshal([]byte(strings.TrimSpace(strings.TrimPrefix(line, "#"))), &md); err != nil || md.ID == "" {
+		return
+	}
+	if last == nil {
+		if current == nil {
+			return
+		}
+		switch md.ID {
+		case metaSourceFile:
+			current.sourceFile = md.FileName
+		case metaSynthesized:
+			current.synthesized = true
+		}
+		return
+	}
+	switch md.ID {
+	case metaSynthesized:
+		last.synthesized = true
+	case metaOutline:
+		last.outline = true
+	case metaOutlineCallsite:
+		last.outlinePositions = make(map[int]int, len(md.Positions))
+		for k, v := range md.Positions {
+			if pos, err := strconv.Atoi(k); err == nil {
+				last.outlinePositions[pos] = v
+			}
+		}
+	case metaRewriteFrame:
+		rule := rewriteRule{conditions: md.Conditions}
+		for _, action := range md.Actions {
+			if n, ok := strings.CutPrefix(action, "removeInnerFrames("); ok {
+				rule.removeInnerFrame, _ = strconv.Atoi(strings.TrimSuffix(n, ")"))
+			}
+		}
+		last.rewrites = append(last.rewrites, rule)
+	}
+}
+
+// originalLine maps an obfuscated line inside the range to the source.
+func (mr *memberRange) originalLine(line int) int {
+	switch {
+	case !mr.hasOrig:
+		return line
+	case mr.origEnd-mr.origStart == mr.obfEnd-mr.obfStart && mr.origEnd != mr.origStart:
+		return mr.origStart + line - mr.obfStart
+	}
+	return mr.origStart
+}
+
+func (mr *memberRange) contains(line int) bool {
+	return mr.hasObfRange && mr.obfStart <= line && line <= mr.obfEnd
+}
+
+// Class returns the original name of an obfuscated class, or name itself
+// when the mapping does not rename it.
+func (m *Mapping) Class(name string) string {
+	if c, ok := m.classes[name]; ok {
+		return c.name
+	}
+	return name
+}
@@ -0,0 +1,308 @@
+package proguard
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// frameRe matches "at [module//]class.method(source)" stack frames.
+	frameRe = regexp.MustCompile(`^(\s*at\s+)([^\s(]+)\.([^\s.(]+)\(([^)]*)\)(.*)$`)
+	// exceptionRe matches the line naming a thrown exception.
+	exceptionRe = regexp.MustCompile(`^(\s*(?:Exception in thread "[^"]*" |Caused by: |Suppressed: )?)([A-Za-z_$][\w$]*(?:\.[\w$]+)+)(:.*)?$`)
+)
+
+// Result is a retraced stack trace.
+type Result struct {
+	Text string `json:"text"`
+	// Frames counts stack frames read; Retraced counts those the mapping
+	// knew, whether rewritten, expanded into inline frames or removed.
+	Frames   int `json:"frames"`
+	Retraced int `json:"retraced"`
+}
+
+// frame is one source-level stack frame; line is 0 when unknown.
+type frame struct {
+	class, method, sourceFile string
+	line                      int
+	synthesized               bool
+}
+
+func (f frame) String() string {
+	if f.line > 0 {
+		return fmt.Sprintf("%s.%s(%s:%d)", f.class, f.method, f.sourceFile, f.line)
+	}
+	return fmt.Sprintf("%s.%s(%s)", f.class, f.method, f.sourceFile)
+}
+
+// Retrace rewrites the exception names and frames of a Java stack trace
+// to their original names. Inlined methods expand into one frame each;
+// frames R8 marks as synthesized are dropped from an expansion; outline
+// frames are folded into their callsite; and rewriteFrame rules remove
+// frames R8 inserted, e.g. for null checks. Frames the mapping cannot
+// resolve to one method are followed by "<OR> at" alternatives. Lines
+// that are not part of a trace are kept.
+func (m *Mapping) Retrace(text string) Result {
+	r := &retracer{m: m}
+	lines := strings.Split(text, "\n")
+	out := make([]string, 0, len(lines))
+	for _, line := range lines {
+		out = append(out, r.line(line)...)
+	}
+	return Result{Text: strings.Join(out, "\n"), Frames: r.frames, Retraced: r.retraced}
+}
+
+type retracer struct {
+	m *Mapping
+	// exception is the obfuscated class of the last exception header;
+	// topFrame is set until its first frame is read.
+	exception string
+	topFrame  bool
+	// outlinePos is the position inside an outline frame, resolved at the
+	// outline's callsite in the next frame.
+	outlinePos int
+	inOutline  bool
+	frames     int
+	retraced   int
+}
+
+func (r *retracer) line(line string) []string {
+	if mm := frameRe.FindStringSubmatch(line); mm != nil {
+		r.frames++
+		lines, ok := r.retraceFrame(mm)
+		r.topFrame = false
+		if !ok {
+			return []string{line}
+		}
+		r.retraced++
+		return lines
+	}
+	if mm := exceptionRe.FindStringSubmatch(line); mm != nil {
+		r.exception, r.topFrame, r.inOutline = mm[2], true, false
+		return []string{mm[1] + r.m.Class(mm[2]) + mm[3]}
+	}
+	return []string{line}
+}
+
+// retraceFrame retraces one frame line and reports whether the mapping
+// knew it.
+func (r *retracer) retraceFrame(mm []string) ([]string, bool) {
+	prefix, qualified, method, source, rest := mm[1], mm[2], mm[3], mm[4], mm[5]
+	if i := strings.LastIndex(qualified, "/"); i >= 0 {
+		prefix, qualified = prefix+qualified[:i+1], qualified[i+1:]
+	}
+	c, ok := r.m.classes[qualified]
+	if !ok {
+		r.inOutline = false
+		return nil, false
+	}
+	file, line := parseSource(source)
+	if file == "Native Method" {
+		r.inOutline = false
+		return []string{prefix + c.name + "." + method + "(" + source + ")" + rest}, true
+	}
+
+	ranges := c.methods[method]
+	if len(ranges) == 0 {
+		// The class was renamed but the method kept its name.
+		r.inOutline = false
+		f := frame{class: c.name, method: method, sourceFile: r.sourceFile(c, c.name, file), line: line}
+		return []string{prefix + f.String() + rest}, true
+	}
+
+	stacks := lookup(ranges, line)
+	if r.inOutline {
+		if pos, ok := callsitePosition(stacks, r.outlinePos); ok {
+			line = pos
+			stacks = lookup(ranges, line)
+		}
+		r.inOutline = false
+	}
+	if len(stacks) == 1 && stacks[0][0].outline {
+		r.outlinePos, r.inOutline = line, true
+		return nil, true
+	}
+
+	seen := make(map[string]bool)
+	out := make([]string, 0, len(stacks))
+	for i, stack := range stacks {
+		frames := r.expand(c, stack, line, file)
+		if r.topFrame {
+			frames = r.rewrite(stack, frames)
+		}
+		framePrefix := prefix
+		if i > 0 {
+			framePrefix = strings.Replace(prefix, "at ", "<OR> at ", 1)
+		}
+		for j, f := range frames {
+			s := f.String()
+			if j == 0 && seen[s] {
+				break
+			}
+			seen[s] = true
+			p := prefix
+			if j == 0 {
+				p = framePrefix
+			}
+			out = append(out, p+s+rest)
+		}
+	}
+	return out, true
+}
+
+// lookup returns the inline stacks that can produce line, one per
+// alternative. With no line, or a line no range covers, it returns one
+// stack per distinct method the obfuscated name can be.
+func lookup(ranges []*memberRange, line int) [][]*memberRange {
+	if line > 0 {
+		stacks := make([][]*memberRange, 0, 1)
+		prev := -2
+		for i, mr := range ranges {
+			if !mr.contains(line) {
+				continue
+			}
+			if n := len(stacks); n > 0 && prev == i-1 && sameRange(stacks[n-1][0], mr) {
+				stacks[n-1] = append(stacks[n-1], mr)
+			} else {
+				stacks = append(stacks, []*memberRange{mr})
+			}
+			prev = i
+		}
+		if len(stacks) > 0 {
+			return stacks
+		}
+		for _, mr := range ranges {
+			if !mr.hasObfRange {
+				stacks = append(stacks, []*memberRange{mr})
+			}
+		}
+		if len(stacks) > 0 {
+			return stacks
+		}
+	}
+
+	// The residual method is the outermost frame of each inline stack.
+	stacks := make([][]*memberRange, 0, 1)
+	seen := make(map[string]bool)
+	for i, mr := range ranges {
+		if i+1 < len(ranges) && mr.hasObfRange && sameRange(mr, ranges[i+1]) {
+			continue
+		}
+		key := mr.class + "." + mr.name + "(" + mr.args + ")"
+		if !seen[key] {
+			seen[key] = true
+			stacks = append(stacks, []*memberRange{{class: mr.class, name: mr.name, args: mr.args, synthesized: mr.synthesized}})
+		}
+	}
+	return stacks
+}
+
+func sameRange(a, b *memberRange) bool {
+	return a.hasObfRange == b.hasObfRange && a.obfStart == b.obfStart && a.obfEnd == b.obfEnd
+}
+
+// callsitePosition maps a position inside an outline to the obfuscated
+// position at its callsite.
+func callsitePosition(stacks [][]*memberRange, outlinePos int) (int, bool) {
+	for _, stack := range stacks {
+		for _, mr := range stack {
+			if pos, ok := mr.outlinePositions[outlinePos]; ok {
+				return pos, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// expand turns an inline stack into frames, innermost first, dropping
+// synthesized frames unless nothing else is left.
+func (r *retracer) expand(c *class, stack []*memberRange, line int, file string) []frame {
+	frames := make([]frame, 0, len(stack))
+	for _, mr := range stack {
+		name := c.name
+		if mr.class != "" {
+			name = mr.class
+		}
+		f := frame{class: name, method: mr.name, sourceFile: r.sourceFile(c, name, file), synthesized: mr.synthesized}
+		if oc := r.m.original[name]; oc != nil && oc.synthesized {
+			f.synthesized = true
+		}
+		if line > 0 && (mr.hasObfRange || !mr.hasOrig) {
+			f.line = mr.originalLine(line)
+		} else if mr.hasOrig {
+			f.line = mr.origStart
+		}
+		frames = append(frames, f)
+	}
+	kept := make([]frame, 0, len(frames))
+	for _, f := range frames {
+		if !f.synthesized {
+			kept = append(kept, f)
+		}
+	}
+	if len(kept) == 0 {
+		return frames
+	}
+	return kept
+}
+
+// rewrite applies the rewriteFrame rules of the top frame whose conditions
+// hold for the thrown exception.
+func (r *retracer) rewrite(stack []*memberRange, frames []frame) []frame {
+	thrown := "L" + strings.ReplaceAll(r.exception, ".", "/") + ";"
+	for _, mr := range stack {
+		for _, rule := range mr.rewrites {
+			if !rule.holds(thrown) || rule.removeInnerFrame <= 0 {
+				continue
+			}
+			if rule.removeInnerFrame >= len(frames) {
+				return frames[len(frames)-1:]
+			}
+			frames = frames[rule.removeInnerFrame:]
+		}
+	}
+	return frames
+}
+
+func (rule rewriteRule) holds(thrown string) bool {
+	if len(rule.conditions) == 0 {
+		return false
+	}
+	for _, cond := range rule.conditions {
+		desc, ok := strings.CutPrefix(cond, "throws(")
+		if !ok || strings.TrimSuffix(desc, ")") != thrown {
+			return false
+		}
+	}
+	return true
+}
+
+// sourceFile names the source of a frame in class name: the sourceFile
+// metadata, else the file in the trace when it is real and the frame is
+// in the obfuscated class itself, else <OuterClass>.java.
+func (r *retracer) sourceFile(c *class, name, traceFile string) string {
+	if oc := r.m.original[name]; oc != nil && oc.sourceFile != "" {
+		return oc.sourceFile
+	}
+	if name == c.name && traceFile != "" && traceFile != "SourceFile" && traceFile != "Unknown Source" {
+		return traceFile
+	}
+	simple := name[strings.LastIndexByte(name, '.')+1:]
+	if i := strings.IndexByte(simple, '$'); i > 0 {
+		simple = simple[:i]
+	}
+	return simple + ".java"
+}
+
+// parseSource splits "File.java:12" into the file and line; line is 0
+// when absent.
+func parseSource(source string) (string, int) {
+	if i := strings.LastIndexByte(source, ':'); i >= 0 {
+		if n, err := strconv.Atoi(source[i+1:]); err == nil {
+			return source[:i], n
+		}
+	}
+	return source, 0
+}
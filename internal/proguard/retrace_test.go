@@ -0,0 +1,154 @@
+//go:build unit
+// +build unit
+
+package proguard
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const appMapping = `# compiler: R8
+# compiler_version: 8.5.35
+# pg_map_id: 2d8c6ba
+# pg_map_hash: SHA-256 2d8c6ba4f0e1
+com.example.app.MainActivity -> a.a:
+# {"id":"sourceFile","fileName":"MainActivity.kt"}
+    int count -> a
+    # {"id":"com.android.tools.r8.synthesized"}
+    1:4:void onCreate(android.os.Bundle):20:23 -> onCreate
+    5:5:void com.example.app.Repo.load(java.lang.String):42:42 -> b
+    5:5:void refresh():31 -> b
+    6:8:void refresh():32:34 -> b
+    9:9:void com.example.app.Repo.check():50:50 -> b
+    9:9:void refresh():35 -> b
+    # {"id":"com.android.tools.r8.rewriteFrame","conditions":["throws(Ljava/lang/NullPointerException;)"],"actions":["removeInnerFrames(1)"]}
+    void save() -> c
+    void store() -> c
+    12:12:void com.example.app.MainActivity$$ExternalSyntheticLambda0.run():0:0 -> e
+    # {"id":"com.android.tools.r8.synthesized"}
+    12:12:void lambda$onCreate$0():60:60 -> e
+com.example.app.Repo -> a.b:
+    1:1:void fetch():10:10 -> a
+com.example.app.Outline -> a.c:
+    1:2:int outline0() -> a
+    # {"id":"com.android.tools.r8.outline"}
+com.example.app.Caller -> a.d:
+    4:4:int compute():70:70 -> a
+    # {"id":"com.android.tools.r8.outlineCallsite","positions":{"1":10,"2":11},"outline":"La/c;a()I"}
+    10:10:int compute():72:72 -> a
+    11:11:int compute():73:73 -> a
+`
+
+const obfuscatedTrace = `java.lang.IllegalStateException: boom
+	at a.b.a(SourceFile:1)
+	at a.a.b(SourceFile:5)
+	at a.a.b(SourceFile:7)
+	at a.a.c(Unknown Source)
+	at a.a.e(SourceFile:12)
+	at a.a.onCreate(SourceFile:3)
+	at android.app.Activity.performCreate(Activity.java:8000)
+Caused by: java.lang.NullPointerException
+	at a.a.b(SourceFile:9)
+	at a.c.a(SourceFile:2)
+	at a.d.a(SourceFile:4)
+	... 3 more`
+
+const retracedTrace = `java.lang.IllegalStateException: boom
+	at com.example.app.Repo.fetch(Repo.java:10)
+	at com.example.app.Repo.load(Repo.java:42)
+	at com.example.app.MainActivity.refresh(MainActivity.kt:31)
+	at com.example.app.MainActivity.refresh(MainActivity.kt:33)
+	at com.example.app.MainActivity.save(MainActivity.kt)
+	<OR> at com.example.app.MainActivity.store(MainActivity.kt)
+	at com.example.app.MainActivity.lambda$onCreate$0(MainActivity.kt:60)
+	at com.example.app.MainActivity.onCreate(MainActivity.kt:22)
+	at android.app.Activity.performCreate(Activity.java:8000)
+Caused by: java.lang.NullPointerException
+	at com.example.app.MainActivity.refresh(MainActivity.kt:35)
+	at com.example.app.Caller.compute(Caller.java:73)
+	... 3 more`
+
+func parseApp(t *testing.T) *Mapping {
+	t.Helper()
+	m, err := Parse(strings.NewReader(appMapping))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	return m
+}
+
+func TestParse(t *testing.T) {
+	m := parseApp(t)
+	if m.Header.MapID != "2d8c6ba" || m.Header.Compiler != "R8" || m.Header.Size != int64(len(appMapping)) {
+		t.Errorf("header = %+v", m.Header)
+	}
+	if got := m.Class("a.a"); got != "com.example.app.MainActivity" {
+		t.Errorf("Class(a.a) = %q", got)
+	}
+	if got := m.Class("java.lang.String"); got != "java.lang.String" {
+		t.Errorf("Class(java.lang.String) = %q", got)
+	}
+	// Metadata after a field belongs to the field, not the class.
+	if m.classes["a.a"].synthesized {
+		t.Error("MainActivity marked synthesized")
+	}
+
+	for _, bad := range []string{"com.example.A -> a\n", "    void a() -> b\n", "com.example.A -> a:\n    void a( -> b\n"} {
+		if _, err := Parse(strings.NewReader(bad)); err == nil {
+			t.Errorf("Parse(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestRetrace(t *testing.T) {
+	res := parseApp(t).Retrace(obfuscatedTrace)
+	if res.Text != retracedTrace {
+		t.Errorf("retraced:\n%s\nwant:\n%s", res.Text, retracedTrace)
+	}
+	if res.Frames != 10 || res.Retraced != 9 {
+		t.Errorf("frames = %d retraced = %d", res.Frames, res.Retraced)
+	}
+}
+
+func TestRetrace_RewriteOnlyForMatchingException(t *testing.T) {
+	res := parseApp(t).Retrace("java.lang.IllegalStateException\n\tat a.a.b(SourceFile:9)")
+	want := "java.lang.IllegalStateException\n" +
+		"\tat com.example.app.Repo.check(Repo.java:50)\n" +
+		"\tat com.example.app.MainActivity.refresh(MainActivity.kt:35)"
+	if res.Text != want {
+		t.Errorf("retraced:\n%s\nwant:\n%s", res.Text, want)
+	}
+}
+
+func TestStore(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("31/mapping.txt", appMapping)
+	write("mapping-32.txt", "com.example.Other -> b:\n")
+	write("33.txt", "not a mapping\n")
+
+	s := NewStore(dir)
+	if m, path, err := s.Mapping(31); err != nil || m == nil || m.Class("a.a") != "com.example.app.MainActivity" || !strings.HasSuffix(path, filepath.Join("31", "mapping.txt")) {
+		t.Errorf("Mapping(31) = %v, %q, %v", m, path, err)
+	}
+	if m, _, err := s.Mapping(32); err != nil || m == nil || m.Class("b") != "com.example.Other" {
+		t.Errorf("Mapping(32) = %v, %v", m, err)
+	}
+	if _, _, err := s.Mapping(33); err == nil {
+		t.Error("Mapping(33) parsed a malformed mapping")
+	}
+	if m, path, err := s.Mapping(34); m != nil || path != "" || err != nil {
+		t.Errorf("Mapping(34) = %v, %q, %v", m, path, err)
+	}
+}
@@ -0,0 +1,63 @@
+package proguard
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strconv"
+)
+
+// Store finds the mapping of each versionCode in a local directory laid
+// out as any of
+//
+//	<dir>/<versionCode>/mapping.txt
+//	<dir>/<versionCode>.txt
+//	<dir>/mapping-<versionCode>.txt
+//
+// and caches what it parsed, including versionCodes without a mapping.
+type Store struct {
+	Dir     string
+	entries map[int64]storeEntry
+}
+
+type storeEntry struct {
+	mapping *Mapping
+	path    string
+}
+
+// NewStore returns a store reading from dir.
+func NewStore(dir string) *Store {
+	return &Store{Dir: dir, entries: make(map[int64]storeEntry)}
+}
+
+// Candidates lists the paths checked for a versionCode, in order.
+func (s *Store) Candidates(versionCode int64) []string {
+	vc := strconv.FormatInt(versionCode, 10)
+	return []string{
+		filepath.Join(s.Dir, vc, "mapping.txt"),
+		filepath.Join(s.Dir, vc+".txt"),
+		filepath.Join(s.Dir, "mapping-"+vc+".txt"),
+	}
+}
+
+// Mapping returns the mapping for versionCode and its path, or nil when
+// the directory has none.
+func (s *Store) Mapping(versionCode int64) (*Mapping, string, error) {
+	if e, ok := s.entries[versionCode]; ok {
+		return e.mapping, e.path, nil
+	}
+	for _, path := range s.Candidates(versionCode) {
+		m, err := ParseFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, path, fmt.Errorf("%s: %w", path, err)
+		}
+		s.entries[versionCode] = storeEntry{mapping: m, path: path}
+		return m, path, nil
+	}
+	s.entries[versionCode] = storeEntry{}
+	return nil, "", nil
+}